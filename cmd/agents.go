@@ -25,6 +25,8 @@ var (
 	agentDefault      bool
 	agentAllowTools   string
 	agentDenyTools    string
+	agentAllowSkills  string
+	agentDenySkills   string
 )
 
 // agents list flags
@@ -93,6 +95,20 @@ var agentsAddCmd = &cobra.Command{
 				}
 			}
 		}
+		if agentAllowSkills != "" {
+			for _, s := range strings.Split(agentAllowSkills, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					entry.AllowSkills = append(entry.AllowSkills, s)
+				}
+			}
+		}
+		if agentDenySkills != "" {
+			for _, s := range strings.Split(agentDenySkills, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					entry.DenySkills = append(entry.DenySkills, s)
+				}
+			}
+		}
 
 		// If this is marked default, clear default on others
 		if agentDefault {
@@ -297,6 +313,8 @@ func init() {
 	agentsAddCmd.Flags().BoolVar(&agentDefault, "default", false, "Mark as default agent")
 	agentsAddCmd.Flags().StringVar(&agentAllowTools, "allow-tools", "", "Comma-separated tool whitelist")
 	agentsAddCmd.Flags().StringVar(&agentDenyTools, "deny-tools", "", "Comma-separated tool blacklist")
+	agentsAddCmd.Flags().StringVar(&agentAllowSkills, "allow-skills", "", "Comma-separated skill whitelist")
+	agentsAddCmd.Flags().StringVar(&agentDenySkills, "deny-skills", "", "Comma-separated skill blacklist")
 
 	// agents list flags
 	agentsListCmd.Flags().BoolVarP(&agentsListBindings, "bindings", "b", false, "Also show routing bindings")
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
+	"github.com/spf13/cobra"
+)
+
+var cronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Inspect scheduled tasks and edit their delivery targets",
+	Long: `Inspect scheduled tasks stored in ~/.lingti.db.
+
+Tasks are created by chatting with the bot (cron_create). Use these commands
+to see them and to change where their results are delivered. Changes apply to
+a running gateway on the task's next run.
+
+Target formats:
+  platform                               the chat the task was created from
+  platform:<name>:<channelID>[:<userID>] e.g. platform:feishu:oc_xxx
+  webhook:<url>                          POST JSON {job_id, job_name, text, timestamp}
+  file:<path>                            append results to a file`,
+}
+
+var cronListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled tasks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openCronStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		jobs, err := store.Load()
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			fmt.Println("No scheduled tasks.")
+			return nil
+		}
+		sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+
+		for _, job := range jobs {
+			status := "enabled"
+			if !job.Enabled {
+				status = "paused"
+			}
+			fmt.Printf("%s  %s\n", job.ID, job.Name)
+			if job.Schedule != "" {
+				fmt.Printf("  Schedule: %s (%s)\n", job.Schedule, status)
+			} else {
+				fmt.Printf("  Schedule: none (%s)\n", status)
+			}
+			if job.After != "" {
+				on := job.AfterOn
+				if on == "" {
+					on = cronpkg.AfterSuccess
+				}
+				fmt.Printf("  After: %s (on %s)\n", job.After, on)
+			}
+			if job.Platform != "" {
+				fmt.Printf("  Created from: %s/%s\n", job.Platform, job.ChannelID)
+			}
+			for _, t := range job.Targets {
+				fmt.Printf("  Target: %s\n", t)
+			}
+			if job.LastError != "" {
+				fmt.Printf("  Last error: %s\n", job.LastError)
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+var cronPreviewCount int
+
+var cronPreviewCmd = &cobra.Command{
+	Use:   "preview <schedule>",
+	Short: "Show how a schedule is understood and when it will fire",
+	Long: `Parse a cron expression or a natural-language schedule the same way
+cron_create does, and list the next fire times.
+
+Examples:
+  lingti-bot cron preview "every weekday at 9:30"
+  lingti-bot cron preview "每月最后一个周五下午五点" -n 5`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		parsed, err := cronpkg.ParseSchedule(strings.Join(args, " "), now)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schedule: %s\n", parsed.Spec)
+		fmt.Printf("Runs:     %s\n", parsed.Description)
+		fmt.Println("Next runs:")
+		for _, t := range parsed.NextRuns(now, cronPreviewCount) {
+			fmt.Printf("  %s\n", t.Format("2006-01-02 15:04 (Mon)"))
+		}
+		return nil
+	},
+}
+
+var cronTargetsCmd = &cobra.Command{
+	Use:   "targets <id>",
+	Short: "Show a task's delivery targets",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editCronTargets(args[0], func(job *cronpkg.Job, _ []cronpkg.Target) ([]cronpkg.Target, bool) {
+			return job.Targets, false
+		}, nil)
+	},
+}
+
+var cronTargetsAddCmd = &cobra.Command{
+	Use:   "add <id> <target>...",
+	Short: "Add delivery targets to a task",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editCronTargets(args[0], func(job *cronpkg.Job, changes []cronpkg.Target) ([]cronpkg.Target, bool) {
+			return append(job.Targets, changes...), true
+		}, args[1:])
+	},
+}
+
+var cronTargetsRemoveCmd = &cobra.Command{
+	Use:     "rm <id> <target>...",
+	Aliases: []string{"remove"},
+	Short:   "Remove delivery targets from a task",
+	Args:    cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editCronTargets(args[0], func(job *cronpkg.Job, changes []cronpkg.Target) ([]cronpkg.Target, bool) {
+			var kept []cronpkg.Target
+			for _, t := range job.Targets {
+				if !containsTarget(changes, t) {
+					kept = append(kept, t)
+				}
+			}
+			return kept, true
+		}, args[1:])
+	},
+}
+
+var cronTargetsSetCmd = &cobra.Command{
+	Use:   "set <id> [target]...",
+	Short: "Replace a task's delivery targets (no targets = back to the originating chat)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editCronTargets(args[0], func(_ *cronpkg.Job, changes []cronpkg.Target) ([]cronpkg.Target, bool) {
+			return changes, true
+		}, args[1:])
+	},
+}
+
+// editCronTargets loads a job, applies edit to its targets, saves it if
+// changed, and prints the resulting target list.
+func editCronTargets(id string, edit func(job *cronpkg.Job, changes []cronpkg.Target) ([]cronpkg.Target, bool), specs []string) error {
+	changes, err := cronpkg.ParseTargets(specs)
+	if err != nil {
+		return err
+	}
+
+	store, err := openCronStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	job, err := store.LoadJob(id)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return fmt.Errorf("task not found: %s", id)
+	}
+
+	targets, changed := edit(job, changes)
+	if changed {
+		// Only the targets column is written, so a running gateway's run
+		// state for the job isn't overwritten
+		if err := store.SetTargets(job.ID, targets); err != nil {
+			return fmt.Errorf("failed to save task: %w", err)
+		}
+	}
+
+	if len(targets) == 0 {
+		fmt.Printf("%s (%s): no explicit targets, results go to %s/%s\n", job.ID, job.Name, job.Platform, job.ChannelID)
+		return nil
+	}
+	fmt.Printf("%s (%s) targets:\n", job.ID, job.Name)
+	for _, t := range targets {
+		fmt.Printf("  %s\n", t)
+	}
+	return nil
+}
+
+func containsTarget(targets []cronpkg.Target, t cronpkg.Target) bool {
+	for _, c := range targets {
+		if c == t {
+			return true
+		}
+	}
+	return false
+}
+
+// openCronStore opens the job database shared with the gateway
+func openCronStore() (*cronpkg.Store, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return cronpkg.NewStore(filepath.Join(homeDir, ".lingti.db"))
+}
+
+func init() {
+	rootCmd.AddCommand(cronCmd)
+	cronCmd.AddCommand(cronListCmd)
+	cronCmd.AddCommand(cronPreviewCmd)
+	cronCmd.AddCommand(cronTargetsCmd)
+	cronTargetsCmd.AddCommand(cronTargetsAddCmd)
+	cronTargetsCmd.AddCommand(cronTargetsRemoveCmd)
+	cronTargetsCmd.AddCommand(cronTargetsSetCmd)
+
+	cronPreviewCmd.Flags().IntVarP(&cronPreviewCount, "count", "n", 5, "Number of upcoming runs to show")
+}
@@ -11,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pltanton/lingti-bot/internal/agent/mcpclient"
 	"github.com/pltanton/lingti-bot/internal/config"
+	"github.com/pltanton/lingti-bot/internal/skills"
 	"github.com/spf13/cobra"
 )
 
@@ -117,24 +119,13 @@ func runDoctor(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	// 7. MCP servers
+	// 7. MCP servers: connect to each one as the agent would
 	if cfg != nil && len(cfg.AI.MCPServers) > 0 {
-		for _, s := range cfg.AI.MCPServers {
-			if s.URL != "" {
-				ok := checkURL(s.URL)
-				status := "reachable"
-				if !ok {
-					status = "unreachable"
-				}
-				results = append(results, checkResult{fmt.Sprintf("MCP server: %s", s.Name), ok, status})
-			} else if s.Command != "" {
-				if _, err := exec.LookPath(s.Command); err != nil {
-					results = append(results, checkResult{fmt.Sprintf("MCP server: %s", s.Name), false, fmt.Sprintf("command %q not found", s.Command)})
-				} else {
-					results = append(results, checkResult{fmt.Sprintf("MCP server: %s", s.Name), true, fmt.Sprintf("command %q available", s.Command)})
-				}
-			}
+		m := mcpclient.New(mcpServerConfigs(cfg))
+		for _, st := range m.Status() {
+			results = append(results, checkResult{fmt.Sprintf("MCP server: %s (%s)", st.Name, st.Transport), st.State == mcpclient.StateConnected, st.Summary()})
 		}
+		m.Close()
 	}
 
 	// 8. Temp dir writable
@@ -162,11 +153,54 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Printf("\n%d passed, %d failed\n", passed, failed)
+
+	offerSkillSetup()
+
 	if failed > 0 {
 		os.Exit(1)
 	}
 }
 
+// offerSkillSetup lists skills whose missing binaries have an installer for
+// this OS and, when run interactively, offers to set each one up
+func offerSkillSetup() {
+	disabled, extraDirs := config.SkillsSettings()
+	report := skills.BuildStatusReport(disabled, extraDirs)
+
+	var plans []*skills.SetupPlan
+	for _, s := range report.MissingSkills() {
+		if plan, err := skills.PlanSetup(s); err == nil {
+			plans = append(plans, plan)
+		}
+	}
+	if len(plans) == 0 {
+		return
+	}
+
+	fmt.Println("\nSkills with installable requirements:")
+	for _, plan := range plans {
+		commands := make([]string, len(plan.Steps))
+		for i, step := range plan.Steps {
+			commands[i] = step.String()
+		}
+		fmt.Printf("  \033[33m!\033[0m %s — %s\n", plan.Skill.Name, strings.Join(commands, "; "))
+	}
+
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		fmt.Println("\nRun 'lingti-bot skills setup <name>' to install.")
+		return
+	}
+	fmt.Println()
+	for _, plan := range plans {
+		if !confirm(fmt.Sprintf("Set up %s now?", plan.Skill.Name)) {
+			continue
+		}
+		if err := runSetupPlan(plan, true, false); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+}
+
 func detectPlatforms(cfg *config.Config) []string {
 	var platforms []string
 	if cfg.Platforms.Slack.BotToken != "" {
@@ -274,15 +308,3 @@ func checkAIConnectivity(provider, apiKey, baseURL string) (bool, string) {
 	}
 	return true, fmt.Sprintf("reachable (HTTP %d)", resp.StatusCode)
 }
-
-func checkURL(url string) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return false
-	}
-	resp.Body.Close()
-	return true
-}
@@ -328,15 +328,28 @@ func runGateway(cmd *cobra.Command, args []string) {
 	cronNotifier := agent.NewRouterCronNotifier(r)
 	cronScheduler := cronpkg.NewScheduler(cronStore, aiAgent, aiAgent, cronNotifier)
 	aiAgent.SetCronScheduler(cronScheduler)
+	if savedCfg != nil {
+		cronScheduler.SetMaxConcurrent(savedCfg.Cron.MaxConcurrent)
+		cronScheduler.SetWebhookHosts(savedCfg.Cron.WebhookHosts)
+	}
+	cronScheduler.SetFileLimits(loadAllowedPaths(), loadDisableFileTools())
 	if err := cronScheduler.Start(); err != nil {
 		logger.Warn("Failed to start cron scheduler: %v", err)
 	}
 
+	skillRegistry := agent.NewSkillRegistry(aiAgent, "")
+	aiAgent.SetSkillRegistry(skillRegistry)
+	agent.SyncSkillSchedules(skillRegistry, cronScheduler)
+	skillCache := agent.NewSkillCache()
+	aiAgent.SetSkillCache(skillCache)
+
 	registerPlatforms(r)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	agent.WatchSkills(ctx, skillCache, skillRegistry, cronScheduler)
+
 	if err := r.Start(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting router: %v\n", err)
 		os.Exit(1)
@@ -364,6 +377,8 @@ func runGateway(cmd *cobra.Command, args []string) {
 			AuthToken:  gatewayAuthToken,
 			AuthTokens: gatewayAuthTokens,
 		})
+		gw.SetSender(r)
+		gw.SetFilePolicy(loadAllowedPaths(), loadDisableFileTools())
 
 		gw.SetMessageHandler(func(ctx context.Context, clientID, sessionID, text string) (<-chan gateway.ResponsePayload, error) {
 			respChan := make(chan gateway.ResponsePayload, 1)
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pltanton/lingti-bot/internal/agent/mcpclient"
+	"github.com/pltanton/lingti-bot/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Manage external MCP servers (ai.mcp_servers)",
+}
+
+// mcp add flags
+var (
+	mcpURL                string
+	mcpTransport          string
+	mcpHeaders            []string
+	mcpEnv                []string
+	mcpTimeout            int
+	mcpOAuthFlow          string
+	mcpOAuthTokenURL      string
+	mcpOAuthDeviceURL     string
+	mcpOAuthClientID      string
+	mcpOAuthClientSecret  string
+	mcpOAuthScopes        []string
+	mcpIncludeTools       []string
+	mcpExcludeTools       []string
+	mcpToolAliases        []string
+	mcpLazy               bool
+	mcpSampling           bool
+	mcpSamplingMaxTokens  int
+	mcpSamplingRequireYes bool
+	mcpForce              bool
+)
+
+var mcpAddCmd = &cobra.Command{
+	Use:   "add <name> [--url <url> | -- <command> [args...]]",
+	Short: "Add an external MCP server to ~/.lingti.yaml",
+	Example: `  lingti-bot mcp add chrome -- npx chrome-devtools-mcp@latest
+  lingti-bot mcp add github --url https://api.githubcopilot.com/mcp/ --transport http \
+    --header 'Authorization: Bearer ${GITHUB_TOKEN}'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry := config.MCPServerConfig{
+			Name:      args[0],
+			URL:       mcpURL,
+			Transport: mcpTransport,
+			Env:       mcpEnv,
+			Timeout:   mcpTimeout,
+
+			IncludeTools: mcpIncludeTools,
+			ExcludeTools: mcpExcludeTools,
+			Lazy:         mcpLazy,
+		}
+		if len(args) > 1 {
+			entry.Command, entry.Args = args[1], args[2:]
+		}
+		for _, h := range mcpHeaders {
+			name, value, ok := strings.Cut(h, ":")
+			if !ok {
+				name, value, ok = strings.Cut(h, "=")
+			}
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid --header %q, want \"Name: value\"", h)
+			}
+			if entry.Headers == nil {
+				entry.Headers = make(map[string]string)
+			}
+			entry.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		for _, a := range mcpToolAliases {
+			tool, alias, ok := strings.Cut(a, "=")
+			if !ok || tool == "" || alias == "" {
+				return fmt.Errorf("invalid --alias %q, want tool=alias", a)
+			}
+			if entry.ToolAliases == nil {
+				entry.ToolAliases = make(map[string]string)
+			}
+			entry.ToolAliases[tool] = alias
+		}
+		if mcpOAuthFlow != "" {
+			entry.OAuth = &config.MCPOAuthConfig{
+				Flow:          mcpOAuthFlow,
+				TokenURL:      mcpOAuthTokenURL,
+				DeviceAuthURL: mcpOAuthDeviceURL,
+				ClientID:      mcpOAuthClientID,
+				ClientSecret:  mcpOAuthClientSecret,
+				Scopes:        mcpOAuthScopes,
+			}
+		}
+		if mcpSampling {
+			entry.Sampling = &config.MCPSamplingConfig{
+				Allow:      true,
+				MaxTokens:  mcpSamplingMaxTokens,
+				RequireYes: mcpSamplingRequireYes,
+			}
+		} else if mcpSamplingMaxTokens != 0 || mcpSamplingRequireYes {
+			return fmt.Errorf("--sampling-max-tokens and --sampling-require-yes need --sampling")
+		}
+		if err := mcpServerConfig(entry).Validate(); err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		replaced := false
+		for i, s := range cfg.AI.MCPServers {
+			if s.Name == entry.Name {
+				if !mcpForce {
+					return fmt.Errorf("MCP server %q already exists (use --force to replace it)", entry.Name)
+				}
+				cfg.AI.MCPServers[i] = entry
+				replaced = true
+			}
+		}
+		if !replaced {
+			cfg.AI.MCPServers = append(cfg.AI.MCPServers, entry)
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		fmt.Printf("MCP server %q saved. Check it with 'lingti-bot mcp test %s'.\n", entry.Name, entry.Name)
+		return nil
+	},
+}
+
+var mcpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured MCP servers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if len(cfg.AI.MCPServers) == 0 {
+			fmt.Println("No MCP servers configured. Use 'lingti-bot mcp add <name>' to add one.")
+			return nil
+		}
+
+		fmt.Printf("%-12s  %-9s  %-20s  %-7s  %s\n", "NAME", "TRANSPORT", "AUTH", "TIMEOUT", "TARGET")
+		fmt.Printf("%-12s  %-9s  %-20s  %-7s  %s\n", "----", "---------", "----", "-------", "------")
+		for _, s := range cfg.AI.MCPServers {
+			target := s.URL
+			if s.Command != "" {
+				target = strings.Join(append([]string{s.Command}, s.Args...), " ")
+			}
+			timeout := "default"
+			if s.Timeout > 0 {
+				timeout = fmt.Sprintf("%ds", s.Timeout)
+			}
+			fmt.Printf("%-12s  %-9s  %-20s  %-7s  %s\n", s.Name, mcpServerConfig(s).EffectiveTransport(), mcpAuthSummary(s), timeout, target)
+		}
+		return nil
+	},
+}
+
+var mcpTestCmd = &cobra.Command{
+	Use:   "test [name...]",
+	Short: "Connect to MCP servers and list their tools",
+	Long: `Connect to each MCP server (all configured servers by default), list its
+tools and disconnect. Servers using the OAuth device flow are signed in
+interactively when they have no cached token.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		var servers []config.MCPServerConfig
+		if len(args) == 0 {
+			servers = cfg.AI.MCPServers
+		}
+		for _, name := range args {
+			s, ok := findMCPServer(cfg, name)
+			if !ok {
+				return fmt.Errorf("MCP server %q not found", name)
+			}
+			servers = append(servers, s)
+		}
+		if len(servers) == 0 {
+			fmt.Println("No MCP servers configured. Use 'lingti-bot mcp add <name>' to add one.")
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		failed := 0
+		for _, s := range servers {
+			tools, err := probeMCPServer(ctx, mcpServerConfig(s))
+			if err != nil {
+				fmt.Printf("✗ %s: %v\n", s.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s: %d tools\n", s.Name, len(tools))
+			for _, t := range tools {
+				desc, _, _ := strings.Cut(t.Description, "\n")
+				if len(desc) > 70 {
+					desc = desc[:67] + "..."
+				}
+				fmt.Printf("    %-40s  %s\n", t.FullName, desc)
+			}
+		}
+		if failed > 0 {
+			fmt.Printf("\n%d of %d MCP servers failed\n", failed, len(servers))
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+var mcpRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove an MCP server and its cached tokens",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		var kept []config.MCPServerConfig
+		for _, s := range cfg.AI.MCPServers {
+			if s.Name != name {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) == len(cfg.AI.MCPServers) {
+			return fmt.Errorf("MCP server %q not found", name)
+		}
+		cfg.AI.MCPServers = kept
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		if err := mcpclient.ForgetTokens(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove cached tokens: %v\n", err)
+		}
+
+		fmt.Printf("MCP server %q removed\n", name)
+		return nil
+	},
+}
+
+// probeMCPServer connects to a server, signing in first if it uses the
+// device flow and has no token yet
+func probeMCPServer(ctx context.Context, s mcpclient.ServerConfig) ([]mcpclient.Tool, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	tools, err := mcpclient.Probe(probeCtx, s)
+	cancel()
+	if err == nil || !errors.Is(err, mcpclient.ErrLoginRequired) {
+		return tools, err
+	}
+
+	fmt.Printf("… %s: sign-in required\n", s.Name)
+	err = mcpclient.Login(ctx, s, func(code mcpclient.DeviceCode) {
+		uri := code.VerificationURIComplete
+		if uri == "" {
+			uri = code.VerificationURI
+		}
+		fmt.Printf("  Open %s and enter the code %s\n", uri, code.UserCode)
+		fmt.Println("  Waiting for approval (Ctrl+C to cancel)...")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sign-in failed: %w", err)
+	}
+
+	probeCtx, cancel = context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return mcpclient.Probe(probeCtx, s)
+}
+
+// mcpAuthSummary describes how a server authenticates without showing secrets
+func mcpAuthSummary(s config.MCPServerConfig) string {
+	var parts []string
+	if s.OAuth != nil {
+		parts = append(parts, "oauth:"+s.OAuth.Flow)
+	}
+	if len(s.Headers) > 0 {
+		names := make([]string, 0, len(s.Headers))
+		for name := range s.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts = append(parts, strings.Join(names, ","))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
+
+func findMCPServer(cfg *config.Config, name string) (config.MCPServerConfig, bool) {
+	for _, s := range cfg.AI.MCPServers {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return config.MCPServerConfig{}, false
+}
+
+// mcpServerConfig converts an external MCP server in the config for mcpclient
+func mcpServerConfig(s config.MCPServerConfig) mcpclient.ServerConfig {
+	sc := mcpclient.ServerConfig{
+		Name:      s.Name,
+		Command:   s.Command,
+		Args:      s.Args,
+		Env:       s.Env,
+		URL:       s.URL,
+		Transport: s.Transport,
+		Headers:   s.Headers,
+		Timeout:   s.Timeout,
+
+		IncludeTools:     s.IncludeTools,
+		ExcludeTools:     s.ExcludeTools,
+		ToolAliases:      s.ToolAliases,
+		ToolDescriptions: s.ToolDescriptions,
+		Lazy:             s.Lazy,
+	}
+	if s.OAuth != nil {
+		sc.OAuth = &mcpclient.OAuthConfig{
+			Flow:          s.OAuth.Flow,
+			TokenURL:      s.OAuth.TokenURL,
+			DeviceAuthURL: s.OAuth.DeviceAuthURL,
+			ClientID:      s.OAuth.ClientID,
+			ClientSecret:  s.OAuth.ClientSecret,
+			Scopes:        s.OAuth.Scopes,
+		}
+	}
+	if s.Sampling != nil {
+		sc.Sampling = &mcpclient.SamplingPolicy{
+			Allow:      s.Sampling.Allow,
+			MaxTokens:  s.Sampling.MaxTokens,
+			RequireYes: s.Sampling.RequireYes,
+		}
+	}
+	return sc
+}
+
+// mcpServerConfigs converts the external MCP servers in the config for mcpclient
+func mcpServerConfigs(cfg *config.Config) []mcpclient.ServerConfig {
+	var servers []mcpclient.ServerConfig
+	for _, s := range cfg.AI.MCPServers {
+		servers = append(servers, mcpServerConfig(s))
+	}
+	return servers
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpAddCmd)
+	mcpCmd.AddCommand(mcpListCmd)
+	mcpCmd.AddCommand(mcpTestCmd)
+	mcpCmd.AddCommand(mcpRmCmd)
+
+	// mcp add flags
+	mcpAddCmd.Flags().StringVar(&mcpURL, "url", "", "URL of a remote MCP server")
+	mcpAddCmd.Flags().StringVar(&mcpTransport, "transport", "", "Transport: stdio, sse or http (default: stdio with a command, sse with --url)")
+	mcpAddCmd.Flags().StringArrayVar(&mcpHeaders, "header", nil, "HTTP header \"Name: value\"; values may be env:NAME, file:/path or contain ${NAME} (repeatable)")
+	mcpAddCmd.Flags().StringArrayVar(&mcpEnv, "env", nil, "Environment variable KEY=value for a stdio server (repeatable)")
+	mcpAddCmd.Flags().IntVar(&mcpTimeout, "timeout", 0, "Tool call timeout in seconds (default 120)")
+	mcpAddCmd.Flags().StringVar(&mcpOAuthFlow, "oauth-flow", "", "OAuth flow: client_credentials or device")
+	mcpAddCmd.Flags().StringVar(&mcpOAuthTokenURL, "oauth-token-url", "", "OAuth token endpoint")
+	mcpAddCmd.Flags().StringVar(&mcpOAuthDeviceURL, "oauth-device-url", "", "OAuth device authorization endpoint (device flow)")
+	mcpAddCmd.Flags().StringVar(&mcpOAuthClientID, "oauth-client-id", "", "OAuth client ID")
+	mcpAddCmd.Flags().StringVar(&mcpOAuthClientSecret, "oauth-client-secret", "", "OAuth client secret, or a secret reference such as env:NAME")
+	mcpAddCmd.Flags().StringSliceVar(&mcpOAuthScopes, "oauth-scope", nil, "OAuth scopes (comma-separated or repeatable)")
+	mcpAddCmd.Flags().StringSliceVar(&mcpIncludeTools, "include-tools", nil, "Only expose tools matching these globs, e.g. 'take_*,click'")
+	mcpAddCmd.Flags().StringSliceVar(&mcpExcludeTools, "exclude-tools", nil, "Hide tools matching these globs")
+	mcpAddCmd.Flags().StringArrayVar(&mcpToolAliases, "alias", nil, "Rename a tool: tool=alias exposes mcp_<name>_<alias> (repeatable)")
+	mcpAddCmd.Flags().BoolVar(&mcpLazy, "lazy", false, "Offer the tools only after the model calls mcp_list_tools")
+	mcpAddCmd.Flags().BoolVar(&mcpSampling, "sampling", false, "Let the server ask this bot's model for completions (MCP sampling)")
+	mcpAddCmd.Flags().IntVar(&mcpSamplingMaxTokens, "sampling-max-tokens", 0, "Max tokens per sampling request (default 1024)")
+	mcpAddCmd.Flags().BoolVar(&mcpSamplingRequireYes, "sampling-require-yes", false, "Refuse sampling requests unless the bot runs with --yes")
+	mcpAddCmd.Flags().BoolVar(&mcpForce, "force", false, "Replace an existing server with the same name")
+}
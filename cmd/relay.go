@@ -334,15 +334,7 @@ func runRelay(cmd *cobra.Command, args []string) {
 	// Load MCP server configs from yaml config file
 	var mcpServers []mcpclient.ServerConfig
 	if cfgErr == nil {
-		for _, s := range savedCfg.AI.MCPServers {
-			mcpServers = append(mcpServers, mcpclient.ServerConfig{
-				Name:    s.Name,
-				Command: s.Command,
-				Args:    s.Args,
-				Env:     s.Env,
-				URL:     s.URL,
-			})
-		}
+		mcpServers = mcpServerConfigs(savedCfg)
 	}
 
 	// Create the AI agent
@@ -393,10 +385,21 @@ func runRelay(cmd *cobra.Command, args []string) {
 	cronNotifier := agent.NewRouterCronNotifier(r)
 	cronScheduler := cronpkg.NewScheduler(cronStore, aiAgent, aiAgent, cronNotifier)
 	aiAgent.SetCronScheduler(cronScheduler)
+	if savedCfg != nil {
+		cronScheduler.SetMaxConcurrent(savedCfg.Cron.MaxConcurrent)
+		cronScheduler.SetWebhookHosts(savedCfg.Cron.WebhookHosts)
+	}
+	cronScheduler.SetFileLimits(loadAllowedPaths(), loadDisableFileTools())
 	if err := cronScheduler.Start(); err != nil {
 		log.Printf("Warning: Failed to start cron scheduler: %v", err)
 	}
 
+	skillRegistry := agent.NewSkillRegistry(aiAgent, "")
+	aiAgent.SetSkillRegistry(skillRegistry)
+	agent.SyncSkillSchedules(skillRegistry, cronScheduler)
+	skillCache := agent.NewSkillCache()
+	aiAgent.SetSkillCache(skillCache)
+
 	// Create and register relay platform
 	relayPlatformInstance, err := relay.New(relay.Config{
 		UserID:       relayUserID,
@@ -423,6 +426,8 @@ func runRelay(cmd *cobra.Command, args []string) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	agent.WatchSkills(ctx, skillCache, skillRegistry, cronScheduler)
+
 	if err := r.Start(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting relay: %v\n", err)
 		os.Exit(1)
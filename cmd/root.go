@@ -7,6 +7,7 @@ import (
 	"github.com/pltanton/lingti-bot/internal/config"
 	"github.com/pltanton/lingti-bot/internal/logger"
 	"github.com/pltanton/lingti-bot/internal/mcp"
+	"github.com/pltanton/lingti-bot/internal/skills"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +36,8 @@ It provides tools for:
 			return err
 		}
 		logger.SetLevel(level)
+		// Skills find binaries installed by `skills setup` downloads
+		skills.UseSetupBinDir()
 		return nil
 	},
 }
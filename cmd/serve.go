@@ -1,30 +1,214 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 
-	"github.com/mark3labs/mcp-go/server"
+	"github.com/pltanton/lingti-bot/internal/agent"
+	"github.com/pltanton/lingti-bot/internal/config"
+	"github.com/pltanton/lingti-bot/internal/gateway"
+	"github.com/pltanton/lingti-bot/internal/logger"
 	"github.com/pltanton/lingti-bot/internal/mcp"
+	"github.com/pltanton/lingti-bot/internal/router"
 	"github.com/spf13/cobra"
 )
 
+var (
+	serveTransport string
+	servePort      int
+	serveHost      string
+	serveToken     string
+	serveNoToken   bool
+
+	servePlatforms    bool
+	serveGateway      string
+	serveGatewayToken string
+)
+
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the MCP server",
-	Long:  `Start the MCP server and listen for requests via stdio.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		s := mcp.NewServer(loadSecurityOptions())
-		defer s.Stop()
-
-		// Serve over stdio (default MCP transport)
-		if err := server.ServeStdio(s.GetMCPServer()); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-	},
+	Long: `Start the MCP server.
+
+Transports:
+  stdio   one client over stdin/stdout (default)
+  sse     HTTP+SSE: clients connect to http://<host>:<port>/sse
+  http    Streamable HTTP: clients connect to http://<host>:<port>/mcp
+
+The sse and http transports serve any number of clients from one process,
+each in its own session; cron jobs are scheduled once for all of them.
+Clients must send "Authorization: Bearer <token>". Without --token a random
+token is generated once, saved in ~/.lingti/mcp-serve-token and printed at
+startup. --no-token turns authentication off, which is only allowed on a
+loopback address. Requests from web pages on other origins are refused.
+
+Besides the low-level tools, the ask_lingti tool hands a whole task to the
+agent (skills, browser rules, named agents) and reports each tool round as
+a progress notification. It is available when an AI provider is configured
+as for the gateway.
+
+With --platforms the server also connects the chat platforms configured for
+the gateway, or with --gateway it sends through a running gateway. Either
+way a send_message tool is offered and cron jobs with platform targets
+deliver their notifications; without them notifications are only logged.
+
+Defaults come from transport, port, host, token and messaging in
+~/.lingti.yaml.`,
+	Example: `  lingti-bot serve
+  lingti-bot serve --transport http --port 8686 --token s3cret
+  lingti-bot serve --gateway http://127.0.0.1:18789 --gateway-token s3cret`,
+	Run: runServe,
 }
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveTransport, "transport", "", "Transport: stdio, sse or http (default: transport in config, else stdio)")
+	serveCmd.Flags().IntVar(&servePort, "port", 0, "Port for the sse and http transports (default: port in config, else 8686)")
+	serveCmd.Flags().StringVar(&serveHost, "host", "", "Host for the sse and http transports (default: 127.0.0.1)")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Bearer token clients must send (or LINGTI_MCP_TOKEN env; default: generated)")
+	serveCmd.Flags().BoolVar(&serveNoToken, "no-token", false, "Serve the sse and http transports without a token (loopback addresses only)")
+	serveCmd.Flags().BoolVar(&servePlatforms, "platforms", false, "Connect the configured chat platforms for send_message and cron notifications")
+	serveCmd.Flags().StringVar(&serveGateway, "gateway", "", "Send chat messages through the gateway at this URL, e.g. http://127.0.0.1:18789")
+	serveCmd.Flags().StringVar(&serveGatewayToken, "gateway-token", "", "Auth token of the gateway (or GATEWAY_AUTH_TOKEN env)")
+}
+
+func runServe(_ *cobra.Command, _ []string) {
+	transport, port, host, token := serveTransport, servePort, serveHost, serveToken
+	if token == "" {
+		token = os.Getenv("LINGTI_MCP_TOKEN")
+	}
+	attachPlatforms, gatewayURL, gatewayToken := servePlatforms, serveGateway, serveGatewayToken
+	if gatewayToken == "" {
+		gatewayToken = os.Getenv("GATEWAY_AUTH_TOKEN")
+	}
+	resolveRouterEnvVars()
+	cfg, cfgErr := config.Load()
+	if cfgErr == nil {
+		applyRouterConfigFallbacks(cfg)
+		if transport == "" {
+			transport = cfg.Transport
+		}
+		if port == 0 {
+			port = cfg.Port
+		}
+		if host == "" {
+			host = cfg.Host
+		}
+		if token == "" {
+			token = cfg.Token
+		}
+		if !attachPlatforms && gatewayURL == "" {
+			attachPlatforms, gatewayURL = cfg.Messaging.Platforms, cfg.Messaging.Gateway
+		}
+		if gatewayToken == "" {
+			gatewayToken = cfg.Messaging.GatewayToken
+		}
+	}
+	if attachPlatforms && gatewayURL != "" {
+		fmt.Fprintln(os.Stderr, "Error: use either --platforms or --gateway, not both")
+		os.Exit(1)
+	}
+	if port == 0 {
+		port = mcp.DefaultPort
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	s := mcp.NewServer(loadSecurityOptions())
+	defer s.Stop()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool := newServeAgent(ctx, cfg)
+	if pool != nil {
+		s.SetAgent(pool)
+	}
+
+	switch {
+	case attachPlatforms:
+		r := newServeRouter(pool)
+		if err := r.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting platforms: %v\n", err)
+			os.Exit(1)
+		}
+		defer r.Stop()
+		s.SetMessenger(r)
+	case gatewayURL != "":
+		s.SetMessenger(gateway.NewRemote(gatewayURL, gatewayToken))
+		logger.Info("[MCP] Sending chat messages through the gateway at %s", gatewayURL)
+	}
+
+	if token == "" && !serveNoToken && (transport == mcp.TransportSSE || transport == mcp.TransportHTTP) {
+		var err error
+		if token, err = mcp.LoadOrCreateToken(mcp.TokenPath()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create a token: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "MCP clients must send \"Authorization: Bearer %s\" (saved in %s)\n", token, mcp.TokenPath())
+	}
+
+	opts := mcp.HTTPOptions{Addr: net.JoinHostPort(host, strconv.Itoa(port)), Token: token}
+	if err := s.Serve(ctx, transport, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newServeRouter attaches the configured chat platforms. Incoming messages
+// go to the agent when there is one and are ignored otherwise.
+func newServeRouter(pool *agent.AgentPool) *router.Router {
+	handler := func(_ context.Context, msg router.Message) (router.Response, error) {
+		logger.Info("[MCP] Ignoring message from %s/%s: no AI provider configured", msg.Platform, msg.Username)
+		return router.Response{}, nil
+	}
+	if pool != nil {
+		handler = pool.HandleMessage
+	}
+	r := router.New(handler)
+	registerPlatforms(r)
+	if len(r.Platforms()) == 0 {
+		logger.Warn("[MCP] --platforms is set but no chat platforms are configured")
+	}
+	return r
+}
+
+// newServeAgent builds the agent behind the ask_lingti tool, or returns nil
+// when no AI provider is configured
+func newServeAgent(ctx context.Context, savedCfg *config.Config) *agent.AgentPool {
+	if aiAPIKey == "" && !strings.EqualFold(aiProvider, "ollama") {
+		logger.Info("[MCP] No AI_API_KEY configured, ask_lingti is disabled")
+		return nil
+	}
+	agentCfg := agent.Config{
+		Provider:         aiProvider,
+		APIKey:           aiAPIKey,
+		BaseURL:          aiBaseURL,
+		Model:            aiModel,
+		AutoApprove:      IsAutoApprove(),
+		AllowedPaths:     loadAllowedPaths(),
+		DisableFileTools: loadDisableFileTools(),
+		CallTimeoutSecs:  aiCallTimeout,
+	}
+	aiAgent, err := agent.New(agentCfg)
+	if err != nil {
+		logger.Warn("[MCP] ask_lingti is disabled: %v", err)
+		return nil
+	}
+
+	skillRegistry := agent.NewSkillRegistry(aiAgent, "")
+	aiAgent.SetSkillRegistry(skillRegistry)
+	skillCache := agent.NewSkillCache()
+	aiAgent.SetSkillCache(skillCache)
+	agent.WatchSkills(ctx, skillCache, skillRegistry, nil)
+
+	return agent.NewAgentPool(aiAgent, agentCfg, savedCfg)
 }
@@ -1,10 +1,18 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 
+	"github.com/pltanton/lingti-bot/internal/agent"
 	"github.com/pltanton/lingti-bot/internal/config"
 	"github.com/pltanton/lingti-bot/internal/skills"
 	"github.com/spf13/cobra"
@@ -14,6 +22,33 @@ var (
 	skillsJSON     bool
 	skillsEligible bool
 	skillsVerbose  bool
+	skillsIndex    string
+	skillsForce    bool
+	skillsDryRun   bool
+	skillsYes      bool
+	skillsLive     bool
+	skillsRecord   bool
+	skillsStrict   bool
+)
+
+// skills new flags
+var (
+	skillsNewDescription string
+	skillsNewEmoji       string
+	skillsNewOS          []string
+	skillsNewBins        []string
+	skillsNewAnyBins     []string
+	skillsNewEnv         []string
+	skillsNewInstall     []string
+	skillsNewOpenClaw    bool
+	skillsNewDir         string
+)
+
+// skills pack/keygen and signature flags
+var (
+	skillsAllowUnsigned bool
+	skillsPackKey       string
+	skillsPackOut       string
 )
 
 var skillsCmd = &cobra.Command{
@@ -27,7 +62,14 @@ Skills are loaded from multiple directories in precedence order:
   3. Workspace skills (./skills/)
 
 Each skill is a directory containing a SKILL.md file with YAML frontmatter
-that declares requirements (binaries, env vars, OS) and metadata.`,
+that declares requirements (binaries, env vars, OS) and metadata.
+
+Trigger skills are JSON files in ~/.lingti/skills/ that run actions (shell,
+http, prompt, tool, workflow) when a message matches a command, pattern or
+keyword trigger, or on a schedule.
+
+Use 'skills install', 'update', 'remove' and 'search' to manage skills from
+git repos, archives, directories and a skill index.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runSkillsList(cmd, args)
 	},
@@ -49,7 +91,13 @@ var skillsInfoCmd = &cobra.Command{
 var skillsCheckCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check which skills are ready vs missing requirements",
-	Run:   runSkillsCheck,
+	Long: `Summarize which skills are ready, disabled, or missing requirements.
+
+With --strict, also validate the frontmatter of every SKILL.md in the skill
+directories — unknown or misspelled keys, wrong value types, unknown install
+kinds or OS names — including files that fail to load. Exits non-zero if
+any error is found.`,
+	Run: runSkillsCheck,
 }
 
 var skillsEnableCmd = &cobra.Command{
@@ -73,6 +121,134 @@ var skillsDownloadCmd = &cobra.Command{
 	Run:   runSkillsDownload,
 }
 
+var skillsInstallCmd = &cobra.Command{
+	Use:   "install <git-url|archive|dir|file.json|name>[@ref]",
+	Short: "Install skills from a git repo, archive, directory, or the skill index",
+	Long: `Install skills into ~/.lingti/skills/ and record them in its lockfile.
+
+Sources:
+  git URL     https://github.com/acme/skills.git, git@github.com:acme/skills.git
+  archive     .tar.gz, .tgz or .zip file or URL
+  directory   a local skill directory, or a directory of skill directories
+  file.json   a JSON trigger skill (file or URL)
+  name        a skill looked up in the skill index (skills.index in bot.yaml)
+
+Append @ref to pin a git branch, tag or commit, or an index version.
+Every skill found in the source is installed; existing skills with the same
+name are replaced. If any SKILL.md in the source has frontmatter errors (see
+'skills check --strict'), nothing is installed.
+
+Skills must be signed (see 'skills pack') by a publisher listed in
+skills.trusted_keys in ~/.lingti.yaml. A skill whose files don't match its
+signed manifest is always rejected; --allow-unsigned installs skills that
+are unsigned or signed by an untrusted publisher. JSON trigger skills can't
+be signed and always need --allow-unsigned.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSkillsInstall,
+}
+
+var skillsUpdateCmd = &cobra.Command{
+	Use:   "update [name...]",
+	Short: "Update installed skills from their recorded sources",
+	Long: `Reinstall skills from the sources recorded in the lockfile.
+
+Skills installed with a verified signature must still be signed by a
+trusted publisher; skills installed unsigned may stay unsigned.`,
+	Run: runSkillsUpdate,
+}
+
+var skillsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an installed skill",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSkillsRemove,
+}
+
+var skillsSearchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search the skill index",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runSkillsSearch,
+}
+
+var skillsSetupCmd = &cobra.Command{
+	Use:   "setup <name>",
+	Short: "Install a skill's missing binaries",
+	Long: `Run the installers declared in a skill's SKILL.md (brew, apt, go, npm,
+download) that work on this OS, then check that the required binaries are
+now in PATH.
+
+Downloaded binaries are placed in ~/.lingti/bin. Use --dry-run to print the
+commands without running them.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSkillsSetup,
+}
+
+var skillsTestCmd = &cobra.Command{
+	Use:   "test [name]",
+	Short: "Run a skill's test cases against the agent",
+	Long: `Run the test cases in SKILL.test.yaml next to a skill's SKILL.md (or of
+every skill that has one). Each case sends a prompt to a fresh agent and
+checks the tool calls it made and its final answer.
+
+By default the provider is replayed from the case's inline replay responses
+or from SKILL.test.replay.json, so runs are deterministic and need no API
+key. Use --live to run against the configured provider, and --record to
+also save its responses and the tool results for later replays. Replayed
+tool calls pass the agent's tool checks but return their recorded results
+instead of running (except skill_read); with --live, tools run for real.
+
+Exits non-zero if any case fails.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runSkillsTest,
+}
+
+var skillsNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Create a new skill from a template",
+	Long: `Create a skill directory with SKILL.md, an example script in scripts/ and a
+SKILL.test.yaml, then show the result as 'skills info' would.
+
+Frontmatter comes from the flags; in a terminal, fields not given as flags
+are asked for interactively (skip the questions with -y). Installers are
+given as kind:target, e.g. brew:jq, apt:jq, go:github.com/acme/tool,
+npm:tool or download:https://example.com/tool.tar.gz.
+
+The skill is created in ~/.lingti/skills/ unless --dir is set.`,
+	Example: `  lingti-bot skills new weather -d "Weather forecasts via wttr.in" --bins curl
+  lingti-bot skills new gh-review --bins gh --env GH_TOKEN --install brew:gh --os darwin,linux`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSkillsNew,
+}
+
+var skillsPackCmd = &cobra.Command{
+	Use:   "pack <dir>",
+	Short: "Package and sign a skill for distribution",
+	Long: `Validate a skill directory and write <name>-<version>.tar.gz containing the
+skill, a MANIFEST.json with the sha256 hash of every file, and MANIFEST.sig,
+the ed25519 signature of the manifest. The version comes from the SKILL.md
+frontmatter.
+
+Create a signing key with 'skills keygen' and share its public key with
+users, who add it to skills.trusted_keys in ~/.lingti.yaml.`,
+	Example: `  lingti-bot skills keygen acme
+  lingti-bot skills pack ./weather -o dist/`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSkillsPack,
+}
+
+var skillsKeygenCmd = &cobra.Command{
+	Use:   "keygen <publisher>",
+	Short: "Create an ed25519 key for signing skills",
+	Long: `Create an ed25519 key pair for signing skills with 'skills pack' and print
+the trusted_keys entry users add to ~/.lingti.yaml to accept them.
+
+The key is written to ~/.lingti/skills-signing.key unless --key is set.
+Keep it private.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSkillsKeygen,
+}
+
 func init() {
 	rootCmd.AddCommand(skillsCmd)
 
@@ -83,26 +259,56 @@ func init() {
 	skillsCmd.AddCommand(skillsEnableCmd)
 	skillsCmd.AddCommand(skillsDisableCmd)
 	skillsCmd.AddCommand(skillsDownloadCmd)
+	skillsCmd.AddCommand(skillsInstallCmd)
+	skillsCmd.AddCommand(skillsUpdateCmd)
+	skillsCmd.AddCommand(skillsRemoveCmd)
+	skillsCmd.AddCommand(skillsSearchCmd)
+	skillsCmd.AddCommand(skillsSetupCmd)
+	skillsCmd.AddCommand(skillsTestCmd)
+	skillsCmd.AddCommand(skillsNewCmd)
+	skillsCmd.AddCommand(skillsPackCmd)
+	skillsCmd.AddCommand(skillsKeygenCmd)
 
 	// Flags shared across subcommands
 	for _, cmd := range []*cobra.Command{skillsCmd, skillsListCmd, skillsInfoCmd, skillsCheckCmd} {
 		cmd.Flags().BoolVar(&skillsJSON, "json", false, "Output as JSON")
 	}
 
+	skillsCheckCmd.Flags().BoolVar(&skillsStrict, "strict", false, "Validate every SKILL.md and exit non-zero on errors")
 	skillsListCmd.Flags().BoolVar(&skillsEligible, "eligible", false, "Show only eligible (ready-to-use) skills")
 	skillsListCmd.Flags().BoolVarP(&skillsVerbose, "verbose", "v", false, "Show missing requirements details")
-}
 
-func loadSkillsConfig() ([]string, []string) {
-	cfg, err := config.Load()
-	if err != nil {
-		return nil, nil
+	for _, cmd := range []*cobra.Command{skillsInstallCmd, skillsUpdateCmd, skillsSearchCmd} {
+		cmd.Flags().StringVar(&skillsIndex, "index", "", "Skill index URL or file (default: skills.index in bot.yaml)")
 	}
-	return cfg.Skills.Disabled, cfg.Skills.ExtraDirs
+	skillsUpdateCmd.Flags().BoolVar(&skillsForce, "force", false, "Overwrite locally modified skills")
+	for _, cmd := range []*cobra.Command{skillsInstallCmd, skillsUpdateCmd} {
+		cmd.Flags().BoolVar(&skillsAllowUnsigned, "allow-unsigned", false, "Install skills that are unsigned or signed by an untrusted publisher")
+	}
+	for _, cmd := range []*cobra.Command{skillsPackCmd, skillsKeygenCmd} {
+		cmd.Flags().StringVar(&skillsPackKey, "key", "", "Signing key file (default: ~/.lingti/skills-signing.key)")
+	}
+	skillsPackCmd.Flags().StringVarP(&skillsPackOut, "output", "o", ".", "Directory to write the archive to")
+	skillsKeygenCmd.Flags().BoolVar(&skillsForce, "force", false, "Overwrite an existing key file")
+	skillsSearchCmd.Flags().BoolVar(&skillsJSON, "json", false, "Output as JSON")
+	skillsSetupCmd.Flags().BoolVar(&skillsDryRun, "dry-run", false, "Print the install commands without running them")
+	skillsSetupCmd.Flags().BoolVarP(&skillsYes, "yes", "y", false, "Skip the confirmation prompt")
+	skillsTestCmd.Flags().BoolVar(&skillsLive, "live", false, "Run against the configured AI provider instead of replaying")
+	skillsTestCmd.Flags().BoolVar(&skillsRecord, "record", false, "Run live and save the provider responses to SKILL.test.replay.json")
+
+	skillsNewCmd.Flags().StringVarP(&skillsNewDescription, "description", "d", "", "Short description of the skill")
+	skillsNewCmd.Flags().StringVar(&skillsNewEmoji, "emoji", "", "Emoji shown in skills list")
+	skillsNewCmd.Flags().StringSliceVar(&skillsNewOS, "os", nil, "Supported operating systems (darwin, linux, windows)")
+	skillsNewCmd.Flags().StringSliceVar(&skillsNewBins, "bins", nil, "Binaries that must all be in PATH")
+	skillsNewCmd.Flags().StringSliceVar(&skillsNewAnyBins, "any-bins", nil, "Binaries of which at least one must be in PATH")
+	skillsNewCmd.Flags().StringSliceVar(&skillsNewEnv, "env", nil, "Environment variables that must be set")
+	skillsNewCmd.Flags().StringArrayVar(&skillsNewInstall, "install", nil, "Installer as kind:target (repeatable)")
+	skillsNewCmd.Flags().BoolVar(&skillsNewOpenClaw, "openclaw", false, "Write metadata in the nested OpenClaw format")
+	skillsNewCmd.Flags().StringVar(&skillsNewDir, "dir", "", "Directory to create the skill in (default: ~/.lingti/skills)")
 }
 
 func runSkillsList(_ *cobra.Command, _ []string) {
-	disabled, extraDirs := loadSkillsConfig()
+	disabled, extraDirs := config.SkillsSettings()
 	report := skills.BuildStatusReport(disabled, extraDirs)
 	fmt.Println(skills.FormatList(report, skills.FormatListOptions{
 		JSON:     skillsJSON,
@@ -112,15 +318,22 @@ func runSkillsList(_ *cobra.Command, _ []string) {
 }
 
 func runSkillsInfo(_ *cobra.Command, args []string) {
-	disabled, extraDirs := loadSkillsConfig()
+	disabled, extraDirs := config.SkillsSettings()
 	report := skills.BuildStatusReport(disabled, extraDirs)
 	fmt.Println(skills.FormatInfo(report, args[0], skillsJSON))
 }
 
 func runSkillsCheck(_ *cobra.Command, _ []string) {
-	disabled, extraDirs := loadSkillsConfig()
+	disabled, extraDirs := config.SkillsSettings()
 	report := skills.BuildStatusReport(disabled, extraDirs)
-	fmt.Println(skills.FormatCheck(report, skillsJSON))
+	var diags []skills.Diagnostic
+	if skillsStrict {
+		diags = report.Lint()
+	}
+	fmt.Println(skills.FormatCheck(report, skillsJSON, skillsStrict, diags))
+	if skills.HasErrors(diags) {
+		os.Exit(1)
+	}
 }
 
 func runSkillsDownload(_ *cobra.Command, _ []string) {
@@ -133,6 +346,264 @@ func runSkillsDownload(_ *cobra.Command, _ []string) {
 	fmt.Printf("Downloaded %d skills to %s\n", count, config.SkillsDir())
 }
 
+// newSkillsInstaller creates an installer using --index or skills.index from
+// bot.yaml, trusting the publisher keys in skills.trusted_keys
+func newSkillsInstaller() *skills.Installer {
+	index := skillsIndex
+	var trusted map[string]string
+	if cfg, err := config.Load(); err == nil {
+		if index == "" {
+			index = cfg.Skills.Index
+		}
+		trusted = cfg.Skills.TrustedKeys
+	}
+	in := skills.NewInstaller(index)
+	in.TrustedKeys = trusted
+	in.AllowUnsigned = skillsAllowUnsigned
+	return in
+}
+
+func runSkillsInstall(_ *cobra.Command, args []string) {
+	results, err := newSkillsInstaller().Install(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, r := range results {
+		action := "Installed"
+		if r.Replaced {
+			action = "Replaced"
+		}
+		version := ""
+		if r.Version != "" {
+			version = " " + r.Version
+		}
+		signature := string(r.Signature)
+		if r.Publisher != "" {
+			signature += " by " + r.Publisher
+		}
+		fmt.Printf("%s %s%s (%s, %s)\n", action, r.Name, version, r.Kind, signature)
+		for _, w := range r.Warnings {
+			fmt.Printf("  warning: %s: %s\n", w.Field, w.Message)
+		}
+	}
+	fmt.Printf("%d skill(s) installed to %s\n", len(results), skills.ShortenHomePath(config.SkillsDir()))
+}
+
+func runSkillsUpdate(_ *cobra.Command, args []string) {
+	results, err := newSkillsInstaller().Update(args, skillsForce)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(results) == 0 {
+		fmt.Println("No installed skills recorded in the lockfile.")
+		return
+	}
+
+	failed := false
+	for _, r := range results {
+		switch r.Status {
+		case "updated":
+			fmt.Printf("  ✓ %s updated %s → %s\n", r.Name, orNone(r.OldVersion), orNone(r.NewVersion))
+		case "up to date":
+			fmt.Printf("  = %s up to date (%s)\n", r.Name, orNone(r.NewVersion))
+		default:
+			failed = failed || r.Status == "failed"
+			fmt.Printf("  ✗ %s %s: %s\n", r.Name, r.Status, r.Detail)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func orNone(version string) string {
+	if version == "" {
+		return "unversioned"
+	}
+	return version
+}
+
+func runSkillsRemove(_ *cobra.Command, args []string) {
+	if err := newSkillsInstaller().Remove(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Skill %q removed.\n", args[0])
+}
+
+func runSkillsSearch(_ *cobra.Command, args []string) {
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+	results, err := newSkillsInstaller().Search(query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if skillsJSON {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	if len(results) == 0 {
+		fmt.Println("No matching skills in the index.")
+		return
+	}
+	for _, r := range results {
+		fmt.Printf("  %-20s %-10s %s\n", r.Name, r.Version, r.Description)
+	}
+	fmt.Println("\nInstall with: lingti-bot skills install <name>[@version]")
+}
+
+func runSkillsSetup(_ *cobra.Command, args []string) {
+	disabled, extraDirs := config.SkillsSettings()
+	report := skills.BuildStatusReport(disabled, extraDirs)
+	skill, ok := report.Find(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: skill %q not found\n", args[0])
+		os.Exit(1)
+	}
+	if skill.Status == skills.StatusReady {
+		fmt.Printf("Skill %q is already ready.\n", skill.Name)
+		return
+	}
+
+	plan, err := skills.PlanSetup(*skill)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := runSetupPlan(plan, skillsYes, skillsDryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSetupPlan prints a setup plan and, unless dryRun, runs it after
+// confirmation and verifies the skill's binaries
+func runSetupPlan(plan *skills.SetupPlan, assumeYes, dryRun bool) error {
+	fmt.Printf("Setup for %s:\n", plan.Skill.Name)
+	for _, step := range plan.Steps {
+		fmt.Printf("  $ %s\n", step)
+	}
+	for _, skipped := range plan.Skipped {
+		fmt.Printf("  (skipped %s)\n", skipped)
+	}
+	if dryRun {
+		return nil
+	}
+	if !assumeYes && !confirm("Run these commands?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	if err := plan.Run(context.Background(), os.Stdout); err != nil {
+		return err
+	}
+	if missing := plan.Verify(); len(missing) > 0 {
+		return fmt.Errorf("installers finished but still not found: %s (downloads go to %s)",
+			strings.Join(missing, ", "), skills.ShortenHomePath(skills.SetupBinDir()))
+	}
+	fmt.Printf("✓ %s binaries installed\n", plan.Skill.Name)
+	if len(plan.Skill.Missing.Env) > 0 {
+		fmt.Printf("  still needs env: %s\n", strings.Join(plan.Skill.Missing.Env, ", "))
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func runSkillsTest(_ *cobra.Command, args []string) {
+	disabled, extraDirs := config.SkillsSettings()
+	report := skills.BuildStatusReport(disabled, extraDirs)
+
+	var suites []*skills.TestSuite
+	for _, s := range report.Skills {
+		if len(args) > 0 && s.Name != args[0] {
+			continue
+		}
+		suite, err := skills.LoadTestSuite(s.SkillEntry)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		suites = append(suites, suite)
+	}
+	if len(suites) == 0 {
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: skill %q has no %s\n", args[0], skills.TestFileName)
+			os.Exit(1)
+		}
+		fmt.Printf("No skills have a %s.\n", skills.TestFileName)
+		return
+	}
+
+	live := skillsLive || skillsRecord
+	resolveRouterEnvVars()
+	if savedCfg, err := config.Load(); err == nil {
+		applyRouterConfigFallbacks(savedCfg)
+	}
+	if live && aiAPIKey == "" && !strings.EqualFold(aiProvider, "ollama") {
+		fmt.Fprintln(os.Stderr, "Error: AI_API_KEY is required for --live and --record")
+		os.Exit(1)
+	}
+	agentCfg := agent.Config{
+		Provider:         aiProvider,
+		APIKey:           aiAPIKey,
+		BaseURL:          aiBaseURL,
+		Model:            aiModel,
+		AutoApprove:      IsAutoApprove(),
+		AllowedPaths:     loadAllowedPaths(),
+		DisableFileTools: loadDisableFileTools(),
+		CallTimeoutSecs:  aiCallTimeout,
+	}
+
+	passed, failed, skipped := 0, 0, 0
+	for _, suite := range suites {
+		fmt.Printf("%s (%s)\n", suite.Skill, skills.ShortenHomePath(suite.Path))
+		results, err := agent.RunSkillTests(context.Background(), agentCfg, suite, agent.SkillTestOptions{Live: live, Record: skillsRecord})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+		}
+		for _, r := range results {
+			switch r.Status {
+			case agent.SkillTestPass:
+				passed++
+				fmt.Printf("  ✓ %s\n", r.Case)
+			case agent.SkillTestSkip:
+				skipped++
+				fmt.Printf("  - %s (skipped: %s)\n", r.Case, strings.Join(r.Failures, "; "))
+			default:
+				failed++
+				fmt.Printf("  ✗ %s\n", r.Case)
+				for _, f := range r.Failures {
+					fmt.Printf("      %s\n", f)
+				}
+			}
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
 func runSkillsEnable(_ *cobra.Command, args []string) {
 	name := args[0]
 	cfg, err := config.Load()
@@ -177,3 +648,138 @@ func runSkillsDisable(_ *cobra.Command, args []string) {
 
 	fmt.Printf("Skill %q disabled.\n", name)
 }
+
+func runSkillsNew(cmd *cobra.Command, args []string) {
+	dir := skillsNewDir
+	if dir == "" {
+		dir = config.SkillsDir()
+	}
+	if err := skills.ValidateSkillName(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(filepath.Join(dir, args[0])); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists\n", filepath.Join(dir, args[0]))
+		os.Exit(1)
+	}
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 && !IsAutoApprove() {
+		askSkillFields(cmd)
+	}
+
+	opts := skills.ScaffoldOptions{
+		Name:        args[0],
+		Description: skillsNewDescription,
+		Emoji:       skillsNewEmoji,
+		OS:          skillsNewOS,
+		Bins:        skillsNewBins,
+		AnyBins:     skillsNewAnyBins,
+		Env:         skillsNewEnv,
+		OpenClaw:    skillsNewOpenClaw,
+	}
+	for _, s := range skillsNewInstall {
+		spec, err := skills.ParseInstallSpec(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Install = append(opts.Install, spec)
+	}
+
+	path, err := skills.Scaffold(dir, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created %s\n\n", skills.ShortenHomePath(filepath.Dir(path)))
+
+	// Show the new skill even if it was created outside the skill directories
+	disabled, extraDirs := config.SkillsSettings()
+	report := skills.BuildStatusReport(disabled, append(extraDirs, dir))
+	fmt.Println(skills.FormatInfo(report, opts.Name, false))
+	if found, ok := report.Find(opts.Name); ok && found.FilePath != path {
+		fmt.Printf("\nNote: %s is shadowed by %s\n", skills.ShortenHomePath(path), skills.ShortenHomePath(found.FilePath))
+	}
+}
+
+// askSkillFields prompts for the skill fields not given as flags
+func askSkillFields(cmd *cobra.Command) {
+	reader := bufio.NewReader(os.Stdin)
+	ask := func(flag, question string) string {
+		if cmd.Flags().Changed(flag) {
+			return ""
+		}
+		fmt.Printf("%s: ", question)
+		answer, _ := reader.ReadString('\n')
+		return strings.TrimSpace(answer)
+	}
+	list := func(answer string) []string {
+		var items []string
+		for _, item := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			items = append(items, item)
+		}
+		return items
+	}
+
+	if answer := ask("description", "Description"); answer != "" {
+		skillsNewDescription = answer
+	}
+	if answer := ask("emoji", "Emoji (optional)"); answer != "" {
+		skillsNewEmoji = answer
+	}
+	if answer := ask("bins", "Required binaries (comma-separated, optional)"); answer != "" {
+		skillsNewBins = list(answer)
+	}
+	if answer := ask("env", "Required environment variables (optional)"); answer != "" {
+		skillsNewEnv = list(answer)
+	}
+	if answer := ask("os", "Supported OS: darwin, linux, windows (empty = all)"); answer != "" {
+		skillsNewOS = list(answer)
+	}
+	if answer := ask("install", "Installers as kind:target, e.g. brew:jq (optional)"); answer != "" {
+		skillsNewInstall = list(answer)
+	}
+}
+
+// signingKeyPath returns --key or the default signing key file
+func signingKeyPath() string {
+	if skillsPackKey != "" {
+		return skillsPackKey
+	}
+	return filepath.Join(config.ConfigDir(), "skills-signing.key")
+}
+
+func runSkillsPack(_ *cobra.Command, args []string) {
+	key, err := skills.LoadSigningKey(signingKeyPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Create a key with: lingti-bot skills keygen <publisher>")
+		os.Exit(1)
+	}
+	path, err := skills.Pack(args[0], key, skillsPackOut)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Packed %s (signed by %s)\n", path, key.Publisher)
+}
+
+func runSkillsKeygen(_ *cobra.Command, args []string) {
+	path := signingKeyPath()
+	if _, err := os.Stat(path); err == nil && !skillsForce {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to replace it)\n", path)
+		os.Exit(1)
+	}
+	key, err := skills.GenerateSigningKey(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := key.Save(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Signing key written to %s\n\n", skills.ShortenHomePath(path))
+	fmt.Println("Users trust skills you sign by adding this to ~/.lingti.yaml:")
+	fmt.Printf("\nskills:\n  trusted_keys:\n    %s: %s\n", key.Publisher, key.PublicKey)
+}
@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"github.com/pltanton/lingti-bot/internal/agent/mcpclient"
-	"github.com/pltanton/lingti-bot/internal/config"
+	"github.com/pltanton/lingti-bot/internal/browser"
 	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
 	"github.com/pltanton/lingti-bot/internal/logger"
 	"github.com/pltanton/lingti-bot/internal/router"
@@ -20,6 +20,7 @@ import (
 
 // Agent processes messages using AI providers and tools
 type Agent struct {
+	id                 string // named agent ID; empty for the default agent
 	provider           Provider
 	memory             *ConversationMemory
 	sessions           *SessionStore
@@ -33,10 +34,16 @@ type Agent struct {
 	maxToolRounds      int
 	callTimeoutSecs    int
 	mcpManager         *mcpclient.Manager
+	allowTools         []string         // tool whitelist (globs); empty = allow all
+	denyTools          []string         // tool blacklist (globs); applied after allowlist
+	skillRegistry      *skills.Registry // trigger/action (JSON) skills
+	skillAccess        skills.Access    // skills this agent may see and use
+	skillCache         *skills.Cache    // SKILL.md skills kept up to date by a watcher (nil = scan per message)
 }
 
 // Config holds agent configuration
 type Config struct {
+	ID                 string // Named agent ID from the agents list (empty for the default agent)
 	Provider           string // "claude" or "deepseek" (default: "claude")
 	APIKey             string
 	BaseURL            string // Custom API base URL (optional)
@@ -48,8 +55,10 @@ type Config struct {
 	MaxToolRounds      int      // Max tool-call iterations per message (0 = use default 100)
 	CallTimeoutSecs    int      // Base timeout in seconds for each AI API call (0 = use default 90s base)
 	MCPServers         []mcpclient.ServerConfig // External MCP servers to connect to
-	AllowTools         []string // Tool whitelist; empty = allow all
-	DenyTools          []string // Tool blacklist; applied after allowlist
+	AllowTools         []string // Tool whitelist, names or globs like "browser_*"; empty = allow all
+	DenyTools          []string // Tool blacklist, names or globs; applied after allowlist
+	AllowSkills        []string // Skill whitelist; empty = all skills
+	DenySkills         []string // Skill blacklist; applied after allowlist
 	Workspace          string   // Working directory for this agent
 }
 
@@ -63,12 +72,17 @@ func New(cfg Config) (*Agent, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newAgent(cfg, provider), nil
+}
 
+// newAgent creates an Agent that talks to the given provider
+func newAgent(cfg Config, provider Provider) *Agent {
 	maxRounds := cfg.MaxToolRounds
 	if maxRounds <= 0 {
 		maxRounds = 100
 	}
-	return &Agent{
+	a := &Agent{
+		id:                 cfg.ID,
 		provider:           provider,
 		memory:             NewMemory(50, 60*time.Minute), // Keep 50 messages, 60 min TTL
 		sessions:           NewSessionStore(),
@@ -79,7 +93,12 @@ func New(cfg Config) (*Agent, error) {
 		maxToolRounds:      maxRounds,
 		callTimeoutSecs:    cfg.CallTimeoutSecs,
 		mcpManager:         mcpclient.New(cfg.MCPServers),
-	}, nil
+		allowTools:         cfg.AllowTools,
+		denyTools:          cfg.DenyTools,
+		skillAccess:        skills.Access{Allow: cfg.AllowSkills, Deny: cfg.DenySkills},
+	}
+	a.mcpManager.SetSampler(a)
+	return a
 }
 
 // openaiCompatProviders maps provider names to their default base URLs and models.
@@ -227,9 +246,9 @@ func (a *Agent) handleBuiltinCommand(msg router.Message) (router.Response, bool)
 - 历史消息: %d 条
 - 思考模式: %s
 - 详细模式: %v
-- AI 模型: %s`,
+- AI 模型: %s%s`,
 				msg.Platform, msg.Username, len(history),
-				settings.ThinkingLevel, settings.Verbose, a.provider.Name()),
+				settings.ThinkingLevel, settings.Verbose, a.provider.Name(), a.mcpStatusText()),
 		}, true
 
 	case "/model", "模型":
@@ -276,7 +295,10 @@ func (a *Agent) handleBuiltinCommand(msg router.Message) (router.Response, bool)
   system_info, shell_execute, process_list
 
 ⏰ 定时任务:
-  cron_create, cron_list, cron_delete, cron_pause, cron_resume` + formatSkillsSection()
+  cron_create, cron_list, cron_targets, cron_delete, cron_pause, cron_resume
+
+🧩 技能:
+  skill_read, skill_script` + formatSkillsSection(a.skillReport()) + a.skillChanges()
 		return router.Response{Text: toolsText}, true
 
 	case "/verbose on", "详细模式开":
@@ -314,6 +336,9 @@ func (a *Agent) SetCronScheduler(s *cronpkg.Scheduler) {
 
 // ExecuteTool implements the cron.ToolExecutor interface
 func (a *Agent) ExecuteTool(ctx context.Context, toolName string, arguments map[string]any) (any, error) {
+	if !a.toolAllowed(toolName) {
+		return nil, fmt.Errorf("tool %s is not available to this agent", toolName)
+	}
 	result := a.callToolDirect(ctx, toolName, arguments)
 	return result, nil
 }
@@ -346,11 +371,22 @@ func (a *Agent) HandleMessage(ctx context.Context, msg router.Message) (router.R
 		return resp, nil
 	}
 
+	// Trigger/action skills answer matching messages without calling the LLM
+	if resp, handled := a.handleSkillTrigger(ctx, msg); handled {
+		return resp, nil
+	}
+
+	// Tool calls this turn are limited to what the skills it reads declare
+	ctx = withSkillScope(ctx)
+
 	// Generate conversation key
 	convKey := ConversationKey(msg.Platform, msg.ChannelID, msg.UserID)
 
+	// Browser tools work in the conversation's (or agent's) own session
+	ctx = browser.WithSession(ctx, browser.Sessions().Key(convKey, a.id))
+
 	// Build the tools list
-	tools := a.buildToolsList()
+	tools := a.buildToolsList(convKey)
 
 	// Get conversation history
 	history := a.memory.GetHistory(convKey)
@@ -397,6 +433,9 @@ func (a *Agent) HandleMessage(ctx context.Context, msg router.Message) (router.R
 - For normal operations (file writes, reads, modifications), proceed immediately`
 	}
 
+	// Skills listed in (or inlined into) the system prompt
+	skillReport := a.skillReport()
+
 	// System prompt with actual paths
 	systemPrompt := fmt.Sprintf(`You are 灵缇 (Lingti), a helpful AI assistant running on the user's computer.%s
 
@@ -467,10 +506,15 @@ func (a *Agent) HandleMessage(ctx context.Context, msg router.Message) (router.R
 ### Scheduled Tasks (Cron)
 - cron_create: Create ONE scheduled task with 'prompt' parameter. The AI runs a full conversation each trigger (can use web_search, weather, etc.) and sends the result to the user. For raw tool execution, use 'tool'+'arguments' instead.
 - cron_list: List all scheduled tasks with their status
+- cron_targets: List or edit where a scheduled task delivers results (multiple chats, webhooks, files)
 - cron_delete: Delete a scheduled task by ID
 - cron_pause: Pause a scheduled task
 - cron_resume: Resume a paused scheduled task
 
+### Skills
+- skill_read: Load a skill's full SKILL.md instructions and file listing (do this before using a skill)
+- skill_script: Run a script from a skill's directory (e.g. scripts/fetch.py) with arguments
+
 ### Browser Automation (snapshot-then-act pattern)
 - browser_start: Start new browser or connect to existing Chrome via cdp_url (e.g. "127.0.0.1:9222")
 - browser_navigate: Navigate to a URL (auto-connects to Chrome on port 9222 if available, otherwise launches new)
@@ -480,6 +524,7 @@ func (a *Agent) HandleMessage(ctx context.Context, msg router.Message) (router.R
 - browser_press: Press keyboard key (Enter, Tab, Escape, etc.)
 - browser_execute_js: Run JavaScript on the page (dismiss modals, extract data, etc.)
 - browser_click_all: Click ALL elements matching a CSS selector with delay (batch like/follow)
+- browser_site_action: Run an action of the current site's adapter (listed under "Site" below when one matches)
 - browser_screenshot: Take page screenshot
 - browser_tabs: List all open tabs
 - browser_tab_open: Open new tab
@@ -514,37 +559,6 @@ Use the page's UI elements (search boxes, buttons, menus) to accomplish the task
 - Seeing a page snapshot in a tool result means: "here is the current state — what should I do next?"
 - If you see a login modal or any obstacle, handle it (dismiss, log in, or report to user) — do not silently stop.
 
-**Zhihu (知乎) commenting — EXACT VERIFIED METHOD:**
-Zhihu uses a Draft.js editor. Direct DOM manipulation (innerHTML, value=, execCommand insertText) does NOT update Draft.js internal state — the 发布 button will stay DISABLED. You MUST use ClipboardEvent paste.
-
-PREFERRED: call browser_comment_zhihu(comment="...") — handles everything automatically.
-To reply to a specific person's comment (nested reply), use browser_comment_zhihu(comment="...", reply_to="username"). The tool will find that user's 回复 button and post a nested reply instead of a top-level comment.
-
-If using mcp_chrome_evaluate_script instead, use EXACTLY this 3-step sequence:
-
-Step 1 — Click the comment trigger (button with 评论 in text):
-  function: "() => { var btn = Array.from(document.querySelectorAll('button,span')).find(function(e){ var t = e.textContent.replace(/\u200b/g,'').trim(); return /^[\d\s]*条?评论$/.test(t) || t === '添加评论'; }); if(btn){btn.click();return 'clicked:'+btn.textContent.trim();} return 'not found'; }"
-
-Step 2 — Wait ~1s for editor to appear, then paste via ClipboardEvent (CRITICAL — do NOT use execCommand or innerHTML):
-  function: "() => { var ed = document.querySelector('.public-DraftEditor-content'); if(!ed){return 'editor not found';} ed.click(); ed.focus(); document.execCommand('selectAll',false); var dt = new DataTransfer(); dt.setData('text/plain', 'COMMENT_TEXT'); ed.dispatchEvent(new ClipboardEvent('paste',{clipboardData:dt,bubbles:true,cancelable:true})); return 'pasted'; }"
-
-Step 3 — Wait ~600ms then click 发布 by text match (querySelector returns search button first, MUST find by text):
-  function: "() => { var btn = Array.from(document.querySelectorAll('button')).find(function(b){ return b.textContent.replace(/\u200b/g,'').trim() === '发布'; }); if(btn&&!btn.disabled){btn.click();return 'submitted';} if(btn&&btn.disabled){return 'button disabled — paste step likely failed';} return 'submit btn not found'; }"
-
-Replace COMMENT_TEXT with actual comment. If 发布 is disabled after paste, retry step 2 — the editor may not have focused properly.
-DO NOT click "写回答" — that writes a full answer, not a comment.
-
-**Xiaohongshu (小红书) commenting — EXACT VERIFIED METHOD:**
-Xiaohongshu uses a contenteditable <p id="content-textarea">. Direct DOM manipulation (textContent=, innerText=) does NOT update the framework state — the 发送 button stays DISABLED. You MUST use ClipboardEvent paste.
-
-PREFERRED: call browser_comment_xiaohongshu(comment="...") — handles everything automatically.
-
-If using mcp_chrome_evaluate_script instead, use EXACTLY this 2-step sequence:
-Step 1 — Paste comment via ClipboardEvent:
-  function: "() => { var ed = document.querySelector('#content-textarea'); if(!ed) return 'not found'; ed.focus(); ed.textContent=''; ed.dispatchEvent(new Event('input',{bubbles:true})); var dt = new DataTransfer(); dt.setData('text/plain', 'COMMENT_TEXT'); ed.dispatchEvent(new ClipboardEvent('paste',{clipboardData:dt,bubbles:true,cancelable:true})); return 'pasted'; }"
-Step 2 — Click 发送:
-  function: "() => { var btn = Array.from(document.querySelectorAll('button')).find(function(b){ return b.textContent.trim() === '发送'; }); if(btn&&!btn.disabled){btn.click();return 'submitted';} if(btn&&btn.disabled){return 'button disabled — paste step likely failed';} return 'submit btn not found'; }"
-
 **Handling modals/overlays:** If an element is blocked by a modal or overlay (error message mentions "element covered by"), use browser_execute_js to dismiss it. Example scripts:
 - document.querySelector('.modal-overlay').remove()
 - document.querySelector('.dialog-close-btn').click()
@@ -565,20 +579,6 @@ Do NOT waste rounds — try clicking first, inspect only if it fails.
 5. Navigate back to search results and continue with next article
 This prevents re-processing articles and survives page reloads within the same session.
 
-**CRITICAL: Xiaohongshu (小红书) anti-bot protection — NEVER navigate to XHS article URLs directly.**
-XHS blocks direct URL navigation and returns 404 security pages. You MUST open articles by CLICKING on them from the search results page.
-Correct workflow:
-1. Navigate to https://www.xiaohongshu.com and search via the search box
-2. On the search results page, use browser_execute_js to click on article covers/titles:
-   function: "() => { var items = document.querySelectorAll('section.note-item a.cover'); if(items.length > INDEX) { items[INDEX].click(); return 'clicked item INDEX'; } return 'not found'; }"
-   Replace INDEX with the 0-based index of the article to open.
-3. After the article detail opens (as an overlay/modal), perform actions (comment, like)
-4. Close the overlay by pressing Escape or clicking outside, then continue with the next article
-5. NEVER use browser_navigate to go to a xiaohongshu.com/explore/... URL — it WILL fail with 404
-
-**Xiaohongshu (小红书) like/点赞:** On a note detail page, click the heart icon. Use browser_execute_js:
-  function: "() => { var likeBtn = document.querySelector('.like-wrapper:not(.active), [class*=\"like\"]:not(.active)'); if(likeBtn){likeBtn.click();return 'liked';} return 'already liked or not found'; }"
-
 ## Important Rules
 1. **ALWAYS use tools** - Never tell users to do things manually
 2. **Be action-oriented** - Execute tasks, don't just describe them
@@ -590,19 +590,24 @@ Correct workflow:
 8. **CRITICAL: Cron job rules** - When user asks for periodic/scheduled tasks:
    - Call cron_create EXACTLY ONCE with the 'prompt' parameter.
    - Example: cron_create(name="motivation", schedule="43 * * * *", prompt="生成一条独特的编程激励鸡汤，鼓励用户写代码创造新产品")
-   - NEVER call cron_create multiple times. NEVER use shell_execute or file_write for cron tasks.
+   - For the schedule, pass the user's own wording (e.g. schedule="每月最后一个周五下午五点") instead of hand-writing cron, and confirm using the "Runs"/"Next runs" lines the tool returns.
+   - NEVER call cron_create multiple times (except for pipeline steps chained with 'after'). NEVER use shell_execute or file_write for cron tasks.
+   - For pipelines ("fetch the report, then summarize it"), schedule the first step and chain each next step with after=<previous task ID>; reference the previous result with {{output}}.
+   - For monitoring tasks ("tell me if the price drops"), set notify="on_change" or notify="on_match" with notify_condition so the user is only messaged when it matters.
 9. **Progress updates** — For iterative/multi-step tasks (e.g., commenting on multiple articles, processing a list), output a brief status message after each completed item (e.g., "✅ 已完成第3篇，继续下一篇"). The user will see these updates in real time.
 
-Current date: %s%s%s`, autoApprovalNotice, runtime.GOOS, runtime.GOARCH, homeDir, homeDir, homeDir, homeDir, msg.Username, time.Now().Format("2006-01-02"), thinkingPrompt, formatSkillsSection())
+Current date: %s%s%s`, autoApprovalNotice, runtime.GOOS, runtime.GOARCH, homeDir, homeDir, homeDir, homeDir, msg.Username, time.Now().Format("2006-01-02"), thinkingPrompt, formatSkillsSection(skillReport)+formatAlwaysSkills(skillReport))
 
 	if a.customInstructions != "" {
 		systemPrompt += "\n\n## Custom Instructions\n" + a.customInstructions
 	}
 
-	// Call AI provider
+	// Call AI provider. The current site's adapter section is recomputed each
+	// round, as tools may navigate elsewhere.
+	site := currentSite(ctx)
 	resp, err := a.provider.Chat(ctx, ChatRequest{
 		Messages:       messages,
-		SystemPrompt:   systemPrompt,
+		SystemPrompt:   systemPrompt + sitePrompt(site),
 		Tools:          tools,
 		MaxTokens:      4096,
 		ThinkingBudget: thinkingBudget,
@@ -621,7 +626,7 @@ Current date: %s%s%s`, autoApprovalNotice, runtime.GOOS, runtime.GOARCH, homeDir
 		}
 
 		// Process tool calls and track counts; detect stalls
-		stallHint := ""
+		hint := ""
 		for _, tc := range resp.ToolCalls {
 			toolCallCounts[tc.Name]++
 			count := toolCallCounts[tc.Name]
@@ -629,25 +634,36 @@ Current date: %s%s%s`, autoApprovalNotice, runtime.GOOS, runtime.GOARCH, homeDir
 				logger.Warn("[Agent] Tool %s called %d times (round %d/%d, user: %s)", tc.Name, count, round+1, maxToolRounds, msg.Username)
 			}
 			if count >= 3 && strings.HasPrefix(tc.Name, "browser_") {
-				stallHint = fmt.Sprintf(
-					"\n\n[SYSTEM HINT] You have called %s %d times in a row. STOP and use the dedicated comment tool instead. "+
-						"For Zhihu: call browser_comment_zhihu(comment=\"...\"). "+
-						"For Xiaohongshu: call browser_comment_xiaohongshu(comment=\"...\"). "+
-						"These handle everything automatically. Do NOT keep clicking buttons or interacting manually.",
-					tc.Name, count,
-				)
+				hint = stallHint(site, tc.Name, count)
 			}
 		}
 
 		toolResults, files := a.processToolCalls(ctx, resp.ToolCalls)
 		pendingFiles = append(pendingFiles, files...)
 
+		// Offer the MCP tools that mcp_list_tools just loaded
+		for _, tc := range resp.ToolCalls {
+			if tc.Name == mcpListToolsName {
+				tools = a.buildToolsList(convKey)
+				break
+			}
+		}
+
 		// Log tool results that look like errors
+		toolErrors := 0
 		for _, result := range toolResults {
 			if result.IsError || strings.HasPrefix(result.Content, "Error") {
+				toolErrors++
 				logger.Warn("[Agent] Tool error (round %d/%d): %s", round+1, maxToolRounds, result.Content)
 			}
 		}
+		if onRound := router.ToolRoundsFromContext(ctx); onRound != nil {
+			names := make([]string, len(resp.ToolCalls))
+			for i, tc := range resp.ToolCalls {
+				names[i] = tc.Name
+			}
+			onRound(router.ToolRound{Round: round + 1, MaxRounds: maxToolRounds, Tools: names, Errors: toolErrors})
+		}
 
 		// Add assistant response with tool calls
 		messages = append(messages, Message{
@@ -659,8 +675,8 @@ Current date: %s%s%s`, autoApprovalNotice, runtime.GOOS, runtime.GOARCH, homeDir
 
 		// Add tool results; append stall hint to last result if detected
 		for i, result := range toolResults {
-			if stallHint != "" && i == len(toolResults)-1 {
-				result.Content += stallHint
+			if hint != "" && i == len(toolResults)-1 {
+				result.Content += hint
 			}
 			messages = append(messages, Message{
 				Role:       "user",
@@ -689,9 +705,12 @@ Current date: %s%s%s`, autoApprovalNotice, runtime.GOOS, runtime.GOARCH, homeDir
 		}
 		callTimeout := baseTimeout + time.Duration(min(len(messages), 90))*time.Second
 		logger.Info("[Agent] Calling AI (round %d/%d, forceToolUse=%v, timeout=%s, user: %s)", round+2, maxToolRounds, hasBrowserTool, callTimeout, msg.Username)
+		if hasBrowserTool {
+			site = currentSite(ctx)
+		}
 		chatReq := ChatRequest{
 			Messages:       messages,
-			SystemPrompt:   systemPrompt,
+			SystemPrompt:   systemPrompt + sitePrompt(site),
 			Tools:          tools,
 			MaxTokens:      4096,
 			ForceToolUse:   hasBrowserTool,
@@ -756,21 +775,28 @@ Current date: %s%s%s`, autoApprovalNotice, runtime.GOOS, runtime.GOARCH, homeDir
 	return router.Response{Text: resp.Content, Files: pendingFiles}, nil
 }
 
-// formatSkillsSection returns a formatted string listing eligible skills, or empty if none.
-func formatSkillsSection() string {
-	cfg, err := config.Load()
-	var disabled, extraDirs []string
-	if err == nil {
-		disabled = cfg.Skills.Disabled
-		extraDirs = cfg.Skills.ExtraDirs
+// mcpStatusText lists the external MCP servers for /status, or "" if none are configured.
+func (a *Agent) mcpStatusText() string {
+	statuses := a.mcpManager.Status()
+	if len(statuses) == 0 {
+		return ""
 	}
-	report := skills.BuildStatusReport(disabled, extraDirs)
+	var sb strings.Builder
+	sb.WriteString("\n- MCP 服务器:")
+	for _, st := range statuses {
+		fmt.Fprintf(&sb, "\n  - %s (%s): %s", st.Name, st.Transport, st.Summary())
+	}
+	return sb.String()
+}
+
+// formatSkillsSection returns a formatted string listing eligible skills, or empty if none.
+func formatSkillsSection(report skills.StatusReport) string {
 	eligible := report.EligibleSkills()
 	if len(eligible) == 0 {
 		return ""
 	}
 	var sb strings.Builder
-	sb.WriteString("\n\nSkills:\n")
+	sb.WriteString("\n\nSkills (call skill_read for a skill's full instructions before using it):\n")
 	for _, s := range eligible {
 		fmt.Fprintf(&sb, "  %s: %s\n", s.Name, s.Description)
 	}
@@ -778,8 +804,9 @@ func formatSkillsSection() string {
 	return sb.String()
 }
 
-// buildToolsList creates the tools list for the AI provider
-func (a *Agent) buildToolsList() []Tool {
+// buildToolsList creates the tools list for the AI provider, limited to the
+// tools the agent may use
+func (a *Agent) buildToolsList(convKey string) []Tool {
 	tools := []Tool{
 		// === FILE OPERATIONS ===
 		{
@@ -1322,26 +1349,15 @@ func (a *Agent) buildToolsList() []Tool {
 			}),
 		},
 		{
-			Name:        "browser_comment_zhihu",
-			Description: "Post a top-level comment OR a nested reply on Zhihu. Must already be on the Zhihu page. For a top-level comment omit reply_to. To reply to a specific person's comment, set reply_to to their username (e.g. \"Jockery\") — the tool will find their 回复 button and post a nested reply. Handles both Draft.js and plain textarea editors automatically.",
+			Name:        "browser_site_action",
+			Description: "Run a site adapter action on the current page, e.g. action=\"zhihu.comment\" with args {\"comment\": \"...\"}. The actions of the current site are listed in the system prompt. Prefer these over clicking and typing step by step: they use verified methods for the site's editors.",
 			InputSchema: jsonSchema(map[string]any{
 				"type": "object",
 				"properties": map[string]any{
-					"comment":  map[string]string{"type": "string", "description": "The comment text to post"},
-					"reply_to": map[string]string{"type": "string", "description": "Username to reply to (nested reply). Omit for a top-level comment."},
+					"action": map[string]string{"type": "string", "description": "<site>.<action>, e.g. zhihu.comment"},
+					"args":   map[string]string{"type": "object", "description": "The action's arguments as string values"},
 				},
-				"required": []string{"comment"},
-			}),
-		},
-		{
-			Name:        "browser_comment_xiaohongshu",
-			Description: "Post a comment on a Xiaohongshu (小红书) note. Must already be on a Xiaohongshu note detail page (with the comment input visible at the bottom). Automatically types the comment into the editor via ClipboardEvent paste and clicks 发送 to submit. Use this instead of browser_click + browser_type for Xiaohongshu commenting.",
-			InputSchema: jsonSchema(map[string]any{
-				"type": "object",
-				"properties": map[string]any{
-					"comment": map[string]string{"type": "string", "description": "The comment text to post"},
-				},
-				"required": []string{"comment"},
+				"required": []string{"action"},
 			}),
 		},
 		{
@@ -1402,24 +1418,46 @@ func (a *Agent) buildToolsList() []Tool {
 		// === SCHEDULED TASKS (CRON) ===
 		{
 			Name:        "cron_create",
-			Description: "Create ONE scheduled task. Use 'prompt' to describe what the AI should do each time (generate text, search web, check weather, etc.). The AI runs a full conversation each trigger, so content is fresh every time. Use 'tool'+'arguments' only for raw MCP tool execution without AI. Schedule uses standard 5-field cron: minute hour day month weekday.",
+			Description: "Create ONE scheduled task. Use 'prompt' to describe what the AI should do each time (generate text, search web, check weather, etc.). The AI runs a full conversation each trigger, so content is fresh every time. Use 'tool'+'arguments' only for raw MCP tool execution without AI. Schedule accepts standard 5-field cron (minute hour day month weekday) or a natural-language phrase; the result lists the next run times, which you should relay to the user.",
 			InputSchema: jsonSchema(map[string]any{
 				"type": "object",
 				"properties": map[string]any{
-					"name":      map[string]string{"type": "string", "description": "Human-readable task name"},
-					"schedule":  map[string]string{"type": "string", "description": "Cron expression (e.g., '43 * * * *' for every hour at :43, '0 9 * * 1-5' for weekdays at 9am)"},
-					"prompt":    map[string]string{"type": "string", "description": "What the AI should do each time this job triggers. AI runs a full conversation and sends the result to the user. Example: '生成一条独特的编程激励鸡汤'"},
-					"tool":      map[string]string{"type": "string", "description": "MCP tool to execute periodically (for raw tool execution without AI)"},
-					"arguments": map[string]string{"type": "object", "description": "Arguments for the tool (when using tool parameter)"},
+					"name":             map[string]string{"type": "string", "description": "Human-readable task name"},
+					"schedule":         map[string]string{"type": "string", "description": "When to run: a cron expression (e.g., '43 * * * *' for every hour at :43, '0 9 * * 1-5' for weekdays at 9am) or a phrase such as 'every weekday at 9:30', '每月最后一个周五下午五点', 'tomorrow at 9am', '30分钟后' (one-shot). Prefer passing the user's own wording"},
+					"prompt":           map[string]string{"type": "string", "description": "What the AI should do each time this job triggers. AI runs a full conversation and sends the result to the user. Example: '生成一条独特的编程激励鸡汤'"},
+					"tool":             map[string]string{"type": "string", "description": "MCP tool to execute periodically (for raw tool execution without AI)"},
+					"arguments":        map[string]string{"type": "object", "description": "Arguments for the tool (when using tool parameter)"},
+					"notify":           map[string]string{"type": "string", "description": "When to message the user: 'always' (default), 'on_change' (only when the result differs from the previous run), or 'on_match' (only when the result matches notify_match or notify_condition)"},
+					"notify_match":     map[string]string{"type": "string", "description": "Regex the result must match (for notify='on_match')"},
+					"notify_condition": map[string]string{"type": "string", "description": "Natural-language condition judged by the AI, e.g. 'price is below 100' (on_match) or 'the price changed' (on_change)"},
+					"quiet_hours":      map[string]string{"type": "string", "description": "Local time window HH:MM-HH:MM during which notifications are held and delivered afterwards, e.g. '22:00-08:00'"},
+					"targets":          map[string]any{"type": "array", "items": map[string]string{"type": "string"}, "description": cronTargetsHelp},
+					"after":            map[string]string{"type": "string", "description": "ID or name of another task; this task runs when that one finishes (schedule may then be omitted). Use {{output}}, {{error}}, {{upstream}} in prompt/message/arguments to receive the upstream result"},
+					"overlap":          map[string]string{"type": "string", "description": "If the previous run is still going when the task triggers: 'skip' (default), 'queue' (run once it finishes), or 'allow' (run concurrently)"},
+					"timeout":          map[string]string{"type": "string", "description": "Maximum duration of one run, e.g. '10m' or '90s' (default 5m)"},
+					"after_on":         map[string]string{"type": "string", "description": "When to run after the upstream task: 'success' (default), 'failure', or 'always'"},
 				},
-				"required": []string{"name", "schedule"},
+				"required": []string{"name"},
 			}),
 		},
 		{
 			Name:        "cron_list",
-			Description: "List all scheduled tasks with their status, schedule, and last run time",
+			Description: "List all scheduled tasks with their status (including which are running right now), schedule, and last run time",
 			InputSchema: jsonSchema(map[string]any{"type": "object", "properties": map[string]any{}}),
 		},
+		{
+			Name:        "cron_targets",
+			Description: "List or edit where a scheduled task delivers its results (chat platforms, webhooks, files)",
+			InputSchema: jsonSchema(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":      map[string]string{"type": "string", "description": "Task ID"},
+					"action":  map[string]string{"type": "string", "description": "One of: list (default), add, remove, set"},
+					"targets": map[string]any{"type": "array", "items": map[string]string{"type": "string"}, "description": cronTargetsHelp},
+				},
+				"required": []string{"id"},
+			}),
+		},
 		{
 			Name:        "cron_delete",
 			Description: "Delete a scheduled task by its ID",
@@ -1447,19 +1485,44 @@ func (a *Agent) buildToolsList() []Tool {
 				"required":   []string{"id"},
 			}),
 		},
+
+		// === SKILLS ===
+		{
+			Name:        "skill_read",
+			Description: "Load a skill's full instructions (SKILL.md body) and the list of files in its directory. Call this before using a skill listed in the system prompt.",
+			InputSchema: jsonSchema(map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"name": map[string]string{"type": "string", "description": "Skill name"}},
+				"required":   []string{"name"},
+			}),
+		},
+		{
+			Name:        "skill_script",
+			Description: "Run a script from a skill's directory (working directory is the skill directory, SKILL_DIR is set). Use the paths listed by skill_read.",
+			InputSchema: jsonSchema(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":    map[string]string{"type": "string", "description": "Skill name"},
+					"script":  map[string]string{"type": "string", "description": "Script path relative to the skill directory, e.g. scripts/search.sh"},
+					"args":    map[string]any{"type": "array", "items": map[string]string{"type": "string"}, "description": "Command-line arguments"},
+					"stdin":   map[string]string{"type": "string", "description": "Text passed on standard input (optional)"},
+					"timeout": map[string]string{"type": "number", "description": "Timeout in seconds (default 60, max 600)"},
+				},
+				"required": []string{"name", "script"},
+			}),
+		},
 	}
 
 	// Append tools from external MCP servers
-	for _, t := range a.mcpManager.AllTools() {
-		schema := json.RawMessage(t.InputSchema)
-		tools = append(tools, Tool{
-			Name:        t.FullName,
-			Description: t.Description,
-			InputSchema: schema,
-		})
-	}
+	tools = append(tools, a.mcpTools(convKey)...)
 
-	return tools
+	allowed := tools[:0]
+	for _, t := range tools {
+		if a.toolAllowed(t.Name) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
 }
 
 // processToolCalls executes tool calls and returns results plus any file attachments
@@ -1469,6 +1532,14 @@ func (a *Agent) processToolCalls(ctx context.Context, toolCalls []ToolCall) ([]T
 
 	for _, tc := range toolCalls {
 		if tc.Name == "file_send" {
+			if !a.toolAllowed(tc.Name) {
+				results = append(results, ToolResult{ToolCallID: tc.ID, Content: toolDeniedMessage(tc.Name), IsError: true})
+				continue
+			}
+			if err := checkSkillScope(ctx, tc.Name, nil); err != nil {
+				results = append(results, ToolResult{ToolCallID: tc.ID, Content: "ACCESS DENIED: " + err.Error(), IsError: true})
+				continue
+			}
 			content, file := executeFileSend(tc.Input)
 			if file != nil {
 				files = append(files, *file)
@@ -1502,18 +1573,33 @@ func (a *Agent) executeTool(ctx context.Context, name string, input json.RawMess
 		return fmt.Sprintf("Error parsing arguments: %v", err)
 	}
 
+	// Enforce the agent's allow_tools and deny_tools
+	if !a.toolAllowed(name) {
+		return toolDeniedMessage(name)
+	}
+
+	// Enforce the tools declared by skills in use this turn
+	if err := checkSkillScope(ctx, name, args); err != nil {
+		return "ACCESS DENIED: " + err.Error() + ". Do NOT retry with a different command; tell the user this skill can't do that."
+	}
+	a.useSkillInCall(ctx, name, args)
+
 	// Handle cron tools that need Agent context
 	switch name {
 	case "cron_create":
 		return a.executeCronCreate(args)
 	case "cron_list":
 		return a.executeCronList()
+	case "cron_targets":
+		return a.executeCronTargets(args)
 	case "cron_delete":
 		return a.executeCronDelete(args)
 	case "cron_pause":
 		return a.executeCronPause(args)
 	case "cron_resume":
 		return a.executeCronResume(args)
+	case mcpListToolsName:
+		return a.executeMCPListTools(args)
 	}
 
 	// Block file tools entirely if disabled
@@ -1530,6 +1616,13 @@ func (a *Agent) executeTool(ctx context.Context, name string, input json.RawMess
 		}
 	}
 
+	// Skill test replays answer tool calls from the recording
+	if stub, ok := ctx.Value(toolStubKey{}).(toolStub); ok {
+		if result, ok := stub(name, args); ok {
+			return result
+		}
+	}
+
 	// Call tools directly
 	result := a.callToolDirect(ctx, name, args)
 
@@ -1543,6 +1636,11 @@ func (a *Agent) executeTool(ctx context.Context, name string, input json.RawMess
 	return result
 }
 
+// toolDeniedMessage is the result of a tool the agent's tool lists forbid
+func toolDeniedMessage(name string) string {
+	return "ACCESS DENIED: tool " + name + " is not available to this agent. Do NOT retry; tell the user it can't be used here."
+}
+
 // fileToolPaths maps tool names to the argument key that contains the path.
 var fileToolPaths = map[string]string{
 	"file_list":     "path",
@@ -1833,13 +1931,8 @@ func (a *Agent) callToolDirect(ctx context.Context, name string, args map[string
 			script = s
 		}
 		return executeBrowserExecuteJS(ctx, script)
-	case "browser_comment_zhihu":
-		comment, _ := args["comment"].(string)
-		replyTo, _ := args["reply_to"].(string)
-		return executeBrowserCommentZhihu(ctx, comment, replyTo)
-	case "browser_comment_xiaohongshu":
-		comment, _ := args["comment"].(string)
-		return executeBrowserCommentXiaohongshu(ctx, comment)
+	case "browser_site_action":
+		return executeBrowserSiteAction(ctx, args)
 	case "browser_visited":
 		return executeBrowserVisited(ctx, args)
 	case "browser_click_all":
@@ -1857,11 +1950,24 @@ func (a *Agent) callToolDirect(ctx context.Context, name string, args map[string
 	case "browser_stop":
 		return executeBrowserStop(ctx)
 
+	// Skills
+	case "skill_read":
+		return a.executeSkillRead(ctx, args)
+	case "skill_script":
+		return a.executeSkillScript(ctx, args)
+
+	// Trigger/action skills (schedule triggers run through cron)
+	case skillRunTool:
+		return a.executeSkillRun(ctx, args)
+
 	default:
 		return fmt.Sprintf("Tool '%s' not implemented", name)
 	}
 }
 
+// cronTargetsHelp describes the target spec format for cron tool schemas
+const cronTargetsHelp = "Delivery targets. Omit to send results to the current chat. Formats: 'platform' (current chat), 'platform:<name>:<channelID>' (e.g. 'platform:feishu:oc_xxx'), 'webhook:<url>', 'file:<path>'"
+
 func jsonSchema(schema map[string]any) json.RawMessage {
 	data, _ := json.Marshal(schema)
 	return data
@@ -1,10 +1,23 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pltanton/lingti-bot/internal/agent/mcpclient"
+	"github.com/pltanton/lingti-bot/internal/config"
+	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
 	"github.com/pltanton/lingti-bot/internal/router"
+	"github.com/pltanton/lingti-bot/internal/skills"
 )
 
 func TestCreateProvider_ValidProviders(t *testing.T) {
@@ -82,3 +95,440 @@ func TestHandleBuiltinCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleSkillTrigger(t *testing.T) {
+	agent, err := New(Config{Provider: "claude", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	os.WriteFile(notes, []byte("buy milk"), 0644)
+
+	reg := NewSkillRegistry(agent, dir)
+	agent.SetSkillRegistry(reg)
+	err = reg.Register(&skills.Skill{
+		ID:       "notes",
+		Name:     "Notes",
+		Enabled:  true,
+		Triggers: []skills.Trigger{{Type: skills.TriggerCommand, Command: "notes"}},
+		Actions: []skills.Action{{ID: "read", Type: skills.ActionTool, Config: map[string]any{
+			"tool":      "file_read",
+			"arguments": map[string]any{"path": dir + "/{{.Match1}}"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	msg := router.Message{Text: "/notes notes.txt", Platform: "test", ChannelID: "c1", UserID: "u1"}
+	resp, handled := agent.handleSkillTrigger(context.Background(), msg)
+	if !handled || !strings.Contains(resp.Text, "buy milk") {
+		t.Errorf("handleSkillTrigger = %q, %v", resp.Text, handled)
+	}
+
+	// Another agent sharing the registry runs the skill with its own tool limits
+	limited, err := New(Config{Provider: "claude", APIKey: "test-key", DenyTools: []string{"file_read"}})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	limited.SetSkillRegistry(reg)
+	if resp, _ := limited.handleSkillTrigger(context.Background(), msg); strings.Contains(resp.Text, "buy milk") {
+		t.Errorf("skill ran with another agent's tools: %q", resp.Text)
+	}
+
+	msg.Text = "what are my notes?"
+	if _, handled := agent.handleSkillTrigger(context.Background(), msg); handled {
+		t.Error("expected message without a trigger to reach the LLM")
+	}
+
+	msg.Text = "/notes notes.txt"
+	msg.Metadata = map[string]string{skillSourceKey: "skill"}
+	if _, handled := agent.handleSkillTrigger(context.Background(), msg); handled {
+		t.Error("expected skill prompt messages not to re-trigger skills")
+	}
+}
+
+func TestSyncSkillSchedules(t *testing.T) {
+	agent, err := New(Config{Provider: "claude", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	dir := t.TempDir()
+	store, err := cronpkg.NewStore(filepath.Join(dir, "cron.db"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	sched := cronpkg.NewScheduler(store, agent, agent, nil)
+	if err := sched.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer sched.Stop()
+
+	reg := NewSkillRegistry(agent, dir)
+	skill := &skills.Skill{
+		ID:       "digest",
+		Enabled:  true,
+		Triggers: []skills.Trigger{{Type: skills.TriggerSchedule, Schedule: "every day at 8am"}},
+		Actions:  []skills.Action{{ID: "a", Type: skills.ActionShell, Config: map[string]any{"command": "echo hi"}}},
+	}
+	reg.Register(skill)
+
+	SyncSkillSchedules(reg, sched)
+	SyncSkillSchedules(reg, sched)
+	jobs := sched.ListJobs()
+	if len(jobs) != 1 || jobs[0].Tool != skillRunTool || jobs[0].Schedule != "0 0 8 * * *" {
+		t.Fatalf("expected one skill job at 8am, got %+v", jobs)
+	}
+
+	skill.Triggers[0].Schedule = "0 9 * * *"
+	SyncSkillSchedules(reg, sched)
+	if jobs := sched.ListJobs(); len(jobs) != 1 || jobs[0].Schedule != "0 0 9 * * *" {
+		t.Fatalf("expected the changed schedule to replace the job, got %+v", jobs)
+	}
+
+	reg.Unregister("digest")
+	SyncSkillSchedules(reg, sched)
+	if jobs := sched.ListJobs(); len(jobs) != 0 {
+		t.Errorf("expected removed skill's job to be deleted, got %+v", jobs)
+	}
+}
+
+func TestSkillTools(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	bundled := filepath.Join(home, "bundled")
+	t.Setenv("LINGTI_BUNDLED_SKILLS_DIR", bundled)
+
+	dir := filepath.Join(bundled, "greet")
+	os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: greet\ndescription: Greets people\nmetadata:\n  always: true\n---\nRun scripts/hello.sh with a name."), 0644)
+	os.WriteFile(filepath.Join(dir, "scripts", "hello.sh"), []byte("echo hello $1\n"), 0644)
+
+	agent, err := New(Config{Provider: "claude", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	ctx := context.Background()
+
+	out := agent.executeSkillRead(ctx, map[string]any{"name": "greet"})
+	if !strings.Contains(out, "Run scripts/hello.sh with a name.") || !strings.Contains(out, "- scripts/hello.sh") {
+		t.Errorf("skill_read output missing instructions or files:\n%s", out)
+	}
+	if out := agent.executeSkillRead(ctx, map[string]any{"name": "nope"}); !strings.HasPrefix(out, "Error") {
+		t.Errorf("expected error for unknown skill, got %q", out)
+	}
+
+	out = agent.executeSkillScript(ctx, map[string]any{"name": "greet", "script": "scripts/hello.sh", "args": []any{"world"}})
+	if strings.TrimSpace(out) != "hello world" {
+		t.Errorf("skill_script output = %q", out)
+	}
+	if out := agent.executeSkillScript(ctx, map[string]any{"name": "greet", "script": "../../x.sh"}); !strings.HasPrefix(out, "Error") {
+		t.Errorf("expected path escape to be rejected, got %q", out)
+	}
+	if out := truncateSkillOutput(strings.Repeat("中", skillOutputMaxLen)); !utf8.ValidString(out) {
+		t.Errorf("truncated skill output is not valid UTF-8: %q", out[len(out)-40:])
+	}
+
+	if prompt := formatAlwaysSkills(loadSkillReport()); !strings.Contains(prompt, "Run scripts/hello.sh with a name.") {
+		t.Errorf("always skill body not inlined: %q", prompt)
+	}
+}
+
+func TestSkillAccessAndToolScope(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	bundled := filepath.Join(home, "bundled")
+	t.Setenv("LINGTI_BUNDLED_SKILLS_DIR", bundled)
+
+	for name, metadata := range map[string]string{
+		"lister":  "metadata:\n  tools:\n    - name: shell_execute\n      bins: [ls]\n",
+		"private": "",
+	} {
+		os.MkdirAll(filepath.Join(bundled, name), 0755)
+		os.WriteFile(filepath.Join(bundled, name, "SKILL.md"), []byte("---\nname: "+name+"\ndescription: test\n"+metadata+"---\nInstructions"), 0644)
+	}
+
+	agent, err := New(Config{Provider: "claude", APIKey: "test-key", DenySkills: []string{"private"}})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	if section := formatSkillsSection(agent.skillReport()); strings.Contains(section, "private") || !strings.Contains(section, "lister") {
+		t.Errorf("skills section should hide denied skills:\n%s", section)
+	}
+
+	ctx := withSkillScope(context.Background())
+	if out := agent.executeSkillRead(ctx, map[string]any{"name": "private"}); !strings.HasPrefix(out, "Error") {
+		t.Errorf("denied skill should not be readable, got %q", out)
+	}
+	if out := agent.executeTool(ctx, "shell_execute", json.RawMessage(`{"command":"echo before"}`)); strings.HasPrefix(out, "ACCESS DENIED") {
+		t.Errorf("tools should be unrestricted before a scoped skill is read, got %q", out)
+	}
+
+	out := agent.executeSkillRead(ctx, map[string]any{"name": "lister"})
+	if !strings.Contains(out, "Allowed tools: shell_execute (ls)") {
+		t.Errorf("skill_read should announce the tool scope:\n%s", out)
+	}
+	if out := agent.executeTool(ctx, "shell_execute", json.RawMessage(`{"command":"ls /"}`)); strings.HasPrefix(out, "ACCESS DENIED") {
+		t.Errorf("declared bin was rejected: %q", out)
+	}
+	for _, input := range []string{
+		`{"command":"ls / && cat /etc/passwd"}`,
+		`{"command":"ls $(whoami)"}`,
+	} {
+		if out := agent.executeTool(ctx, "shell_execute", json.RawMessage(input)); !strings.HasPrefix(out, "ACCESS DENIED") {
+			t.Errorf("%s should be denied, got %q", input, out)
+		}
+	}
+	if out := agent.executeTool(ctx, "file_read", json.RawMessage(`{"path":"/etc/hosts"}`)); !strings.HasPrefix(out, "ACCESS DENIED") {
+		t.Errorf("undeclared tool should be denied, got %q", out)
+	}
+
+	// Running a skill's script or reading its files also uses the skill
+	ctx = withSkillScope(context.Background())
+	agent.executeSkillScript(ctx, map[string]any{"name": "lister", "script": "missing.sh"})
+	if out := agent.executeTool(ctx, "shell_execute", json.RawMessage(`{"command":"cat /etc/passwd"}`)); !strings.HasPrefix(out, "ACCESS DENIED") {
+		t.Errorf("skill_script should bind the tool scope, got %q", out)
+	}
+	ctx = withSkillScope(context.Background())
+	skillFile, _ := json.Marshal(map[string]string{"path": filepath.Join(bundled, "lister", "SKILL.md")})
+	if out := agent.executeTool(ctx, "file_read", skillFile); strings.HasPrefix(out, "ACCESS DENIED") {
+		t.Errorf("reading a skill file should be allowed, got %q", out)
+	}
+	if out := agent.executeTool(ctx, "shell_execute", json.RawMessage(`{"command":"cat /etc/passwd"}`)); !strings.HasPrefix(out, "ACCESS DENIED") {
+		t.Errorf("reading SKILL.md should bind the tool scope, got %q", out)
+	}
+}
+
+func TestRunSkillTests(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	bundled := filepath.Join(home, "bundled")
+	t.Setenv("LINGTI_BUNDLED_SKILLS_DIR", bundled)
+
+	dir := filepath.Join(bundled, "greet")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: greet\ndescription: Greets people\n---\nSay hello."), 0644)
+	touched := filepath.Join(home, "touched")
+
+	suite := &skills.TestSuite{Skill: "greet", Path: filepath.Join(dir, skills.TestFileName), Cases: []skills.TestCase{
+		{
+			Name:   "reads the skill",
+			Prompt: "greet me",
+			Expect: skills.TestExpect{
+				Tools:          []skills.ExpectedToolCall{{Name: "skill_read", Args: map[string]string{"name": "^greet$"}}},
+				AnswerContains: []string{"hello"},
+			},
+			Replay: []skills.ReplayTurn{
+				{ToolCalls: []skills.ToolCallData{{Name: "skill_read", Args: map[string]any{"name": "greet"}}}},
+				{Text: "Hello there!"},
+			},
+		},
+		{
+			Name:   "wrong answer",
+			Prompt: "greet me",
+			Expect: skills.TestExpect{AnswerContains: []string{"goodbye"}},
+			Replay: []skills.ReplayTurn{{Text: "Hello there!"}},
+		},
+		{
+			Name:   "replay too short",
+			Prompt: "greet me",
+			Replay: []skills.ReplayTurn{{ToolCalls: []skills.ToolCallData{{Name: "skill_read", Args: map[string]any{"name": "greet"}}}}},
+		},
+		{Name: "not recorded", Prompt: "greet me"},
+		{
+			Name:   "replays tool results",
+			Prompt: "greet the file",
+			Expect: skills.TestExpect{Tools: []skills.ExpectedToolCall{{Name: "shell_execute", Result: "^recorded hello$"}}},
+			Replay: []skills.ReplayTurn{
+				{ToolCalls: []skills.ToolCallData{{Name: "shell_execute", Args: map[string]any{"command": "touch " + touched}, Result: "recorded hello"}}},
+				{Text: "Done."},
+			},
+		},
+		{
+			Name:   "wrong tool result",
+			Prompt: "greet the file",
+			Expect: skills.TestExpect{Tools: []skills.ExpectedToolCall{{Name: "shell_execute", Result: "^goodbye$"}}},
+			Replay: []skills.ReplayTurn{
+				{ToolCalls: []skills.ToolCallData{{Name: "shell_execute", Args: map[string]any{"command": "touch " + touched}, Result: "recorded hello"}}},
+				{Text: "Done."},
+			},
+		},
+	}}
+
+	results, err := RunSkillTests(context.Background(), Config{Provider: "claude"}, suite, SkillTestOptions{})
+	if err != nil {
+		t.Fatalf("RunSkillTests: %v", err)
+	}
+	want := []string{SkillTestPass, SkillTestFail, SkillTestFail, SkillTestSkip, SkillTestPass, SkillTestFail}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("case %q: status %s, want %s (%v)", r.Case, r.Status, want[i], r.Failures)
+		}
+	}
+	if _, err := os.Stat(touched); err == nil {
+		t.Error("replayed shell_execute ran the command")
+	}
+}
+
+func TestHandleMessageReportsToolRounds(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	provider := &replayProvider{turns: []skills.ReplayTurn{
+		{ToolCalls: []skills.ToolCallData{{Name: "file_list", Args: map[string]any{"path": home}}}},
+		{Text: "Listed."},
+	}}
+	a := newAgent(Config{Provider: "claude"}, provider)
+
+	var rounds []router.ToolRound
+	ctx := router.ContextWithToolRounds(context.Background(), func(r router.ToolRound) { rounds = append(rounds, r) })
+	resp, err := a.HandleMessage(ctx, router.Message{Platform: "mcp", ChannelID: "s1", UserID: "mcp", Text: "list my home"})
+	if err != nil {
+		t.Fatalf("HandleMessage: %v", err)
+	}
+	if resp.Text != "Listed." {
+		t.Errorf("response = %q", resp.Text)
+	}
+	if len(rounds) != 1 || rounds[0].Round != 1 || rounds[0].MaxRounds != 100 || len(rounds[0].Tools) != 1 || rounds[0].Tools[0] != "file_list" {
+		t.Errorf("rounds = %+v", rounds)
+	}
+}
+
+func TestAgentPoolHandleMessageAs(t *testing.T) {
+	pool := NewAgentPool(newAgent(Config{Provider: "claude"}, &replayProvider{}), Config{Provider: "claude"},
+		&config.Config{Agents: []config.AgentEntry{{ID: "writer"}, {ID: "coder"}}})
+	if ids := pool.AgentIDs(); strings.Join(ids, ",") != "writer,coder" {
+		t.Errorf("AgentIDs = %v", ids)
+	}
+	if _, err := pool.HandleMessageAs(context.Background(), "nobody", router.Message{Text: "hi"}); err == nil || !strings.Contains(err.Error(), "unknown agent") {
+		t.Errorf("unknown agent error = %v", err)
+	}
+}
+
+// toolListProvider records the tool names offered with each request
+type toolListProvider struct {
+	Provider
+	offered [][]string
+}
+
+func (p *toolListProvider) Chat(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	var names []string
+	for _, t := range req.Tools {
+		names = append(names, t.Name)
+	}
+	p.offered = append(p.offered, names)
+	return p.Provider.Chat(ctx, req)
+}
+
+func TestToolAccessAndLazyMCPTools(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	mcpServer := server.NewMCPServer("chrome", "1.0", server.WithToolCapabilities(true))
+	for _, name := range []string{"take_snapshot", "click"} {
+		mcpServer.AddTool(mcp.NewTool(name), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("called " + req.Params.Name), nil
+		})
+	}
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config.Handler = server.NewSSEServer(mcpServer, server.WithBaseURL("http://"+ts.Listener.Addr().String()))
+	ts.Start()
+	defer func() {
+		ts.CloseClientConnections()
+		ts.Close()
+	}()
+
+	provider := &toolListProvider{Provider: &replayProvider{turns: []skills.ReplayTurn{
+		{ToolCalls: []skills.ToolCallData{{Name: "mcp_list_tools", Args: map[string]any{"server": "chrome"}}}},
+		{ToolCalls: []skills.ToolCallData{
+			{Name: "mcp_chrome_snapshot"},
+			{Name: "mcp_chrome_click"},
+			{Name: "shell_execute", Args: map[string]any{"command": "true"}},
+		}},
+		{Text: "Done."},
+	}}}
+	a := newAgent(Config{
+		Provider:   "claude",
+		MCPServers: []mcpclient.ServerConfig{{Name: "chrome", URL: ts.URL + "/sse", Lazy: true, ToolAliases: map[string]string{"take_snapshot": "snapshot"}}},
+		DenyTools:  []string{"shell_*", "mcp_chrome_click"},
+	}, provider)
+	defer a.mcpManager.Close()
+
+	msg := router.Message{Platform: "test", ChannelID: "c1", UserID: "u1", Text: "take a snapshot"}
+	if _, err := a.HandleMessage(context.Background(), msg); err != nil {
+		t.Fatalf("HandleMessage: %v", err)
+	}
+
+	first, second := provider.offered[0], provider.offered[1]
+	if !slices.Contains(first, "mcp_list_tools") || slices.Contains(first, "mcp_chrome_snapshot") {
+		t.Errorf("first request should offer mcp_list_tools but no chrome tools: %v", first)
+	}
+	if !slices.Contains(second, "mcp_chrome_snapshot") || slices.Contains(second, "mcp_list_tools") {
+		t.Errorf("second request should offer the loaded chrome tools: %v", second)
+	}
+	for _, tools := range provider.offered {
+		if slices.Contains(tools, "shell_execute") || slices.Contains(tools, "mcp_chrome_click") {
+			t.Errorf("denied tools offered: %v", tools)
+		}
+	}
+
+	var results []string
+	for _, name := range []string{"mcp_chrome_snapshot", "mcp_chrome_click", "shell_execute"} {
+		results = append(results, a.executeTool(context.Background(), name, json.RawMessage("{}")))
+	}
+	if results[0] != "called take_snapshot" {
+		t.Errorf("snapshot result = %q", results[0])
+	}
+	for _, r := range results[1:] {
+		if !strings.HasPrefix(r, "ACCESS DENIED") {
+			t.Errorf("denied tool result = %q", r)
+		}
+	}
+
+	// Loaded tools stay offered in the conversation until /new
+	tools := a.buildToolsList(ConversationKey("test", "c1", "u1"))
+	if !slices.ContainsFunc(tools, func(t Tool) bool { return t.Name == "mcp_chrome_snapshot" }) {
+		t.Errorf("chrome tools not offered on the next message")
+	}
+}
+
+// chatRecorder records the requests sent to the provider
+type chatRecorder struct {
+	Provider
+	reqs []ChatRequest
+}
+
+func (p *chatRecorder) Chat(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	p.reqs = append(p.reqs, req)
+	return p.Provider.Chat(ctx, req)
+}
+
+func TestSample(t *testing.T) {
+	provider := &chatRecorder{Provider: &replayProvider{turns: []skills.ReplayTurn{{Text: "A summary."}}}}
+	a := newAgent(Config{Provider: "claude"}, provider)
+	defer a.mcpManager.Close()
+
+	result, err := a.Sample(context.Background(), mcpclient.SamplingRequest{
+		Server:       "docs",
+		SystemPrompt: "Summarize.",
+		MaxTokens:    200,
+		Messages:     []mcpclient.SamplingMessage{{Role: "user", Text: "long text"}, {Role: "assistant", Text: "ok"}, {Role: "user", Text: "now"}},
+	})
+	if err != nil || result.Text != "A summary." || result.Model != "replay" {
+		t.Fatalf("Sample = %+v, %v", result, err)
+	}
+	req := provider.reqs[0]
+	if req.SystemPrompt != "Summarize." || req.MaxTokens != 200 || len(req.Tools) != 0 || len(req.Messages) != 3 || req.Messages[1].Role != "assistant" {
+		t.Errorf("provider request = %+v", req)
+	}
+
+	if a.ApproveSampling(context.Background(), mcpclient.SamplingRequest{Server: "docs"}) {
+		t.Error("sampling approved without auto-approval")
+	}
+	a.autoApprove = true
+	if !a.ApproveSampling(context.Background(), mcpclient.SamplingRequest{Server: "docs"}) {
+		t.Error("sampling not approved in auto-approval mode")
+	}
+}
@@ -1,9 +1,13 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
+
+	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
 )
 
 // executeCronCreate creates a new scheduled task
@@ -12,23 +16,43 @@ func (a *Agent) executeCronCreate(args map[string]any) string {
 		return "Error: cron scheduler not available"
 	}
 
-	// Enforce: only ONE cron_create per user request
-	a.cronCreatedCount++
-	if a.cronCreatedCount > 1 {
-		return "Error: You already created a cron job for this request. Only ONE cron job per user request is allowed. If you need varied/random content each time, use the 'prompt' parameter instead of creating multiple 'message' jobs."
-	}
-
 	name, _ := args["name"].(string)
 	schedule, _ := args["schedule"].(string)
 	message, _ := args["message"].(string)
 	tool, _ := args["tool"].(string)
 	prompt, _ := args["prompt"].(string)
+	after, _ := args["after"].(string)
+	afterOn, _ := args["after_on"].(string)
+
+	// Enforce: only ONE cron_create per user request (further steps of a
+	// pipeline chained with 'after' are allowed)
+	a.cronCreatedCount++
+	if a.cronCreatedCount > 1 && after == "" {
+		return "Error: You already created a cron job for this request. Only ONE cron job per user request is allowed. If you need varied/random content each time, use the 'prompt' parameter instead of creating multiple 'message' jobs. To build a pipeline, chain the next step with 'after'."
+	}
 
 	if name == "" {
 		return "Error: name is required"
 	}
-	if schedule == "" {
-		return "Error: schedule is required"
+	if schedule == "" && after == "" {
+		return "Error: schedule is required (or 'after' to run when another task finishes)"
+	}
+	// Accept natural-language schedules and echo back when the job will run
+	var parsed *cronpkg.ParsedSchedule
+	if schedule != "" {
+		p, err := cronpkg.ParseSchedule(schedule, time.Now())
+		if err != nil {
+			return fmt.Sprintf("Error: %v. Pass a 5-field cron expression or a phrase such as 'every weekday at 9:30' or '每月最后一个周五下午五点'.", err)
+		}
+		parsed = p
+		schedule = p.Spec
+	}
+	if after != "" {
+		id, err := a.resolveCronJob(after)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		after = id
 	}
 
 	// Auto-upgrade: if AI sent 'message' but no 'prompt' or 'tool',
@@ -38,28 +62,51 @@ func (a *Agent) executeCronCreate(args map[string]any) string {
 		message = ""
 	}
 
+	// Delivery rules apply to every job kind
+	spec := &cronpkg.Job{
+		Name:      name,
+		Schedule:  schedule,
+		Platform:  a.currentMsg.Platform,
+		ChannelID: a.currentMsg.ChannelID,
+		UserID:    a.currentMsg.UserID,
+		After:     after,
+		AfterOn:   afterOn,
+		// File targets stay within this agent's limits
+		AllowedPaths:  a.pathChecker.AllowedPaths(),
+		NoFileTargets: a.disableFileTools,
+	}
+	spec.Notify, _ = args["notify"].(string)
+	spec.NotifyMatch, _ = args["notify_match"].(string)
+	spec.NotifyCondition, _ = args["notify_condition"].(string)
+	spec.QuietHours, _ = args["quiet_hours"].(string)
+	spec.Overlap, _ = args["overlap"].(string)
+	spec.Timeout, _ = args["timeout"].(string)
+	if rawTargets, ok := args["targets"]; ok {
+		targets, err := parseTargetArgs(rawTargets)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		spec.Targets = targets
+	}
+
 	// Prompt-based job: run full AI conversation on schedule
 	if prompt != "" {
-		job, err := a.cronScheduler.AddJobWithPrompt(
-			name, schedule, prompt,
-			a.currentMsg.Platform, a.currentMsg.ChannelID, a.currentMsg.UserID,
-		)
+		spec.Prompt = prompt
+		job, err := a.cronScheduler.CreateJob(spec)
 		if err != nil {
 			return fmt.Sprintf("Error creating scheduled task: %v", err)
 		}
-		return fmt.Sprintf("Scheduled AI task created:\n- ID: %s\n- Name: %s\n- Schedule: %s\n- Prompt: %s", job.ID, job.Name, job.Schedule, job.Prompt)
+		return fmt.Sprintf("Scheduled AI task created:\n- ID: %s\n- Name: %s\n- Schedule: %s\n- Prompt: %s%s", job.ID, job.Name, a.describeSchedule(job), job.Prompt, formatSchedulePreview(parsed)+formatJobDelivery(job))
 	}
 
 	// Message-based job
 	if message != "" {
-		job, err := a.cronScheduler.AddJobWithMessage(
-			name, schedule, message,
-			a.currentMsg.Platform, a.currentMsg.ChannelID, a.currentMsg.UserID,
-		)
+		spec.Message = message
+		job, err := a.cronScheduler.CreateJob(spec)
 		if err != nil {
 			return fmt.Sprintf("Error creating scheduled task: %v", err)
 		}
-		return fmt.Sprintf("Scheduled task created:\n- ID: %s\n- Name: %s\n- Schedule: %s\n- Message: %s", job.ID, job.Name, job.Schedule, job.Message)
+		return fmt.Sprintf("Scheduled task created:\n- ID: %s\n- Name: %s\n- Schedule: %s\n- Message: %s%s", job.ID, job.Name, a.describeSchedule(job), job.Message, formatSchedulePreview(parsed)+formatJobDelivery(job))
 	}
 
 	// Tool-based job
@@ -76,11 +123,13 @@ func (a *Agent) executeCronCreate(args map[string]any) string {
 				}
 			}
 		}
-		job, err := a.cronScheduler.AddJob(name, schedule, tool, arguments)
+		spec.Tool = tool
+		spec.Arguments = arguments
+		job, err := a.cronScheduler.CreateJob(spec)
 		if err != nil {
 			return fmt.Sprintf("Error creating scheduled task: %v", err)
 		}
-		return fmt.Sprintf("Scheduled task created:\n- ID: %s\n- Name: %s\n- Schedule: %s\n- Tool: %s", job.ID, job.Name, job.Schedule, job.Tool)
+		return fmt.Sprintf("Scheduled task created:\n- ID: %s\n- Name: %s\n- Schedule: %s\n- Tool: %s%s", job.ID, job.Name, a.describeSchedule(job), job.Tool, formatSchedulePreview(parsed)+formatJobDelivery(job))
 	}
 
 	return "Error: either 'prompt', 'message', or 'tool' is required"
@@ -97,15 +146,29 @@ func (a *Agent) executeCronList() string {
 		return "No scheduled tasks."
 	}
 
+	running := make(map[string]cronpkg.RunningJob)
+	for _, r := range a.cronScheduler.RunningJobs() {
+		running[r.ID] = r
+	}
+
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Scheduled tasks (%d):\n\n", len(jobs)))
+	sb.WriteString(fmt.Sprintf("Scheduled tasks (%d, %d running):\n\n", len(jobs), len(running)))
 	for _, job := range jobs {
 		status := "enabled"
 		if !job.Enabled {
 			status = "paused"
 		}
+		if r, ok := running[job.ID]; ok {
+			status += fmt.Sprintf(", running for %s", time.Since(r.Since).Round(time.Second))
+			if r.Runs > 1 {
+				status += fmt.Sprintf(" (%d runs)", r.Runs)
+			}
+			if r.Queued {
+				status += ", another run queued"
+			}
+		}
 
-		sb.WriteString(fmt.Sprintf("- ID: %s\n  Name: %s\n  Schedule: %s\n  Status: %s\n", job.ID, job.Name, job.Schedule, status))
+		sb.WriteString(fmt.Sprintf("- ID: %s\n  Name: %s\n  Schedule: %s\n  Status: %s\n", job.ID, job.Name, a.describeSchedule(job), status))
 		if job.Prompt != "" {
 			sb.WriteString(fmt.Sprintf("  Prompt: %s\n", job.Prompt))
 		}
@@ -115,6 +178,23 @@ func (a *Agent) executeCronList() string {
 		if job.Tool != "" {
 			sb.WriteString(fmt.Sprintf("  Tool: %s\n", job.Tool))
 		}
+		if job.Notify != "" {
+			sb.WriteString(fmt.Sprintf("  Notify: %s\n", describeNotify(job)))
+		}
+		if job.QuietHours != "" {
+			sb.WriteString(fmt.Sprintf("  Quiet hours: %s (%d deferred)\n", job.QuietHours, len(job.Deferred)))
+		}
+		if len(job.Targets) > 0 {
+			sb.WriteString(fmt.Sprintf("  Targets: %s\n", formatTargets(job.Targets)))
+		}
+		if job.Overlap != "" || job.Timeout != "" {
+			sb.WriteString(fmt.Sprintf("  Limits: %s\n", describeLimits(job)))
+		}
+		if job.Enabled && job.Schedule != "" {
+			if runs, err := cronpkg.NextRuns(job.Schedule, time.Now(), 1); err == nil && len(runs) > 0 {
+				sb.WriteString(fmt.Sprintf("  Next run: %s\n", runs[0].Format("2006-01-02 15:04 (Mon)")))
+			}
+		}
 		if job.LastRun != nil {
 			sb.WriteString(fmt.Sprintf("  Last run: %s\n", job.LastRun.Format("2006-01-02 15:04:05")))
 		}
@@ -127,6 +207,75 @@ func (a *Agent) executeCronList() string {
 	return sb.String()
 }
 
+// describeLimits summarizes a job's overlap policy and timeout
+func describeLimits(job *cronpkg.Job) string {
+	overlap := job.Overlap
+	if overlap == "" {
+		overlap = cronpkg.OverlapSkip
+	}
+	timeout := job.Timeout
+	if timeout == "" {
+		timeout = cronpkg.DefaultJobTimeout.String()
+	}
+	return fmt.Sprintf("overlap=%s, timeout=%s", overlap, timeout)
+}
+
+// formatSchedulePreview describes a parsed schedule and its next fire times
+func formatSchedulePreview(p *cronpkg.ParsedSchedule) string {
+	if p == nil {
+		return ""
+	}
+	var runs []string
+	for _, t := range p.NextRuns(time.Now(), 3) {
+		runs = append(runs, t.Format("2006-01-02 15:04 (Mon)"))
+	}
+	out := "\n- Runs: " + p.Description
+	if len(runs) > 0 {
+		out += "\n- Next runs: " + strings.Join(runs, ", ")
+	}
+	return out
+}
+
+// resolveCronJob finds a job by ID or, failing that, by exact name
+func (a *Agent) resolveCronJob(ref string) (string, error) {
+	if job, ok := a.cronScheduler.GetJob(ref); ok {
+		return job.ID, nil
+	}
+	var matches []*cronpkg.Job
+	for _, job := range a.cronScheduler.ListJobs() {
+		if job.Name == ref {
+			matches = append(matches, job)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("upstream task not found: %s", ref)
+	case 1:
+		return matches[0].ID, nil
+	}
+	return "", fmt.Errorf("several tasks are named %q, use the task ID instead", ref)
+}
+
+// describeSchedule shows a job's cron schedule and/or the job it runs after
+func (a *Agent) describeSchedule(job *cronpkg.Job) string {
+	if job.After == "" {
+		return job.Schedule
+	}
+	upstream := job.After
+	if up, ok := a.cronScheduler.GetJob(job.After); ok {
+		upstream = fmt.Sprintf("%s (%s)", up.Name, up.ID)
+	}
+	on := job.AfterOn
+	if on == "" {
+		on = cronpkg.AfterSuccess
+	}
+	chained := fmt.Sprintf("after %s, on %s", upstream, on)
+	if job.Schedule == "" {
+		return chained
+	}
+	return job.Schedule + "; also " + chained
+}
+
 // executeCronDelete deletes a scheduled task
 func (a *Agent) executeCronDelete(args map[string]any) string {
 	if a.cronScheduler == nil {
@@ -177,3 +326,168 @@ func (a *Agent) executeCronResume(args map[string]any) string {
 	}
 	return fmt.Sprintf("Scheduled task %s resumed.", id)
 }
+
+// executeCronTargets lists or edits where a scheduled task delivers its results
+func (a *Agent) executeCronTargets(args map[string]any) string {
+	if a.cronScheduler == nil {
+		return "Error: cron scheduler not available"
+	}
+
+	id, _ := args["id"].(string)
+	if id == "" {
+		return "Error: id is required"
+	}
+	job, ok := a.cronScheduler.GetJob(id)
+	if !ok {
+		return fmt.Sprintf("Error: job not found: %s", id)
+	}
+
+	action, _ := args["action"].(string)
+	if action == "" {
+		action = "list"
+	}
+	if action == "list" {
+		if len(job.Targets) == 0 {
+			return fmt.Sprintf("Task %s has no explicit targets; results go to the chat it was created from.", id)
+		}
+		return fmt.Sprintf("Task %s targets: %s", id, formatTargets(job.Targets))
+	}
+
+	changes, err := parseTargetArgs(args["targets"])
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if len(changes) == 0 && action != "set" {
+		return "Error: targets is required"
+	}
+
+	if err := a.checkFileTargets(changes); err != nil {
+		return err.Error()
+	}
+
+	var updated []cronpkg.Target
+	switch action {
+	case "set":
+		updated = changes
+	case "add":
+		updated = append(job.Targets, changes...)
+	case "remove":
+		for _, t := range job.Targets {
+			keep := true
+			for _, c := range changes {
+				if t == c {
+					keep = false
+					break
+				}
+			}
+			if keep {
+				updated = append(updated, t)
+			}
+		}
+	default:
+		return fmt.Sprintf("Error: unknown action %q (use list, add, remove, or set)", action)
+	}
+
+	if err := a.cronScheduler.SetTargets(id, updated); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if len(updated) == 0 {
+		return fmt.Sprintf("Task %s targets cleared; results go to the chat it was created from.", id)
+	}
+	return fmt.Sprintf("Task %s targets updated: %s", id, formatTargets(updated))
+}
+
+// checkFileTargets refuses file targets outside this agent's file limits
+func (a *Agent) checkFileTargets(targets []cronpkg.Target) error {
+	for _, t := range targets {
+		if t.Type != cronpkg.TargetFile {
+			continue
+		}
+		if a.disableFileTools {
+			return fmt.Errorf("ACCESS DENIED: file operations are disabled by security policy. Do NOT retry. Inform the user that file access is disabled.")
+		}
+		if err := a.pathChecker.CheckPath(t.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTargetArgs accepts target specs as a JSON array or a comma-separated string
+func parseTargetArgs(raw any) ([]cronpkg.Target, error) {
+	var specs []string
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		specs = strings.Split(v, ",")
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				specs = append(specs, s)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("targets must be an array of strings")
+	}
+	return cronpkg.ParseTargets(specs)
+}
+
+// formatTargets renders targets in their spec form
+func formatTargets(targets []cronpkg.Target) string {
+	specs := make([]string, len(targets))
+	for i, t := range targets {
+		specs[i] = t.String()
+	}
+	return strings.Join(specs, ", ")
+}
+
+// formatJobDelivery renders a job's delivery rules for creation confirmations
+func formatJobDelivery(job *cronpkg.Job) string {
+	var sb strings.Builder
+	if job.Notify != "" {
+		sb.WriteString(fmt.Sprintf("\n- Notify: %s", describeNotify(job)))
+	}
+	if job.QuietHours != "" {
+		sb.WriteString(fmt.Sprintf("\n- Quiet hours: %s", job.QuietHours))
+	}
+	if len(job.Targets) > 0 {
+		sb.WriteString(fmt.Sprintf("\n- Targets: %s", formatTargets(job.Targets)))
+	}
+	return sb.String()
+}
+
+// describeNotify summarizes a job's notify policy
+func describeNotify(job *cronpkg.Job) string {
+	desc := job.Notify
+	if job.NotifyMatch != "" {
+		desc += fmt.Sprintf(" (regex: %s)", job.NotifyMatch)
+	}
+	if job.NotifyCondition != "" {
+		desc += fmt.Sprintf(" (condition: %s)", job.NotifyCondition)
+	}
+	return desc
+}
+
+// JudgeResult implements cron.ResultJudge. It asks the model a yes/no
+// question about a scheduled job's output. When previous is set, the model
+// decides whether the change from previous to current is material.
+func (a *Agent) JudgeResult(ctx context.Context, condition, previous, current string) (bool, error) {
+	var prompt string
+	if previous != "" {
+		prompt = fmt.Sprintf("A scheduled task produced a new result. Decide whether it changed materially from the previous result.\n\nWhat counts as a material change: %s\n\n<previous>\n%s\n</previous>\n\n<current>\n%s\n</current>\n\nAnswer with exactly YES or NO.", condition, previous, current)
+	} else {
+		prompt = fmt.Sprintf("A scheduled task produced a result. Decide whether the result satisfies the condition.\n\nCondition: %s\n\n<result>\n%s\n</result>\n\nAnswer with exactly YES or NO.", condition, current)
+	}
+
+	resp, err := a.provider.Chat(ctx, ChatRequest{
+		Messages:     []Message{{Role: "user", Content: prompt}},
+		SystemPrompt: "You evaluate the output of scheduled tasks. Reply with a single word: YES or NO.",
+		MaxTokens:    16,
+	})
+	if err != nil {
+		return false, err
+	}
+	answer := strings.ToUpper(strings.TrimSpace(resp.Content))
+	return strings.HasPrefix(answer, "YES") || strings.HasPrefix(answer, "是"), nil
+}
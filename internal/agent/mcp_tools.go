@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pltanton/lingti-bot/internal/agent/mcpclient"
+	"github.com/pltanton/lingti-bot/internal/logger"
+)
+
+// mcpListToolsName is the meta-tool that reveals the tools of lazy MCP servers
+const mcpListToolsName = "mcp_list_tools"
+
+// toolAllowed reports whether the agent's allow_tools and deny_tools lists
+// let it use a tool. Entries are tool names or globs such as "browser_*".
+func (a *Agent) toolAllowed(name string) bool {
+	if len(a.allowTools) > 0 && !matchToolPattern(a.allowTools, name) {
+		return false
+	}
+	return !matchToolPattern(a.denyTools, name)
+}
+
+func matchToolPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mcpTools returns the external MCP tools offered in a conversation. Tools of
+// lazy servers are left out until mcp_list_tools lists them; while any are
+// left out the meta-tool itself is offered.
+func (a *Agent) mcpTools(convKey string) []Tool {
+	hidden := make(map[string]bool)
+	var servers []string
+	for _, st := range a.mcpManager.Status() {
+		if !st.Lazy || a.sessions.MCPToolsLoaded(convKey, st.Name) {
+			continue
+		}
+		hidden[st.Name] = true
+		if st.State == mcpclient.StateConnected {
+			servers = append(servers, fmt.Sprintf("%s (%d tools)", st.Name, st.Tools))
+		} else {
+			servers = append(servers, fmt.Sprintf("%s (%s)", st.Name, st.State))
+		}
+	}
+
+	var tools []Tool
+	for _, t := range a.mcpManager.AllTools() {
+		if hidden[t.Server] {
+			continue
+		}
+		tools = append(tools, Tool{
+			Name:        t.FullName,
+			Description: t.Description,
+			InputSchema: t.InputSchema,
+		})
+	}
+
+	if len(servers) > 0 {
+		tools = append(tools, Tool{
+			Name:        mcpListToolsName,
+			Description: "List the tools of an external MCP server and make them callable for the rest of the conversation. Servers whose tools are not loaded yet: " + strings.Join(servers, ", "),
+			InputSchema: jsonSchema(map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"server": map[string]string{"type": "string", "description": "MCP server name"}},
+				"required":   []string{"server"},
+			}),
+		})
+	}
+	return tools
+}
+
+// executeMCPListTools lists a server's tools and offers them from the next
+// round on
+func (a *Agent) executeMCPListTools(args map[string]any) string {
+	server, _ := args["server"].(string)
+	if server == "" {
+		return "Error: server is required"
+	}
+	tools, err := a.mcpManager.ServerTools(server)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	a.sessions.LoadMCPTools(ConversationKey(a.currentMsg.Platform, a.currentMsg.ChannelID, a.currentMsg.UserID), server)
+
+	var sb strings.Builder
+	n := 0
+	for _, t := range tools {
+		if !a.toolAllowed(t.FullName) {
+			continue
+		}
+		desc, _, _ := strings.Cut(t.Description, "\n")
+		fmt.Fprintf(&sb, "\n- %s: %s", t.FullName, desc)
+		n++
+	}
+	if n == 0 {
+		return fmt.Sprintf("MCP server %q has no tools available to you.", server)
+	}
+	return fmt.Sprintf("MCP server %q has %d tools, callable from now on:%s", server, n, sb.String())
+}
+
+// Sample answers an MCP server's sampling request with the agent's own
+// model. The server's messages are sent as they are, without tools or
+// conversation history.
+func (a *Agent) Sample(ctx context.Context, req mcpclient.SamplingRequest) (mcpclient.SamplingResult, error) {
+	messages := make([]Message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		role := "user"
+		if m.Role == "assistant" {
+			role = "assistant"
+		}
+		messages = append(messages, Message{Role: role, Content: m.Text})
+	}
+	if len(messages) == 0 {
+		return mcpclient.SamplingResult{}, fmt.Errorf("no messages to sample")
+	}
+
+	resp, err := a.provider.Chat(ctx, ChatRequest{
+		Messages:     messages,
+		SystemPrompt: req.SystemPrompt,
+		MaxTokens:    req.MaxTokens,
+	})
+	if err != nil {
+		return mcpclient.SamplingResult{}, err
+	}
+	return mcpclient.SamplingResult{Text: resp.Content, Model: a.provider.Name()}, nil
+}
+
+// ApproveSampling decides sampling requests of servers with require_yes set.
+// It is a static gate, nobody is asked: they are approved in auto-approval
+// mode (--yes) and refused otherwise.
+func (a *Agent) ApproveSampling(_ context.Context, req mcpclient.SamplingRequest) bool {
+	if !a.autoApprove {
+		logger.Warn("[MCP] Refused sampling request from %q: its policy has require_yes, so it is only allowed when running with --yes", req.Server)
+	}
+	return a.autoApprove
+}
@@ -0,0 +1,358 @@
+package mcpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pltanton/lingti-bot/internal/logger"
+)
+
+const sessionHeader = "Mcp-Session-Id"
+
+// authTransport adds the configured headers and a fresh OAuth bearer token
+// to every request. A request rejected with 401 is retried once with a new
+// token.
+type authTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+	tokens  *tokenSource // nil without OAuth
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || t.tokens == nil {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	t.tokens.invalidate()
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.send(retry)
+}
+
+func (t *authTransport) send(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if t.tokens != nil {
+		tok, err := t.tokens.Token(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// streamableTransport is a Streamable HTTP client transport. mcp-go v0.27
+// has one, but it can't take a custom http.Client, which the auth headers
+// and token refresh need, drops requests from the server and never listens
+// for notifications between requests.
+type streamableTransport struct {
+	url    string
+	client *http.Client
+	peer   rpcPeer
+
+	mu        sync.Mutex
+	sessionID string
+	listening bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var _ transport.Interface = (*streamableTransport)(nil)
+
+func newStreamableTransport(url string, client *http.Client, requests requestHandler) *streamableTransport {
+	t := &streamableTransport{url: url, client: client}
+	t.peer.requests = requests
+	return t
+}
+
+// Start binds the transport to ctx; requests are sent on demand
+func (t *streamableTransport) Start(ctx context.Context) error {
+	t.ctx, t.cancel = context.WithCancel(ctx)
+	return nil
+}
+
+func (t *streamableTransport) SetNotificationHandler(handler func(mcp.JSONRPCNotification)) {
+	t.peer.setNotificationHandler(handler)
+}
+
+// post sends one JSON-RPC message
+func (t *streamableTransport) post(ctx context.Context, message any) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.mu.Lock()
+	if t.sessionID != "" {
+		req.Header.Set(sessionHeader, t.sessionID)
+	}
+	t.mu.Unlock()
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound && req.Header.Get(sessionHeader) != "" {
+		resp.Body.Close()
+		return nil, fmt.Errorf("session expired (HTTP 404)")
+	}
+	return resp, nil
+}
+
+// send posts a message that has no response of its own: a notification or
+// the answer to a server request
+func (t *streamableTransport) send(ctx context.Context, message any) error {
+	resp, err := t.post(ctx, message)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("request failed with HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *streamableTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	ctx, cancel := t.requestContext(ctx)
+	defer cancel()
+
+	ch, err := t.peer.expect(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer t.peer.forget(request.ID)
+
+	resp, err := t.post(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var response transport.JSONRPCResponse
+		if json.Unmarshal(body, &response) == nil && response.Error != nil {
+			return &response, nil
+		}
+		return nil, fmt.Errorf("request failed with HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if request.Method == string(mcp.MethodInitialize) {
+		if id := resp.Header.Get(sessionHeader); id != "" {
+			t.mu.Lock()
+			t.sessionID = id
+			t.mu.Unlock()
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		t.peer.dispatch(t.ctx, body, t.send)
+	case "text/event-stream":
+		// The stream may carry notifications and server requests (e.g.
+		// sampling) before the response, after which the server closes it
+		done := make(chan error, 1)
+		go func() {
+			done <- readEvents(resp.Body, func(_ string, data []byte) bool {
+				t.peer.dispatch(t.ctx, data, t.send)
+				return true
+			})
+		}()
+		select {
+		case r := <-ch:
+			return r, nil
+		case err := <-done:
+			if err == nil {
+				err = errors.New("stream ended without a response")
+			}
+			select {
+			case r := <-ch:
+				return r, nil
+			default:
+				return nil, err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	default:
+		return nil, fmt.Errorf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	select {
+	case r := <-ch:
+		return r, nil
+	default:
+		return nil, errors.New("response did not answer the request")
+	}
+}
+
+func (t *streamableTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	ctx, cancel := t.requestContext(ctx)
+	defer cancel()
+
+	if err := t.send(ctx, notification); err != nil {
+		return err
+	}
+	if notification.Method == "notifications/initialized" {
+		t.listen()
+	}
+	return nil
+}
+
+// requestContext ends a request when either ctx or the transport is done
+func (t *streamableTransport) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if t.ctx != nil {
+		stop := context.AfterFunc(t.ctx, cancel)
+		return ctx, func() { stop(); cancel() }
+	}
+	return ctx, cancel
+}
+
+// listen opens the stream on which the server sends notifications and
+// requests outside of our requests, e.g. tools/list_changed. Servers may
+// not offer one.
+func (t *streamableTransport) listen() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listening || t.ctx == nil {
+		return
+	}
+	t.listening = true
+	go func() {
+		for t.ctx.Err() == nil {
+			if !t.listenOnce() {
+				return
+			}
+			select {
+			case <-t.ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+}
+
+// listenOnce reads one GET stream and reports whether to open another
+func (t *streamableTransport) listenOnce() bool {
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.url, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	t.mu.Lock()
+	if t.sessionID != "" {
+		req.Header.Set(sessionHeader, t.sessionID)
+	}
+	t.mu.Unlock()
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logger.Debug("[MCP] %s has no notification stream (HTTP %d)", t.url, resp.StatusCode)
+		return false
+	}
+	readEvents(resp.Body, func(_ string, data []byte) bool {
+		t.peer.dispatch(t.ctx, data, t.send)
+		return true
+	})
+	return true
+}
+
+// Close ends the session on the server
+func (t *streamableTransport) Close() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.peer.fail(errors.New("transport closed"))
+	t.mu.Lock()
+	id := t.sessionID
+	t.sessionID = ""
+	t.mu.Unlock()
+	if id == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(sessionHeader, id)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// readEvents calls fn with the event name and data of each server-sent
+// event until fn returns false or the stream ends
+func readEvents(r io.Reader, fn func(event string, data []byte) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	var (
+		event string
+		data  []byte
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 && !fn(event, data) {
+				return nil
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+		}
+	}
+	if len(data) > 0 {
+		fn(event, data)
+	}
+	return scanner.Err()
+}
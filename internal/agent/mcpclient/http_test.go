@@ -0,0 +1,300 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("MCPCLIENT_TEST_TOKEN", "s3cret")
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("from-file\n"), 0600)
+
+	tests := []struct {
+		value, want string
+	}{
+		{"plain", "plain"},
+		{"env:MCPCLIENT_TEST_TOKEN", "s3cret"},
+		{"file:" + path, "from-file"},
+		{"Bearer ${MCPCLIENT_TEST_TOKEN}", "Bearer s3cret"},
+	}
+	for _, tt := range tests {
+		got, err := resolveSecret(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveSecret(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []string{"env:MCPCLIENT_TEST_UNSET", "Bearer ${MCPCLIENT_TEST_UNSET}", "file:" + path + ".missing"} {
+		if _, err := resolveSecret(value); err == nil {
+			t.Errorf("resolveSecret(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+// fakeStreamable serves an MCP server over Streamable HTTP, answering every
+// POST with an event stream that carries a log notification before the
+// response. It has no GET stream.
+type fakeStreamable struct {
+	mcp       *server.MCPServer
+	header    string // required value of X-Api-Key
+	authorize func(r *http.Request) bool
+}
+
+func (f *fakeStreamable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get("X-Api-Key") != f.header {
+		http.Error(w, "bad api key", http.StatusForbidden)
+		return
+	}
+	if f.authorize != nil && !f.authorize(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	response := f.mcp.HandleMessage(r.Context(), body)
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set(sessionHeader, "session-1")
+	note, _ := json.Marshal(mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION, Notification: mcp.Notification{Method: "notifications/message"}})
+	data, _ := json.Marshal(response)
+	fmt.Fprintf(w, "event: message\ndata: %s\n\nevent: message\ndata: %s\n\n", note, data)
+}
+
+// fakeTokenServer issues numbered tokens for client credentials
+type fakeTokenServer struct {
+	mu     sync.Mutex
+	issued int
+}
+
+func (f *fakeTokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_secret") != "client-s3cret" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+	f.mu.Lock()
+	f.issued++
+	tok := fmt.Sprintf("tok-%d", f.issued)
+	f.mu.Unlock()
+	json.NewEncoder(w).Encode(map[string]any{"access_token": tok, "expires_in": 3600})
+}
+
+func (f *fakeTokenServer) current() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fmt.Sprintf("tok-%d", f.issued)
+}
+
+func TestManager_StreamableHTTPWithOAuth(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("MCPCLIENT_TEST_KEY", "k3y")
+	t.Setenv("MCPCLIENT_TEST_CLIENT_SECRET", "client-s3cret")
+
+	tokens := &fakeTokenServer{}
+	tokenSrv := httptest.NewServer(tokens)
+	defer tokenSrv.Close()
+
+	fake := newFakeServer()
+	fake.AddTool(mcp.NewTool("slow"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	var (
+		mu      sync.Mutex
+		revoked string // a token the server no longer accepts
+	)
+	mcpSrv := httptest.NewServer(&fakeStreamable{
+		mcp:    fake,
+		header: "k3y",
+		authorize: func(r *http.Request) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			auth := r.Header.Get("Authorization")
+			return strings.HasPrefix(auth, "Bearer tok-") && auth != "Bearer "+revoked
+		},
+	})
+	defer mcpSrv.Close()
+
+	m := newManager([]ServerConfig{{
+		Name:      "remote",
+		URL:       mcpSrv.URL,
+		Transport: TransportHTTP,
+		Headers:   map[string]string{"X-Api-Key": "env:MCPCLIENT_TEST_KEY"},
+		Timeout:   1,
+		OAuth: &OAuthConfig{
+			Flow:         FlowClientCredentials,
+			TokenURL:     tokenSrv.URL,
+			ClientID:     "lingti",
+			ClientSecret: "env:MCPCLIENT_TEST_CLIENT_SECRET",
+		},
+	}}, testSupervision)
+	defer m.Close()
+
+	if st := m.Status()[0]; st.State != StateConnected || st.Transport != TransportHTTP {
+		t.Fatalf("status = %+v, want connected over http", st)
+	}
+	if out, err := m.Call(context.Background(), "mcp_remote_echo", map[string]any{"text": "hi"}); err != nil || out != "hi" {
+		t.Fatalf("call = %q, %v", out, err)
+	}
+
+	// A revoked token is replaced and the call retried
+	mu.Lock()
+	revoked = tokens.current()
+	mu.Unlock()
+	if out, err := m.Call(context.Background(), "mcp_remote_echo", map[string]any{"text": "again"}); err != nil || out != "again" {
+		t.Fatalf("call after revocation = %q, %v", out, err)
+	}
+	if got := tokens.current(); got != "tok-2" {
+		t.Errorf("latest token = %s, want tok-2", got)
+	}
+
+	start := time.Now()
+	_, err := m.Call(context.Background(), "mcp_remote_slow", nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow call error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("slow call took %s, want about 1s", elapsed)
+	}
+
+	// The token is cached for the next process
+	if tok := loadToken("remote|" + tokenSrv.URL + "|lingti"); tok == nil || tok.AccessToken != "tok-2" {
+		t.Errorf("cached token = %+v, want tok-2", tok)
+	}
+}
+
+func TestLogin_DeviceFlow(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var (
+		mu    sync.Mutex
+		polls int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "dev-1",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": "https://example.com/activate",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Form.Get("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			mu.Lock()
+			polls++
+			pending := polls < 2
+			mu.Unlock()
+			if pending {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "access-1", "refresh_token": "refresh-1", "expires_in": 3600})
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "access-2", "expires_in": 3600})
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := ServerConfig{
+		Name:      "device",
+		URL:       "http://127.0.0.1:1/mcp",
+		Transport: TransportHTTP,
+		OAuth: &OAuthConfig{
+			Flow:          FlowDevice,
+			TokenURL:      srv.URL + "/token",
+			DeviceAuthURL: srv.URL + "/device",
+			ClientID:      "lingti",
+		},
+	}
+
+	if _, err := Probe(context.Background(), cfg); !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("probe before sign-in: err = %v, want ErrLoginRequired", err)
+	}
+
+	var shown DeviceCode
+	if err := Login(context.Background(), cfg, func(code DeviceCode) { shown = code }); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if shown.UserCode != "ABCD-EFGH" || shown.VerificationURI != "https://example.com/activate" {
+		t.Errorf("prompt got %+v", shown)
+	}
+
+	ts := newTokenSource(cfg.Name, *cfg.OAuth)
+	if tok, err := ts.Token(context.Background()); err != nil || tok != "access-1" {
+		t.Fatalf("cached token = %q, %v; want access-1", tok, err)
+	}
+
+	// An expired token is renewed with the refresh token, which is kept
+	ts.tok.Expiry = time.Now()
+	if tok, err := ts.Token(context.Background()); err != nil || tok != "access-2" {
+		t.Fatalf("refreshed token = %q, %v; want access-2", tok, err)
+	}
+	if ts.tok.RefreshToken != "refresh-1" {
+		t.Errorf("refresh token = %q, want refresh-1", ts.tok.RefreshToken)
+	}
+
+	if err := ForgetTokens(cfg.Name); err != nil {
+		t.Fatal(err)
+	}
+	if tok := loadToken(ts.cacheKey()); tok != nil {
+		t.Errorf("token still cached after ForgetTokens: %+v", tok)
+	}
+}
+
+func TestServerConfig_Validate(t *testing.T) {
+	tests := []struct {
+		cfg     ServerConfig
+		wantErr bool
+	}{
+		{ServerConfig{Name: "a", Command: "npx"}, false},
+		{ServerConfig{Name: "a", URL: "http://x", Transport: "http"}, false},
+		{ServerConfig{Name: "a", URL: "http://x", Headers: map[string]string{"X": "y"}}, false},
+		{ServerConfig{Name: "a"}, true},
+		{ServerConfig{Name: "a", Command: "npx", Headers: map[string]string{"X": "y"}}, true},
+		{ServerConfig{Name: "a", URL: "http://x", Transport: "grpc"}, true},
+		{ServerConfig{Name: "a", URL: "http://x", OAuth: &OAuthConfig{Flow: FlowClientCredentials, TokenURL: "http://t", ClientID: "c"}}, true},
+		{ServerConfig{Name: "a", Command: "npx", IncludeTools: []string{"take_["}}, true},
+		{ServerConfig{Name: "a", Command: "npx", ToolAliases: map[string]string{"click": ""}}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
@@ -3,9 +3,14 @@
 package mcpclient
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -27,8 +32,137 @@ type ServerConfig struct {
 	Args    []string `yaml:"args,omitempty"`
 	Env     []string `yaml:"env,omitempty"`
 
-	// URL connects to an SSE-based MCP server.
+	// URL connects to a remote MCP server over Transport.
 	URL string `yaml:"url,omitempty"`
+
+	// Transport is "stdio", "sse" or "http" (Streamable HTTP). When empty it
+	// is "stdio" with a command and "sse" with a URL.
+	Transport string `yaml:"transport,omitempty"`
+
+	// Headers are sent with every request to a remote server. Values may be
+	// secret references such as "Bearer ${GITHUB_TOKEN}" or "env:API_KEY".
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// Timeout limits each tool call, in seconds (default 120).
+	Timeout int `yaml:"timeout,omitempty"`
+
+	// OAuth obtains a bearer token for a remote server.
+	OAuth *OAuthConfig `yaml:"oauth,omitempty"`
+
+	// IncludeTools and ExcludeTools are glob patterns (e.g. "take_*") on the
+	// server's own tool names. When IncludeTools is set only matching tools
+	// are exposed; ExcludeTools then removes tools from that set.
+	IncludeTools []string `yaml:"include_tools,omitempty"`
+	ExcludeTools []string `yaml:"exclude_tools,omitempty"`
+
+	// ToolAliases renames tools: "take_snapshot: snapshot" exposes
+	// mcp_<name>_snapshot instead of mcp_<name>_take_snapshot.
+	ToolAliases map[string]string `yaml:"tool_aliases,omitempty"`
+
+	// ToolDescriptions replaces the descriptions of tools, by tool name.
+	ToolDescriptions map[string]string `yaml:"tool_descriptions,omitempty"`
+
+	// Lazy keeps the server's tools out of the tool list until the model
+	// asks for them with mcp_list_tools, to save context on big servers.
+	Lazy bool `yaml:"lazy,omitempty"`
+
+	// Sampling lets the server ask lingti's model for completions. It is
+	// off unless set.
+	Sampling *SamplingPolicy `yaml:"sampling,omitempty"`
+}
+
+// Transports
+const (
+	TransportStdio = "stdio"
+	TransportSSE   = "sse"
+	TransportHTTP  = "http"
+)
+
+// defaultCallTimeout limits tool calls of servers without a timeout
+const defaultCallTimeout = 2 * time.Minute
+
+// EffectiveTransport returns the configured transport or the one implied by
+// Command and URL.
+func (c ServerConfig) EffectiveTransport() string {
+	switch {
+	case c.Transport != "":
+		return c.Transport
+	case c.Command != "":
+		return TransportStdio
+	}
+	return TransportSSE
+}
+
+// Validate checks that the config describes a server that can be connected.
+func (c ServerConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch c.EffectiveTransport() {
+	case TransportStdio:
+		if c.Command == "" {
+			return fmt.Errorf("server %q: the stdio transport needs a command", c.Name)
+		}
+		if len(c.Headers) > 0 || c.OAuth != nil {
+			return fmt.Errorf("server %q: headers and oauth need a remote (sse or http) server", c.Name)
+		}
+	case TransportSSE, TransportHTTP:
+		if c.URL == "" {
+			return fmt.Errorf("server %q: the %s transport needs a url", c.Name, c.EffectiveTransport())
+		}
+		if c.Command != "" {
+			return fmt.Errorf("server %q: set either command or url, not both", c.Name)
+		}
+	default:
+		return fmt.Errorf("server %q: unknown transport %q (want stdio, sse or http)", c.Name, c.Transport)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("server %q: timeout must not be negative", c.Name)
+	}
+	if c.Sampling != nil && c.Sampling.MaxTokens < 0 {
+		return fmt.Errorf("server %q: sampling max_tokens must not be negative", c.Name)
+	}
+	if c.OAuth != nil {
+		if err := c.OAuth.validate(); err != nil {
+			return fmt.Errorf("server %q: %w", c.Name, err)
+		}
+	}
+	for _, pattern := range append(append([]string{}, c.IncludeTools...), c.ExcludeTools...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("server %q: invalid tool pattern %q", c.Name, pattern)
+		}
+	}
+	for tool, alias := range c.ToolAliases {
+		if sanitizeName(alias) == "" {
+			return fmt.Errorf("server %q: empty alias for tool %q", c.Name, tool)
+		}
+	}
+	return nil
+}
+
+// exposes reports whether a tool of the server passes the include and
+// exclude patterns.
+func (c ServerConfig) exposes(tool string) bool {
+	if len(c.IncludeTools) > 0 && !matchAny(c.IncludeTools, tool) {
+		return false
+	}
+	return !matchAny(c.ExcludeTools, tool)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ServerConfig) callTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout) * time.Second
+	}
+	return defaultCallTimeout
 }
 
 // Tool is a discovered tool from an external MCP server, ready to be exposed
@@ -38,50 +172,169 @@ type Tool struct {
 	FullName    string
 	Description string
 	InputSchema json.RawMessage
+	Server      string // name of the server the tool belongs to
 
 	server *serverConn // back-reference for calling
 	// original tool name on the MCP server
 	remoteName string
 }
 
-// serverConn holds a live connection to one MCP server.
+// Server states reported by Status.
+const (
+	StateConnected    = "connected"
+	StateReconnecting = "reconnecting" // disconnected, waiting for the next attempt
+	StateStopped      = "stopped"
+)
+
+// ServerStatus is a snapshot of one server's connection.
+type ServerStatus struct {
+	Name      string
+	Transport string // "stdio", "sse" or "http"
+	State     string
+	Tools     int       // exposed tools, after include/exclude
+	Lazy      bool      // tools are listed on demand by mcp_list_tools
+	Since     time.Time // when the server entered State
+	LastError string    // why the server last disconnected or failed to connect
+	Restarts  int       // reconnects after the first successful connection
+	NextRetry time.Time // next connection attempt, while reconnecting
+}
+
+// Summary describes the status in one line, e.g. "connected, 12 tools".
+func (st ServerStatus) Summary() string {
+	switch st.State {
+	case StateConnected:
+		summary := fmt.Sprintf("connected, %d tools", st.Tools)
+		if st.Restarts > 0 {
+			summary += fmt.Sprintf(", %d restarts (last: %s)", st.Restarts, st.LastError)
+		}
+		return summary
+	case StateReconnecting:
+		wait := max(time.Until(st.NextRetry), 0).Round(time.Second)
+		return fmt.Sprintf("disconnected, retrying in %s: %s", wait, st.LastError)
+	}
+	return st.State
+}
+
+// supervision holds the health check and reconnect timings.
+type supervision struct {
+	pingInterval time.Duration
+	pingTimeout  time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+}
+
+var defaultSupervision = supervision{
+	pingInterval: 30 * time.Second,
+	pingTimeout:  10 * time.Second,
+	minBackoff:   time.Second,
+	maxBackoff:   5 * time.Minute,
+}
+
+// serverConn holds the connection to one MCP server and the goroutine that
+// keeps it alive.
 type serverConn struct {
-	cfg    ServerConfig
-	client *mcpgo.Client
-	tools  []Tool
-	mu     sync.Mutex
+	cfg     ServerConfig
+	sup     supervision
+	tokens  *tokenSource // OAuth tokens, kept across reconnects
+	manager *Manager     // answers sampling requests; nil when probing
+
+	mu        sync.Mutex // guards the fields below
+	client    *mcpgo.Client
+	cancel    context.CancelFunc // stops the stdio subprocess or SSE stream
+	tools     []Tool
+	state     string
+	since     time.Time
+	lastErr   string
+	restarts  int
+	everUp    bool // has connected at least once
+	nextRetry time.Time
+
+	wake    chan struct{} // check health now, e.g. after a failed call
+	refresh chan struct{} // the server's tool list changed
 }
 
 // Manager owns all external MCP server connections.
 type Manager struct {
 	servers []*serverConn
+	cancel  context.CancelFunc
+
+	samplerMu sync.Mutex
+	sampler   Sampler
 }
 
-// New creates a Manager and connects to all configured servers.
-// Servers that fail to connect are logged and skipped (non-fatal).
+// New creates a Manager and connects to all configured servers. Servers that
+// fail to connect are logged and retried in the background with backoff;
+// connected servers are pinged and reconnected (stdio servers respawned)
+// when they stop answering.
 func New(cfgs []ServerConfig) *Manager {
-	m := &Manager{}
+	return newManager(cfgs, defaultSupervision)
+}
+
+func newManager(cfgs []ServerConfig, sup supervision) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{cancel: cancel}
 	for _, cfg := range cfgs {
-		conn := &serverConn{cfg: cfg}
-		if err := conn.connect(); err != nil {
+		conn := newServerConn(cfg, sup)
+		conn.manager = m
+		if err := conn.connect(ctx); err != nil {
 			logger.Warn("[MCP] failed to connect to server %q: %v", cfg.Name, err)
-			continue
+			conn.retryIn(sup.minBackoff, err)
 		}
-		if err := conn.discoverTools(); err != nil {
-			logger.Warn("[MCP] failed to list tools from server %q: %v", cfg.Name, err)
-			continue
-		}
-		logger.Info("[MCP] connected to %q, %d tools available", cfg.Name, len(conn.tools))
 		m.servers = append(m.servers, conn)
+		go conn.supervise(ctx)
 	}
 	return m
 }
 
+func newServerConn(cfg ServerConfig, sup supervision) *serverConn {
+	conn := &serverConn{
+		cfg:     cfg,
+		sup:     sup,
+		wake:    make(chan struct{}, 1),
+		refresh: make(chan struct{}, 1),
+	}
+	if cfg.OAuth != nil {
+		conn.tokens = newTokenSource(cfg.Name, *cfg.OAuth)
+	}
+	return conn
+}
+
+// Probe connects to one server, lists its tools and disconnects, e.g. to
+// test a config before saving it.
+func Probe(ctx context.Context, cfg ServerConfig) ([]Tool, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	conn := newServerConn(cfg, defaultSupervision)
+	if err := conn.connect(ctx); err != nil {
+		return nil, err
+	}
+	conn.mu.Lock()
+	tools := conn.tools
+	conn.mu.Unlock()
+	conn.disconnect(StateStopped, "")
+	return tools, nil
+}
+
+// Login signs in to a server that uses the OAuth device flow. prompt shows
+// the user the code to enter and where; the token is cached for later runs.
+func Login(ctx context.Context, cfg ServerConfig, prompt func(DeviceCode)) error {
+	if cfg.OAuth == nil || cfg.OAuth.Flow != FlowDevice {
+		return fmt.Errorf("server %q does not use the OAuth device flow", cfg.Name)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	return newTokenSource(cfg.Name, *cfg.OAuth).Login(ctx, prompt)
+}
+
 // AllTools returns all discovered tools across all connected servers.
 func (m *Manager) AllTools() []Tool {
 	var out []Tool
 	for _, s := range m.servers {
+		s.mu.Lock()
 		out = append(out, s.tools...)
+		s.mu.Unlock()
 	}
 	return out
 }
@@ -89,21 +342,53 @@ func (m *Manager) AllTools() []Tool {
 // Call invokes a tool by its full namespaced name and returns the result as a string.
 func (m *Manager) Call(ctx context.Context, fullName string, args map[string]any) (string, error) {
 	for _, s := range m.servers {
-		for _, t := range s.tools {
-			if t.FullName == fullName {
-				return s.call(ctx, t.remoteName, args)
+		if remoteName, ok := s.lookup(fullName); ok {
+			return s.call(ctx, remoteName, args)
+		}
+	}
+	// The tool may belong to a server that is down
+	for _, s := range m.servers {
+		if strings.HasPrefix(fullName, s.prefix()) {
+			if st := s.status(); st.State != StateConnected {
+				return "", fmt.Errorf("MCP server %q is %s: %s", st.Name, st.State, st.LastError)
 			}
 		}
 	}
 	return "", fmt.Errorf("MCP tool %q not found", fullName)
 }
 
-// Close shuts down all server connections.
-func (m *Manager) Close() {
+// ServerTools returns the tools of one server by its configured name.
+func (m *Manager) ServerTools(name string) ([]Tool, error) {
 	for _, s := range m.servers {
-		if s.client != nil {
-			_ = s.client.Close()
+		if s.cfg.Name != name {
+			continue
 		}
+		if st := s.status(); st.State != StateConnected {
+			return nil, fmt.Errorf("MCP server %q is %s: %s", name, st.State, st.LastError)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return append([]Tool(nil), s.tools...), nil
+	}
+	return nil, fmt.Errorf("MCP server %q not found", name)
+}
+
+// Status returns the state of every configured server, in config order.
+func (m *Manager) Status() []ServerStatus {
+	out := make([]ServerStatus, 0, len(m.servers))
+	for _, s := range m.servers {
+		out = append(out, s.status())
+	}
+	return out
+}
+
+// Close stops supervision and shuts down all server connections.
+func (m *Manager) Close() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	for _, s := range m.servers {
+		s.disconnect(StateStopped, "")
 	}
 }
 
@@ -114,74 +399,352 @@ func IsMCPTool(name string) bool {
 
 // --- serverConn internals ---
 
-func (s *serverConn) connect() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+func (s *serverConn) prefix() string {
+	return "mcp_" + sanitizeName(s.cfg.Name) + "_"
+}
+
+func (s *serverConn) lookup(fullName string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tools {
+		if t.FullName == fullName {
+			return t.remoteName, true
+		}
+	}
+	return "", false
+}
+
+func (s *serverConn) status() ServerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := ServerStatus{
+		Name:      s.cfg.Name,
+		Transport: s.cfg.EffectiveTransport(),
+		State:     s.state,
+		Tools:     len(s.tools),
+		Lazy:      s.cfg.Lazy,
+		Since:     s.since,
+		LastError: s.lastErr,
+		Restarts:  s.restarts,
+	}
+	if s.state == StateReconnecting {
+		st.NextRetry = s.nextRetry
+	}
+	return st
+}
+
+// supervise pings the server while it is connected and reconnects with
+// exponential backoff when it isn't, until ctx is done.
+func (s *serverConn) supervise(ctx context.Context) {
+	backoff := s.sup.minBackoff
+	ping := time.NewTicker(s.sup.pingInterval)
+	defer ping.Stop()
+	for {
+		s.mu.Lock()
+		up := s.client != nil
+		s.mu.Unlock()
+
+		if !up {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if err := s.connect(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				backoff = min(backoff*2, s.sup.maxBackoff)
+				s.retryIn(backoff, err)
+				logger.Warn("[MCP] reconnect to %q failed (next attempt in %s): %v", s.cfg.Name, backoff, err)
+				continue
+			}
+			backoff = s.sup.minBackoff
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ping.C:
+			s.checkHealth(ctx)
+		case <-s.wake:
+			s.checkHealth(ctx)
+		case <-s.refresh:
+			if err := s.discoverTools(ctx); err != nil {
+				s.disconnect(StateReconnecting, "list tools: "+err.Error())
+			} else {
+				logger.Info("[MCP] tools of %q changed, %d tools available", s.cfg.Name, s.toolCount())
+			}
+		}
+	}
+}
+
+func (s *serverConn) toolCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tools)
+}
+
+// checkHealth pings the server and drops the connection when it doesn't answer.
+func (s *serverConn) checkHealth(ctx context.Context) {
+	s.mu.Lock()
+	c := s.client
+	s.mu.Unlock()
+	if c == nil {
+		return
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, s.sup.pingTimeout)
 	defer cancel()
+	if err := c.Ping(pingCtx); err != nil && ctx.Err() == nil {
+		logger.Warn("[MCP] server %q stopped answering, reconnecting: %v", s.cfg.Name, err)
+		s.disconnect(StateReconnecting, "ping: "+err.Error())
+	}
+}
 
-	var c *mcpgo.Client
-	var err error
+// retryIn records a failed connection attempt and when the next one is due.
+func (s *serverConn) retryIn(backoff time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != StateReconnecting {
+		s.since = time.Now()
+	}
+	s.state = StateReconnecting
+	s.lastErr = err.Error()
+	s.nextRetry = time.Now().Add(backoff)
+}
 
-	if s.cfg.Command != "" {
-		c, err = mcpgo.NewStdioMCPClient(s.cfg.Command, s.cfg.Env, s.cfg.Args...)
+// disconnect closes the connection, kills a stdio subprocess and hides the
+// server's tools until it reconnects.
+func (s *serverConn) disconnect(state, reason string) {
+	s.mu.Lock()
+	c, cancel := s.client, s.cancel
+	s.client, s.cancel, s.tools = nil, nil, nil
+	if s.state != state {
+		s.since = time.Now()
+	}
+	s.state = state
+	if reason != "" {
+		s.lastErr = reason
+	}
+	s.nextRetry = time.Now().Add(s.sup.minBackoff)
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if c != nil {
+		_ = c.Close()
+	}
+}
+
+// connect starts the server (or opens its SSE stream), performs the MCP
+// handshake and discovers its tools. The connection lives until ctx is done
+// or disconnect is called.
+func (s *serverConn) connect(ctx context.Context) error {
+	connCtx, cancel := context.WithCancel(ctx)
+	c, err := s.dial(connCtx)
+	if err == nil {
+		err = s.initialize(ctx, c)
 		if err != nil {
-			return fmt.Errorf("stdio connect: %w", err)
+			_ = c.Close()
 		}
-	} else if s.cfg.URL != "" {
-		c, err = mcpgo.NewSSEMCPClient(s.cfg.URL)
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	s.mu.Lock()
+	s.client, s.cancel = c, cancel
+	if s.everUp {
+		s.restarts++
+	}
+	s.everUp = true
+	s.state = StateConnected
+	s.since = time.Now()
+	s.mu.Unlock()
+	if ctx.Err() != nil {
+		// The manager closed while connecting
+		s.disconnect(StateStopped, "")
+		return ctx.Err()
+	}
+
+	if err := s.discoverTools(ctx); err != nil {
+		s.disconnect(StateReconnecting, "list tools: "+err.Error())
+		return fmt.Errorf("list tools: %w", err)
+	}
+	logger.Info("[MCP] connected to %q, %d tools available", s.cfg.Name, s.toolCount())
+	return nil
+}
+
+// dial creates and starts the client for the configured transport.
+func (s *serverConn) dial(ctx context.Context) (*mcpgo.Client, error) {
+	var c *mcpgo.Client
+	switch s.cfg.EffectiveTransport() {
+	case TransportStdio:
+		env := make([]string, 0, len(s.cfg.Env))
+		for _, kv := range s.cfg.Env {
+			k, v, _ := strings.Cut(kv, "=")
+			v, err := resolveSecret(v)
+			if err != nil {
+				return nil, fmt.Errorf("env %s: %w", k, err)
+			}
+			env = append(env, k+"="+v)
+		}
+		c = mcpgo.NewClient(newStdioTransport(s.cfg.Command, env, s.cfg.Args, s.handleRequest, s.logStderr))
+		if err := c.Start(ctx); err != nil {
+			return nil, fmt.Errorf("stdio connect: %w", err)
+		}
+	case TransportSSE:
+		httpClient, err := s.httpClient()
+		if err != nil {
+			return nil, err
+		}
+		c = mcpgo.NewClient(newSSETransport(s.cfg.URL, httpClient, s.handleRequest))
+		if err := c.Start(ctx); err != nil {
+			return nil, fmt.Errorf("SSE start: %w", err)
+		}
+	case TransportHTTP:
+		httpClient, err := s.httpClient()
 		if err != nil {
-			return fmt.Errorf("SSE connect: %w", err)
+			return nil, err
 		}
+		c = mcpgo.NewClient(newStreamableTransport(s.cfg.URL, httpClient, s.handleRequest))
 		if err := c.Start(ctx); err != nil {
-			return fmt.Errorf("SSE start: %w", err)
+			return nil, fmt.Errorf("HTTP start: %w", err)
 		}
-	} else {
-		return fmt.Errorf("server %q: either command or url must be set", s.cfg.Name)
+	default:
+		return nil, fmt.Errorf("server %q: unknown transport %q", s.cfg.Name, s.cfg.Transport)
 	}
 
-	// MCP handshake
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == mcp.MethodNotificationToolsListChanged {
+			// Notifications arrive on the transport's read loop, which must
+			// keep running to answer tools/list, so rediscover from supervise
+			select {
+			case s.refresh <- struct{}{}:
+			default:
+			}
+		}
+	})
+	return c, nil
+}
+
+// httpClient returns a client that adds the configured headers and OAuth
+// token to each request. Secrets are resolved on every connect so rotated
+// values are picked up. It has no overall timeout: SSE streams stay open.
+func (s *serverConn) httpClient() (*http.Client, error) {
+	headers := make(map[string]string, len(s.cfg.Headers))
+	for name, value := range s.cfg.Headers {
+		v, err := resolveSecret(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+		headers[name] = v
+	}
+	return &http.Client{Transport: &authTransport{
+		base:    http.DefaultTransport,
+		headers: headers,
+		tokens:  s.tokens,
+	}}, nil
+}
+
+// initialize performs the MCP handshake.
+func (s *serverConn) initialize(ctx context.Context, c *mcpgo.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
 	initReq := mcp.InitializeRequest{}
 	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
 	initReq.Params.ClientInfo = mcp.Implementation{Name: "lingti-bot", Version: "1.0"}
-	_, err = c.Initialize(ctx, initReq)
-	if err != nil {
-		_ = c.Close()
+	if s.cfg.Sampling.enabled() {
+		initReq.Params.Capabilities.Sampling = &struct{}{}
+	}
+	if _, err := c.Initialize(ctx, initReq); err != nil {
 		return fmt.Errorf("initialize: %w", err)
 	}
-
-	s.client = c
 	return nil
 }
 
-func (s *serverConn) discoverTools() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// logStderr forwards a stdio server's stderr to the debug log; an unread
+// stderr pipe would eventually block the server.
+func (s *serverConn) logStderr(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		logger.Debug("[MCP] %s: %s", s.cfg.Name, scanner.Text())
+	}
+	// Keep draining past a line too long for the scanner
+	_, _ = io.Copy(io.Discard, r)
+}
+
+func (s *serverConn) discoverTools(ctx context.Context) error {
+	s.mu.Lock()
+	c := s.client
+	s.mu.Unlock()
+	if c == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	result, err := s.client.ListTools(ctx, mcp.ListToolsRequest{})
+	result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
 	if err != nil {
 		return err
 	}
 
-	prefix := "mcp_" + sanitizeName(s.cfg.Name) + "_"
+	prefix := s.prefix()
+	var tools []Tool
+	seen := make(map[string]string) // full name -> remote name
 	for _, t := range result.Tools {
+		if !s.cfg.exposes(t.Name) {
+			continue
+		}
 		schema, err := toolSchema(t)
 		if err != nil {
 			logger.Warn("[MCP] skipping tool %q from %q: %v", t.Name, s.cfg.Name, err)
 			continue
 		}
-		s.tools = append(s.tools, Tool{
-			FullName:    prefix + sanitizeName(t.Name),
-			Description: t.Description,
+		name := t.Name
+		if alias, ok := s.cfg.ToolAliases[t.Name]; ok {
+			name = alias
+		}
+		fullName := prefix + sanitizeName(name)
+		if other, ok := seen[fullName]; ok {
+			logger.Warn("[MCP] skipping tool %q from %q: %s is already used by %q", t.Name, s.cfg.Name, fullName, other)
+			continue
+		}
+		seen[fullName] = t.Name
+		description := t.Description
+		if d, ok := s.cfg.ToolDescriptions[t.Name]; ok {
+			description = d
+		}
+		tools = append(tools, Tool{
+			FullName:    fullName,
+			Description: description,
 			InputSchema: schema,
+			Server:      s.cfg.Name,
 			server:      s,
 			remoteName:  t.Name,
 		})
 	}
+
+	s.mu.Lock()
+	if s.client == c {
+		s.tools = tools
+	}
+	s.mu.Unlock()
 	return nil
 }
 
 func (s *serverConn) call(ctx context.Context, toolName string, args map[string]any) (string, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	c := s.client
+	s.mu.Unlock()
+	if c == nil {
+		return "", fmt.Errorf("MCP server %q is not connected", s.cfg.Name)
+	}
 
 	req := mcp.CallToolRequest{}
 	req.Params.Name = toolName
@@ -190,8 +753,20 @@ func (s *serverConn) call(ctx context.Context, toolName string, args map[string]
 	}
 	req.Params.Arguments = args
 
-	result, err := s.client.CallTool(ctx, req)
+	callCtx, cancel := context.WithTimeout(ctx, s.cfg.callTimeout())
+	defer cancel()
+	result, err := c.CallTool(callCtx, req)
 	if err != nil {
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return "", fmt.Errorf("call %q: timed out after %s", toolName, s.cfg.callTimeout())
+		}
+		if ctx.Err() == nil {
+			// The server may be gone; check now instead of at the next ping
+			select {
+			case s.wake <- struct{}{}:
+			default:
+			}
+		}
 		return "", fmt.Errorf("call %q: %w", toolName, err)
 	}
 
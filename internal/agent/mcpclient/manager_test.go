@@ -0,0 +1,75 @@
+package mcpclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newBrowserServer has a few tools named like chrome-devtools-mcp's
+func newBrowserServer() *server.MCPServer {
+	s := server.NewMCPServer("browser", "1.0", server.WithToolCapabilities(true))
+	for _, name := range []string{"take_snapshot", "take_screenshot", "click", "evaluate_script"} {
+		s.AddTool(mcp.NewTool(name, mcp.WithDescription("original "+name)), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("called " + req.Params.Name), nil
+		})
+	}
+	return s
+}
+
+func TestManager_ToolFilters(t *testing.T) {
+	ts := serveSSE(t, newBrowserServer(), "127.0.0.1:0")
+	defer stopSSE(ts)
+
+	m := newManager([]ServerConfig{{
+		Name:             "chrome",
+		URL:              ts.URL + "/sse",
+		IncludeTools:     []string{"take_*", "click"},
+		ExcludeTools:     []string{"*_screenshot"},
+		ToolAliases:      map[string]string{"take_snapshot": "snapshot"},
+		ToolDescriptions: map[string]string{"click": "Click an element by uid"},
+		Lazy:             true,
+	}}, testSupervision)
+	defer m.Close()
+
+	got := map[string]string{}
+	for _, tool := range m.AllTools() {
+		if tool.Server != "chrome" {
+			t.Errorf("tool %s has server %q", tool.FullName, tool.Server)
+		}
+		got[tool.FullName] = tool.Description
+	}
+	want := map[string]string{
+		"mcp_chrome_snapshot": "original take_snapshot",
+		"mcp_chrome_click":    "Click an element by uid",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("tools = %v, want %v", got, want)
+	}
+	for name, desc := range want {
+		if got[name] != desc {
+			t.Errorf("%s description = %q, want %q", name, got[name], desc)
+		}
+	}
+
+	// Aliased tools are called by their original name; filtered ones are gone
+	if out, err := m.Call(context.Background(), "mcp_chrome_snapshot", nil); err != nil || out != "called take_snapshot" {
+		t.Errorf("call alias = %q, %v", out, err)
+	}
+	if _, err := m.Call(context.Background(), "mcp_chrome_evaluate_script", nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("call excluded tool: err = %v, want not found", err)
+	}
+
+	if st := m.Status()[0]; !st.Lazy || st.Tools != 2 {
+		t.Errorf("status = %+v, want lazy with 2 tools", st)
+	}
+	if tools, err := m.ServerTools("chrome"); err != nil || len(tools) != 2 {
+		t.Errorf("ServerTools = %d tools, %v", len(tools), err)
+	}
+	if _, err := m.ServerTools("nope"); err == nil {
+		t.Error("ServerTools of an unknown server succeeded")
+	}
+}
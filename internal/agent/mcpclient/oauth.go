@@ -0,0 +1,366 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pltanton/lingti-bot/internal/logger"
+)
+
+// OAuth flows
+const (
+	FlowClientCredentials = "client_credentials"
+	FlowDevice            = "device"
+)
+
+// tokenExpiryMargin renews tokens this long before they expire
+const tokenExpiryMargin = time.Minute
+
+// ErrLoginRequired is returned when a device-flow server has no usable token;
+// `lingti-bot mcp test <name>` signs in interactively.
+var ErrLoginRequired = errors.New("sign-in required")
+
+// OAuthConfig obtains a bearer token for an HTTP MCP server.
+type OAuthConfig struct {
+	// Flow is "client_credentials" (machine to machine) or "device" (a user
+	// signs in once in a browser; the refresh token is cached).
+	Flow          string   `yaml:"flow"`
+	TokenURL      string   `yaml:"token_url"`
+	DeviceAuthURL string   `yaml:"device_auth_url,omitempty"` // device flow only
+	ClientID      string   `yaml:"client_id"`
+	ClientSecret  string   `yaml:"client_secret,omitempty"` // may be a secret reference
+	Scopes        []string `yaml:"scopes,omitempty"`
+}
+
+func (o *OAuthConfig) validate() error {
+	switch o.Flow {
+	case FlowClientCredentials:
+		if o.ClientSecret == "" {
+			return fmt.Errorf("oauth: client_credentials needs client_secret")
+		}
+	case FlowDevice:
+		if o.DeviceAuthURL == "" {
+			return fmt.Errorf("oauth: device flow needs device_auth_url")
+		}
+	default:
+		return fmt.Errorf("oauth: unknown flow %q (want %s or %s)", o.Flow, FlowClientCredentials, FlowDevice)
+	}
+	if o.TokenURL == "" || o.ClientID == "" {
+		return fmt.Errorf("oauth: token_url and client_id are required")
+	}
+	return nil
+}
+
+// token is a cached OAuth token
+type token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+func (t *token) valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Until(t.Expiry) > tokenExpiryMargin)
+}
+
+// tokenResponse is a token endpoint's answer (RFC 6749 §5, RFC 8628 §3.5)
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// DeviceCode is what a user needs to approve a device-flow sign-in.
+type DeviceCode struct {
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	deviceCode              string
+	interval                time.Duration
+	expiry                  time.Time
+}
+
+// tokenSource hands out bearer tokens for one server, renewing them from the
+// refresh token or the client credentials and caching them on disk.
+type tokenSource struct {
+	server string
+	cfg    OAuthConfig
+	http   *http.Client
+
+	mu  sync.Mutex
+	tok *token
+}
+
+func newTokenSource(server string, cfg OAuthConfig) *tokenSource {
+	return &tokenSource{server: server, cfg: cfg, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Token returns a valid access token, renewing it if needed.
+func (ts *tokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.tok == nil {
+		ts.tok = loadToken(ts.cacheKey())
+	}
+	if ts.tok.valid() {
+		return ts.tok.AccessToken, nil
+	}
+
+	var (
+		tok *token
+		err error
+	)
+	if ts.tok != nil && ts.tok.RefreshToken != "" {
+		if tok, err = ts.request(ctx, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {ts.tok.RefreshToken}}); err == nil && tok.RefreshToken == "" {
+			// Servers may keep the refresh token without repeating it
+			tok.RefreshToken = ts.tok.RefreshToken
+		}
+	}
+	if tok == nil {
+		if ts.cfg.Flow != FlowClientCredentials {
+			if err != nil {
+				return "", fmt.Errorf("%w for MCP server %q: refreshing the token failed (%v); run `lingti-bot mcp test %s`", ErrLoginRequired, ts.server, err, ts.server)
+			}
+			return "", fmt.Errorf("%w for MCP server %q: run `lingti-bot mcp test %s`", ErrLoginRequired, ts.server, ts.server)
+		}
+		if tok, err = ts.request(ctx, url.Values{"grant_type": {"client_credentials"}}); err != nil {
+			return "", err
+		}
+	}
+	ts.save(tok)
+	return tok.AccessToken, nil
+}
+
+// invalidate drops the access token after the server rejected it; the
+// refresh token is kept.
+func (ts *tokenSource) invalidate() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.tok != nil {
+		ts.tok.AccessToken = ""
+	}
+}
+
+// Login runs the device flow: it asks the authorization server for a code,
+// shows it with prompt and waits for the user to approve it.
+func (ts *tokenSource) Login(ctx context.Context, prompt func(DeviceCode)) error {
+	code, err := ts.deviceCode(ctx)
+	if err != nil {
+		return err
+	}
+	prompt(*code)
+
+	interval := code.interval
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		if time.Now().After(code.expiry) {
+			return fmt.Errorf("the sign-in code expired")
+		}
+		tok, err := ts.request(ctx, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {code.deviceCode},
+		})
+		var oauthErr *oauthError
+		switch {
+		case errors.As(err, &oauthErr) && oauthErr.code == "authorization_pending":
+			continue
+		case errors.As(err, &oauthErr) && oauthErr.code == "slow_down":
+			interval += 5 * time.Second
+			continue
+		case err != nil:
+			return err
+		}
+		ts.mu.Lock()
+		ts.save(tok)
+		ts.mu.Unlock()
+		return nil
+	}
+}
+
+func (ts *tokenSource) deviceCode(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{"client_id": {ts.cfg.ClientID}}
+	if len(ts.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(ts.cfg.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.cfg.DeviceAuthURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := ts.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("device authorization: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var data struct {
+		DeviceCode
+		DeviceCodeValue string `json:"device_code"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil || data.DeviceCodeValue == "" {
+		return nil, fmt.Errorf("device authorization: unexpected response: %s", strings.TrimSpace(string(body)))
+	}
+	code := data.DeviceCode
+	code.deviceCode = data.DeviceCodeValue
+	code.interval = 5 * time.Second
+	if data.Interval > 0 {
+		code.interval = time.Duration(data.Interval) * time.Second
+	}
+	code.expiry = time.Now().Add(time.Duration(max(data.ExpiresIn, 60)) * time.Second)
+	return &code, nil
+}
+
+// oauthError is an error response from the token endpoint
+type oauthError struct {
+	code, description string
+}
+
+func (e *oauthError) Error() string {
+	if e.description != "" {
+		return fmt.Sprintf("oauth: %s: %s", e.code, e.description)
+	}
+	return "oauth: " + e.code
+}
+
+// request calls the token endpoint with the client's credentials added
+func (ts *tokenSource) request(ctx context.Context, form url.Values) (*token, error) {
+	form.Set("client_id", ts.cfg.ClientID)
+	if ts.cfg.ClientSecret != "" {
+		secret, err := resolveSecret(ts.cfg.ClientSecret)
+		if err != nil {
+			return nil, fmt.Errorf("oauth client_secret: %w", err)
+		}
+		form.Set("client_secret", secret)
+	}
+	if len(ts.cfg.Scopes) > 0 && form.Get("grant_type") == "client_credentials" {
+		form.Set("scope", strings.Join(ts.cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := ts.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var data tokenResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("token request: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if data.Error != "" {
+		return nil, &oauthError{code: data.Error, description: data.ErrorDescription}
+	}
+	if resp.StatusCode != http.StatusOK || data.AccessToken == "" {
+		return nil, fmt.Errorf("token request: HTTP %d without an access token", resp.StatusCode)
+	}
+
+	tok := &token{AccessToken: data.AccessToken, RefreshToken: data.RefreshToken}
+	if data.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+// save keeps tok in memory and in the cache file; the caller holds ts.mu
+func (ts *tokenSource) save(tok *token) {
+	ts.tok = tok
+	if err := storeToken(ts.cacheKey(), tok); err != nil {
+		// The token still works for this process
+		logger.Warn("[MCP] failed to cache OAuth token for %q: %v", ts.server, err)
+	}
+}
+
+// cacheKey identifies the token; a changed token URL or client ID needs a
+// new token
+func (ts *tokenSource) cacheKey() string {
+	return ts.server + "|" + ts.cfg.TokenURL + "|" + ts.cfg.ClientID
+}
+
+// --- token cache file ---
+
+var tokenCacheMu sync.Mutex
+
+// tokenCachePath is where OAuth tokens of MCP servers are kept
+func tokenCachePath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lingti", "mcp-tokens.json")
+}
+
+func readTokenCache() map[string]*token {
+	tokens := make(map[string]*token)
+	if data, err := os.ReadFile(tokenCachePath()); err == nil {
+		json.Unmarshal(data, &tokens)
+	}
+	return tokens
+}
+
+func writeTokenCache(tokens map[string]*token) error {
+	path := tokenCachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func loadToken(key string) *token {
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
+	return readTokenCache()[key]
+}
+
+func storeToken(key string, tok *token) error {
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
+	tokens := readTokenCache()
+	tokens[key] = tok
+	return writeTokenCache(tokens)
+}
+
+// ForgetTokens removes the cached OAuth tokens of a server.
+func ForgetTokens(server string) error {
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
+	tokens := readTokenCache()
+	n := len(tokens)
+	for key := range tokens {
+		if strings.HasPrefix(key, server+"|") {
+			delete(tokens, key)
+		}
+	}
+	if len(tokens) == n {
+		return nil
+	}
+	return writeTokenCache(tokens)
+}
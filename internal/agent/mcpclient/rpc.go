@@ -0,0 +1,197 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pltanton/lingti-bot/internal/logger"
+)
+
+// requestHandler answers a request the server sends to the client, such as
+// sampling/createMessage.
+type requestHandler func(ctx context.Context, method string, params json.RawMessage) (any, error)
+
+// rpcPeer is the JSON-RPC bookkeeping shared by the transports: it matches
+// responses to pending requests and dispatches notifications and server
+// requests. mcp-go's own transports drop server requests, so they can't
+// support sampling.
+type rpcPeer struct {
+	mu       sync.Mutex
+	pending  map[int64]chan *transport.JSONRPCResponse
+	notify   func(mcp.JSONRPCNotification)
+	requests requestHandler
+	err      error // set once the connection is gone
+}
+
+// message is any JSON-RPC message; Method is set for requests and
+// notifications, ID for requests and responses
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// reply answers a server request as {"jsonrpc":"2.0","id":...,"result":...}
+type reply struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+func (p *rpcPeer) setNotificationHandler(handler func(mcp.JSONRPCNotification)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notify = handler
+}
+
+// expect registers a request before it is sent
+func (p *rpcPeer) expect(id int64) (chan *transport.JSONRPCResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.pending == nil {
+		p.pending = make(map[int64]chan *transport.JSONRPCResponse)
+	}
+	ch := make(chan *transport.JSONRPCResponse, 1)
+	p.pending[id] = ch
+	return ch, nil
+}
+
+func (p *rpcPeer) forget(id int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, id)
+}
+
+// await waits for the response to a request registered with expect
+func (p *rpcPeer) await(ctx context.Context, id int64, ch chan *transport.JSONRPCResponse) (*transport.JSONRPCResponse, error) {
+	defer p.forget(id)
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			return nil, p.err
+		}
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fail ends all pending requests once the connection is gone
+func (p *rpcPeer) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return
+	}
+	p.err = err
+	for id, ch := range p.pending {
+		close(ch)
+		delete(p.pending, id)
+	}
+}
+
+// dispatch handles one message from the server. Server requests are
+// answered in the background with send, so a request that arrives during a
+// tool call (as sampling does) doesn't block reading the call's result.
+func (p *rpcPeer) dispatch(ctx context.Context, data []byte, send func(context.Context, any) error) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		logger.Debug("[MCP] ignoring malformed message: %v", err)
+		return
+	}
+
+	switch {
+	case msg.Method != "" && msg.ID != nil:
+		go p.answer(ctx, msg, send)
+	case msg.Method != "":
+		var notification mcp.JSONRPCNotification
+		if err := json.Unmarshal(data, &notification); err != nil {
+			return
+		}
+		p.mu.Lock()
+		notify := p.notify
+		p.mu.Unlock()
+		if notify != nil {
+			notify(notification)
+		}
+	default:
+		var id int64
+		if err := json.Unmarshal(msg.ID, &id); err != nil {
+			return
+		}
+		resp := &transport.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: &id, Result: msg.Result}
+		if msg.Error != nil {
+			resp.Error = &struct {
+				Code    int             `json:"code"`
+				Message string          `json:"message"`
+				Data    json.RawMessage `json:"data"`
+			}{msg.Error.Code, msg.Error.Message, msg.Error.Data}
+		}
+		p.mu.Lock()
+		ch, ok := p.pending[id]
+		delete(p.pending, id)
+		p.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+}
+
+func (p *rpcPeer) answer(ctx context.Context, msg message, send func(context.Context, any) error) {
+	p.mu.Lock()
+	handle := p.requests
+	p.mu.Unlock()
+
+	r := reply{JSONRPC: mcp.JSONRPC_VERSION, ID: msg.ID}
+	var (
+		result any
+		err    error
+	)
+	switch {
+	case msg.Method == string(mcp.MethodPing):
+		result = struct{}{}
+	case handle != nil:
+		result, err = handle(ctx, msg.Method, msg.Params)
+	default:
+		err = errMethodNotFound
+	}
+	var rpcErr *rpcError
+	switch {
+	case errors.As(err, &rpcErr):
+		r.Error = rpcErr
+	case errors.Is(err, errMethodNotFound):
+		r.Error = &rpcError{Code: mcp.METHOD_NOT_FOUND, Message: fmt.Sprintf("method %q is not supported", msg.Method)}
+	case err != nil:
+		r.Error = &rpcError{Code: mcp.INTERNAL_ERROR, Message: err.Error()}
+	default:
+		r.Result = result
+	}
+	if err := send(ctx, r); err != nil {
+		logger.Warn("[MCP] failed to answer %s: %v", msg.Method, err)
+	}
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// errMethodNotFound is returned by request handlers for methods they don't
+// implement
+var errMethodNotFound = errors.New("method not found")
@@ -0,0 +1,239 @@
+package mcpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pltanton/lingti-bot/internal/logger"
+)
+
+// methodCreateMessage is the sampling request a server sends to the client
+const methodCreateMessage = "sampling/createMessage"
+
+// defaultSamplingMaxTokens caps sampled completions of servers without a
+// max_tokens policy
+const defaultSamplingMaxTokens = 1024
+
+// SamplingPolicy controls whether a server may ask lingti's model for
+// completions (MCP sampling/createMessage).
+type SamplingPolicy struct {
+	Allow      bool `yaml:"allow"`
+	MaxTokens  int  `yaml:"max_tokens,omitempty"`  // cap per request (default 1024)
+	RequireYes bool `yaml:"require_yes,omitempty"` // refuse unless the sampler approves (lingti: --yes)
+}
+
+func (p *SamplingPolicy) enabled() bool {
+	return p != nil && p.Allow
+}
+
+func (p *SamplingPolicy) maxTokens(requested int) int {
+	limit := defaultSamplingMaxTokens
+	if p.MaxTokens > 0 {
+		limit = p.MaxTokens
+	}
+	if requested > 0 && requested < limit {
+		return requested
+	}
+	return limit
+}
+
+// SamplingMessage is one message of a sampling request.
+type SamplingMessage struct {
+	Role string `json:"role"` // "user" or "assistant"
+	Text string `json:"text"`
+}
+
+// SamplingRequest is a server's request for a completion.
+type SamplingRequest struct {
+	Server       string
+	Messages     []SamplingMessage
+	SystemPrompt string
+	MaxTokens    int // already capped by the server's policy
+}
+
+// SamplingResult is the completion returned to the server.
+type SamplingResult struct {
+	Text       string
+	Model      string
+	StopReason string // "endTurn" or "maxTokens"
+}
+
+// Sampler answers sampling requests, normally with the agent that owns the
+// Manager.
+type Sampler interface {
+	// Sample runs the completion.
+	Sample(ctx context.Context, req SamplingRequest) (SamplingResult, error)
+	// ApproveSampling decides a request of a server whose policy has
+	// RequireYes set.
+	ApproveSampling(ctx context.Context, req SamplingRequest) bool
+}
+
+// SetSampler routes sampling requests of servers whose policy allows them to
+// s. Without a sampler they are refused.
+func (m *Manager) SetSampler(s Sampler) {
+	m.samplerMu.Lock()
+	defer m.samplerMu.Unlock()
+	m.sampler = s
+}
+
+func (m *Manager) currentSampler() Sampler {
+	if m == nil {
+		return nil
+	}
+	m.samplerMu.Lock()
+	defer m.samplerMu.Unlock()
+	return m.sampler
+}
+
+// handleRequest answers requests the server sends to the client.
+func (s *serverConn) handleRequest(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	if method != methodCreateMessage {
+		return nil, errMethodNotFound
+	}
+	var req mcp.CreateMessageRequest
+	if err := json.Unmarshal(params, &req.Params); err != nil {
+		return nil, &rpcError{Code: mcp.INVALID_PARAMS, Message: err.Error()}
+	}
+	return s.sample(ctx, req)
+}
+
+// sample enforces the server's sampling policy, runs the request and
+// records it in the audit log.
+func (s *serverConn) sample(ctx context.Context, create mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	policy := s.cfg.Sampling
+	req := SamplingRequest{Server: s.cfg.Name, SystemPrompt: create.Params.SystemPrompt}
+	for _, msg := range create.Params.Messages {
+		text := ""
+		switch c := msg.Content.(type) {
+		case mcp.TextContent:
+			text = c.Text
+		case map[string]any:
+			// Unmarshalled content is a plain map
+			if t, _ := c["type"].(string); t == "text" {
+				text, _ = c["text"].(string)
+			} else {
+				text = fmt.Sprintf("[%s content]", t)
+			}
+		default:
+			text = "[unsupported content]"
+		}
+		req.Messages = append(req.Messages, SamplingMessage{Role: string(msg.Role), Text: text})
+	}
+
+	entry := samplingAudit{Time: time.Now(), Server: s.cfg.Name, RequestedTokens: create.Params.MaxTokens, SystemPrompt: req.SystemPrompt, Messages: req.Messages}
+	refuse := func(reason string) (*mcp.CreateMessageResult, error) {
+		entry.Decision, entry.Reason = "denied", reason
+		audit(entry)
+		return nil, &rpcError{Code: mcp.INVALID_REQUEST, Message: "sampling refused: " + reason}
+	}
+
+	if !policy.enabled() {
+		return refuse("sampling is not allowed for this server")
+	}
+	req.MaxTokens = policy.maxTokens(create.Params.MaxTokens)
+	entry.MaxTokens = req.MaxTokens
+	sampler := s.manager.currentSampler()
+	if sampler == nil {
+		return refuse("no model is available to this client")
+	}
+	if policy.RequireYes && !sampler.ApproveSampling(ctx, req) {
+		return refuse("not approved")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.callTimeout())
+	defer cancel()
+	start := time.Now()
+	result, err := sampler.Sample(ctx, req)
+	entry.Duration = time.Since(start).Round(time.Millisecond).String()
+	if err != nil {
+		entry.Decision, entry.Reason = "failed", err.Error()
+		audit(entry)
+		return nil, fmt.Errorf("sampling failed: %w", err)
+	}
+	entry.Decision, entry.Model, entry.Response = "allowed", result.Model, result.Text
+	audit(entry)
+
+	stop := result.StopReason
+	if stop == "" {
+		stop = "endTurn"
+	}
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(result.Text)},
+		Model:           result.Model,
+		StopReason:      stop,
+	}, nil
+}
+
+// --- audit log ---
+
+// samplingAuditMaxText truncates texts in the audit log
+const samplingAuditMaxText = 4000
+
+// samplingAudit is one line of the sampling audit log
+type samplingAudit struct {
+	Time            time.Time         `json:"time"`
+	Server          string            `json:"server"`
+	Decision        string            `json:"decision"` // allowed, denied or failed
+	Reason          string            `json:"reason,omitempty"`
+	RequestedTokens int               `json:"requested_tokens,omitempty"`
+	MaxTokens       int               `json:"max_tokens,omitempty"`
+	Model           string            `json:"model,omitempty"`
+	Duration        string            `json:"duration,omitempty"`
+	SystemPrompt    string            `json:"system_prompt,omitempty"`
+	Messages        []SamplingMessage `json:"messages"`
+	Response        string            `json:"response,omitempty"`
+}
+
+var auditMu sync.Mutex
+
+// SamplingAuditPath is where every sampling request is recorded, one JSON
+// object per line.
+func SamplingAuditPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lingti", "mcp-sampling.jsonl")
+}
+
+func audit(entry samplingAudit) {
+	logger.Info("[MCP] sampling request from %q: %s %s", entry.Server, entry.Decision, entry.Reason)
+
+	entry.SystemPrompt = truncateText(entry.SystemPrompt)
+	entry.Response = truncateText(entry.Response)
+	messages := make([]SamplingMessage, len(entry.Messages))
+	for i, m := range entry.Messages {
+		messages[i] = SamplingMessage{Role: m.Role, Text: truncateText(m.Text)}
+	}
+	entry.Messages = messages
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	path := SamplingAuditPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		logger.Warn("[MCP] failed to write sampling audit log: %v", err)
+		return
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		logger.Warn("[MCP] failed to write sampling audit log: %v", err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+func truncateText(s string) string {
+	if len(s) <= samplingAuditMaxText {
+		return s
+	}
+	return strings.ToValidUTF8(s[:samplingAuditMaxText], "") + "…"
+}
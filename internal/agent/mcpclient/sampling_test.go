@@ -0,0 +1,156 @@
+package mcpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// samplingServer serves newFakeServer over Streamable HTTP and adds an "ask"
+// tool that asks the client for a completion while the call is running, the
+// way servers use sampling.
+type samplingServer struct {
+	fake    http.Handler
+	replies chan message
+}
+
+func (f *samplingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var msg message
+	json.Unmarshal(body, &msg)
+	if r.Method == http.MethodPost && msg.Method == "" && msg.ID != nil {
+		// The client's reply to our sampling request
+		f.replies <- msg
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	var call struct {
+		Params struct {
+			Name string `json:"name"`
+		} `json:"params"`
+	}
+	json.Unmarshal(body, &call)
+	if msg.Method != "tools/call" || call.Params.Name != "ask" {
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		f.fake.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", `{"jsonrpc":"2.0","id":"s-1","method":"sampling/createMessage","params":{"messages":[{"role":"user","content":{"type":"text","text":"hello"}}],"systemPrompt":"be brief","maxTokens":500}}`)
+	w.(http.Flusher).Flush()
+
+	var text string
+	select {
+	case reply := <-f.replies:
+		if reply.Error != nil {
+			text = "error: " + reply.Error.Message
+		} else {
+			var result struct {
+				Model   string `json:"model"`
+				Content struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			}
+			json.Unmarshal(reply.Result, &result)
+			text = result.Content.Text + " (" + result.Model + ")"
+		}
+	case <-time.After(5 * time.Second):
+		text = "no reply"
+	}
+	result, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      msg.ID,
+		"result":  map[string]any{"content": []map[string]any{{"type": "text", "text": text}}},
+	})
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", result)
+}
+
+// fakeSampler records requests and echoes the last message
+type fakeSampler struct {
+	mu      sync.Mutex
+	approve bool
+	reqs    []SamplingRequest
+}
+
+func (f *fakeSampler) Sample(_ context.Context, req SamplingRequest) (SamplingResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reqs = append(f.reqs, req)
+	return SamplingResult{Text: "sampled " + req.Messages[len(req.Messages)-1].Text, Model: "test-model"}, nil
+}
+
+func (f *fakeSampler) ApproveSampling(context.Context, SamplingRequest) bool {
+	return f.approve
+}
+
+func TestManager_Sampling(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	fake := newFakeServer()
+	fake.AddTool(mcp.NewTool("ask"), nil) // answered by samplingServer
+	srv := httptest.NewServer(&samplingServer{
+		fake:    &fakeStreamable{mcp: fake},
+		replies: make(chan message, 1),
+	})
+	defer srv.Close()
+
+	m := newManager([]ServerConfig{
+		{Name: "allowed", URL: srv.URL, Transport: TransportHTTP, Sampling: &SamplingPolicy{Allow: true, MaxTokens: 100}},
+		{Name: "off", URL: srv.URL, Transport: TransportHTTP},
+		{Name: "gated", URL: srv.URL, Transport: TransportHTTP, Sampling: &SamplingPolicy{Allow: true, RequireYes: true}},
+	}, testSupervision)
+	defer m.Close()
+	sampler := &fakeSampler{}
+	m.SetSampler(sampler)
+
+	tests := []struct {
+		tool, want string
+	}{
+		{"mcp_allowed_ask", "sampled hello (test-model)"},
+		{"mcp_off_ask", "error: sampling refused: sampling is not allowed for this server"},
+		{"mcp_gated_ask", "error: sampling refused: not approved"},
+	}
+	for _, tt := range tests {
+		if out, err := m.Call(context.Background(), tt.tool, nil); err != nil || out != tt.want {
+			t.Errorf("%s = %q, %v; want %q", tt.tool, out, err, tt.want)
+		}
+	}
+
+	if len(sampler.reqs) != 1 {
+		t.Fatalf("sampler got %d requests, want 1", len(sampler.reqs))
+	}
+	if req := sampler.reqs[0]; req.Server != "allowed" || req.MaxTokens != 100 || req.SystemPrompt != "be brief" {
+		t.Errorf("request = %+v, want server allowed, max tokens capped at 100", req)
+	}
+
+	// Every request is audited, refused ones included
+	f, err := os.Open(SamplingAuditPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var decisions []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry samplingAudit
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		decisions = append(decisions, entry.Server+":"+entry.Decision)
+	}
+	if got := strings.Join(decisions, " "); got != "allowed:allowed off:denied gated:denied" {
+		t.Errorf("audit log = %s", got)
+	}
+}
@@ -0,0 +1,65 @@
+package mcpclient
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// secretVarPattern matches ${NAME} references inside a value
+var secretVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveSecret resolves a config value that may reference a secret instead
+// of holding it, so tokens can stay out of ~/.lingti.yaml:
+//
+//	env:NAME              the environment variable NAME
+//	file:/path/to/token   the trimmed contents of a file (~ is expanded)
+//	Bearer ${NAME}        ${NAME} anywhere in the value is replaced
+//
+// Other values are returned unchanged. Referencing an unset variable or an
+// unreadable file is an error, so a missing secret fails the connection
+// instead of sending an empty credential.
+func resolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(value, "file:"):
+		path := strings.TrimPrefix(value, "file:")
+		if strings.HasPrefix(path, "~/") {
+			home, _ := os.UserHomeDir()
+			path = filepath.Join(home, path[2:])
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	var missing []string
+	resolved := secretVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := secretVarPattern.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// IsSecretRef reports whether a value references a secret rather than
+// holding it, e.g. to decide whether it is safe to display.
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, "env:") || strings.HasPrefix(value, "file:") || secretVarPattern.MatchString(value)
+}
@@ -0,0 +1,157 @@
+package mcpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sseTransport is the legacy HTTP+SSE transport: the server sends every
+// message on one event stream, and the client POSTs its messages to the
+// endpoint named in the stream's first event.
+type sseTransport struct {
+	url    string
+	client *http.Client
+	peer   rpcPeer
+
+	mu       sync.Mutex
+	endpoint string
+	ready    chan struct{} // closed once the endpoint is known
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var _ transport.Interface = (*sseTransport)(nil)
+
+func newSSETransport(url string, client *http.Client, requests requestHandler) *sseTransport {
+	t := &sseTransport{url: url, client: client, ready: make(chan struct{})}
+	t.peer.requests = requests
+	return t
+}
+
+// Start opens the event stream and waits for the endpoint; the stream stays
+// open until ctx is done or Close is called.
+func (t *sseTransport) Start(ctx context.Context) error {
+	t.ctx, t.cancel = context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		t.cancel()
+		return fmt.Errorf("failed to connect to SSE stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		t.cancel()
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	go t.read(resp.Body)
+
+	select {
+	case <-t.ready:
+		return nil
+	case <-t.ctx.Done():
+		return fmt.Errorf("stream closed while waiting for the endpoint")
+	case <-time.After(30 * time.Second):
+		t.cancel()
+		return fmt.Errorf("timeout waiting for the endpoint")
+	}
+}
+
+func (t *sseTransport) read(body io.ReadCloser) {
+	defer body.Close()
+	base, _ := url.Parse(t.url)
+	readEvents(body, func(event string, data []byte) bool {
+		switch event {
+		case "endpoint":
+			endpoint, err := base.Parse(string(data))
+			if err != nil || endpoint.Host != base.Host {
+				t.peer.fail(fmt.Errorf("invalid endpoint %q", data))
+				return false
+			}
+			t.mu.Lock()
+			if t.endpoint == "" {
+				t.endpoint = endpoint.String()
+				close(t.ready)
+			}
+			t.mu.Unlock()
+		case "", "message":
+			t.peer.dispatch(t.ctx, data, t.send)
+		}
+		return true
+	})
+	t.peer.fail(errors.New("SSE stream closed"))
+	t.cancel()
+}
+
+func (t *sseTransport) send(ctx context.Context, msg any) error {
+	t.mu.Lock()
+	endpoint := t.endpoint
+	t.mu.Unlock()
+	if endpoint == "" {
+		return errors.New("endpoint not received")
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("request failed with HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
+
+func (t *sseTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	ch, err := t.peer.expect(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.send(ctx, request); err != nil {
+		t.peer.forget(request.ID)
+		return nil, err
+	}
+	return t.peer.await(ctx, request.ID, ch)
+}
+
+func (t *sseTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	return t.send(ctx, notification)
+}
+
+func (t *sseTransport) SetNotificationHandler(handler func(mcp.JSONRPCNotification)) {
+	t.peer.setNotificationHandler(handler)
+}
+
+func (t *sseTransport) Close() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.peer.fail(errors.New("transport closed"))
+	return nil
+}
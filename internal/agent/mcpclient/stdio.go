@@ -0,0 +1,150 @@
+package mcpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// stdioTransport runs an MCP server as a subprocess and speaks JSON-RPC over
+// its stdin and stdout, one message per line.
+type stdioTransport struct {
+	command string
+	args    []string
+	env     []string
+
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	logStderr  func(io.Reader)
+	stderrDone chan struct{}
+
+	writeMu sync.Mutex
+	peer    rpcPeer
+	ctx     context.Context
+	exited  chan struct{}
+}
+
+var _ transport.Interface = (*stdioTransport)(nil)
+
+// newStdioTransport returns a transport for command. logStderr is given the
+// server's stderr and must read it until EOF.
+func newStdioTransport(command string, env []string, args []string, requests requestHandler, logStderr func(io.Reader)) *stdioTransport {
+	t := &stdioTransport{
+		command:    command,
+		args:       args,
+		env:        env,
+		logStderr:  logStderr,
+		stderrDone: make(chan struct{}),
+		exited:     make(chan struct{}),
+	}
+	t.peer.requests = requests
+	return t
+}
+
+// Start spawns the server; it is killed when ctx is done.
+func (t *stdioTransport) Start(ctx context.Context) error {
+	t.ctx = ctx
+	cmd := exec.CommandContext(ctx, t.command, t.args...)
+	cmd.Env = append(os.Environ(), t.env...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", t.command, err)
+	}
+	t.cmd, t.stdin = cmd, stdin
+
+	go func() {
+		t.logStderr(stderr)
+		close(t.stderrDone)
+	}()
+	go t.read(stdout)
+	return nil
+}
+
+func (t *stdioTransport) read(stdout io.Reader) {
+	r := bufio.NewReaderSize(stdout, 64*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 1 {
+			t.peer.dispatch(t.ctx, line, t.send)
+		}
+		if err != nil {
+			t.peer.fail(errors.New("server exited"))
+			// Wait closes the pipes, so stderr must be read to the end first
+			<-t.stderrDone
+			_ = t.cmd.Wait()
+			close(t.exited)
+			return
+		}
+	}
+}
+
+func (t *stdioTransport) send(_ context.Context, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to server: %w", err)
+	}
+	return nil
+}
+
+func (t *stdioTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	ch, err := t.peer.expect(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.send(ctx, request); err != nil {
+		t.peer.forget(request.ID)
+		return nil, err
+	}
+	return t.peer.await(ctx, request.ID, ch)
+}
+
+func (t *stdioTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	return t.send(ctx, notification)
+}
+
+func (t *stdioTransport) SetNotificationHandler(handler func(mcp.JSONRPCNotification)) {
+	t.peer.setNotificationHandler(handler)
+}
+
+// Close closes the server's stdin, which asks it to exit, and kills it if
+// it doesn't within a few seconds.
+func (t *stdioTransport) Close() error {
+	if t.cmd == nil {
+		return nil
+	}
+	t.peer.fail(errors.New("transport closed"))
+	_ = t.stdin.Close()
+	select {
+	case <-t.exited:
+	case <-time.After(3 * time.Second):
+		_ = t.cmd.Process.Kill()
+		<-t.exited
+	}
+	return nil
+}
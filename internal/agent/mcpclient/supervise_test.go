@@ -0,0 +1,168 @@
+package mcpclient
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var testSupervision = supervision{
+	pingInterval: 50 * time.Millisecond,
+	pingTimeout:  time.Second,
+	minBackoff:   20 * time.Millisecond,
+	maxBackoff:   200 * time.Millisecond,
+}
+
+// TestMain doubles as a fake stdio MCP server when the tests spawn
+// themselves with MCPCLIENT_FAKE_SERVER set.
+func TestMain(m *testing.M) {
+	if pidFile := os.Getenv("MCPCLIENT_FAKE_SERVER"); pidFile != "" {
+		os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644)
+		if err := server.ServeStdio(newFakeServer()); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func newFakeServer() *server.MCPServer {
+	s := server.NewMCPServer("fake", "1.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text, _ := req.Params.Arguments["text"].(string)
+		return mcp.NewToolResultText(text), nil
+	})
+	return s
+}
+
+// serveSSE serves s over SSE on addr ("127.0.0.1:0" picks a port)
+func serveSSE(t *testing.T, s *server.MCPServer, addr string) *httptest.Server {
+	t.Helper()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ts := httptest.NewUnstartedServer(server.NewSSEServer(s, server.WithBaseURL("http://"+ln.Addr().String())))
+	ts.Listener.Close()
+	ts.Listener = ln
+	ts.Start()
+	return ts
+}
+
+func stopSSE(ts *httptest.Server) {
+	ts.CloseClientConnections()
+	ts.Close()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestManager_ReconnectsSSE(t *testing.T) {
+	fake := newFakeServer()
+	ts := serveSSE(t, fake, "127.0.0.1:0")
+	addr := ts.Listener.Addr().String()
+
+	m := newManager([]ServerConfig{{Name: "fake", URL: ts.URL + "/sse"}}, testSupervision)
+	defer m.Close()
+	if out, err := m.Call(context.Background(), "mcp_fake_echo", map[string]any{"text": "hi"}); err != nil || out != "hi" {
+		t.Fatalf("Call = %q, %v", out, err)
+	}
+
+	// New tools are picked up from tools/list_changed
+	fake.AddTool(mcp.NewTool("shout"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("HI"), nil
+	})
+	waitFor(t, "the new tool", func() bool { return len(m.AllTools()) == 2 })
+
+	stopSSE(ts)
+	waitFor(t, "the disconnect", func() bool { return m.Status()[0].State == StateReconnecting })
+	if len(m.AllTools()) != 0 {
+		t.Errorf("tools of a disconnected server are still listed: %v", m.AllTools())
+	}
+	if _, err := m.Call(context.Background(), "mcp_fake_echo", nil); err == nil || !strings.Contains(err.Error(), "reconnecting") {
+		t.Errorf("Call on a disconnected server = %v", err)
+	}
+
+	ts = serveSSE(t, fake, addr)
+	defer stopSSE(ts)
+	waitFor(t, "the reconnect", func() bool { return m.Status()[0].State == StateConnected })
+	st := m.Status()[0]
+	if st.Restarts != 1 || st.Tools != 2 || st.Transport != "sse" || !strings.Contains(st.LastError, "ping") {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestManager_RespawnsStdio(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	m := newManager([]ServerConfig{{Name: "fake", Command: os.Args[0], Env: []string{"MCPCLIENT_FAKE_SERVER=" + pidFile}}}, testSupervision)
+	defer m.Close()
+	if st := m.Status()[0]; st.State != StateConnected || st.Tools != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+	pid := func() int {
+		data, _ := os.ReadFile(pidFile)
+		n, _ := strconv.Atoi(string(data))
+		return n
+	}
+	first := pid()
+	proc, err := os.FindProcess(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proc.Kill()
+
+	waitFor(t, "the respawn", func() bool {
+		st := m.Status()[0]
+		return st.State == StateConnected && st.Restarts == 1
+	})
+	if pid() == first {
+		t.Error("server was not respawned")
+	}
+	if out, err := m.Call(context.Background(), "mcp_fake_echo", map[string]any{"text": "back"}); err != nil || out != "back" {
+		t.Errorf("Call after respawn = %q, %v", out, err)
+	}
+}
+
+func TestManager_RetriesUnreachableServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	m := newManager([]ServerConfig{{Name: "late", URL: "http://" + addr + "/sse"}}, testSupervision)
+	defer m.Close()
+	if st := m.Status()[0]; st.State != StateReconnecting || st.LastError == "" || st.NextRetry.IsZero() {
+		t.Fatalf("status = %+v", st)
+	}
+
+	ts := serveSSE(t, newFakeServer(), addr)
+	defer stopSSE(ts)
+	waitFor(t, "the first connection", func() bool { return m.Status()[0].State == StateConnected })
+	if st := m.Status()[0]; st.Restarts != 0 {
+		t.Errorf("first connection counted as a restart: %+v", st)
+	}
+
+	m.Close()
+	if st := m.Status()[0]; st.State != StateStopped || st.Tools != 0 {
+		t.Errorf("status after Close = %+v", st)
+	}
+}
@@ -74,6 +74,38 @@ func (p *AgentPool) HandleMessage(ctx context.Context, msg router.Message) (rout
 	return a.HandleMessage(ctx, msg)
 }
 
+// HandleMessageAs delegates to the named agent from the agents list. An empty
+// agentID routes the message like HandleMessage.
+func (p *AgentPool) HandleMessageAs(ctx context.Context, agentID string, msg router.Message) (router.Response, error) {
+	if agentID == "" {
+		return p.HandleMessage(ctx, msg)
+	}
+	if p.fullCfg == nil {
+		return router.Response{}, fmt.Errorf("unknown agent %q: no agents are configured", agentID)
+	}
+	entry, found := p.fullCfg.FindAgent(agentID)
+	if !found {
+		return router.Response{}, fmt.Errorf("unknown agent %q", agentID)
+	}
+	a := p.getOrCreateByID(agentID, entry)
+	if a == nil {
+		return router.Response{}, fmt.Errorf("failed to create agent %q", agentID)
+	}
+	return a.HandleMessage(ctx, msg)
+}
+
+// AgentIDs returns the IDs of the named agents, in config order.
+func (p *AgentPool) AgentIDs() []string {
+	if p.fullCfg == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(p.fullCfg.Agents))
+	for _, a := range p.fullCfg.Agents {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
 // handleWithAgentRouting uses the routing package to pick a named agent.
 func (p *AgentPool) handleWithAgentRouting(ctx context.Context, msg router.Message) (router.Response, error) {
 	platform := msg.Platform
@@ -140,6 +172,7 @@ func (p *AgentPool) getOrCreateByID(id string, entry config.AgentEntry) *Agent {
 	}
 
 	cfg := p.baseCfg
+	cfg.ID = id
 	cfg.Provider = aiCfg.Provider
 	cfg.APIKey = aiCfg.APIKey
 	cfg.BaseURL = aiCfg.BaseURL
@@ -153,6 +186,12 @@ func (p *AgentPool) getOrCreateByID(id string, entry config.AgentEntry) *Agent {
 	if len(entry.DenyTools) > 0 {
 		cfg.DenyTools = entry.DenyTools
 	}
+	if len(entry.AllowSkills) > 0 {
+		cfg.AllowSkills = entry.AllowSkills
+	}
+	if len(entry.DenySkills) > 0 {
+		cfg.DenySkills = entry.DenySkills
+	}
 	if entry.Workspace != "" {
 		cfg.Workspace = entry.Workspace
 	}
@@ -166,6 +205,12 @@ func (p *AgentPool) getOrCreateByID(id string, entry config.AgentEntry) *Agent {
 	if p.defaultAgent.cronScheduler != nil {
 		a.SetCronScheduler(p.defaultAgent.cronScheduler)
 	}
+	if p.defaultAgent.skillRegistry != nil {
+		a.SetSkillRegistry(p.defaultAgent.skillRegistry)
+	}
+	if p.defaultAgent.skillCache != nil {
+		a.SetSkillCache(p.defaultAgent.skillCache)
+	}
 
 	name := entry.Name
 	if name == "" {
@@ -209,6 +254,12 @@ func (p *AgentPool) getOrCreate(aiCfg config.AIConfig) *Agent {
 	if p.defaultAgent.cronScheduler != nil {
 		a.SetCronScheduler(p.defaultAgent.cronScheduler)
 	}
+	if p.defaultAgent.skillRegistry != nil {
+		a.SetSkillRegistry(p.defaultAgent.skillRegistry)
+	}
+	if p.defaultAgent.skillCache != nil {
+		a.SetSkillCache(p.defaultAgent.skillCache)
+	}
 
 	logger.Info("[AgentPool] Created agent for provider=%s model=%s", aiCfg.Provider, aiCfg.Model)
 	p.agents[key] = a
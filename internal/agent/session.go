@@ -18,6 +18,7 @@ const (
 type SessionSettings struct {
 	ThinkingLevel ThinkingLevel
 	Verbose       bool
+	MCPServers    map[string]bool // lazy MCP servers whose tools were listed
 }
 
 // SessionStore manages session settings
@@ -76,6 +77,27 @@ func (s *SessionStore) SetVerbose(key string, verbose bool) {
 	settings.Verbose = verbose
 }
 
+// LoadMCPTools records that the tools of a lazy MCP server were listed, so
+// they are offered for the rest of the session
+func (s *SessionStore) LoadMCPTools(key, server string) {
+	settings := s.Get(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if settings.MCPServers == nil {
+		settings.MCPServers = make(map[string]bool)
+	}
+	settings.MCPServers[server] = true
+}
+
+// MCPToolsLoaded reports whether the tools of a lazy MCP server were listed
+// in the session
+func (s *SessionStore) MCPToolsLoaded(key, server string) bool {
+	settings := s.Get(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return settings.MCPServers[server]
+}
+
 // Clear removes settings for a session
 func (s *SessionStore) Clear(key string) {
 	s.mu.Lock()
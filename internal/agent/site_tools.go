@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pltanton/lingti-bot/internal/browser"
+	"github.com/pltanton/lingti-bot/internal/sites"
+)
+
+// currentSite returns the adapter for the page the conversation's browser
+// is on, or nil. It never starts a browser or opens a tab.
+func currentSite(ctx context.Context) *sites.Adapter {
+	b, ok := browser.Sessions().Lookup(browser.SessionFromContext(ctx))
+	if !ok || b == nil {
+		return nil
+	}
+	url := b.PageURL()
+	if url == "" {
+		return nil
+	}
+	return sites.Default().Match(url)
+}
+
+// sitePrompt returns the system prompt section for the current site, after
+// the navigation rules of all sites, which apply before a site is open
+func sitePrompt(site *sites.Adapter) string {
+	var sb strings.Builder
+	if rules := sites.Default().NavigationRules(); len(rules) > 0 {
+		sb.WriteString("\n\n## Site Navigation Rules")
+		for _, rule := range rules {
+			sb.WriteString("\n- " + rule)
+		}
+	}
+	if site != nil {
+		sb.WriteString("\n\n" + site.Prompt())
+	}
+	return sb.String()
+}
+
+// stallHint is appended to a tool result when the model keeps calling the
+// same browser tool
+func stallHint(site *sites.Adapter, tool string, count int) string {
+	hint := fmt.Sprintf("\n\n[SYSTEM HINT] You have called %s %d times in a row. STOP repeating it. ", tool, count)
+	if site != nil && len(site.Actions) > 0 && tool != "browser_site_action" {
+		names := make([]string, 0, len(site.Actions))
+		for _, name := range site.ActionNames() {
+			names = append(names, site.Name+"."+name)
+		}
+		return hint + fmt.Sprintf("Call browser_site_action instead if one of these actions fits: %s. "+
+			"They handle the site automatically. Do NOT keep clicking buttons or interacting manually.", strings.Join(names, ", "))
+	}
+	return hint + "Take a fresh browser_snapshot and try a different approach, or tell the user what is blocking you."
+}
@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/pltanton/lingti-bot/internal/router"
+	"github.com/pltanton/lingti-bot/internal/skills"
+)
+
+// Skill test case outcomes
+const (
+	SkillTestPass = "pass"
+	SkillTestFail = "fail"
+	SkillTestSkip = "skip"
+)
+
+// SkillTestOptions selects the provider skill tests run against
+type SkillTestOptions struct {
+	Live   bool // use the configured provider instead of replaying responses
+	Record bool // with Live, save the provider's responses for later replays
+}
+
+// SkillTestResult is the outcome of one test case
+type SkillTestResult struct {
+	Case     string
+	Status   string
+	Failures []string
+	Answer   string
+}
+
+// RunSkillTests runs a skill's test cases, each in a fresh agent built from
+// cfg. Without Live, cases replay their inline responses or the recording
+// next to the test file; cases with neither are skipped. Replayed tool calls
+// still go through the agent's tool and skill checks, but return their
+// recorded results instead of running, except skill_read.
+func RunSkillTests(ctx context.Context, cfg Config, suite *skills.TestSuite, opts SkillTestOptions) ([]SkillTestResult, error) {
+	dir := filepath.Dir(suite.Path)
+	recordings, err := skills.LoadRecordings(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var live Provider
+	if opts.Live {
+		if live, err = createProvider(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]SkillTestResult, 0, len(suite.Cases))
+	for _, tc := range suite.Cases {
+		var provider Provider
+		switch {
+		case opts.Live:
+			provider = live
+		case len(tc.Replay) > 0:
+			provider = &replayProvider{turns: tc.Replay}
+		case len(recordings[tc.Name]) > 0:
+			provider = &replayProvider{turns: recordings[tc.Name]}
+		default:
+			results = append(results, SkillTestResult{Case: tc.Name, Status: SkillTestSkip, Failures: []string{"no replay or recording (run with --record)"}})
+			continue
+		}
+
+		rec := &recordingProvider{Provider: provider}
+		a := newAgent(cfg, rec)
+		caseCtx := ctx
+		if replay, ok := provider.(*replayProvider); ok {
+			caseCtx = context.WithValue(ctx, toolStubKey{}, toolStub(replay.result))
+		}
+		resp, err := a.HandleMessage(caseCtx, router.Message{
+			Platform:  "skills-test",
+			ChannelID: suite.Skill,
+			UserID:    "tester",
+			Username:  "tester",
+			Text:      tc.Prompt,
+		})
+
+		result := SkillTestResult{Case: tc.Name, Answer: resp.Text}
+		if err != nil {
+			result.Failures = []string{"agent error: " + err.Error()}
+		} else {
+			result.Failures = tc.Check(rec.transcript(resp.Text))
+		}
+		result.Status = SkillTestPass
+		if len(result.Failures) > 0 {
+			result.Status = SkillTestFail
+		}
+		results = append(results, result)
+
+		if opts.Record && err == nil {
+			recordings[tc.Name] = rec.turns
+		}
+	}
+
+	if opts.Record {
+		if err := skills.SaveRecordings(dir, recordings); err != nil {
+			return results, fmt.Errorf("failed to save recording: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// toolStubKey holds a toolStub in a context
+type toolStubKey struct{}
+
+// toolStub returns the result of a tool call instead of running the tool,
+// or false to run it
+type toolStub func(name string, args map[string]any) (string, bool)
+
+// replayProvider answers with prerecorded turns, in order
+type replayProvider struct {
+	mu      sync.Mutex
+	turns   []skills.ReplayTurn
+	next    int
+	pending []skills.ToolCallData // calls replayed but not yet run
+}
+
+// result returns the recorded result of the next replayed call to name.
+// skill_read runs for real: it only reads the skill and binds its tools.
+func (p *replayProvider) result(name string, _ map[string]any) (string, bool) {
+	if name == "skill_read" {
+		return "", false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, call := range p.pending {
+		if call.Name == name {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			if call.Result == "" {
+				return "(replayed: no recorded result)", true
+			}
+			return call.Result, true
+		}
+	}
+	return "(replayed: no recorded result)", true
+}
+
+func (p *replayProvider) Name() string { return "replay" }
+
+func (p *replayProvider) Chat(_ context.Context, _ ChatRequest) (ChatResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next >= len(p.turns) {
+		return ChatResponse{}, errors.New("replay exhausted: the agent asked for more responses than were recorded")
+	}
+	turn := p.turns[p.next]
+	p.next++
+
+	resp := ChatResponse{Content: turn.Text, FinishReason: "stop"}
+	for i, call := range turn.ToolCalls {
+		input, err := json.Marshal(call.Args)
+		if err != nil {
+			return ChatResponse{}, fmt.Errorf("invalid replay arguments for %s: %w", call.Name, err)
+		}
+		if call.Args == nil {
+			input = []byte("{}")
+		}
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{ID: fmt.Sprintf("replay_%d_%d", p.next, i), Name: call.Name, Input: input})
+		p.pending = append(p.pending, call)
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_use"
+	}
+	return resp, nil
+}
+
+// recordingProvider keeps every response of the wrapped provider, and the
+// tool results sent back to it, so tests can assert on the tool calls and
+// save them for replay
+type recordingProvider struct {
+	Provider
+	mu    sync.Mutex
+	turns []skills.ReplayTurn
+	calls map[string][2]int // tool call ID -> turn and call index
+}
+
+func (p *recordingProvider) Chat(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+	p.mu.Lock()
+	for _, m := range req.Messages {
+		if m.ToolResult == nil {
+			continue
+		}
+		if at, ok := p.calls[m.ToolResult.ToolCallID]; ok {
+			p.turns[at[0]].ToolCalls[at[1]].Result = m.ToolResult.Content
+		}
+	}
+	p.mu.Unlock()
+
+	resp, err := p.Provider.Chat(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	turn := skills.ReplayTurn{Text: resp.Content}
+	for _, call := range resp.ToolCalls {
+		var args map[string]any
+		json.Unmarshal(call.Input, &args)
+		turn.ToolCalls = append(turn.ToolCalls, skills.ToolCallData{Name: call.Name, Args: args})
+	}
+	p.mu.Lock()
+	if p.calls == nil {
+		p.calls = make(map[string][2]int)
+	}
+	for i, call := range resp.ToolCalls {
+		p.calls[call.ID] = [2]int{len(p.turns), i}
+	}
+	p.turns = append(p.turns, turn)
+	p.mu.Unlock()
+	return resp, nil
+}
+
+// transcript collects the recorded tool calls and the agent's final answer
+func (p *recordingProvider) transcript(answer string) skills.Transcript {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t := skills.Transcript{Answer: answer}
+	for _, turn := range p.turns {
+		t.ToolCalls = append(t.ToolCalls, turn.ToolCalls...)
+	}
+	return t
+}
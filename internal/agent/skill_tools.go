@@ -0,0 +1,312 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pltanton/lingti-bot/internal/config"
+	"github.com/pltanton/lingti-bot/internal/logger"
+	"github.com/pltanton/lingti-bot/internal/skills"
+)
+
+const (
+	skillScriptTimeout    = 60 * time.Second // default run time of a skill script
+	skillScriptMaxTimeout = 10 * time.Minute
+	skillOutputMaxLen     = 8000
+)
+
+// loadSkillReport discovers SKILL.md skills using the configured disabled list and extra dirs
+func loadSkillReport() skills.StatusReport {
+	return skills.BuildStatusReport(config.SkillsSettings())
+}
+
+// NewSkillCache creates a skills cache using the configured disabled list
+// and extra dirs. Call Watch on it to keep it up to date.
+func NewSkillCache() *skills.Cache {
+	return skills.NewCache(config.SkillsSettings)
+}
+
+// SetSkillCache makes the agent read skills from the cache's snapshots
+// instead of scanning the skill directories on every message
+func (a *Agent) SetSkillCache(c *skills.Cache) {
+	a.skillCache = c
+}
+
+// skillReport returns the skills this agent may use, from the skills cache
+// when one is set
+func (a *Agent) skillReport() skills.StatusReport {
+	if a.skillCache != nil {
+		return a.skillCache.Snapshot().Report.Filter(a.skillAccess)
+	}
+	return loadSkillReport().Filter(a.skillAccess)
+}
+
+// skillChanges describes the latest skills reload for /tools
+func (a *Agent) skillChanges() string {
+	if a.skillCache == nil {
+		return ""
+	}
+	snap := a.skillCache.Snapshot()
+	var changes []skills.Change
+	for _, ch := range snap.Changes {
+		if a.skillAccess.Allowed(ch.Name) {
+			changes = append(changes, ch)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n🔄 技能更新 (%s): %s", snap.LoadedAt.Format("01-02 15:04"), skills.FormatChanges(changes))
+}
+
+// skillScopeKey holds the *skills.ToolScope of the current turn in a context
+type skillScopeKey struct{}
+
+// withSkillScope starts an empty tool scope for one turn of HandleMessage
+func withSkillScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skillScopeKey{}, &skills.ToolScope{})
+}
+
+// checkSkillScope rejects tool calls outside the tools declared by the
+// skills read so far this turn
+func checkSkillScope(ctx context.Context, name string, args map[string]any) error {
+	scope, ok := ctx.Value(skillScopeKey{}).(*skills.ToolScope)
+	if !ok {
+		return nil
+	}
+	return scope.Check(name, args)
+}
+
+// useSkill limits the rest of the turn to the tools a ready skill declares.
+// It reports whether the skill narrowed the scope.
+func useSkill(ctx context.Context, skill *skills.SkillStatus) bool {
+	scope, ok := ctx.Value(skillScopeKey{}).(*skills.ToolScope)
+	if !ok || skill.Status != skills.StatusReady || len(skill.Metadata.Tools) == 0 {
+		return false
+	}
+	scope.Add(skill.SkillEntry)
+	return true
+}
+
+// useSkillInCall brings a skill into the scope when a tool call runs its
+// script or a file tool reads from its directory, so reading SKILL.md
+// directly is the same as skill_read
+func (a *Agent) useSkillInCall(ctx context.Context, name string, args map[string]any) {
+	if _, ok := ctx.Value(skillScopeKey{}).(*skills.ToolScope); !ok {
+		return
+	}
+	if name == "skill_script" {
+		report := a.skillReport()
+		skillName, _ := args["name"].(string)
+		if skill, ok := report.Find(skillName); ok {
+			useSkill(ctx, skill)
+		}
+		return
+	}
+	if name != "file_read" && name != "file_list" {
+		return
+	}
+	path, _ := args[fileToolPaths[name]].(string)
+	if path == "" {
+		return
+	}
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, path[2:])
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	report := a.skillReport()
+	for i := range report.Skills {
+		dir := report.Skills[i].BaseDir
+		if dir == "" {
+			continue
+		}
+		if dir = filepath.Clean(dir); path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			useSkill(ctx, &report.Skills[i])
+		}
+	}
+}
+
+// executeSkillRead returns a skill's full SKILL.md instructions and its file
+// listing. Reading a ready skill that declares tools limits the rest of the
+// turn to those tools.
+func (a *Agent) executeSkillRead(ctx context.Context, args map[string]any) string {
+	name, _ := args["name"].(string)
+	if name == "" {
+		return "Error: name is required"
+	}
+	report := a.skillReport()
+	skill, ok := report.Find(name)
+	if !ok {
+		return fmt.Sprintf("Error: skill %q not found", name)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Skill: %s\n", skill.Name)
+	if skill.Description != "" {
+		fmt.Fprintf(&sb, "%s\n", skill.Description)
+	}
+	fmt.Fprintf(&sb, "Directory: %s\n", skill.BaseDir)
+	switch skill.Status {
+	case skills.StatusDisabled:
+		sb.WriteString("Status: disabled by the user — do not use this skill\n")
+	case skills.StatusMissing:
+		fmt.Fprintf(&sb, "Status: missing requirements (%s) — tell the user what to install\n", describeMissing(skill.Missing))
+	case skills.StatusReady:
+		if useSkill(ctx, skill) {
+			fmt.Fprintf(&sb, "Allowed tools: %s — other tools are blocked for the rest of this request\n", describeTools(skill.Metadata.Tools))
+		}
+	}
+
+	sb.WriteString("\n## Instructions\n\n")
+	if skill.Content != "" {
+		sb.WriteString(skill.Content)
+	} else {
+		sb.WriteString("(SKILL.md has no instructions)")
+	}
+
+	files, truncated, err := skills.SkillFiles(skill.SkillEntry)
+	if err != nil {
+		fmt.Fprintf(&sb, "\n\n## Files\n\nError: %v", err)
+		return sb.String()
+	}
+	sb.WriteString("\n\n## Files\n\n")
+	for _, f := range files {
+		fmt.Fprintf(&sb, "- %s\n", f)
+	}
+	if truncated {
+		sb.WriteString("- ... (more files not shown)\n")
+	}
+	sb.WriteString("\nRun scripts from this directory with skill_script.")
+	return sb.String()
+}
+
+// executeSkillScript runs a script from a ready skill's directory, with the
+// skill directory as working directory. Like skill_read, it limits the rest
+// of the turn to the skill's declared tools.
+func (a *Agent) executeSkillScript(ctx context.Context, args map[string]any) string {
+	name, _ := args["name"].(string)
+	script, _ := args["script"].(string)
+	if name == "" || script == "" {
+		return "Error: name and script are required"
+	}
+
+	report := a.skillReport()
+	skill, ok := report.Find(name)
+	if !ok {
+		return fmt.Sprintf("Error: skill %q not found", name)
+	}
+	if skill.Status != skills.StatusReady {
+		return fmt.Sprintf("Error: skill %q is %s and cannot run scripts", name, skill.Status)
+	}
+	useSkill(ctx, skill)
+
+	path, err := skills.ResolveSkillScript(skill.SkillEntry, script)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	var scriptArgs []string
+	if raw, ok := args["args"].([]any); ok {
+		for _, v := range raw {
+			scriptArgs = append(scriptArgs, fmt.Sprint(v))
+		}
+	}
+	program, argv, err := skills.ScriptCommand(path, scriptArgs)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+
+	timeout := skillScriptTimeout
+	if t, ok := args["timeout"].(float64); ok && t > 0 {
+		timeout = min(time.Duration(t)*time.Second, skillScriptMaxTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logger.Info("[Skills] Running %s/%s %v", name, script, scriptArgs)
+	cmd := exec.CommandContext(ctx, program, argv...)
+	cmd.Dir = skill.BaseDir
+	cmd.Env = append(os.Environ(), "SKILL_DIR="+skill.BaseDir)
+	if stdin, ok := args["stdin"].(string); ok && stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+
+	var result strings.Builder
+	result.WriteString(stdout.String())
+	if stderr.Len() > 0 {
+		result.WriteString("\nstderr: " + stderr.String())
+	}
+	if err != nil {
+		result.WriteString("\nerror: " + err.Error())
+	}
+
+	return truncateSkillOutput(result.String())
+}
+
+// truncateSkillOutput caps script output at skillOutputMaxLen bytes without
+// splitting a multi-byte character
+func truncateSkillOutput(output string) string {
+	if len(output) <= skillOutputMaxLen {
+		return output
+	}
+	return strings.ToValidUTF8(output[:skillOutputMaxLen], "") + fmt.Sprintf("\n... (truncated, total %d chars)", len(output))
+}
+
+// describeMissing summarizes a skill's unmet requirements
+func describeMissing(m skills.MissingRequirements) string {
+	var parts []string
+	if len(m.Bins) > 0 {
+		parts = append(parts, "binaries: "+strings.Join(m.Bins, ", "))
+	}
+	if len(m.AnyBins) > 0 {
+		parts = append(parts, "one of: "+strings.Join(m.AnyBins, ", "))
+	}
+	if len(m.Env) > 0 {
+		parts = append(parts, "env: "+strings.Join(m.Env, ", "))
+	}
+	if len(m.OS) > 0 {
+		parts = append(parts, "os: "+strings.Join(m.OS, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeTools lists a skill's declared tools, with bins for shell_execute
+func describeTools(tools []skills.ToolSpec) string {
+	parts := make([]string, len(tools))
+	for i, t := range tools {
+		parts[i] = t.Name
+		if len(t.Bins) > 0 {
+			parts[i] += " (" + strings.Join(t.Bins, ", ") + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatAlwaysSkills inlines the instructions of always-on skills for the system prompt
+func formatAlwaysSkills(report skills.StatusReport) string {
+	always := report.AlwaysSkills()
+	if len(always) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n## Always-on Skills")
+	for _, s := range always {
+		fmt.Fprintf(&sb, "\n\n### %s (%s)\n\n%s", s.Name, s.BaseDir, s.Content)
+	}
+	return sb.String()
+}
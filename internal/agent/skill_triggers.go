@@ -0,0 +1,268 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
+	"github.com/pltanton/lingti-bot/internal/logger"
+	"github.com/pltanton/lingti-bot/internal/router"
+	"github.com/pltanton/lingti-bot/internal/skills"
+)
+
+// skillRunTool is the internal tool cron jobs call to run a skill's schedule trigger
+const skillRunTool = "skill_run"
+
+// skillSourceKey marks messages sent by skill prompt actions so that they
+// don't trigger skills again
+const skillSourceKey = "lingti_source"
+
+// NewSkillRegistry creates the trigger/action skill registry for an agent
+// and loads JSON skills from dir (default ~/.lingti/skills). Tool actions run
+// through the tool executor of the agent running the skill, and prompt
+// actions as a turn of that agent in the conversation that triggered the
+// skill, so agents sharing the registry keep their own tool limits. a runs
+// skills executed without an agent.
+func NewSkillRegistry(a *Agent, dir string) *skills.Registry {
+	reg := skills.NewRegistry(dir)
+	reg.RegisterExecutor(skills.ActionShell, skills.NewShellExecutor())
+	reg.RegisterExecutor(skills.ActionHTTP, skills.NewHTTPExecutor())
+	reg.RegisterExecutor(skills.ActionPrompt, skillPromptExecutor{agent: a})
+	reg.RegisterExecutor(skills.ActionTool, skills.NewToolExecutor(func(ctx context.Context, name string, args map[string]any) (string, error) {
+		return skillAgent(ctx, a).runSkillTool(ctx, name, args)
+	}))
+	reg.RegisterExecutor(skills.ActionWorkflow, skills.NewWorkflowExecutor(reg))
+
+	if err := reg.LoadFromDirectory(""); err != nil {
+		logger.Warn("[Agent] Failed to load skills from %s: %v", reg.Dir(), err)
+	}
+	return reg
+}
+
+// SetSkillRegistry sets the trigger/action skills matched against incoming messages
+func (a *Agent) SetSkillRegistry(r *skills.Registry) {
+	a.skillRegistry = r
+}
+
+// WatchSkills keeps the skills cache up to date until ctx is done. When the
+// trigger/action skills change, the registry is reloaded and their schedules
+// are synced with the scheduler (either may be nil).
+func WatchSkills(ctx context.Context, cache *skills.Cache, reg *skills.Registry, sched *cronpkg.Scheduler) {
+	if err := cache.Watch(ctx); err != nil {
+		logger.Warn("[Agent] Skills will not reload automatically: %v", err)
+		return
+	}
+	if reg == nil {
+		return
+	}
+
+	events, unsubscribe := cache.Subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				if !triggerSkillsChanged(event.Changes) {
+					continue
+				}
+				if err := reg.Reload(); err != nil {
+					logger.Warn("[Agent] Failed to reload some skills: %v", err)
+				}
+				if sched != nil {
+					SyncSkillSchedules(reg, sched)
+				}
+			}
+		}
+	}()
+}
+
+// triggerSkillsChanged reports whether any JSON trigger/action skill changed
+func triggerSkillsChanged(changes []skills.Change) bool {
+	for _, ch := range changes {
+		if ch.Trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// skillAgentKey holds the *Agent running a skill in a context
+type skillAgentKey struct{}
+
+// skillAgent returns the agent running the skill, or fallback
+func skillAgent(ctx context.Context, fallback *Agent) *Agent {
+	if ctx != nil {
+		if a, ok := ctx.Value(skillAgentKey{}).(*Agent); ok {
+			return a
+		}
+	}
+	return fallback
+}
+
+// skillPromptExecutor runs prompt actions through the agent running the skill
+type skillPromptExecutor struct {
+	agent *Agent // used when no agent is running the skill
+}
+
+// Execute sends the action's prompt to the agent in the triggering conversation
+func (e skillPromptExecutor) Execute(ec skills.ExecutionContext, action skills.Action) skills.ExecutionResult {
+	return skills.NewPromptExecutor(func(ctx context.Context, prompt string) (string, error) {
+		resp, err := skillAgent(ctx, e.agent).HandleMessage(ctx, router.Message{
+			Platform:  ec.Platform,
+			ChannelID: ec.ChannelID,
+			UserID:    ec.UserID,
+			Username:  "skill",
+			Text:      prompt,
+			Metadata:  map[string]string{skillSourceKey: "skill"},
+		})
+		return resp.Text, err
+	}).Execute(ec, action)
+}
+
+// runSkillTool dispatches a skill's tool action through the agent's tool
+// executor, subject to the same file and path restrictions as model calls
+func (a *Agent) runSkillTool(ctx context.Context, name string, args map[string]any) (string, error) {
+	input, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("invalid tool arguments: %w", err)
+	}
+	result := a.executeTool(ctx, name, input)
+	if strings.HasPrefix(result, "Error") || strings.HasPrefix(result, "ACCESS DENIED") {
+		return "", errors.New(result)
+	}
+	return result, nil
+}
+
+// handleSkillTrigger runs the skill whose command, pattern or keyword trigger
+// matches the message, bypassing the LLM
+func (a *Agent) handleSkillTrigger(ctx context.Context, msg router.Message) (router.Response, bool) {
+	if a.skillRegistry == nil || msg.Metadata[skillSourceKey] != "" {
+		return router.Response{}, false
+	}
+	match, ok := a.skillRegistry.MatchMessageWith(msg.Text, a.skillAccess)
+	if !ok {
+		return router.Response{}, false
+	}
+	logger.Info("[Agent] Message matched skill %s (%s trigger)", match.Skill.ID, match.Trigger.Type)
+
+	output, err := a.runSkill(ctx, match.Skill, skills.ExecutionContext{
+		UserID:    msg.UserID,
+		Platform:  msg.Platform,
+		ChannelID: msg.ChannelID,
+		Message:   msg.Text,
+		Matches:   match.Matches,
+	})
+	if err != nil {
+		text := fmt.Sprintf("技能 %s 执行失败: %v", match.Skill.Name, err)
+		if output != "" {
+			text += "\n" + output
+		}
+		return router.Response{Text: text}, true
+	}
+	if output == "" {
+		output = fmt.Sprintf("技能 %s 已执行", match.Skill.Name)
+	}
+	return router.Response{Text: output}, true
+}
+
+// runSkill executes a skill's actions as this agent and combines their results
+func (a *Agent) runSkill(ctx context.Context, skill *skills.Skill, ec skills.ExecutionContext) (string, error) {
+	ec.Context = context.WithValue(ctx, skillAgentKey{}, a)
+	ec.SessionID = ConversationKey(ec.Platform, ec.ChannelID, ec.UserID)
+	return skills.ResultOutput(a.skillRegistry.Execute(ec, skill))
+}
+
+// executeSkillRun runs a skill by ID (used by cron jobs for schedule triggers)
+func (a *Agent) executeSkillRun(ctx context.Context, args map[string]any) string {
+	if a.skillRegistry == nil {
+		return "Error: skills are not available"
+	}
+	id, _ := args["skill"].(string)
+	skill, ok := a.skillRegistry.Get(id)
+	if !ok {
+		return "Error: skill not found: " + id
+	}
+	if !skill.Enabled {
+		return "Error: skill is disabled: " + id
+	}
+
+	output, err := a.runSkill(ctx, skill, skills.ExecutionContext{})
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return output
+}
+
+// skillSchedule is a schedule trigger of one skill
+type skillSchedule struct {
+	skillID string
+	trigger skills.Trigger
+	targets []cronpkg.Target
+}
+
+// SyncSkillSchedules registers the schedule triggers of enabled skills as
+// cron jobs that run the skill. Jobs whose trigger changed are replaced and
+// jobs of removed or disabled skills are deleted, so calling it on every
+// start is idempotent. Call after the scheduler has started.
+func SyncSkillSchedules(reg *skills.Registry, sched *cronpkg.Scheduler) {
+	want := make(map[string]skillSchedule)
+	for id, triggers := range reg.ScheduleTriggers() {
+		for _, trigger := range triggers {
+			targets, err := cronpkg.ParseTargets(trigger.Targets)
+			if err != nil {
+				logger.Warn("[Agent] Skill %s: invalid schedule targets: %v", id, err)
+				continue
+			}
+			want[id+"\x00"+trigger.Schedule] = skillSchedule{skillID: id, trigger: trigger, targets: targets}
+		}
+	}
+
+	for _, job := range sched.ListJobs() {
+		if job.Tool != skillRunTool {
+			continue
+		}
+		id, _ := job.Arguments["skill"].(string)
+		schedule, _ := job.Arguments["schedule"].(string)
+		key := id + "\x00" + schedule
+		if s, ok := want[key]; ok && formatTargets(s.targets) == formatTargets(job.Targets) {
+			delete(want, key)
+			continue
+		}
+		if err := sched.RemoveJob(job.ID); err != nil {
+			logger.Warn("[Agent] Failed to remove stale schedule of skill %s: %v", id, err)
+		}
+	}
+
+	keys := make([]string, 0, len(want))
+	for key := range want {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := want[key]
+		parsed, err := cronpkg.ParseSchedule(s.trigger.Schedule, time.Now())
+		if err != nil {
+			logger.Warn("[Agent] Skill %s: invalid schedule %q: %v", s.skillID, s.trigger.Schedule, err)
+			continue
+		}
+		job, err := sched.CreateJob(&cronpkg.Job{
+			Name:      "skill:" + s.skillID,
+			Schedule:  parsed.Spec,
+			Tool:      skillRunTool,
+			Arguments: map[string]any{"skill": s.skillID, "schedule": s.trigger.Schedule},
+			Targets:   s.targets,
+		})
+		if err != nil {
+			logger.Warn("[Agent] Failed to schedule skill %s: %v", s.skillID, err)
+			continue
+		}
+		logger.Info("[Agent] Scheduled skill %s (%s) as cron job %s", s.skillID, s.trigger.Schedule, job.ID)
+	}
+}
@@ -546,20 +546,10 @@ func executeBrowserExecuteJS(ctx context.Context, script string) string {
 	return extractText(result)
 }
 
-func executeBrowserCommentZhihu(ctx context.Context, comment, replyTo string) string {
+func executeBrowserSiteAction(ctx context.Context, args map[string]any) string {
 	req := mcp.CallToolRequest{}
-	req.Params.Arguments = map[string]interface{}{"comment": comment, "reply_to": replyTo}
-	result, err := tools.BrowserCommentZhihu(ctx, req)
-	if err != nil {
-		return "Error: " + err.Error()
-	}
-	return extractText(result)
-}
-
-func executeBrowserCommentXiaohongshu(ctx context.Context, comment string) string {
-	req := mcp.CallToolRequest{}
-	req.Params.Arguments = map[string]interface{}{"comment": comment}
-	result, err := tools.BrowserCommentXiaohongshu(ctx, req)
+	req.Params.Arguments = args
+	result, err := tools.BrowserSiteAction(ctx, req)
 	if err != nil {
 		return "Error: " + err.Error()
 	}
@@ -36,6 +36,16 @@ type Browser struct {
 	// Debug mode configuration
 	debugMode bool
 	debugDir  string
+
+	// manager is the session manager: on Instance it hides the sessions'
+	// tabs, on a session it's the manager to leave when stopped.
+	manager *SessionManager
+
+	// base and key are set on sessions (see SessionManager); browser is
+	// then an incognito context of base's browser, opened from parent.
+	base   *Browser
+	key    string
+	parent *rod.Browser
 }
 
 var (
@@ -43,7 +53,8 @@ var (
 	once     sync.Once
 )
 
-// Instance returns the singleton browser manager.
+// Instance returns the shared browser, which uses the persistent profile.
+// Isolated sessions come from Sessions.
 func Instance() *Browser {
 	once.Do(func() {
 		home, _ := os.UserHomeDir()
@@ -73,6 +84,10 @@ func (b *Browser) Start(opts StartOptions) error {
 		return fmt.Errorf("browser already running")
 	}
 
+	if b.base != nil {
+		return b.startSessionLocked(opts)
+	}
+
 	// Connect to existing Chrome via CDP
 	if opts.ConnectURL != "" {
 		return b.connectLocked(opts.ConnectURL, opts.URL)
@@ -166,8 +181,68 @@ func (b *Browser) connectLocked(addr string, initialURL string) error {
 	return nil
 }
 
+// startSessionLocked opens a session's browser context, starting the shared
+// browser with opts first if needed. Must be called with b.mu held.
+func (b *Browser) startSessionLocked(opts StartOptions) error {
+	if !b.base.IsRunning() {
+		var err error
+		if opts.ConnectURL == "" && opts.ExecutablePath == "" {
+			err = b.base.EnsureRunning()
+		} else {
+			err = b.base.Start(StartOptions{Headless: opts.Headless, ExecutablePath: opts.ExecutablePath, ConnectURL: opts.ConnectURL})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return b.openSessionLocked(opts.URL)
+}
+
+// openSessionLocked opens a session's incognito context in the shared
+// browser. Must be called with b.mu held.
+func (b *Browser) openSessionLocked(initialURL string) error {
+	parent := b.base.Rod()
+	if parent == nil {
+		return fmt.Errorf("browser not running")
+	}
+	brow, err := parent.Incognito()
+	if err != nil {
+		return fmt.Errorf("failed to create browser context: %w", err)
+	}
+	if b.browser != nil {
+		// The context died with the previous shared browser
+		b.manager.track(b.browser.BrowserContextID, false)
+	}
+	b.manager.track(brow.BrowserContextID, true)
+
+	b.browser = brow
+	b.parent = parent
+	b.running = true
+	b.currentPage = nil
+	b.refs = make(map[int]RefEntry)
+
+	if initialURL != "" {
+		page, err := brow.Page(proto.TargetCreateTarget{URL: initialURL, Background: true})
+		if err != nil {
+			return fmt.Errorf("failed to open initial page: %w", err)
+		}
+		// Non-fatal: page may redirect and trigger "navigated or closed"
+		_ = page.WaitLoad()
+	}
+	return nil
+}
+
 // Stop closes the browser (or just disconnects if attached to external Chrome).
+// Stopping the shared browser closes all sessions; stopping a session closes
+// its context and tabs only.
 func (b *Browser) Stop() error {
+	if b.base != nil {
+		return b.stopSession()
+	}
+	if b.manager != nil {
+		b.manager.CloseAll()
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -190,12 +265,52 @@ func (b *Browser) Stop() error {
 	return nil
 }
 
+// stopSession disposes a session's browser context and leaves the manager
+func (b *Browser) stopSession() error {
+	defer b.manager.remove(b.key, b)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.running {
+		return fmt.Errorf("browser not running")
+	}
+	id := b.browser.BrowserContextID
+	err := b.browser.Close()
+	b.manager.track(id, false)
+
+	b.browser = nil
+	b.parent = nil
+	b.running = false
+	b.currentPage = nil
+	b.refs = make(map[int]RefEntry)
+	if err != nil {
+		return fmt.Errorf("failed to close browser session: %w", err)
+	}
+	return nil
+}
+
 // EnsureRunning starts the browser if not already running.
 // Resolution order:
 //  1. cfg.Browser.CDPURL  — user-configured CDP address (highest priority)
 //  2. 127.0.0.1:9222      — well-known default debug port
 //  3. Launch a new Chrome instance (fallback)
+//
+// A session starts the shared browser this way and opens its context in it,
+// again if the shared browser was restarted since.
 func (b *Browser) EnsureRunning() error {
+	if b.base != nil {
+		if err := b.base.EnsureRunning(); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.running && b.parent == b.base.Rod() {
+			return nil
+		}
+		return b.openSessionLocked("")
+	}
+
 	b.mu.Lock()
 	running := b.running
 	b.mu.Unlock()
@@ -239,6 +354,11 @@ func (b *Browser) IsConnected() bool {
 	return b.connected
 }
 
+// Session returns the key of an isolated session, or "" for the shared browser.
+func (b *Browser) Session() string {
+	return b.key
+}
+
 // Rod returns the underlying rod browser. Caller must check IsRunning first.
 func (b *Browser) Rod() *rod.Browser {
 	b.mu.Lock()
@@ -253,13 +373,54 @@ func (b *Browser) PageCount() int {
 	if b.browser == nil {
 		return 0
 	}
-	pages, err := b.browser.Pages()
+	pages, err := b.pagesLocked()
 	if err != nil {
 		return 0
 	}
 	return len(pages)
 }
 
+// Pages returns the open tabs: a session's own, or those of the shared
+// browser outside the sessions.
+func (b *Browser) Pages() (rod.Pages, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.running {
+		return nil, fmt.Errorf("browser not running")
+	}
+	return b.pagesLocked()
+}
+
+// pagesLocked lists b's tabs. Must be called with b.mu held.
+func (b *Browser) pagesLocked() (rod.Pages, error) {
+	if b.base == nil && b.manager == nil {
+		return b.browser.Pages()
+	}
+	targets, err := proto.TargetGetTargets{}.Call(b.browser)
+	if err != nil {
+		return nil, err
+	}
+	id := b.browser.BrowserContextID
+	pages := rod.Pages{}
+	for _, t := range targets.TargetInfos {
+		if t.Type != proto.TargetTargetInfoTypePage {
+			continue
+		}
+		if b.base != nil && t.BrowserContextID != id {
+			continue
+		}
+		if b.base == nil && b.manager.owns(t.BrowserContextID) {
+			continue
+		}
+		page, err := b.browser.PageFromTarget(t.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
 // SwitchToNewestPage updates currentPage to the most recently opened tab,
 // if a new tab has appeared since lastCount. Returns true if switched.
 func (b *Browser) SwitchToNewestPage(lastCount int) bool {
@@ -268,7 +429,7 @@ func (b *Browser) SwitchToNewestPage(lastCount int) bool {
 	if b.browser == nil {
 		return false
 	}
-	pages, err := b.browser.Pages()
+	pages, err := b.pagesLocked()
 	if err != nil || len(pages) <= lastCount {
 		return false
 	}
@@ -279,6 +440,29 @@ func (b *Browser) SwitchToNewestPage(lastCount int) bool {
 	return true
 }
 
+// PageURL returns the URL of the page the bot is working on, or "" when
+// there is none. Unlike ActivePage it never opens a tab.
+func (b *Browser) PageURL() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.running {
+		return ""
+	}
+	page := b.currentPage
+	if page == nil {
+		pages, err := b.pagesLocked()
+		if err != nil || len(pages) == 0 {
+			return ""
+		}
+		page = pages.First()
+	}
+	info, err := page.Info()
+	if err != nil {
+		return ""
+	}
+	return info.URL
+}
+
 // ActivePage returns the page the bot is currently working on.
 // Returns currentPage if one has been set (by browser_navigate).
 // Falls back to the first available tab, or creates a blank one if none exist.
@@ -294,7 +478,7 @@ func (b *Browser) ActivePage() (*rod.Page, error) {
 		return b.currentPage, nil
 	}
 
-	pages, err := b.browser.Pages()
+	pages, err := b.pagesLocked()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pages: %w", err)
 	}
@@ -353,6 +537,7 @@ func (b *Browser) GetRef(ref int) (RefEntry, bool) {
 
 // StatusInfo holds browser status details.
 type StatusInfo struct {
+	Session   string `json:"session,omitempty"` // key of an isolated session
 	Running   bool   `json:"running"`
 	Headless  bool   `json:"headless"`
 	Connected bool   `json:"connected"` // attached to external Chrome (vs launched)
@@ -429,12 +614,18 @@ func (b *Browser) Status() StatusInfo {
 		Headless:  b.headless,
 		Connected: b.connected,
 	}
+	if b.base != nil {
+		base := b.base.Status()
+		info.Session = b.key
+		info.Headless = base.Headless
+		info.Connected = base.Connected
+	}
 
 	if !b.running {
 		return info
 	}
 
-	pages, err := b.browser.Pages()
+	pages, err := b.pagesLocked()
 	if err == nil {
 		info.Pages = len(pages)
 		if len(pages) > 0 {
@@ -458,11 +649,17 @@ func (b *Browser) EnableDebug(debugDir string) {
 // IsDebugMode returns whether debug mode is enabled.
 // No lock needed - debugMode is set once at startup and never modified.
 func (b *Browser) IsDebugMode() bool {
+	if b.base != nil {
+		return b.base.IsDebugMode()
+	}
 	return b.debugMode
 }
 
 // DebugDir returns the debug directory path.
 // No lock needed - debugDir is set once at startup and never modified.
 func (b *Browser) DebugDir() string {
+	if b.base != nil {
+		return b.base.DebugDir()
+	}
 	return b.debugDir
 }
@@ -0,0 +1,268 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pltanton/lingti-bot/internal/config"
+	"github.com/pltanton/lingti-bot/internal/logger"
+)
+
+// Session scopes, set with browser.session_scope in ~/.lingti.yaml
+const (
+	ScopeConversation = "conversation" // one session per chat (default)
+	ScopeAgent        = "agent"        // one session per named agent
+	ScopeGlobal       = "global"       // everyone shares Instance() and its logins
+)
+
+const (
+	DefaultMaxSessions = 5
+	DefaultSessionIdle = 15 * time.Minute
+)
+
+// SessionManager hands out isolated browser sessions by key. A session is
+// an incognito context of the shared browser (Instance), so it has its own
+// cookies, storage and tabs as well as its own current page and refs, while
+// all sessions share one Chrome process.
+type SessionManager struct {
+	base  *Browser
+	max   int
+	idle  time.Duration
+	scope string
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	contexts map[proto.BrowserBrowserContextID]bool // contexts owned by sessions
+	reaping  bool
+}
+
+type session struct {
+	b        *Browser
+	lastUsed time.Time
+}
+
+var (
+	sessionManager *SessionManager
+	sessionsOnce   sync.Once
+)
+
+// Sessions returns the session manager of Instance, configured from the
+// browser settings in ~/.lingti.yaml.
+func Sessions() *SessionManager {
+	sessionsOnce.Do(func() {
+		var bc config.BrowserConfig
+		if cfg, err := config.Load(); err == nil {
+			bc = cfg.Browser
+		}
+		sessionManager = NewSessionManager(Instance(), bc.MaxSessions, time.Duration(bc.SessionIdleMinutes)*time.Minute)
+		switch bc.SessionScope {
+		case "", ScopeGlobal, ScopeConversation, ScopeAgent:
+		default:
+			logger.Warn("[Browser] Unknown browser.session_scope %q, giving each conversation its own session", bc.SessionScope)
+		}
+		sessionManager.scope = bc.SessionScope
+	})
+	return sessionManager
+}
+
+// NewSessionManager manages sessions in base's browser. Zero limits use the
+// defaults.
+func NewSessionManager(base *Browser, maxSessions int, idle time.Duration) *SessionManager {
+	if maxSessions <= 0 {
+		maxSessions = DefaultMaxSessions
+	}
+	if idle <= 0 {
+		idle = DefaultSessionIdle
+	}
+	m := &SessionManager{
+		base:     base,
+		max:      maxSessions,
+		idle:     idle,
+		sessions: make(map[string]*session),
+		contexts: make(map[proto.BrowserBrowserContextID]bool),
+	}
+	base.mu.Lock()
+	base.manager = m
+	base.mu.Unlock()
+	return m
+}
+
+// Key returns the session key for a conversation of an agent (empty for the
+// default agent) under the configured scope. The empty key is the shared
+// browser, used only with the global scope.
+func (m *SessionManager) Key(conversation, agentID string) string {
+	switch m.scope {
+	case ScopeGlobal:
+		return ""
+	case ScopeAgent:
+		if agentID == "" {
+			agentID = "default"
+		}
+		return "agent:" + agentID
+	default:
+		return conversation
+	}
+}
+
+// Get returns the session for key, creating it if needed. The browser
+// context itself is opened by EnsureRunning or Start. The empty key returns
+// the shared browser.
+func (m *SessionManager) Get(key string) (*Browser, error) {
+	if key == "" {
+		return m.base, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if s, ok := m.sessions[key]; ok {
+		s.lastUsed = now
+		return s.b, nil
+	}
+	if len(m.sessions) >= m.max {
+		return nil, fmt.Errorf("too many browser sessions (max %d), try again later", m.max)
+	}
+	b := &Browser{
+		base:    m.base,
+		manager: m,
+		key:     key,
+		refs:    make(map[int]RefEntry),
+	}
+	m.sessions[key] = &session{b: b, lastUsed: now}
+	if !m.reaping {
+		m.reaping = true
+		go m.reaper()
+	}
+	return b, nil
+}
+
+// Lookup returns the session for key without creating one
+func (m *SessionManager) Lookup(key string) (*Browser, bool) {
+	if key == "" {
+		return m.base, true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[key]
+	if !ok {
+		return nil, false
+	}
+	return s.b, true
+}
+
+// Close closes the session for key, if there is one
+func (m *SessionManager) Close(key string) {
+	m.mu.Lock()
+	s, ok := m.sessions[key]
+	delete(m.sessions, key)
+	m.mu.Unlock()
+	if ok {
+		s.b.Stop()
+	}
+}
+
+// CloseAll closes every session
+func (m *SessionManager) CloseAll() {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[string]*session)
+	m.mu.Unlock()
+	for _, s := range sessions {
+		s.b.Stop()
+	}
+}
+
+// Len returns the number of sessions
+func (m *SessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// reaper closes idle sessions until there are none left
+func (m *SessionManager) reaper() {
+	ticker := time.NewTicker(min(m.idle/2, time.Minute))
+	defer ticker.Stop()
+	for range ticker.C {
+		if !m.reap(time.Now()) {
+			return
+		}
+	}
+}
+
+// reap closes the sessions unused since now minus the idle timeout and
+// reports whether any are left
+func (m *SessionManager) reap(now time.Time) bool {
+	m.mu.Lock()
+	var idle []*Browser
+	for key, s := range m.sessions {
+		if now.Sub(s.lastUsed) >= m.idle {
+			idle = append(idle, s.b)
+			delete(m.sessions, key)
+		}
+	}
+	left := len(m.sessions) > 0
+	if !left {
+		m.reaping = false
+	}
+	m.mu.Unlock()
+
+	for _, b := range idle {
+		logger.Info("[Browser] Closing idle session %s", b.key)
+		b.Stop()
+	}
+	return left
+}
+
+// remove forgets b's session once it has stopped
+func (m *SessionManager) remove(key string, b *Browser) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[key]; ok && s.b == b {
+		delete(m.sessions, key)
+	}
+}
+
+func (m *SessionManager) track(id proto.BrowserBrowserContextID, owned bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if owned {
+		m.contexts[id] = true
+	} else {
+		delete(m.contexts, id)
+	}
+}
+
+func (m *SessionManager) owns(id proto.BrowserBrowserContextID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.contexts[id]
+}
+
+// sessionKey holds the browser session key in a context
+type sessionKey struct{}
+
+// WithSession makes the browser tools called with ctx use the session for
+// key. The empty key is the shared browser.
+func WithSession(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, key)
+}
+
+// SessionFromContext returns the session key set with WithSession
+func SessionFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(sessionKey{}).(string)
+	return key
+}
+
+// For returns the browser session of ctx: the session set with WithSession,
+// or the shared browser when there is none.
+func For(ctx context.Context) (*Browser, error) {
+	key := SessionFromContext(ctx)
+	if key == "" {
+		return Instance(), nil
+	}
+	return Sessions().Get(key)
+}
@@ -0,0 +1,153 @@
+package browser
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+func TestSessionManager_Limits(t *testing.T) {
+	base := &Browser{refs: make(map[int]RefEntry)}
+	m := NewSessionManager(base, 2, time.Minute)
+
+	a, err := m.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get("b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get("c"); err == nil {
+		t.Error("Get beyond the cap succeeded")
+	}
+	if again, _ := m.Get("a"); again != a {
+		t.Error("Get returned a new session for an existing key")
+	}
+	if shared, _ := m.Get(""); shared != base {
+		t.Error("the empty key isn't the shared browser")
+	}
+
+	// Sessions have their own refs
+	a.SetRefs(map[int]RefEntry{1: {Role: "button", Name: "OK"}})
+	if b, _ := m.Lookup("b"); b == nil {
+		t.Fatal("Lookup(b) found nothing")
+	} else if _, ok := b.GetRef(1); ok {
+		t.Error("refs leaked between sessions")
+	}
+
+	if !m.reap(time.Now().Add(30*time.Second)) || m.Len() != 2 {
+		t.Errorf("reaped sessions before the idle timeout, %d left", m.Len())
+	}
+	if m.reap(time.Now().Add(2*time.Minute)) || m.Len() != 0 {
+		t.Errorf("idle sessions left after reap: %d", m.Len())
+	}
+	if _, err := m.Get("c"); err != nil {
+		t.Errorf("Get after reap: %v", err)
+	}
+}
+
+func TestSessionManager_Key(t *testing.T) {
+	m := NewSessionManager(&Browser{}, 0, 0)
+	tests := []struct {
+		scope, agent, want string
+	}{
+		{"", "", "slack:C1:U1"},
+		{ScopeConversation, "writer", "slack:C1:U1"},
+		{ScopeAgent, "writer", "agent:writer"},
+		{ScopeAgent, "", "agent:default"},
+		{ScopeGlobal, "writer", ""},
+	}
+	for _, tt := range tests {
+		m.scope = tt.scope
+		if got := m.Key("slack:C1:U1", tt.agent); got != tt.want {
+			t.Errorf("Key with scope %q, agent %q = %q, want %q", tt.scope, tt.agent, got, tt.want)
+		}
+	}
+}
+
+// TestSessions_Isolated runs headless Chrome when one is installed
+func TestSessions_Isolated(t *testing.T) {
+	bin, found := launcher.LookPath()
+	if !found {
+		t.Skip("Chrome not found")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "user", Value: "alice"})
+		}
+		if c, err := r.Cookie("user"); err == nil {
+			fmt.Fprintf(w, "user=%s", c.Value)
+			return
+		}
+		fmt.Fprint(w, "anonymous")
+	}))
+	defer srv.Close()
+
+	base := &Browser{dataDir: t.TempDir(), refs: make(map[int]RefEntry)}
+	if err := base.Start(StartOptions{Headless: true, ExecutablePath: bin}); err != nil {
+		t.Skipf("Chrome won't start: %v", err)
+	}
+	defer base.Stop()
+	m := NewSessionManager(base, 2, time.Minute)
+
+	// visit opens path in the session's current page and returns the body
+	visit := func(b *Browser, path string) string {
+		t.Helper()
+		if err := b.EnsureRunning(); err != nil {
+			t.Fatal(err)
+		}
+		page, err := b.NavigationPage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := page.Navigate(srv.URL + path); err != nil {
+			t.Fatal(err)
+		}
+		_ = page.WaitLoad()
+		b.SetCurrentPage(page)
+		text, err := ExecuteJS(page, "return document.body.innerText")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return text
+	}
+
+	alice, _ := m.Get("alice")
+	bob, _ := m.Get("bob")
+	if got := visit(alice, "/login"); got != "anonymous" {
+		t.Fatalf("login page = %q", got)
+	}
+	if got := visit(alice, "/"); got != "user=alice" {
+		t.Errorf("alice sees %q, want her cookie", got)
+	}
+	if got := visit(bob, "/"); got != "anonymous" {
+		t.Errorf("bob sees %q, cookies leaked between sessions", got)
+	}
+
+	// Each session sees only its own tab; the shared browser sees neither
+	for _, b := range []*Browser{alice, bob} {
+		if pages, err := b.Pages(); err != nil || len(pages) != 1 {
+			t.Errorf("session %s has %d tabs (%v), want 1", b.Session(), len(pages), err)
+		}
+	}
+	if pages, _ := base.Pages(); len(pages) > 1 {
+		t.Errorf("shared browser lists %d tabs, want session tabs hidden", len(pages))
+	}
+
+	if err := bob.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Len() != 1 {
+		t.Errorf("%d sessions after stopping one, want 1", m.Len())
+	}
+	base.Stop()
+	if m.Len() != 0 || alice.IsRunning() {
+		t.Error("stopping the shared browser left sessions open")
+	}
+}
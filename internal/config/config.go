@@ -9,8 +9,11 @@ import (
 )
 
 type Config struct {
-	Transport string                    `yaml:"transport"` // "stdio" or "sse"
+	Transport string                    `yaml:"transport"` // "stdio", "sse" or "http" (Streamable HTTP)
 	Port      int                       `yaml:"port"`
+	Host      string                    `yaml:"host,omitempty"`  // listen host for the sse/http transports (default 127.0.0.1)
+	Token     string                    `yaml:"token,omitempty"` // bearer token required by the sse/http transports
+	Messaging MessagingConfig           `yaml:"messaging,omitempty"` // how `serve` reaches chat platforms
 	Security  SecurityConfig            `yaml:"security"`
 	Logging   LoggingConfig             `yaml:"logging"`
 	AI        AIConfig                  `yaml:"ai,omitempty"`
@@ -20,6 +23,7 @@ type Config struct {
 	Relay     RelayConfig               `yaml:"relay,omitempty"`
 	Skills    SkillsConfig              `yaml:"skills,omitempty"`
 	Browser   BrowserConfig             `yaml:"browser,omitempty"`
+	Cron      CronConfig                `yaml:"cron,omitempty"`
 	Agents    []AgentEntry              `yaml:"agents,omitempty"`
 	Bindings  []AgentBinding            `yaml:"bindings,omitempty"`
 }
@@ -44,6 +48,45 @@ type BrowserConfig struct {
 	// instead of launching a new one. The Chrome must be started with
 	// --remote-debugging-port=<port>.
 	CDPURL string `yaml:"cdp_url,omitempty"`
+
+	// SessionScope decides who shares a browser session: "conversation"
+	// (default) gives each chat its own session, "agent" one per named agent,
+	// and "global" makes everyone share the persistent profile (and its
+	// logins). Sessions other than the global one are incognito contexts of
+	// the same Chrome, with their own cookies, storage and tabs, so they
+	// start logged out.
+	SessionScope string `yaml:"session_scope,omitempty"`
+
+	// MaxSessions caps the concurrent isolated sessions. Default: 5
+	MaxSessions int `yaml:"max_sessions,omitempty"`
+
+	// SessionIdleMinutes closes sessions unused for this long. Default: 15
+	SessionIdleMinutes int `yaml:"session_idle_minutes,omitempty"`
+}
+
+// MessagingConfig lets the MCP server (`lingti-bot serve`) send chat
+// messages: the send_message tool and cron notifications to platform targets.
+type MessagingConfig struct {
+	// Platforms attaches the platforms configured under platforms, as the
+	// gateway does. Don't run it next to a gateway using the same bots.
+	Platforms bool `yaml:"platforms,omitempty"`
+
+	// Gateway sends through a running gateway instead, e.g.
+	// "http://127.0.0.1:18789"; GatewayToken is one of its auth tokens.
+	Gateway      string `yaml:"gateway,omitempty"`
+	GatewayToken string `yaml:"gateway_token,omitempty"`
+}
+
+// CronConfig configures the scheduled task runner.
+type CronConfig struct {
+	// MaxConcurrent limits how many scheduled jobs run at the same time.
+	// Further runs wait for a free slot. Default: 0 (no limit)
+	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
+
+	// WebhookHosts limits webhook targets to these hosts ("*.example.com"
+	// matches subdomains), which may then be private addresses. Default:
+	// any public address
+	WebhookHosts []string `yaml:"webhook_hosts,omitempty"`
 }
 
 type RelayConfig struct {
@@ -55,6 +98,11 @@ type RelayConfig struct {
 type SkillsConfig struct {
 	Disabled  []string `yaml:"disabled,omitempty"`
 	ExtraDirs []string `yaml:"extra_dirs,omitempty"`
+	Index     string   `yaml:"index,omitempty"` // skill index URL or file for `skills install <name>` and `skills search`
+
+	// TrustedKeys maps skill publishers to the "ed25519:<base64>" public keys
+	// `skills install` accepts signatures from
+	TrustedKeys map[string]string `yaml:"trusted_keys,omitempty"`
 }
 
 // SkillsDir returns the managed skills directory path
@@ -63,13 +111,53 @@ func SkillsDir() string {
 	return filepath.Join(home, ".lingti", "skills")
 }
 
+// SkillsSettings loads the config and returns the disabled skills and extra
+// skill dirs, or nil for both if the config can't be read
+func SkillsSettings() (disabled, extraDirs []string) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, nil
+	}
+	return cfg.Skills.Disabled, cfg.Skills.ExtraDirs
+}
+
 // MCPServerConfig describes one external MCP server to connect to.
 type MCPServerConfig struct {
-	Name    string   `yaml:"name"`
-	Command string   `yaml:"command,omitempty"`
-	Args    []string `yaml:"args,omitempty"`
-	Env     []string `yaml:"env,omitempty"`
-	URL     string   `yaml:"url,omitempty"`
+	Name      string            `yaml:"name"`
+	Command   string            `yaml:"command,omitempty"`
+	Args      []string          `yaml:"args,omitempty"`
+	Env       []string          `yaml:"env,omitempty"`
+	URL       string            `yaml:"url,omitempty"`
+	Transport string            `yaml:"transport,omitempty"` // stdio, sse or http; inferred when empty
+	Headers   map[string]string `yaml:"headers,omitempty"`   // values may be env:NAME, file:/path or contain ${NAME}
+	Timeout   int               `yaml:"timeout,omitempty"`   // per tool call, in seconds
+	OAuth     *MCPOAuthConfig   `yaml:"oauth,omitempty"`
+
+	IncludeTools     []string          `yaml:"include_tools,omitempty"`     // globs on the server's tool names; empty = all
+	ExcludeTools     []string          `yaml:"exclude_tools,omitempty"`     // globs removed after include_tools
+	ToolAliases      map[string]string `yaml:"tool_aliases,omitempty"`      // tool name -> exposed name (after mcp_<name>_)
+	ToolDescriptions map[string]string `yaml:"tool_descriptions,omitempty"` // tool name -> description shown to the model
+	Lazy             bool              `yaml:"lazy,omitempty"`              // offer tools only after mcp_list_tools
+
+	Sampling *MCPSamplingConfig `yaml:"sampling,omitempty"` // let the server use lingti's model
+}
+
+// MCPSamplingConfig is the policy for a server's sampling requests. Every
+// request is recorded in ~/.lingti/mcp-sampling.jsonl.
+type MCPSamplingConfig struct {
+	Allow      bool `yaml:"allow"`
+	MaxTokens  int  `yaml:"max_tokens,omitempty"`  // cap per request (default 1024)
+	RequireYes bool `yaml:"require_yes,omitempty"` // refused unless running with --yes
+}
+
+// MCPOAuthConfig obtains a bearer token for a remote MCP server.
+type MCPOAuthConfig struct {
+	Flow          string   `yaml:"flow"` // client_credentials or device
+	TokenURL      string   `yaml:"token_url"`
+	DeviceAuthURL string   `yaml:"device_auth_url,omitempty"`
+	ClientID      string   `yaml:"client_id"`
+	ClientSecret  string   `yaml:"client_secret,omitempty"`
+	Scopes        []string `yaml:"scopes,omitempty"`
 }
 
 // AIOverride allows per-platform or per-channel AI provider settings.
@@ -95,6 +183,8 @@ type AgentEntry struct {
 	Workspace    string   `yaml:"workspace,omitempty"`    // workspace directory for this agent
 	AllowTools   []string `yaml:"allow_tools,omitempty"`  // whitelist; empty = allow all
 	DenyTools    []string `yaml:"deny_tools,omitempty"`   // blacklist; checked after allowlist
+	AllowSkills  []string `yaml:"allow_skills,omitempty"` // skill whitelist; empty = all skills
+	DenySkills   []string `yaml:"deny_skills,omitempty"`  // skill blacklist; checked after allowlist
 }
 
 // AgentBindingMatch holds the filter criteria for a binding.
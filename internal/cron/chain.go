@@ -0,0 +1,187 @@
+package cron
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+)
+
+// Trigger conditions for Job.AfterOn
+const (
+	AfterSuccess = "success"
+	AfterFailure = "failure"
+	AfterAlways  = "always"
+)
+
+// upstreamResult is the outcome of the job that triggered a chained run
+type upstreamResult struct {
+	job    *Job
+	output string
+	err    error
+}
+
+// templateVar matches {{output}}, {{error}} and {{upstream}} (spaces allowed)
+var templateVar = regexp.MustCompile(`\{\{\s*(output|error|upstream)\s*\}\}`)
+
+// renderTemplate substitutes upstream template variables in text:
+//
+//	{{output}}    the upstream job's output
+//	{{error}}     the upstream job's error (empty on success)
+//	{{upstream}}  the upstream job's name
+//
+// Text is returned unchanged for runs not triggered by another job.
+func renderTemplate(text string, upstream *upstreamResult) string {
+	if upstream == nil {
+		return text
+	}
+	return templateVar.ReplaceAllStringFunc(text, func(m string) string {
+		switch templateVar.FindStringSubmatch(m)[1] {
+		case "output":
+			return upstream.output
+		case "error":
+			if upstream.err != nil {
+				return upstream.err.Error()
+			}
+			return ""
+		default:
+			return upstream.job.Name
+		}
+	})
+}
+
+// renderArguments returns a copy of tool arguments with template variables
+// substituted in every string value, including nested maps and lists.
+func renderArguments(args map[string]any, upstream *upstreamResult) map[string]any {
+	if args == nil || upstream == nil {
+		return args
+	}
+	return renderValue(args, upstream).(map[string]any)
+}
+
+func renderValue(v any, upstream *upstreamResult) any {
+	switch val := v.(type) {
+	case string:
+		return renderTemplate(val, upstream)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = renderValue(item, upstream)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = renderValue(item, upstream)
+		}
+		return out
+	}
+	return v
+}
+
+// validateAfter checks that job id may run after the given upstream job:
+// the upstream must exist and following the chain must not lead back to id.
+func validateAfter(jobs map[string]*Job, id, after, on string) error {
+	switch on {
+	case "", AfterSuccess, AfterFailure, AfterAlways:
+	default:
+		return fmt.Errorf("invalid after_on %q (use success, failure, or always)", on)
+	}
+	if after == "" {
+		if on != "" {
+			return fmt.Errorf("after_on requires an upstream job (after)")
+		}
+		return nil
+	}
+	if _, ok := jobs[after]; !ok {
+		return fmt.Errorf("upstream job not found: %s", after)
+	}
+
+	seen := map[string]bool{id: true}
+	for cur := after; cur != ""; {
+		if seen[cur] {
+			return fmt.Errorf("dependency cycle: job %s would run after itself", id)
+		}
+		seen[cur] = true
+		upstream, ok := jobs[cur]
+		if !ok {
+			break
+		}
+		cur = upstream.After
+	}
+	return nil
+}
+
+// dependentsOf returns the jobs chained after the given job, oldest first
+func dependentsOf(jobs map[string]*Job, id string) []*Job {
+	var deps []*Job
+	for _, job := range jobs {
+		if job.After == id {
+			deps = append(deps, job)
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].CreatedAt.Before(deps[j].CreatedAt) })
+	return deps
+}
+
+// triggers reports whether a dependent job runs for the given upstream outcome
+func (j *Job) triggers(err error) bool {
+	switch j.AfterOn {
+	case AfterAlways:
+		return true
+	case AfterFailure:
+		return err != nil
+	default:
+		return err == nil
+	}
+}
+
+// runChain runs a job and then, in order, every enabled job chained after it
+// whose trigger condition matches the outcome. Chains are acyclic, so this
+// always terminates.
+func (s *Scheduler) runChain(job *Job, upstream *upstreamResult) {
+	output, ran, err := s.guardedRun(job, upstream)
+	if !ran {
+		return
+	}
+
+	s.mu.RLock()
+	var next []*Job
+	for _, dep := range dependentsOf(s.jobs, job.ID) {
+		if dep.Enabled && dep.triggers(err) {
+			next = append(next, dep)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, dep := range next {
+		log.Printf("[CRON] Job %s (%s) triggered by %s (%s)", dep.ID, dep.Name, job.ID, job.Name)
+		s.runChain(dep, &upstreamResult{job: job, output: output, err: err})
+	}
+}
+
+// SetAfter chains a job after another job (after="" removes the dependency).
+// Changes that would create a dependency cycle are rejected.
+func (s *Scheduler) SetAfter(id, after, on string) error {
+	s.mu.Lock()
+	job, exists := s.jobs[id]
+	if !exists {
+		s.mu.Unlock()
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if after == "" && job.Schedule == "" {
+		s.mu.Unlock()
+		return fmt.Errorf("job %s has no schedule and would never run without an upstream job", id)
+	}
+	if err := validateAfter(s.jobs, id, after, on); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	job.After, job.AfterOn = after, on
+	s.mu.Unlock()
+
+	if err := s.saveJob(job); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeTools struct {
+	calls []map[string]any
+	err   error
+}
+
+func (f *fakeTools) ExecuteTool(ctx context.Context, toolName string, arguments map[string]any) (any, error) {
+	f.calls = append(f.calls, arguments)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return "report for " + toolName, nil
+}
+
+type fakePrompts struct {
+	prompts []string
+}
+
+func (f *fakePrompts) ExecutePrompt(ctx context.Context, platform, channelID, userID, prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	return "summary", nil
+}
+
+func TestRenderTemplate(t *testing.T) {
+	up := &upstreamResult{job: &Job{Name: "fetch"}, output: "42 rows", err: errors.New("timeout")}
+	got := renderTemplate("{{upstream}}: {{ output }} / {{error}} / {{other}}", up)
+	if want := "fetch: 42 rows / timeout / {{other}}"; got != want {
+		t.Errorf("renderTemplate = %q, want %q", got, want)
+	}
+	if got := renderTemplate("{{output}}", nil); got != "{{output}}" {
+		t.Errorf("expected text unchanged without upstream, got %q", got)
+	}
+
+	args := map[string]any{"text": "got {{output}}", "n": 3, "list": []any{"{{upstream}}"}}
+	rendered := renderArguments(args, up)
+	if rendered["text"] != "got 42 rows" || rendered["n"] != 3 || rendered["list"].([]any)[0] != "fetch" {
+		t.Errorf("renderArguments = %v", rendered)
+	}
+	if args["text"] != "got {{output}}" {
+		t.Errorf("renderArguments modified its input: %v", args)
+	}
+}
+
+func TestCreateJob_RejectsBadDependencies(t *testing.T) {
+	s := newTestScheduler(t, &fakeNotifier{})
+
+	if _, err := s.CreateJob(&Job{Name: "orphan", Message: "hi"}); err == nil {
+		t.Error("expected error for job with neither schedule nor after")
+	}
+	if _, err := s.CreateJob(&Job{Name: "missing", After: "nope", Message: "hi"}); err == nil {
+		t.Error("expected error for unknown upstream job")
+	}
+
+	a, err := s.CreateJob(&Job{Name: "a", Schedule: "0 9 * * *", Message: "a"})
+	if err != nil {
+		t.Fatalf("CreateJob(a): %v", err)
+	}
+	b, err := s.CreateJob(&Job{Name: "b", After: a.ID, Message: "b"})
+	if err != nil {
+		t.Fatalf("CreateJob(b): %v", err)
+	}
+	if _, err := s.CreateJob(&Job{Name: "c", After: b.ID, AfterOn: "sometimes", Message: "c"}); err == nil {
+		t.Error("expected error for invalid after_on")
+	}
+
+	if err := s.SetAfter(a.ID, b.ID, ""); err == nil {
+		t.Error("expected cycle a -> b -> a to be rejected")
+	}
+	if err := s.SetAfter(a.ID, a.ID, ""); err == nil {
+		t.Error("expected self-dependency to be rejected")
+	}
+	if err := s.RemoveJob(a.ID); err == nil {
+		t.Error("expected removing a job with dependents to fail")
+	}
+}
+
+func TestRunChain(t *testing.T) {
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(t, notifier)
+	tools := &fakeTools{}
+	prompts := &fakePrompts{}
+	s.toolExecutor, s.promptExecutor = tools, prompts
+
+	fetch, err := s.CreateJob(&Job{Name: "fetch", Schedule: "0 9 * * *", Tool: "sales_report"})
+	if err != nil {
+		t.Fatalf("CreateJob(fetch): %v", err)
+	}
+	summarize, err := s.CreateJob(&Job{
+		Name: "summarize", After: fetch.ID, Platform: "slack", ChannelID: "C1",
+		Prompt: "Summarize: {{output}}",
+	})
+	if err != nil {
+		t.Fatalf("CreateJob(summarize): %v", err)
+	}
+	if _, err := s.CreateJob(&Job{Name: "alert", After: fetch.ID, AfterOn: AfterFailure, Tool: "page", Arguments: map[string]any{"why": "{{error}}"}}); err != nil {
+		t.Fatalf("CreateJob(alert): %v", err)
+	}
+	if _, err := s.CreateJob(&Job{Name: "archive", After: summarize.ID, Tool: "archive", Arguments: map[string]any{"text": "{{upstream}}: {{output}}"}}); err != nil {
+		t.Fatalf("CreateJob(archive): %v", err)
+	}
+
+	s.executeJob(fetch)
+
+	if len(prompts.prompts) != 1 || prompts.prompts[0] != "Summarize: report for sales_report" {
+		t.Errorf("summarize prompt = %v", prompts.prompts)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0] != "summary" {
+		t.Errorf("expected summary delivered, got %v", notifier.sent)
+	}
+	// fetch + archive ran; alert (on failure) did not
+	if len(tools.calls) != 2 || tools.calls[1]["text"] != "summarize: summary" {
+		t.Errorf("tool calls = %v", tools.calls)
+	}
+
+	tools.calls, prompts.prompts = nil, nil
+	tools.err = errors.New("upstream down")
+	s.executeJob(fetch)
+	if len(prompts.prompts) != 0 {
+		t.Errorf("summarize should not run after failure, got %v", prompts.prompts)
+	}
+	if len(tools.calls) != 2 || tools.calls[1]["why"] != "upstream down" {
+		t.Errorf("expected alert to run with error, got %v", tools.calls)
+	}
+}
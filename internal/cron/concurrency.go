@@ -0,0 +1,165 @@
+package cron
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Overlap policies for Job.Overlap: what happens when a job is triggered
+// while a previous run is still in progress
+const (
+	OverlapSkip  = "skip"  // drop the new run (default)
+	OverlapQueue = "queue" // run again once the current run finishes (at most one pending run)
+	OverlapAllow = "allow" // run concurrently
+)
+
+// DefaultJobTimeout bounds a single run of jobs that don't set Timeout
+const DefaultJobTimeout = 5 * time.Minute
+
+// jobState tracks the in-progress runs of one job
+type jobState struct {
+	gate   chan struct{} // held by the active run (overlap skip/queue)
+	queued bool          // a run is waiting for the gate (overlap queue)
+	active int           // runs in progress
+	since  time.Time     // start of the earliest active run
+}
+
+// RunningJob describes a job with runs in progress
+type RunningJob struct {
+	ID     string
+	Name   string
+	Since  time.Time // when the earliest active run started
+	Runs   int       // runs in progress (more than one only with overlap=allow)
+	Queued bool      // another run is waiting to start (overlap=queue)
+}
+
+// ValidateExecution checks a job's overlap policy and timeout
+func ValidateExecution(job *Job) error {
+	switch job.Overlap {
+	case "", OverlapSkip, OverlapQueue, OverlapAllow:
+	default:
+		return fmt.Errorf("invalid overlap policy %q (use skip, queue, or allow)", job.Overlap)
+	}
+	if job.Timeout != "" {
+		d, err := time.ParseDuration(job.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", job.Timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive, got %s", job.Timeout)
+		}
+	}
+	return nil
+}
+
+// timeout returns how long a single run of the job may take
+func (j *Job) timeout() time.Duration {
+	if d, err := time.ParseDuration(j.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultJobTimeout
+}
+
+// SetMaxConcurrent limits how many jobs run at the same time across the
+// scheduler. Runs beyond the limit wait for a free slot. n <= 0 means no
+// limit. Must be called before Start.
+func (s *Scheduler) SetMaxConcurrent(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= 0 {
+		s.slots = nil
+		return
+	}
+	s.slots = make(chan struct{}, n)
+}
+
+// RunningJobs returns the jobs that currently have runs in progress
+func (s *Scheduler) RunningJobs() []RunningJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var running []RunningJob
+	for id, st := range s.states {
+		if st.active == 0 {
+			continue
+		}
+		r := RunningJob{ID: id, Since: st.since, Runs: st.active, Queued: st.queued}
+		if job, ok := s.jobs[id]; ok {
+			r.Name = job.Name
+		}
+		running = append(running, r)
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].Since.Before(running[j].Since) })
+	return running
+}
+
+// guardedRun runs a job subject to its overlap policy and the global
+// concurrency limit. ran is false when the run was skipped.
+func (s *Scheduler) guardedRun(job *Job, upstream *upstreamResult) (output string, ran bool, err error) {
+	s.mu.Lock()
+	st, ok := s.states[job.ID]
+	if !ok {
+		st = &jobState{gate: make(chan struct{}, 1)}
+		s.states[job.ID] = st
+	}
+	slots := s.slots
+	s.mu.Unlock()
+
+	switch job.Overlap {
+	case OverlapAllow:
+	case OverlapQueue:
+		s.mu.Lock()
+		if st.queued {
+			s.mu.Unlock()
+			log.Printf("[CRON] Job %s (%s) already has a queued run, skipping", job.ID, job.Name)
+			return "", false, nil
+		}
+		st.queued = true
+		s.mu.Unlock()
+
+		st.gate <- struct{}{}
+
+		s.mu.Lock()
+		st.queued = false
+		s.mu.Unlock()
+		defer func() { <-st.gate }()
+	default:
+		select {
+		case st.gate <- struct{}{}:
+			defer func() { <-st.gate }()
+		default:
+			log.Printf("[CRON] Job %s (%s) is still running, skipping this run", job.ID, job.Name)
+			return "", false, nil
+		}
+	}
+
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		default:
+			log.Printf("[CRON] Job %s (%s) waiting for a free slot (max %d concurrent jobs)", job.ID, job.Name, cap(slots))
+			slots <- struct{}{}
+		}
+		defer func() { <-slots }()
+	}
+
+	s.mu.Lock()
+	if st.active == 0 {
+		st.since = time.Now()
+	}
+	st.active++
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		st.active--
+		s.mu.Unlock()
+	}()
+
+	started := time.Now()
+	output, err = s.runJob(job, upstream)
+	s.recordRun(job, started, output, err)
+	return output, true, err
+}
@@ -0,0 +1,175 @@
+package cron
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingPrompts blocks every prompt run until release is closed
+type blockingPrompts struct {
+	started chan string
+	release chan struct{}
+	runs    atomic.Int32
+}
+
+func newBlockingPrompts() *blockingPrompts {
+	return &blockingPrompts{started: make(chan string, 10), release: make(chan struct{})}
+}
+
+func (b *blockingPrompts) ExecutePrompt(ctx context.Context, platform, channelID, userID, prompt string) (string, error) {
+	b.runs.Add(1)
+	b.started <- prompt
+	select {
+	case <-b.release:
+		return "done", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func waitStarted(t *testing.T, b *blockingPrompts) string {
+	t.Helper()
+	select {
+	case p := <-b.started:
+		return p
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for run to start")
+		return ""
+	}
+}
+
+func TestOverlapSkip(t *testing.T) {
+	s := newTestScheduler(t, &fakeNotifier{})
+	prompts := newBlockingPrompts()
+	s.promptExecutor = prompts
+	job, err := s.CreateJob(&Job{Name: "slow", Schedule: "* * * * *", Prompt: "p"})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); s.executeJob(job) }()
+	waitStarted(t, prompts)
+
+	if running := s.RunningJobs(); len(running) != 1 || running[0].ID != job.ID || running[0].Name != "slow" {
+		t.Errorf("RunningJobs = %+v", running)
+	}
+
+	s.executeJob(job) // returns immediately: skipped
+	close(prompts.release)
+	wg.Wait()
+
+	if n := prompts.runs.Load(); n != 1 {
+		t.Errorf("expected overlapping run to be skipped, got %d runs", n)
+	}
+	if running := s.RunningJobs(); len(running) != 0 {
+		t.Errorf("expected no running jobs, got %+v", running)
+	}
+}
+
+func TestOverlapQueue(t *testing.T) {
+	s := newTestScheduler(t, &fakeNotifier{})
+	prompts := newBlockingPrompts()
+	s.promptExecutor = prompts
+	job, err := s.CreateJob(&Job{Name: "slow", Schedule: "* * * * *", Prompt: "p", Overlap: OverlapQueue})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); s.executeJob(job) }()
+		if i == 0 {
+			waitStarted(t, prompts)
+		}
+	}
+	// Wait until the second trigger is queued and the third skipped
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		running := s.RunningJobs()
+		if len(running) == 1 && running[0].Queued {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected a queued run, got %+v", running)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(prompts.release)
+	wg.Wait()
+	if n := prompts.runs.Load(); n != 2 {
+		t.Errorf("expected current run plus one queued run, got %d runs", n)
+	}
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	s := newTestScheduler(t, &fakeNotifier{})
+	prompts := newBlockingPrompts()
+	s.promptExecutor = prompts
+	s.SetMaxConcurrent(1)
+
+	a, _ := s.CreateJob(&Job{Name: "a", Schedule: "* * * * *", Prompt: "a"})
+	b, _ := s.CreateJob(&Job{Name: "b", Schedule: "* * * * *", Prompt: "b"})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); s.executeJob(a) }()
+	waitStarted(t, prompts)
+	go func() { defer wg.Done(); s.executeJob(b) }()
+
+	time.Sleep(50 * time.Millisecond)
+	if n := prompts.runs.Load(); n != 1 {
+		t.Errorf("expected second job to wait for a slot, got %d runs", n)
+	}
+
+	close(prompts.release)
+	wg.Wait()
+	if n := prompts.runs.Load(); n != 2 {
+		t.Errorf("expected both jobs to run, got %d runs", n)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	s := newTestScheduler(t, &fakeNotifier{})
+	prompts := newBlockingPrompts()
+	s.promptExecutor = prompts
+
+	if _, err := s.CreateJob(&Job{Name: "bad", Schedule: "* * * * *", Prompt: "p", Timeout: "soon"}); err == nil {
+		t.Error("expected invalid timeout to be rejected")
+	}
+	if _, err := s.CreateJob(&Job{Name: "bad", Schedule: "* * * * *", Prompt: "p", Overlap: "stack"}); err == nil {
+		t.Error("expected invalid overlap policy to be rejected")
+	}
+
+	var notices atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notices.Add(1)
+	}))
+	defer srv.Close()
+	s.SetWebhookHosts([]string{"127.0.0.1"})
+
+	job, err := s.CreateJob(&Job{Name: "slow", Schedule: "* * * * *", Prompt: "p", Timeout: "50ms",
+		Targets: []Target{{Type: TargetWebhook, URL: srv.URL}}})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	start := time.Now()
+	s.executeJob(job)
+	if time.Since(start) > time.Second {
+		t.Errorf("run was not bounded by the job timeout")
+	}
+	if job.LastError == "" {
+		t.Error("expected timeout to be recorded as the last error")
+	}
+	if notices.Load() != 1 {
+		t.Errorf("failure notice delivered %d times after the run timed out, want 1", notices.Load())
+	}
+}
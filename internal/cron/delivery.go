@@ -0,0 +1,210 @@
+package cron
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Notification policies for Job.Notify
+const (
+	NotifyAlways   = "always"
+	NotifyOnChange = "on_change"
+	NotifyOnMatch  = "on_match"
+)
+
+// ResultJudge is optionally implemented by the PromptExecutor to let the AI
+// model decide whether a job result is worth delivering.
+type ResultJudge interface {
+	// JudgeResult answers a yes/no question about a job result.
+	// previous is empty when judging a condition on the current output only.
+	JudgeResult(ctx context.Context, condition, previous, current string) (bool, error)
+}
+
+// ValidateDelivery checks the delivery rule fields of a job
+func ValidateDelivery(job *Job) error {
+	switch job.Notify {
+	case "", NotifyAlways, NotifyOnChange:
+	case NotifyOnMatch:
+		if job.NotifyMatch == "" && job.NotifyCondition == "" {
+			return fmt.Errorf("notify=on_match requires notify_match or notify_condition")
+		}
+	default:
+		return fmt.Errorf("invalid notify policy %q (use always, on_change, or on_match)", job.Notify)
+	}
+	if job.NotifyMatch != "" {
+		if _, err := regexp.Compile(job.NotifyMatch); err != nil {
+			return fmt.Errorf("invalid notify_match regex: %w", err)
+		}
+	}
+	if job.QuietHours != "" {
+		if _, _, err := parseQuietHours(job.QuietHours); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseQuietHours parses "HH:MM-HH:MM" into minutes since midnight
+func parseQuietHours(window string) (start, end int, err error) {
+	parts := strings.Split(strings.TrimSpace(window), "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid quiet_hours %q (expected HH:MM-HH:MM)", window)
+	}
+	for i, p := range parts {
+		t, perr := time.Parse("15:04", strings.TrimSpace(p))
+		if perr != nil {
+			return 0, 0, fmt.Errorf("invalid quiet_hours %q (expected HH:MM-HH:MM)", window)
+		}
+		m := t.Hour()*60 + t.Minute()
+		if i == 0 {
+			start = m
+		} else {
+			end = m
+		}
+	}
+	return start, end, nil
+}
+
+// inQuietHours reports whether t falls inside the window. Windows that cross
+// midnight (e.g. "22:00-08:00") are supported.
+func inQuietHours(window string, t time.Time) bool {
+	if window == "" {
+		return false
+	}
+	start, end, err := parseQuietHours(window)
+	if err != nil || start == end {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if start < end {
+		return m >= start && m < end
+	}
+	return m >= start || m < end
+}
+
+// hashOutput returns a stable hash of a job's output
+func hashOutput(output string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(output)))
+	return hex.EncodeToString(sum[:])
+}
+
+// shouldNotify applies the job's notify policy to a successful result.
+// previousHash and previousOutput describe the prior run.
+func (s *Scheduler) shouldNotify(ctx context.Context, job *Job, previousHash, previousOutput, output string) bool {
+	switch job.Notify {
+	case NotifyOnChange:
+		if hashOutput(output) == previousHash {
+			return false
+		}
+		if previousHash == "" || job.NotifyCondition == "" {
+			return true
+		}
+		judge, ok := s.promptExecutor.(ResultJudge)
+		if !ok {
+			return true
+		}
+		changed, err := judge.JudgeResult(ctx, job.NotifyCondition, previousOutput, output)
+		if err != nil {
+			log.Printf("[CRON] Job %s (%s): change judgement failed, notifying anyway: %v", job.ID, job.Name, err)
+			return true
+		}
+		return changed
+
+	case NotifyOnMatch:
+		if job.NotifyMatch != "" {
+			re, err := regexp.Compile(job.NotifyMatch)
+			if err != nil {
+				log.Printf("[CRON] Job %s (%s): invalid notify_match: %v", job.ID, job.Name, err)
+				return false
+			}
+			if !re.MatchString(output) {
+				return false
+			}
+		}
+		if job.NotifyCondition == "" {
+			return true
+		}
+		judge, ok := s.promptExecutor.(ResultJudge)
+		if !ok {
+			log.Printf("[CRON] Job %s (%s): no AI judge available for notify_condition", job.ID, job.Name)
+			return false
+		}
+		matched, err := judge.JudgeResult(ctx, job.NotifyCondition, "", output)
+		if err != nil {
+			log.Printf("[CRON] Job %s (%s): condition judgement failed: %v", job.ID, job.Name, err)
+			return false
+		}
+		return matched
+	}
+	return true
+}
+
+// notify sends a message to all of the job's targets, deferring it when the
+// job is inside its quiet-hours window.
+func (s *Scheduler) notify(ctx context.Context, job *Job, message string) error {
+	targets := targetsFor(job)
+	if len(targets) == 0 {
+		return nil
+	}
+	if inQuietHours(job.QuietHours, s.now()) {
+		s.mu.Lock()
+		job.Deferred = append(job.Deferred, message)
+		s.mu.Unlock()
+		log.Printf("[CRON] Job %s (%s): quiet hours, notification deferred", job.ID, job.Name)
+		return nil
+	}
+	return s.deliverToTargets(ctx, job, targets, message)
+}
+
+// failureNoticeTimeout bounds delivering the notice of a failed run
+const failureNoticeTimeout = time.Minute
+
+// notifyFailure reports a failed run. The run's context may have expired
+// with the run, so the notice is delivered under a context of its own.
+func (s *Scheduler) notifyFailure(job *Job, message string) {
+	ctx, cancel := context.WithTimeout(context.Background(), failureNoticeTimeout)
+	defer cancel()
+	if err := s.notify(ctx, job, message); err != nil {
+		log.Printf("[CRON] Job %s (%s): failed to deliver failure notice: %v", job.ID, job.Name, err)
+	}
+}
+
+// flushDeferred delivers notifications that were held back during quiet hours
+func (s *Scheduler) flushDeferred() {
+	now := s.now()
+
+	s.mu.Lock()
+	var ready []*Job
+	for _, job := range s.jobs {
+		if len(job.Deferred) > 0 && !inQuietHours(job.QuietHours, now) {
+			ready = append(ready, job)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, job := range ready {
+		s.mu.Lock()
+		pending := job.Deferred
+		job.Deferred = nil
+		s.mu.Unlock()
+
+		s.refreshTargets(job)
+		targets := targetsFor(job)
+		for _, message := range pending {
+			if err := s.deliverToTargets(context.Background(), job, targets, message); err != nil {
+				log.Printf("[CRON] Job %s (%s): failed to deliver deferred notification: %v", job.ID, job.Name, err)
+			}
+		}
+		log.Printf("[CRON] Job %s (%s): delivered %d deferred notification(s)", job.ID, job.Name, len(pending))
+
+		if err := s.saveJob(job); err != nil {
+			log.Printf("[CRON] Failed to save job: %v", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package cron
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeNotifier struct {
+	sent []string
+}
+
+func (n *fakeNotifier) NotifyChat(message string) error { return nil }
+
+func (n *fakeNotifier) NotifyChatUser(platform, channelID, userID, message string) error {
+	n.sent = append(n.sent, message)
+	return nil
+}
+
+func newTestScheduler(t *testing.T, notifier ChatNotifier) *Scheduler {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return NewScheduler(store, nil, nil, notifier)
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 1, 1, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		window string
+		t      time.Time
+		want   bool
+	}{
+		{"22:00-08:00", at(23, 30), true},
+		{"22:00-08:00", at(7, 59), true},
+		{"22:00-08:00", at(8, 0), false},
+		{"22:00-08:00", at(12, 0), false},
+		{"12:00-13:30", at(13, 0), true},
+		{"12:00-13:30", at(14, 0), false},
+		{"", at(3, 0), false},
+	}
+	for _, tt := range tests {
+		if got := inQuietHours(tt.window, tt.t); got != tt.want {
+			t.Errorf("inQuietHours(%q, %s) = %v, want %v", tt.window, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestValidateDelivery(t *testing.T) {
+	valid := []*Job{
+		{},
+		{Notify: NotifyOnChange},
+		{Notify: NotifyOnMatch, NotifyMatch: `price: \d+`},
+		{Notify: NotifyOnMatch, NotifyCondition: "the price dropped below 100"},
+		{QuietHours: "22:00-07:30"},
+	}
+	for _, job := range valid {
+		if err := ValidateDelivery(job); err != nil {
+			t.Errorf("ValidateDelivery(%+v) unexpected error: %v", job, err)
+		}
+	}
+	invalid := []*Job{
+		{Notify: "sometimes"},
+		{Notify: NotifyOnMatch},
+		{Notify: NotifyOnMatch, NotifyMatch: "("},
+		{QuietHours: "late"},
+	}
+	for _, job := range invalid {
+		if err := ValidateDelivery(job); err == nil {
+			t.Errorf("ValidateDelivery(%+v) expected error", job)
+		}
+	}
+}
+
+func TestDeliverResult_OnChange(t *testing.T) {
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(t, notifier)
+	job := &Job{ID: "j1", Name: "price", Platform: "slack", ChannelID: "C1", Notify: NotifyOnChange}
+
+	for _, out := range []string{"price: 100", "price: 100", "price: 90"} {
+		if err := s.deliverResult(context.Background(), job, out); err != nil {
+			t.Fatalf("deliverResult: %v", err)
+		}
+	}
+	if len(notifier.sent) != 2 || notifier.sent[1] != "price: 90" {
+		t.Errorf("expected first and changed results delivered, got %v", notifier.sent)
+	}
+}
+
+func TestDeliverResult_OnMatch(t *testing.T) {
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(t, notifier)
+	job := &Job{ID: "j1", Name: "stock", Platform: "slack", ChannelID: "C1", Notify: NotifyOnMatch, NotifyMatch: `(?i)in stock`}
+
+	s.deliverResult(context.Background(), job, "Sold out")
+	s.deliverResult(context.Background(), job, "Now IN STOCK")
+	if len(notifier.sent) != 1 || notifier.sent[0] != "Now IN STOCK" {
+		t.Errorf("expected only matching result delivered, got %v", notifier.sent)
+	}
+}
+
+func TestQuietHoursDeferral(t *testing.T) {
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(t, notifier)
+	job := &Job{ID: "j1", Name: "digest", Platform: "slack", ChannelID: "C1", QuietHours: "22:00-08:00"}
+	s.jobs[job.ID] = job
+
+	s.now = func() time.Time { return time.Date(2026, 1, 1, 23, 0, 0, 0, time.Local) }
+	s.deliverResult(context.Background(), job, "night result")
+	if len(notifier.sent) != 0 || len(job.Deferred) != 1 {
+		t.Fatalf("expected notification deferred, sent=%v deferred=%v", notifier.sent, job.Deferred)
+	}
+
+	s.flushDeferred()
+	if len(notifier.sent) != 0 {
+		t.Fatalf("expected no delivery during quiet hours, got %v", notifier.sent)
+	}
+
+	s.now = func() time.Time { return time.Date(2026, 1, 2, 8, 1, 0, 0, time.Local) }
+	s.flushDeferred()
+	if len(notifier.sent) != 1 || notifier.sent[0] != "night result" || len(job.Deferred) != 0 {
+		t.Errorf("expected deferred notification delivered, sent=%v deferred=%v", notifier.sent, job.Deferred)
+	}
+}
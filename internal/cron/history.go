@@ -0,0 +1,154 @@
+package cron
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	maxRunsPerJob   = 50   // older runs are pruned when a new one is recorded
+	maxRunOutputLen = 4000 // longer outputs are truncated in the history
+)
+
+// Run statuses
+const (
+	RunOK    = "ok"
+	RunError = "error"
+)
+
+// Run is one recorded execution of a job
+type Run struct {
+	JobID      string    `json:"job_id"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Status     string    `json:"status"` // "ok" or "error"
+	Output     string    `json:"output,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// initRuns creates the runs table if it doesn't exist
+func (s *Store) initRuns() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS runs (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_id      TEXT NOT NULL,
+			started_at  TEXT NOT NULL,
+			duration_ms INTEGER NOT NULL,
+			status      TEXT NOT NULL,
+			output      TEXT,
+			error       TEXT
+		);
+		CREATE INDEX IF NOT EXISTS runs_job_id ON runs (job_id, id)
+	`)
+	return err
+}
+
+// SaveRun records a run and prunes the job's history to the most recent runs
+func (s *Store) SaveRun(run Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	output := run.Output
+	if len(output) > maxRunOutputLen {
+		output = strings.ToValidUTF8(output[:maxRunOutputLen], "") + "\n... (truncated)"
+	}
+	if _, err := s.db.Exec(`
+		INSERT INTO runs (job_id, started_at, duration_ms, status, output, error)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, run.JobID, run.StartedAt.Format(time.RFC3339Nano), run.DurationMS, run.Status, output, run.Error); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	_, err := s.db.Exec(`
+		DELETE FROM runs WHERE job_id = ? AND id NOT IN (
+			SELECT id FROM runs WHERE job_id = ? ORDER BY id DESC LIMIT ?
+		)
+	`, run.JobID, run.JobID, maxRunsPerJob)
+	return err
+}
+
+// Runs returns a job's most recent runs, newest first. limit <= 0 returns
+// all recorded runs.
+func (s *Store) Runs(jobID string, limit int) ([]Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = maxRunsPerJob
+	}
+	rows, err := s.db.Query(`
+		SELECT job_id, started_at, duration_ms, status, output, error
+		FROM runs WHERE job_id = ? ORDER BY id DESC LIMIT ?
+	`, jobID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var (
+			run       Run
+			startedAt string
+			output    sql.NullString
+			runErr    sql.NullString
+		)
+		if err := rows.Scan(&run.JobID, &startedAt, &run.DurationMS, &run.Status, &output, &runErr); err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		run.StartedAt, _ = time.Parse(time.RFC3339Nano, startedAt)
+		run.Output = output.String
+		run.Error = runErr.String
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// deleteRuns removes a job's history; the caller holds s.mu
+func (s *Store) deleteRuns(jobID string) error {
+	_, err := s.db.Exec("DELETE FROM runs WHERE job_id = ?", jobID)
+	return err
+}
+
+// OnRun registers a function called after every recorded run, e.g. to tell
+// clients watching a job that its history changed
+func (s *Scheduler) OnRun(fn func(job *Job, run Run)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runHooks = append(s.runHooks, fn)
+}
+
+// History returns a job's most recent runs, newest first
+func (s *Scheduler) History(id string, limit int) ([]Run, error) {
+	if _, ok := s.GetJob(id); !ok {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	return s.store.Runs(id, limit)
+}
+
+// recordRun stores the outcome of a run and calls the run hooks
+func (s *Scheduler) recordRun(job *Job, started time.Time, output string, err error) {
+	run := Run{
+		JobID:      job.ID,
+		StartedAt:  started,
+		DurationMS: time.Since(started).Milliseconds(),
+		Status:     RunOK,
+		Output:     output,
+	}
+	if err != nil {
+		run.Status = RunError
+		run.Error = err.Error()
+	}
+	if err := s.store.SaveRun(run); err != nil {
+		log.Printf("[CRON] Failed to record run of job %s: %v", job.ID, err)
+	}
+
+	s.mu.RLock()
+	hooks := append([]func(*Job, Run){}, s.runHooks...)
+	s.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(job, run)
+	}
+}
@@ -0,0 +1,75 @@
+package cron
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHistory_RecordsRuns(t *testing.T) {
+	s := newTestScheduler(t, &fakeNotifier{})
+	tools := &fakeTools{}
+	s.toolExecutor = tools
+	job, err := s.CreateJob(&Job{Name: "weather", Schedule: "0 9 * * *", Tool: "weather_current"})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	var hooked []Run
+	s.OnRun(func(_ *Job, run Run) { hooked = append(hooked, run) })
+
+	s.executeJob(job)
+	tools.err = errors.New("offline")
+	s.executeJob(job)
+
+	runs, err := s.History(job.ID, 0)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(runs) != 2 || len(hooked) != 2 {
+		t.Fatalf("runs = %+v, hooked = %d", runs, len(hooked))
+	}
+	if runs[0].Status != RunError || runs[0].Error != "offline" {
+		t.Errorf("newest run = %+v", runs[0])
+	}
+	if runs[1].Status != RunOK || runs[1].Output != "report for weather_current" {
+		t.Errorf("oldest run = %+v", runs[1])
+	}
+
+	if err := s.RemoveJob(job.ID); err != nil {
+		t.Fatalf("RemoveJob: %v", err)
+	}
+	if runs, _ := s.store.Runs(job.ID, 0); len(runs) != 0 {
+		t.Errorf("history of a removed job = %+v", runs)
+	}
+}
+
+func TestStore_SaveRunPrunesAndTruncates(t *testing.T) {
+	s := newTestScheduler(t, nil)
+	for i := 0; i < maxRunsPerJob+5; i++ {
+		if err := s.store.SaveRun(Run{JobID: "j1", Status: RunOK, Output: strings.Repeat("x", maxRunOutputLen+1)}); err != nil {
+			t.Fatalf("SaveRun: %v", err)
+		}
+	}
+	runs, err := s.store.Runs("j1", 0)
+	if err != nil {
+		t.Fatalf("Runs: %v", err)
+	}
+	if len(runs) != maxRunsPerJob {
+		t.Errorf("kept %d runs, want %d", len(runs), maxRunsPerJob)
+	}
+	if !strings.HasSuffix(runs[0].Output, "(truncated)") {
+		t.Errorf("output not truncated: %d bytes", len(runs[0].Output))
+	}
+	if runs, _ := s.store.Runs("j1", 3); len(runs) != 3 {
+		t.Errorf("Runs(limit 3) = %d runs", len(runs))
+	}
+
+	// 3-byte runes don't line up with the limit; the cut rune is dropped
+	if err := s.store.SaveRun(Run{JobID: "j2", Status: RunOK, Output: strings.Repeat("中", maxRunOutputLen)}); err != nil {
+		t.Fatalf("SaveRun: %v", err)
+	}
+	if runs, _ := s.store.Runs("j2", 1); len(runs) != 1 || !utf8.ValidString(runs[0].Output) {
+		t.Errorf("truncated output is not valid UTF-8")
+	}
+}
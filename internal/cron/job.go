@@ -23,6 +23,31 @@ type Job struct {
 	LastRun   *time.Time             `json:"last_run,omitempty"`  // Last execution timestamp
 	LastError string                 `json:"last_error,omitempty"` // Last error message
 
+	// Delivery rules
+	Notify          string   `json:"notify,omitempty"`           // "always" (default), "on_change", or "on_match"
+	NotifyMatch     string   `json:"notify_match,omitempty"`     // Regex the result must match (on_match)
+	NotifyCondition string   `json:"notify_condition,omitempty"` // Condition judged by the AI model (on_change/on_match)
+	QuietHours      string   `json:"quiet_hours,omitempty"`      // "HH:MM-HH:MM" window during which notifications are deferred
+	LastHash        string   `json:"last_hash,omitempty"`        // Hash of the previous run's output
+	LastOutput      string   `json:"last_output,omitempty"`      // Previous run's output (for AI change detection)
+	Deferred        []string `json:"deferred,omitempty"`         // Notifications held back during quiet hours
+
+	// Chaining: run after another job finishes instead of (or in addition to) Schedule
+	After   string `json:"after,omitempty"`    // Upstream job ID
+	AfterOn string `json:"after_on,omitempty"` // "success" (default), "failure", or "always"
+
+	// Execution limits
+	Overlap string `json:"overlap,omitempty"` // "skip" (default), "queue", or "allow" when triggered while still running
+	Timeout string `json:"timeout,omitempty"` // Maximum duration of one run, e.g. "10m" (default 5m)
+
+	// Targets lists where results are delivered. Empty = the chat the job was created from.
+	Targets []Target `json:"targets,omitempty"`
+
+	// File limits of the agent that created the job; file targets are checked
+	// against them when set and again on delivery. Unset = the scheduler's.
+	AllowedPaths  []string `json:"allowed_paths,omitempty"`   // File targets must be under one of these
+	NoFileTargets bool     `json:"no_file_targets,omitempty"` // File targets are refused
+
 	// Runtime fields (not persisted)
 	EntryID cron.EntryID `json:"-"` // Cron scheduler entry ID
 }
@@ -43,6 +68,18 @@ func (j *Job) Clone() *Job {
 		CreatedAt: j.CreatedAt,
 		LastError: j.LastError,
 		EntryID:   j.EntryID,
+
+		Notify:          j.Notify,
+		NotifyMatch:     j.NotifyMatch,
+		NotifyCondition: j.NotifyCondition,
+		QuietHours:      j.QuietHours,
+		LastHash:        j.LastHash,
+		LastOutput:      j.LastOutput,
+
+		After:   j.After,
+		AfterOn: j.AfterOn,
+		Overlap: j.Overlap,
+		Timeout: j.Timeout,
 	}
 
 	if j.LastRun != nil {
@@ -57,5 +94,15 @@ func (j *Job) Clone() *Job {
 		}
 	}
 
+	if j.Targets != nil {
+		clone.Targets = append([]Target(nil), j.Targets...)
+	}
+	clone.AllowedPaths = append([]string(nil), j.AllowedPaths...)
+	clone.NoFileTargets = j.NoFileTargets
+
+	if j.Deferred != nil {
+		clone.Deferred = append([]string(nil), j.Deferred...)
+	}
+
 	return clone
 }
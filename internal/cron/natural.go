@@ -0,0 +1,547 @@
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedSchedule is a schedule phrase translated into a job schedule
+type ParsedSchedule struct {
+	Spec        string    // Job schedule: 5-field cron expression or one-shot "@at <time>"
+	Once        time.Time // Fire time of one-shot schedules (zero for recurring ones)
+	Description string    // Human-readable summary, e.g. "every weekday at 09:30"
+}
+
+// NextRuns returns up to n upcoming fire times after from
+func (p *ParsedSchedule) NextRuns(from time.Time, n int) []time.Time {
+	runs, _ := NextRuns(p.Spec, from, n)
+	return runs
+}
+
+// ParseSchedule translates a schedule into a job schedule. It accepts cron
+// expressions as-is and deterministically parses common English and Chinese
+// phrases, for example:
+//
+//	every 15 minutes / 每15分钟
+//	every hour at :43 / 每小时43分
+//	every weekday at 9:30 / 工作日上午9点半
+//	every mon, wed and fri at 6pm / 每周一三五晚上6点
+//	on the 1st of every month at 9am / 每月1号早上9点
+//	last friday of every month at 5pm / 每月最后一个周五下午五点
+//	tomorrow at 9am / 明天早上9点 / in 30 minutes / 30分钟后
+//
+// now anchors relative phrases such as "tomorrow".
+func ParseSchedule(text string, now time.Time) (*ParsedSchedule, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("schedule is empty")
+	}
+
+	// Cron expressions and descriptors pass through unchanged
+	if _, err := parseSpec(text); err == nil {
+		p := &ParsedSchedule{Spec: text, Description: "cron " + text}
+		if isOnceSpec(text) {
+			p.Once, _ = time.Parse(time.RFC3339, strings.TrimPrefix(text, onceSpecPrefix))
+			p.Description = describeOnce(p.Once)
+		}
+		return p, nil
+	}
+
+	s := normalizePhrase(text)
+
+	// Intervals don't take a time of day
+	if p, ok, err := parseInterval(s); ok || err != nil {
+		return p, err
+	}
+	if p, ok := parseRelative(s, now); ok {
+		return p, nil
+	}
+
+	rest, clock, err := extractTimeOfDay(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", text, err)
+	}
+	rest = strings.Trim(fillerWords.ReplaceAllString(rest, ""), " ,的")
+
+	p, ok, err := parseDate(rest, clock, now)
+	if !ok && err == nil {
+		return nil, fmt.Errorf("could not understand schedule %q; use a cron expression or a phrase like \"every weekday at 9:30\"", text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", text, err)
+	}
+	return p, nil
+}
+
+// timeOfDay is a parsed clock time
+type timeOfDay struct {
+	hour, minute int
+	set          bool
+}
+
+func (c timeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", c.hour, c.minute)
+}
+
+var (
+	cnWeekdays   = regexp.MustCompile(`(?:周|星期|礼拜)([一二三四五六日天1-7](?:[、,，和及与]?[一二三四五六日天1-7])*)`)
+	cnNumeral    = regexp.MustCompile(`[零〇一二两三四五六七八九十]+`)
+	enNumberWord = regexp.MustCompile(`\b(one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|fifteen|twenty|thirty)\b`)
+	spaces       = regexp.MustCompile(`\s+`)
+)
+
+var enNumbers = map[string]string{
+	"one": "1", "two": "2", "three": "3", "four": "4", "five": "5", "six": "6",
+	"seven": "7", "eight": "8", "nine": "9", "ten": "10", "eleven": "11",
+	"twelve": "12", "fifteen": "15", "twenty": "20", "thirty": "30",
+}
+
+// normalizePhrase lowercases a phrase and rewrites Chinese numerals,
+// weekdays and clock idioms into a canonical form ("周五下午五点半" becomes
+// "周5 下午5点30分").
+func normalizePhrase(text string) string {
+	s := strings.ToLower(text)
+	s = strings.NewReplacer(
+		"：", ":", "，", ",", "、", ",", "　", " ",
+		"半小时", "30分钟", "半个小时", "30分钟",
+		"最后一个", "最后", "最后1个", "最后",
+		"点半", "点30分", "点一刻", "点15分", "点三刻", "点45分",
+		"星期天", "周日", "礼拜天", "周日",
+	).Replace(s)
+
+	s = cnWeekdays.ReplaceAllStringFunc(s, func(m string) string {
+		days := cnWeekdays.FindStringSubmatch(m)[1]
+		var nums []string
+		for _, r := range days {
+			switch {
+			case r == '日' || r == '天' || r == '7':
+				nums = append(nums, "0")
+			case r >= '1' && r <= '6':
+				nums = append(nums, string(r))
+			case strings.ContainsRune("一二三四五六", r):
+				nums = append(nums, strconv.Itoa(strings.IndexRune("一二三四五六", r)/3+1))
+			}
+		}
+		return "周" + strings.Join(nums, ",") + " "
+	})
+
+	s = cnNumeral.ReplaceAllStringFunc(s, func(m string) string {
+		return strconv.Itoa(chineseNumber(m))
+	})
+	s = enNumberWord.ReplaceAllStringFunc(s, func(m string) string { return enNumbers[m] })
+	return strings.TrimSpace(spaces.ReplaceAllString(s, " "))
+}
+
+// chineseNumber converts a Chinese numeral below 100 ("二十三") or a digit
+// string ("二零二六") into an integer
+func chineseNumber(s string) int {
+	digit := func(r rune) int {
+		switch r {
+		case '零', '〇':
+			return 0
+		case '两':
+			return 2
+		}
+		if i := strings.IndexRune("一二三四五六七八九", r); i >= 0 {
+			return i/3 + 1
+		}
+		return 0
+	}
+
+	if tens, ones, ok := strings.Cut(s, "十"); ok {
+		n := 10
+		if tens != "" {
+			n = chineseNumber(tens) * 10
+		}
+		if ones != "" {
+			n += chineseNumber(ones)
+		}
+		return n
+	}
+	n := 0
+	for _, r := range s {
+		n = n*10 + digit(r)
+	}
+	return n
+}
+
+var (
+	everyMinutes = []*regexp.Regexp{
+		regexp.MustCompile(`^(?:every|each) (\d+) ?(?:minutes?|mins?)$`),
+		regexp.MustCompile(`^每隔? ?(\d+) ?分钟$`),
+	}
+	everyMinute = regexp.MustCompile(`^(?:every minute|each minute|每分钟|每1分钟)$`)
+	everyHalf   = regexp.MustCompile(`^every half (?:an )?hour$`)
+	everyHours  = []*regexp.Regexp{
+		regexp.MustCompile(`^(?:every|each) (\d+) ?(?:hours?|hrs?)$`),
+		regexp.MustCompile(`^每隔? ?(\d+) ?个?小时$`),
+	}
+	hourly = []*regexp.Regexp{
+		regexp.MustCompile(`^(?:every hour|each hour|hourly)(?: at (?:minute )?:?(\d{1,2}))?$`),
+		regexp.MustCompile(`^at :?(\d{1,2}) minutes? past (?:every|each) hour$`),
+		regexp.MustCompile(`^每个?小时(?:的)?(?:第? ?(\d{1,2}) ?分钟?)?$`),
+		regexp.MustCompile(`^每个?整点$`),
+	}
+)
+
+// parseInterval handles schedules repeating every N minutes or hours
+func parseInterval(s string) (*ParsedSchedule, bool, error) {
+	if everyMinute.MatchString(s) {
+		return &ParsedSchedule{Spec: "* * * * *", Description: "every minute"}, true, nil
+	}
+	if everyHalf.MatchString(s) {
+		return &ParsedSchedule{Spec: "*/30 * * * *", Description: "every 30 minutes"}, true, nil
+	}
+	for _, re := range everyMinutes {
+		if m := re.FindStringSubmatch(s); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if n < 1 || n > 59 {
+				return nil, true, fmt.Errorf("minute interval must be between 1 and 59, got %d", n)
+			}
+			if n == 1 {
+				return &ParsedSchedule{Spec: "* * * * *", Description: "every minute"}, true, nil
+			}
+			return &ParsedSchedule{Spec: fmt.Sprintf("*/%d * * * *", n), Description: fmt.Sprintf("every %d minutes", n)}, true, nil
+		}
+	}
+	for _, re := range everyHours {
+		if m := re.FindStringSubmatch(s); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if n < 1 || n > 23 {
+				return nil, true, fmt.Errorf("hour interval must be between 1 and 23, got %d", n)
+			}
+			if n == 1 {
+				return &ParsedSchedule{Spec: "0 * * * *", Description: "every hour on the hour"}, true, nil
+			}
+			return &ParsedSchedule{Spec: fmt.Sprintf("0 */%d * * *", n), Description: fmt.Sprintf("every %d hours on the hour", n)}, true, nil
+		}
+	}
+	for _, re := range hourly {
+		if m := re.FindStringSubmatch(s); m != nil {
+			minute := 0
+			if len(m) > 1 && m[1] != "" {
+				minute, _ = strconv.Atoi(m[1])
+			}
+			if minute > 59 {
+				return nil, true, fmt.Errorf("minute must be between 0 and 59, got %d", minute)
+			}
+			desc := "every hour on the hour"
+			if minute != 0 {
+				desc = fmt.Sprintf("every hour at minute %d", minute)
+			}
+			return &ParsedSchedule{Spec: fmt.Sprintf("%d * * * *", minute), Description: desc}, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+var relativeTime = []*regexp.Regexp{
+	regexp.MustCompile(`^in (\d+) ?(minutes?|mins?|hours?|hrs?|days?)$`),
+	regexp.MustCompile(`^(\d+) ?(分钟|个?小时|天)(?:之?后|以后)$`),
+}
+
+// parseRelative handles one-shot schedules relative to now ("in 30 minutes")
+func parseRelative(s string, now time.Time) (*ParsedSchedule, bool) {
+	for _, re := range relativeTime {
+		m := re.FindStringSubmatch(s)
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		var d time.Duration
+		switch unit := m[2]; {
+		case strings.HasPrefix(unit, "m"), unit == "分钟":
+			d = time.Duration(n) * time.Minute
+		case strings.HasPrefix(unit, "h"), strings.HasSuffix(unit, "小时"):
+			d = time.Duration(n) * time.Hour
+		default:
+			d = time.Duration(n) * 24 * time.Hour
+		}
+		at := now.Add(d).Truncate(time.Minute)
+		return &ParsedSchedule{Spec: OnceSpec(at), Once: at, Description: describeOnce(at)}, true
+	}
+	return nil, false
+}
+
+const cnPeriods = `凌晨|早上|早晨|清晨|上午|中午|午后|下午|傍晚|晚上|夜里|夜间|晚|早`
+
+var (
+	cnClock     = regexp.MustCompile(`(` + cnPeriods + `)? ?(\d{1,2}) ?(?:点|时|點)(?: ?(\d{1,2}) ?分?)?`)
+	colonClock  = regexp.MustCompile(`(` + cnPeriods + `)? ?(?:\bat )?(\d{1,2}):(\d{2}) ?(am|pm|a\.m\.|p\.m\.)?`)
+	ampmClock   = regexp.MustCompile(`(?:\bat )?\b(\d{1,2}) ?(am|pm|a\.m\.|p\.m\.)`)
+	atClock     = regexp.MustCompile(`\bat (\d{1,2})(?: o'?clock)?(?: |$)`)
+	namedClock  = regexp.MustCompile(`(?:\bat )?\b(noon|midday|midnight)\b|(午夜|正午)`)
+	pmHint      = regexp.MustCompile(`\b(afternoon|evening|night|tonight)\b`)
+	fillerWords = regexp.MustCompile(`^(?:at |在|于)|( at| on)$`)
+)
+
+// extractTimeOfDay finds the clock time in a phrase and returns the phrase
+// without it
+func extractTimeOfDay(s string) (string, timeOfDay, error) {
+	var (
+		clock  timeOfDay
+		period string
+		loc    []int
+	)
+	if m := cnClock.FindStringSubmatchIndex(s); m != nil {
+		loc = m[:2]
+		period = sub(s, m, 1)
+		clock.hour, _ = strconv.Atoi(sub(s, m, 2))
+		clock.minute, _ = strconv.Atoi(sub(s, m, 3))
+	} else if m := colonClock.FindStringSubmatchIndex(s); m != nil {
+		loc = m[:2]
+		period = sub(s, m, 1) + sub(s, m, 4)
+		clock.hour, _ = strconv.Atoi(sub(s, m, 2))
+		clock.minute, _ = strconv.Atoi(sub(s, m, 3))
+	} else if m := ampmClock.FindStringSubmatchIndex(s); m != nil {
+		loc = m[:2]
+		period = sub(s, m, 2)
+		clock.hour, _ = strconv.Atoi(sub(s, m, 1))
+	} else if m := atClock.FindStringSubmatchIndex(s); m != nil {
+		loc = m[:2]
+		clock.hour, _ = strconv.Atoi(sub(s, m, 1))
+	} else if m := namedClock.FindStringSubmatchIndex(s); m != nil {
+		loc = m[:2]
+		if name := sub(s, m, 1); name == "midnight" {
+			clock.hour = 0
+		} else if name != "" {
+			clock.hour = 12
+		} else if sub(s, m, 2) == "午夜" {
+			clock.hour = 0
+		} else {
+			clock.hour = 12
+		}
+		period = "named"
+	} else {
+		return s, clock, nil
+	}
+
+	if period == "" {
+		switch pmHint.FindString(s) {
+		case "":
+		case "night", "tonight":
+			period = "night"
+		default:
+			period = "pm"
+		}
+	}
+	clock.hour = applyPeriod(clock.hour, period)
+	if clock.hour > 23 || clock.minute > 59 {
+		return "", clock, fmt.Errorf("invalid time of day %02d:%02d", clock.hour, clock.minute)
+	}
+	clock.set = true
+
+	rest := s[:loc[0]] + " " + s[loc[1]:]
+	return strings.TrimSpace(spaces.ReplaceAllString(rest, " ")), clock, nil
+}
+
+// sub returns submatch i of s given the indexes from FindStringSubmatchIndex
+func sub(s string, m []int, i int) string {
+	if m[2*i] < 0 {
+		return ""
+	}
+	return s[m[2*i]:m[2*i+1]]
+}
+
+// applyPeriod converts a 12-hour clock hour using a period of day marker
+func applyPeriod(hour int, period string) int {
+	switch period {
+	case "am", "a.m.", "凌晨", "早上", "早晨", "清晨", "上午", "早":
+		if hour == 12 {
+			return 0
+		}
+	case "pm", "p.m.", "下午", "午后", "傍晚":
+		if hour < 12 {
+			return hour + 12
+		}
+	case "night", "晚上", "夜里", "夜间", "晚":
+		// 晚上12点 is midnight, not noon
+		if hour == 12 {
+			return 0
+		}
+		if hour < 12 {
+			return hour + 12
+		}
+	case "中午":
+		// 中午12点 is noon, 中午1点 is 13:00
+		if hour < 11 {
+			return hour + 12
+		}
+	}
+	return hour
+}
+
+var (
+	daily    = regexp.MustCompile(`^(?:every ?day|daily|each day|every (?:morning|afternoon|evening|night)|每天|每日|天天|每1天|每晚|每早)$`)
+	weekdays = regexp.MustCompile(`^(?:every weekday|weekdays|on weekdays|every working day|(?:每个?)?工作日|(?:每个?)?周1 ?(?:-|~|至|到) ?周?5)$`)
+	weekends = regexp.MustCompile(`^(?:every weekend|weekends|on weekends|(?:每个?)?周末)$`)
+	enDays   = regexp.MustCompile(`^(?:every |each |on )?((?:(?:sun|mon|tue|wed|thu|fri|sat)[a-z]*(?:, ?| and | ?& ?| |$))+)$`)
+	cnDays   = regexp.MustCompile(`^(?:每个?)?周([\d,]+)$`)
+	enDom    = []*regexp.Regexp{
+		regexp.MustCompile(`^(?:every month|monthly|each month) on the (\d{1,2})(?:st|nd|rd|th)?$`),
+		regexp.MustCompile(`^(?:on )?the (\d{1,2})(?:st|nd|rd|th)? (?:of )?(?:every|each) month$`),
+		regexp.MustCompile(`^(?:every|each) month on day (\d{1,2})$`),
+		regexp.MustCompile(`^每个?月(?:的)? ?(\d{1,2}) ?(?:号|日)$`),
+	}
+	lastDay   = regexp.MustCompile(`^(?:(?:on )?the last day of (?:every|each|the) month|(?:every|each) month on the last day|每个?月(?:的)?最后1?天)$`)
+	enLastDow = regexp.MustCompile(`^(?:on )?(?:the )?last (sun|mon|tue|wed|thu|fri|sat)[a-z]* of (?:every|each|the) month$`)
+	cnLastDow = regexp.MustCompile(`^每个?月(?:的)?最后周(\d)$`)
+	onceDay   = regexp.MustCompile(`^(today|tonight|tomorrow|the day after tomorrow|day after tomorrow|今天?|今日|明天?|明日|后天)$`)
+	isoDate   = regexp.MustCompile(`^(?:on )?(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	cnDate    = regexp.MustCompile(`^(?:(\d{4}) ?年)? ?(\d{1,2}) ?月 ?(\d{1,2}) ?(?:日|号)$`)
+)
+
+var weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// parseDate handles schedules that fire at a time of day: daily, weekly,
+// monthly and one-shot dates
+func parseDate(s string, clock timeOfDay, now time.Time) (*ParsedSchedule, bool, error) {
+	recurring := func(dom, dow, desc string) (*ParsedSchedule, bool, error) {
+		if !clock.set {
+			return nil, true, fmt.Errorf("no time of day given (e.g. \"at 9:30\" or \"早上9点\")")
+		}
+		return &ParsedSchedule{
+			Spec:        fmt.Sprintf("%d %d %s * %s", clock.minute, clock.hour, dom, dow),
+			Description: fmt.Sprintf("%s at %s", desc, clock),
+		}, true, nil
+	}
+
+	switch {
+	case daily.MatchString(s):
+		return recurring("*", "*", "every day")
+	case weekdays.MatchString(s):
+		return recurring("*", "1-5", "every weekday (Monday to Friday)")
+	case weekends.MatchString(s):
+		return recurring("*", "0,6", "every Saturday and Sunday")
+	case lastDay.MatchString(s):
+		return recurring("L", "*", "on the last day of every month")
+	}
+
+	if m := enLastDow.FindStringSubmatch(s); m != nil {
+		d := englishWeekday(m[1])
+		return recurring("*", fmt.Sprintf("%dL", d), fmt.Sprintf("on the last %s of every month", weekdayNames[d]))
+	}
+	if m := cnLastDow.FindStringSubmatch(s); m != nil {
+		d, _ := strconv.Atoi(m[1])
+		if d > 6 {
+			return nil, true, fmt.Errorf("invalid weekday %d", d)
+		}
+		return recurring("*", fmt.Sprintf("%dL", d), fmt.Sprintf("on the last %s of every month", weekdayNames[d]))
+	}
+
+	if days := parseWeekdayList(s); days != nil {
+		names := make([]string, len(days))
+		nums := make([]string, len(days))
+		for i, d := range days {
+			names[i] = weekdayNames[d]
+			nums[i] = strconv.Itoa(d)
+		}
+		return recurring("*", strings.Join(nums, ","), "every "+joinAnd(names))
+	}
+
+	for _, re := range enDom {
+		if m := re.FindStringSubmatch(s); m != nil {
+			day, _ := strconv.Atoi(m[1])
+			if day < 1 || day > 31 {
+				return nil, true, fmt.Errorf("day of month must be between 1 and 31, got %d", day)
+			}
+			return recurring(strconv.Itoa(day), "*", fmt.Sprintf("on day %d of every month", day))
+		}
+	}
+
+	// One-shot dates
+	var date time.Time
+	if m := onceDay.FindStringSubmatch(s); m != nil {
+		offset := 0
+		switch m[1] {
+		case "tomorrow", "明", "明天", "明日":
+			offset = 1
+		case "the day after tomorrow", "day after tomorrow", "后天":
+			offset = 2
+		}
+		date = time.Date(now.Year(), now.Month(), now.Day()+offset, 0, 0, 0, 0, now.Location())
+	} else if m := isoDate.FindStringSubmatch(s); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		date = time.Date(y, time.Month(mo), d, 0, 0, 0, 0, now.Location())
+	} else if m := cnDate.FindStringSubmatch(s); m != nil {
+		y := now.Year()
+		if m[1] != "" {
+			y, _ = strconv.Atoi(m[1])
+		}
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		date = time.Date(y, time.Month(mo), d, 0, 0, 0, 0, now.Location())
+		// A date without a year that has already passed means next year
+		if m[1] == "" && date.AddDate(0, 0, 1).Before(now) {
+			date = date.AddDate(1, 0, 0)
+		}
+	} else {
+		return nil, false, nil
+	}
+
+	if !clock.set {
+		return nil, true, fmt.Errorf("no time of day given (e.g. \"at 9:30\" or \"早上9点\")")
+	}
+	at := date.Add(time.Duration(clock.hour)*time.Hour + time.Duration(clock.minute)*time.Minute)
+	if !at.After(now) {
+		return nil, true, fmt.Errorf("%s is in the past", at.Format("2006-01-02 15:04"))
+	}
+	return &ParsedSchedule{Spec: OnceSpec(at), Once: at, Description: describeOnce(at)}, true, nil
+}
+
+// parseWeekdayList parses "mon, wed and fri" or "周1,3,5" into weekday numbers
+func parseWeekdayList(s string) []int {
+	if m := cnDays.FindStringSubmatch(s); m != nil {
+		var days []int
+		for _, part := range strings.Split(m[1], ",") {
+			if d, err := strconv.Atoi(part); err == nil && d <= 6 {
+				days = append(days, d)
+			}
+		}
+		return days
+	}
+	m := enDays.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	var days []int
+	for _, word := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '&' }) {
+		if word == "and" {
+			continue
+		}
+		d := englishWeekday(word)
+		if d < 0 {
+			return nil
+		}
+		days = append(days, d)
+	}
+	return days
+}
+
+// englishWeekday maps a weekday name or abbreviation to 0 (Sunday) - 6
+func englishWeekday(word string) int {
+	for i, name := range weekdayNames {
+		if strings.HasPrefix(word, strings.ToLower(name[:3])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// joinAnd joins names as "a, b and c"
+func joinAnd(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// describeOnce describes a one-shot fire time
+func describeOnce(t time.Time) string {
+	return "once at " + t.Format("2006-01-02 15:04 (Mon)")
+}
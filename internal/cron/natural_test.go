@@ -0,0 +1,123 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Sunday 2026-10-18 10:00
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		text string
+		spec string
+	}{
+		{"0 9 * * 1-5", "0 9 * * 1-5"},
+		{"every 15 minutes", "*/15 * * * *"},
+		{"每隔30分钟", "*/30 * * * *"},
+		{"每半小时", "*/30 * * * *"},
+		{"every two hours", "0 */2 * * *"},
+		{"every hour at :43", "43 * * * *"},
+		{"每小时43分", "43 * * * *"},
+		{"every day at 7am", "0 7 * * *"},
+		{"每天晚上十点", "0 22 * * *"},
+		{"每天晚上12点", "0 0 * * *"},
+		{"每天夜里十二点半", "30 0 * * *"},
+		{"every night at 12", "0 0 * * *"},
+		{"every evening at 6", "0 18 * * *"},
+		{"every weekday at 9:30", "30 9 * * 1-5"},
+		{"工作日上午9点半", "30 9 * * 1-5"},
+		{"周一至周五早上八点一刻", "15 8 * * 1-5"},
+		{"weekends at noon", "0 12 * * 0,6"},
+		{"every mon, wed and fri at 6pm", "0 18 * * 1,3,5"},
+		{"每周一三五晚上6点", "0 18 * * 1,3,5"},
+		{"每周三十点", "0 10 * * 3"},
+		{"每个星期天中午12点", "0 12 * * 0"},
+		{"on the 1st of every month at 9am", "0 9 1 * *"},
+		{"每月15号下午3点", "0 15 15 * *"},
+		{"the last day of every month at 18:00", "0 18 L * *"},
+		{"每月最后一天晚上8点", "0 20 L * *"},
+		{"last friday of every month at 5pm", "0 17 * * 5L"},
+		{"每月最后一个周五下午五点", "0 17 * * 5L"},
+	}
+	for _, tt := range tests {
+		p, err := ParseSchedule(tt.text, now)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error: %v", tt.text, err)
+			continue
+		}
+		if p.Spec != tt.spec {
+			t.Errorf("ParseSchedule(%q) = %q (%s), want %q", tt.text, p.Spec, p.Description, tt.spec)
+		}
+		if len(p.NextRuns(now, 3)) != 3 {
+			t.Errorf("ParseSchedule(%q): expected 3 upcoming runs", tt.text)
+		}
+	}
+}
+
+func TestParseSchedule_OneShot(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"tomorrow at 9am", time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)},
+		{"明天早上9点", time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)},
+		{"今晚8点", time.Date(2026, 10, 18, 20, 0, 0, 0, time.Local)},
+		{"in 30 minutes", now.Add(30 * time.Minute)},
+		{"2小时后", now.Add(2 * time.Hour)},
+		{"2026-12-24 18:30", time.Date(2026, 12, 24, 18, 30, 0, 0, time.Local)},
+		{"3月1日上午10点", time.Date(2027, 3, 1, 10, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		p, err := ParseSchedule(tt.text, now)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error: %v", tt.text, err)
+			continue
+		}
+		if !p.Once.Equal(tt.want) {
+			t.Errorf("ParseSchedule(%q) = %s, want %s", tt.text, p.Once, tt.want)
+		}
+		runs := p.NextRuns(now, 3)
+		if len(runs) != 1 || !runs[0].Equal(tt.want) {
+			t.Errorf("ParseSchedule(%q): expected a single run, got %v", tt.text, runs)
+		}
+	}
+}
+
+func TestParseSchedule_Errors(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local)
+	for _, text := range []string{
+		"",
+		"whenever you feel like it",
+		"every monday", // no time of day
+		"today at 8am", // in the past
+		"every 90 minutes",
+		"every day at 25:00",
+	} {
+		if p, err := ParseSchedule(text, now); err == nil {
+			t.Errorf("ParseSchedule(%q) = %+v, expected error", text, p)
+		}
+	}
+}
+
+func TestNextRuns_LastWeekday(t *testing.T) {
+	from := time.Date(2026, 10, 18, 10, 0, 0, 0, time.Local)
+	runs, err := NextRuns("0 17 * * 5L", from, 3)
+	if err != nil {
+		t.Fatalf("NextRuns: %v", err)
+	}
+	want := []string{"2026-10-30 17:00", "2026-11-27 17:00", "2026-12-25 17:00"}
+	for i, run := range runs {
+		if got := run.Format("2006-01-02 15:04"); got != want[i] {
+			t.Errorf("run %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	runs, _ = NextRuns("0 18 L * *", from, 2)
+	if len(runs) != 2 || runs[0].Day() != 31 || runs[1].Day() != 30 {
+		t.Errorf("last day of month runs = %v", runs)
+	}
+}
@@ -0,0 +1,112 @@
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// onceSpecPrefix marks a one-shot schedule: "@at <RFC3339 time>"
+const onceSpecPrefix = "@at "
+
+// specParser parses 6-field (with seconds) cron expressions and descriptors
+var specParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// lastWeekday matches the "5L" (last Friday of the month) day-of-week form
+var lastWeekday = regexp.MustCompile(`^([0-6])L$`)
+
+// OnceSpec returns the schedule string for a job that runs once at t
+func OnceSpec(t time.Time) string {
+	return onceSpecPrefix + t.Format(time.RFC3339)
+}
+
+// isOnceSpec reports whether a schedule runs only once
+func isOnceSpec(spec string) bool {
+	return strings.HasPrefix(spec, onceSpecPrefix)
+}
+
+// parseSpec parses a job schedule. Besides standard cron syntax it accepts
+// "L" in the day-of-month field (last day of the month), "<weekday>L" in the
+// day-of-week field (last such weekday of the month) and "@at <RFC3339>"
+// for one-shot jobs.
+func parseSpec(spec string) (cron.Schedule, error) {
+	if isOnceSpec(spec) {
+		at, err := time.Parse(time.RFC3339, strings.TrimPrefix(spec, onceSpecPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid one-shot time: %w", err)
+		}
+		return onceSchedule{at: at}, nil
+	}
+
+	spec = normalizeCron(spec)
+	fields := strings.Fields(spec)
+	if len(fields) != 6 {
+		return specParser.Parse(spec)
+	}
+
+	var filter func(time.Time) bool
+	if fields[3] == "L" {
+		fields[3] = "*"
+		filter = func(t time.Time) bool { return t.AddDate(0, 0, 1).Day() == 1 }
+	} else if m := lastWeekday.FindStringSubmatch(fields[5]); m != nil {
+		fields[5] = m[1]
+		filter = func(t time.Time) bool { return t.AddDate(0, 0, 7).Month() != t.Month() }
+	}
+
+	base, err := specParser.Parse(strings.Join(fields, " "))
+	if err != nil || filter == nil {
+		return base, err
+	}
+	return filteredSchedule{base: base, keep: filter}, nil
+}
+
+// onceSchedule fires a single time
+type onceSchedule struct {
+	at time.Time
+}
+
+// Next returns the fire time if it is still ahead, or the zero time (never)
+func (o onceSchedule) Next(t time.Time) time.Time {
+	if o.at.After(t) {
+		return o.at
+	}
+	return time.Time{}
+}
+
+// filteredSchedule fires on the times of base accepted by keep
+type filteredSchedule struct {
+	base cron.Schedule
+	keep func(time.Time) bool
+}
+
+// Next returns the next base fire time accepted by the filter
+func (f filteredSchedule) Next(t time.Time) time.Time {
+	// A weekday fires at most ~5 times a month, so this covers years
+	for i := 0; i < 1000; i++ {
+		t = f.base.Next(t)
+		if t.IsZero() || f.keep(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// NextRuns returns up to n upcoming fire times of a job schedule after from
+func NextRuns(spec string, from time.Time, n int) ([]time.Time, error) {
+	sched, err := parseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	var runs []time.Time
+	for t := from; len(runs) < n; {
+		t = sched.Next(t)
+		if t.IsZero() {
+			break
+		}
+		runs = append(runs, t)
+	}
+	return runs, nil
+}
@@ -37,19 +37,34 @@ type Scheduler struct {
 	promptExecutor PromptExecutor
 	chatNotifier   ChatNotifier
 	jobs           map[string]*Job
+	sinks          map[string]Sink
+	allowedPaths   []string // default file limits, see SetFileLimits
+	noFileTargets  bool
+	webhookHosts   []string // see SetWebhookHosts
+	states         map[string]*jobState
+	slots          chan struct{} // global concurrency limit (nil = unlimited)
+	runHooks       []func(job *Job, run Run)
 	mu             sync.RWMutex
+	now            func() time.Time
 }
 
 // NewScheduler creates a new scheduler
 func NewScheduler(store *Store, toolExecutor ToolExecutor, promptExecutor PromptExecutor, chatNotifier ChatNotifier) *Scheduler {
-	return &Scheduler{
+	s := &Scheduler{
 		cron:           cron.New(cron.WithSeconds()), // Support second-level precision
 		store:          store,
 		toolExecutor:   toolExecutor,
 		promptExecutor: promptExecutor,
 		chatNotifier:   chatNotifier,
 		jobs:           make(map[string]*Job),
+		sinks:          make(map[string]Sink),
+		states:         make(map[string]*jobState),
+		now:            time.Now,
 	}
+	s.sinks[TargetPlatform] = SinkFunc(s.platformSink)
+	s.sinks[TargetWebhook] = SinkFunc(s.webhookSink)
+	s.sinks[TargetFile] = SinkFunc(fileSink)
+	return s
 }
 
 // normalizeCron prepends "0 " to standard 5-field cron expressions
@@ -79,6 +94,11 @@ func (s *Scheduler) Start() error {
 		}
 	}
 
+	// Deliver notifications deferred by quiet hours once the window ends
+	if _, err := s.cron.AddFunc("@every 1m", s.flushDeferred); err != nil {
+		log.Printf("[CRON] Failed to schedule deferred delivery: %v", err)
+	}
+
 	// Start the cron scheduler
 	s.cron.Start()
 	log.Printf("[CRON] Scheduler started with %d jobs (%d enabled)", len(s.jobs), s.countEnabled())
@@ -135,23 +155,51 @@ func (s *Scheduler) AddJobWithPrompt(name, schedule, prompt, platform, channelID
 	})
 }
 
+// CreateJob adds a fully specified job (including delivery rules).
+// Exactly one of Tool, Message, or Prompt should be set.
+func (s *Scheduler) CreateJob(job *Job) (*Job, error) {
+	return s.addJob(job)
+}
+
 // addJob validates and schedules a job
 func (s *Scheduler) addJob(job *Job) (*Job, error) {
 	// Normalize 5-field cron to 6-field (our cron instance uses WithSeconds)
 	job.Schedule = normalizeCron(job.Schedule)
 
+	// Chained jobs may omit the schedule and run only after their upstream job
+	if job.Schedule == "" && job.After == "" {
+		return nil, fmt.Errorf("either a schedule or an upstream job (after) is required")
+	}
+
 	// Validate cron expression using the 6-field (with seconds) parser
-	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	if _, err := parser.Parse(job.Schedule); err != nil {
-		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	if job.Schedule != "" {
+		if _, err := parseSpec(job.Schedule); err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %w", err)
+		}
+	}
+
+	if err := ValidateDelivery(job); err != nil {
+		return nil, err
+	}
+	if err := ValidateExecution(job); err != nil {
+		return nil, err
+	}
+	for _, t := range job.Targets {
+		if err := s.checkTarget(job, t); err != nil {
+			return nil, err
+		}
 	}
 
 	job.ID = uuid.New().String()
 	job.Enabled = true
 	job.CreatedAt = time.Now()
 
-	// Add to jobs map
+	// Add to jobs map, rejecting unknown upstream jobs and dependency cycles
 	s.mu.Lock()
+	if err := validateAfter(s.jobs, job.ID, job.After, job.AfterOn); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
 	s.jobs[job.ID] = job
 	s.mu.Unlock()
 
@@ -164,7 +212,7 @@ func (s *Scheduler) addJob(job *Job) (*Job, error) {
 	}
 
 	// Save to database
-	if err := s.store.SaveJob(job); err != nil {
+	if err := s.saveJob(job); err != nil {
 		log.Printf("[CRON] Failed to save job: %v", err)
 	}
 
@@ -182,6 +230,15 @@ func (s *Scheduler) RemoveJob(id string) error {
 		return fmt.Errorf("job not found: %s", id)
 	}
 
+	// Refuse to orphan chained jobs, which would otherwise never run again
+	if deps := dependentsOf(s.jobs, id); len(deps) > 0 {
+		names := make([]string, len(deps))
+		for i, dep := range deps {
+			names[i] = fmt.Sprintf("%s (%s)", dep.ID, dep.Name)
+		}
+		return fmt.Errorf("job %s has dependent jobs, delete them first: %s", id, strings.Join(names, ", "))
+	}
+
 	// Remove from cron scheduler if it has an entry
 	if job.EntryID != 0 {
 		s.cron.Remove(job.EntryID)
@@ -222,7 +279,7 @@ func (s *Scheduler) PauseJob(id string) error {
 	job.Enabled = false
 
 	// Save to database
-	if err := s.store.SaveJob(job); err != nil {
+	if err := s.saveJobLocked(job); err != nil {
 		log.Printf("[CRON] Failed to save job: %v", err)
 	}
 
@@ -257,7 +314,7 @@ func (s *Scheduler) ResumeJob(id string) error {
 	}
 
 	// Save to database
-	if err := s.store.SaveJob(job); err != nil {
+	if err := s.saveJobLocked(job); err != nil {
 		log.Printf("[CRON] Failed to save job: %v", err)
 	}
 
@@ -278,36 +335,69 @@ func (s *Scheduler) ListJobs() []*Job {
 	return jobs
 }
 
-// scheduleJob schedules a job in the cron scheduler
+// scheduleJob schedules a job in the cron scheduler. Jobs without a schedule
+// only run when their upstream job finishes.
 func (s *Scheduler) scheduleJob(job *Job) error {
-	entryID, err := s.cron.AddFunc(job.Schedule, func() {
-		s.executeJob(job)
-	})
+	if job.Schedule == "" {
+		return nil
+	}
+	sched, err := parseSpec(job.Schedule)
 	if err != nil {
 		return err
 	}
 
-	job.EntryID = entryID
+	job.EntryID = s.cron.Schedule(sched, cron.FuncJob(func() {
+		s.executeJob(job)
+	}))
 	return nil
 }
 
-// executeJob executes a job
+// executeJob executes a job on its schedule and then runs any jobs chained after it
 func (s *Scheduler) executeJob(job *Job) {
+	s.runChain(job, nil)
+	if isOnceSpec(job.Schedule) {
+		s.finishOnce(job)
+	}
+}
+
+// finishOnce disables a one-shot job after it has run
+func (s *Scheduler) finishOnce(job *Job) {
+	s.mu.Lock()
+	if job.EntryID != 0 {
+		s.cron.Remove(job.EntryID)
+		job.EntryID = 0
+	}
+	job.Enabled = false
+	s.mu.Unlock()
+
+	if err := s.saveJob(job); err != nil {
+		log.Printf("[CRON] Failed to save job: %v", err)
+	}
+	log.Printf("[CRON] One-shot job finished: %s (%s)", job.ID, job.Name)
+}
+
+// runJob executes a job once and returns its output. upstream is set when the
+// run was triggered by another job and fills the job's template variables.
+func (s *Scheduler) runJob(job *Job, upstream *upstreamResult) (string, error) {
 	now := time.Now()
+	s.refreshTargets(job)
 
 	// Message-based job: send message directly to user
 	if job.Message != "" {
 		log.Printf("[CRON] Sending message for job: %s (%s)", job.ID, job.Name)
+		message := renderTemplate(job.Message, upstream)
 
 		s.mu.Lock()
 		job.LastRun = &now
 		s.mu.Unlock()
 
-		if s.chatNotifier != nil && job.Platform != "" && job.ChannelID != "" {
-			if err := s.chatNotifier.NotifyChatUser(job.Platform, job.ChannelID, job.UserID, job.Message); err != nil {
+		var runErr error
+		if len(targetsFor(job)) > 0 {
+			if err := s.deliverResult(context.Background(), job, message); err != nil {
 				s.mu.Lock()
 				job.LastError = err.Error()
 				s.mu.Unlock()
+				runErr = err
 				log.Printf("[CRON] Job failed to send message: %s (%s) - error: %v", job.ID, job.Name, err)
 			} else {
 				s.mu.Lock()
@@ -316,16 +406,16 @@ func (s *Scheduler) executeJob(job *Job) {
 				log.Printf("[CRON] Job message sent: %s (%s)", job.ID, job.Name)
 			}
 		} else {
-			log.Printf("[CRON] Job %s has no chat target, logging message: %s", job.ID, job.Message)
+			log.Printf("[CRON] Job %s has no chat target, logging message: %s", job.ID, message)
 			if s.chatNotifier != nil {
-				s.chatNotifier.NotifyChat(fmt.Sprintf("[%s] %s", job.Name, job.Message))
+				s.chatNotifier.NotifyChat(fmt.Sprintf("[%s] %s", job.Name, message))
 			}
 		}
 
-		if err := s.store.SaveJob(job); err != nil {
+		if err := s.saveJob(job); err != nil {
 			log.Printf("[CRON] Failed to save job: %v", err)
 		}
-		return
+		return message, runErr
 	}
 
 	// Prompt-based job: run full AI conversation
@@ -341,50 +431,56 @@ func (s *Scheduler) executeJob(job *Job) {
 			job.LastError = "prompt executor not available"
 			s.mu.Unlock()
 			log.Printf("[CRON] Job failed: %s (%s) - prompt executor not available", job.ID, job.Name)
-			if err := s.store.SaveJob(job); err != nil {
+			if err := s.saveJob(job); err != nil {
 				log.Printf("[CRON] Failed to save job: %v", err)
 			}
-			return
+			return "", fmt.Errorf("prompt executor not available")
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), job.timeout())
 		defer cancel()
 
-		result, err := s.promptExecutor.ExecutePrompt(ctx, job.Platform, job.ChannelID, job.UserID, job.Prompt)
+		result, err := s.promptExecutor.ExecutePrompt(ctx, job.Platform, job.ChannelID, job.UserID, renderTemplate(job.Prompt, upstream))
 		if err != nil {
 			s.mu.Lock()
 			job.LastError = err.Error()
 			s.mu.Unlock()
 			log.Printf("[CRON] Job prompt failed: %s (%s) - error: %v", job.ID, job.Name, err)
 
-			if s.chatNotifier != nil && job.Platform != "" && job.ChannelID != "" {
-				s.chatNotifier.NotifyChatUser(job.Platform, job.ChannelID, job.UserID,
-					fmt.Sprintf("⚠️ Scheduled AI task '%s' failed: %v", job.Name, err))
-			}
+			s.notifyFailure(job, fmt.Sprintf("⚠️ Scheduled AI task '%s' failed: %v", job.Name, err))
 		} else {
 			s.mu.Lock()
 			job.LastError = ""
 			s.mu.Unlock()
 			log.Printf("[CRON] Job prompt completed: %s (%s)", job.ID, job.Name)
 
-			if s.chatNotifier != nil && job.Platform != "" && job.ChannelID != "" {
-				s.chatNotifier.NotifyChatUser(job.Platform, job.ChannelID, job.UserID, result)
+			if err := s.deliverResult(ctx, job, result); err != nil {
+				log.Printf("[CRON] Job %s (%s): failed to deliver result: %v", job.ID, job.Name, err)
 			}
 		}
 
-		if err := s.store.SaveJob(job); err != nil {
+		if err := s.saveJob(job); err != nil {
 			log.Printf("[CRON] Failed to save job: %v", err)
 		}
-		return
+		return result, err
 	}
 
 	// Tool-based job: execute MCP tool
 	log.Printf("[CRON] Executing job: %s (%s) - tool: %s", job.ID, job.Name, job.Tool)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), job.timeout())
 	defer cancel()
 
-	result, err := s.toolExecutor.ExecuteTool(ctx, job.Tool, job.Arguments)
+	var (
+		result any
+		err    error
+	)
+	if s.toolExecutor == nil {
+		err = fmt.Errorf("tool executor not available")
+	} else {
+		result, err = s.toolExecutor.ExecuteTool(ctx, job.Tool, renderArguments(job.Arguments, upstream))
+	}
+	output := resultText(result)
 
 	// Update job status
 	s.mu.Lock()
@@ -395,30 +491,60 @@ func (s *Scheduler) executeJob(job *Job) {
 
 		log.Printf("[CRON] Job failed: %s (%s) - error: %v", job.ID, job.Name, err)
 
-		if s.chatNotifier != nil {
-			errMsg := fmt.Sprintf("⚠️ Scheduled job '%s' failed: %v", job.Name, err)
-			if job.Platform != "" && job.ChannelID != "" {
-				s.chatNotifier.NotifyChatUser(job.Platform, job.ChannelID, job.UserID, errMsg)
-			} else {
-				s.chatNotifier.NotifyChat(errMsg)
-			}
+		errMsg := fmt.Sprintf("⚠️ Scheduled job '%s' failed: %v", job.Name, err)
+		if len(targetsFor(job)) > 0 {
+			s.notifyFailure(job, errMsg)
+		} else if s.chatNotifier != nil {
+			s.chatNotifier.NotifyChat(errMsg)
 		}
 	} else {
 		job.LastError = ""
 		s.mu.Unlock()
 
-		resultStr := ""
-		if result != nil {
-			if resultJSON, err := json.Marshal(result); err == nil {
-				resultStr = fmt.Sprintf(" - result: %s", string(resultJSON))
+		log.Printf("[CRON] Job completed: %s (%s) - result: %s", job.ID, job.Name, output)
+
+		// Tool jobs only report successful results when a delivery rule or explicit targets are set
+		if job.Notify != "" || len(job.Targets) > 0 {
+			if err := s.deliverResult(ctx, job, output); err != nil {
+				log.Printf("[CRON] Job %s (%s): failed to deliver result: %v", job.ID, job.Name, err)
 			}
 		}
-		log.Printf("[CRON] Job completed: %s (%s)%s", job.ID, job.Name, resultStr)
 	}
 
-	if err := s.store.SaveJob(job); err != nil {
+	if err := s.saveJob(job); err != nil {
 		log.Printf("[CRON] Failed to save job: %v", err)
 	}
+	return output, err
+}
+
+// deliverResult records a successful run's output and notifies the user
+// when the job's notify policy allows it.
+func (s *Scheduler) deliverResult(ctx context.Context, job *Job, output string) error {
+	s.mu.Lock()
+	previousHash, previousOutput := job.LastHash, job.LastOutput
+	job.LastHash = hashOutput(output)
+	job.LastOutput = output
+	s.mu.Unlock()
+
+	if !s.shouldNotify(ctx, job, previousHash, previousOutput, output) {
+		log.Printf("[CRON] Job %s (%s): notification suppressed by notify=%s", job.ID, job.Name, job.Notify)
+		return nil
+	}
+	return s.notify(ctx, job, output)
+}
+
+// resultText converts a tool result into text for logging and delivery
+func resultText(result any) string {
+	switch v := result.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	if resultJSON, err := json.Marshal(result); err == nil {
+		return string(resultJSON)
+	}
+	return fmt.Sprintf("%v", result)
 }
 
 // countEnabled returns the number of enabled jobs
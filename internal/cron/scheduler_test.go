@@ -1,6 +1,9 @@
 package cron
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNormalizeCron(t *testing.T) {
 	tests := []struct {
@@ -18,3 +21,23 @@ func TestNormalizeCron(t *testing.T) {
 		}
 	}
 }
+
+func TestExecuteJob_OneShotDisables(t *testing.T) {
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(t, notifier)
+	job, err := s.CreateJob(&Job{
+		Name: "reminder", Schedule: OnceSpec(time.Now().Add(time.Hour)),
+		Message: "stand up", Platform: "slack", ChannelID: "C1",
+	})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	s.executeJob(job)
+	if len(notifier.sent) != 1 {
+		t.Errorf("expected message delivered, got %v", notifier.sent)
+	}
+	if job.Enabled || job.EntryID != 0 {
+		t.Errorf("expected one-shot job disabled after running, enabled=%v entry=%v", job.Enabled, job.EntryID)
+	}
+}
@@ -51,7 +51,7 @@ func NewStore(path string) (*Store, error) {
 	return s, nil
 }
 
-// init creates the jobs table if it doesn't exist
+// init creates the jobs and runs tables if they don't exist
 func (s *Store) init() error {
 	_, err := s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS jobs (
@@ -71,7 +71,67 @@ func (s *Store) init() error {
 			last_error TEXT
 		)
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := s.initRuns(); err != nil {
+		return err
+	}
+	return s.migrateColumns()
+}
+
+// addedColumns lists columns introduced after the initial schema.
+// They are added to existing databases on startup.
+var addedColumns = []struct{ name, decl string }{
+	{"notify", "TEXT"},
+	{"notify_match", "TEXT"},
+	{"notify_condition", "TEXT"},
+	{"quiet_hours", "TEXT"},
+	{"last_hash", "TEXT"},
+	{"last_output", "TEXT"},
+	{"deferred", "TEXT"},
+	{"targets", "TEXT"},
+	{"after_job", "TEXT"},
+	{"after_on", "TEXT"},
+	{"overlap", "TEXT"},
+	{"timeout", "TEXT"},
+	{"allowed_paths", "TEXT"},
+	{"no_file_targets", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrateColumns adds any missing columns from addedColumns to the jobs table
+func (s *Store) migrateColumns() error {
+	rows, err := s.db.Query("PRAGMA table_info(jobs)")
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+
+	for _, col := range addedColumns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE jobs ADD COLUMN %s %s", col.name, col.decl)); err != nil {
+			return fmt.Errorf("failed to add column %s: %w", col.name, err)
+		}
+	}
+	return nil
 }
 
 // migrateFromJSON imports jobs from the legacy crons.json if it exists
@@ -121,7 +181,9 @@ func (s *Store) Load() ([]*Job, error) {
 
 	rows, err := s.db.Query(`
 		SELECT id, name, schedule, tool, arguments, message, prompt,
-		       platform, channel_id, user_id, enabled, created_at, last_run, last_error
+		       platform, channel_id, user_id, enabled, created_at, last_run, last_error,
+		       notify, notify_match, notify_condition, quiet_hours, last_hash, last_output, deferred,
+		       targets, after_job, after_on, overlap, timeout, allowed_paths, no_file_targets
 		FROM jobs
 	`)
 	if err != nil {
@@ -147,6 +209,28 @@ func (s *Store) Load() ([]*Job, error) {
 	return jobs, nil
 }
 
+// LoadJob reads a single job by ID, returning nil if it doesn't exist
+func (s *Store) LoadJob(id string) (*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	row := s.db.QueryRow(`
+		SELECT id, name, schedule, tool, arguments, message, prompt,
+		       platform, channel_id, user_id, enabled, created_at, last_run, last_error,
+		       notify, notify_match, notify_condition, quiet_hours, last_hash, last_output, deferred,
+		       targets, after_job, after_on, overlap, timeout, allowed_paths, no_file_targets
+		FROM jobs WHERE id = ?
+	`, id)
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	return job, nil
+}
+
 // SaveJob upserts a single job into the database
 func (s *Store) SaveJob(job *Job) error {
 	s.mu.Lock()
@@ -173,31 +257,106 @@ func (s *Store) SaveJob(job *Job) error {
 		enabled = 1
 	}
 
+	var deferred *string
+	if len(job.Deferred) > 0 {
+		data, err := json.Marshal(job.Deferred)
+		if err != nil {
+			return fmt.Errorf("failed to marshal deferred notifications: %w", err)
+		}
+		d := string(data)
+		deferred = &d
+	}
+
+	targets, err := marshalTargets(job.Targets)
+	if err != nil {
+		return err
+	}
+
+	var allowedPaths *string
+	if len(job.AllowedPaths) > 0 {
+		data, err := json.Marshal(job.AllowedPaths)
+		if err != nil {
+			return fmt.Errorf("failed to marshal allowed paths: %w", err)
+		}
+		p := string(data)
+		allowedPaths = &p
+	}
+
+	noFileTargets := 0
+	if job.NoFileTargets {
+		noFileTargets = 1
+	}
+
 	_, err = s.db.Exec(`
 		INSERT INTO jobs (id, name, schedule, tool, arguments, message, prompt,
-		                  platform, channel_id, user_id, enabled, created_at, last_run, last_error)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                  platform, channel_id, user_id, enabled, created_at, last_run, last_error,
+		                  notify, notify_match, notify_condition, quiet_hours, last_hash, last_output, deferred,
+		                  targets, after_job, after_on, overlap, timeout, allowed_paths, no_file_targets)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name=excluded.name, schedule=excluded.schedule, tool=excluded.tool,
 			arguments=excluded.arguments, message=excluded.message, prompt=excluded.prompt,
 			platform=excluded.platform, channel_id=excluded.channel_id, user_id=excluded.user_id,
 			enabled=excluded.enabled, created_at=excluded.created_at,
-			last_run=excluded.last_run, last_error=excluded.last_error
+			last_run=excluded.last_run, last_error=excluded.last_error,
+			notify=excluded.notify, notify_match=excluded.notify_match,
+			notify_condition=excluded.notify_condition, quiet_hours=excluded.quiet_hours,
+			last_hash=excluded.last_hash, last_output=excluded.last_output, deferred=excluded.deferred,
+			targets=excluded.targets, after_job=excluded.after_job, after_on=excluded.after_on,
+			overlap=excluded.overlap, timeout=excluded.timeout,
+			allowed_paths=excluded.allowed_paths, no_file_targets=excluded.no_file_targets
 	`,
 		job.ID, job.Name, job.Schedule, job.Tool, string(argsJSON), job.Message, job.Prompt,
 		job.Platform, job.ChannelID, job.UserID, enabled, job.CreatedAt.Format(time.RFC3339),
 		lastRun, lastError,
+		job.Notify, job.NotifyMatch, job.NotifyCondition, job.QuietHours, job.LastHash, job.LastOutput, deferred,
+		targets, job.After, job.AfterOn, job.Overlap, job.Timeout, allowedPaths, noFileTargets,
 	)
 	return err
 }
 
+// SetTargets replaces only the targets of a job, leaving its run state to
+// whoever else is updating the row (e.g. a running scheduler)
+func (s *Store) SetTargets(id string, targets []Target) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, err := marshalTargets(targets)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec("UPDATE jobs SET targets = ? WHERE id = ?", value, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("job not found: %s", id)
+	}
+	return nil
+}
+
+// marshalTargets encodes targets for the targets column; none is NULL
+func marshalTargets(targets []Target) (*string, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal targets: %w", err)
+	}
+	t := string(data)
+	return &t, nil
+}
+
 // DeleteJob removes a job from the database
 func (s *Store) DeleteJob(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	_, err := s.db.Exec("DELETE FROM jobs WHERE id = ?", id)
-	return err
+	if _, err := s.db.Exec("DELETE FROM jobs WHERE id = ?", id); err != nil {
+		return err
+	}
+	return s.deleteRuns(id)
 }
 
 // Save writes all jobs to the database (bulk upsert, used by Stop)
@@ -234,11 +393,28 @@ func scanJob(s scanner) (*Job, error) {
 		createdAt string
 		lastRun   sql.NullString
 		lastError sql.NullString
+
+		notify          sql.NullString
+		notifyMatch     sql.NullString
+		notifyCondition sql.NullString
+		quietHours      sql.NullString
+		lastHash        sql.NullString
+		lastOutput      sql.NullString
+		deferred        sql.NullString
+		targets         sql.NullString
+		afterJob        sql.NullString
+		afterOn         sql.NullString
+		overlap         sql.NullString
+		timeout         sql.NullString
+		allowedPaths    sql.NullString
+		noFileTargets   int
 	)
 
 	err := s.Scan(
 		&job.ID, &job.Name, &job.Schedule, &tool, &argsJSON, &message, &prompt,
 		&platform, &channelID, &userID, &enabled, &createdAt, &lastRun, &lastError,
+		&notify, &notifyMatch, &notifyCondition, &quietHours, &lastHash, &lastOutput, &deferred,
+		&targets, &afterJob, &afterOn, &overlap, &timeout, &allowedPaths, &noFileTargets,
 	)
 	if err != nil {
 		return nil, err
@@ -252,6 +428,17 @@ func scanJob(s scanner) (*Job, error) {
 	job.UserID = userID.String
 	job.Enabled = enabled != 0
 	job.LastError = lastError.String
+	job.Notify = notify.String
+	job.NotifyMatch = notifyMatch.String
+	job.NotifyCondition = notifyCondition.String
+	job.QuietHours = quietHours.String
+	job.LastHash = lastHash.String
+	job.LastOutput = lastOutput.String
+	job.After = afterJob.String
+	job.AfterOn = afterOn.String
+	job.Overlap = overlap.String
+	job.Timeout = timeout.String
+	job.NoFileTargets = noFileTargets != 0
 
 	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
 		job.CreatedAt = t
@@ -268,5 +455,23 @@ func scanJob(s scanner) (*Job, error) {
 		}
 	}
 
+	if deferred.Valid && deferred.String != "" {
+		if err := json.Unmarshal([]byte(deferred.String), &job.Deferred); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal deferred notifications: %w", err)
+		}
+	}
+
+	if targets.Valid && targets.String != "" {
+		if err := json.Unmarshal([]byte(targets.String), &job.Targets); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal targets: %w", err)
+		}
+	}
+
+	if allowedPaths.Valid && allowedPaths.String != "" {
+		if err := json.Unmarshal([]byte(allowedPaths.String), &job.AllowedPaths); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal allowed paths: %w", err)
+		}
+	}
+
 	return &job, nil
 }
@@ -68,3 +68,82 @@ func TestStore_Delete(t *testing.T) {
 		t.Errorf("expected 0 jobs after delete, got %d", len(jobs))
 	}
 }
+
+func TestStore_DeliveryFields(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer store.Close()
+
+	job := &Job{
+		ID:              "notify-1",
+		Name:            "price watch",
+		Schedule:        "0 0 * * * *",
+		Prompt:          "check price",
+		Enabled:         true,
+		CreatedAt:       time.Now(),
+		Notify:          NotifyOnMatch,
+		NotifyMatch:     `\d+`,
+		NotifyCondition: "price dropped",
+		QuietHours:      "22:00-08:00",
+		LastHash:        "abc",
+		LastOutput:      "price: 100",
+		Deferred:        []string{"first", "second"},
+		Targets:         []Target{{Type: TargetWebhook, URL: "https://example.com/hook"}},
+		AllowedPaths:    []string{"/srv/reports"},
+		NoFileTargets:   true,
+	}
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+
+	jobs, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	got := jobs[0]
+	if got.Notify != job.Notify || got.NotifyMatch != job.NotifyMatch || got.NotifyCondition != job.NotifyCondition ||
+		got.QuietHours != job.QuietHours || got.LastHash != job.LastHash || got.LastOutput != job.LastOutput {
+		t.Errorf("delivery fields not round-tripped: %+v", got)
+	}
+	if len(got.Deferred) != 2 || got.Deferred[1] != "second" {
+		t.Errorf("deferred not round-tripped: %v", got.Deferred)
+	}
+	if len(got.Targets) != 1 || got.Targets[0] != job.Targets[0] {
+		t.Errorf("targets not round-tripped: %v", got.Targets)
+	}
+	if len(got.AllowedPaths) != 1 || got.AllowedPaths[0] != "/srv/reports" || !got.NoFileTargets {
+		t.Errorf("file limits not round-tripped: %v, %v", got.AllowedPaths, got.NoFileTargets)
+	}
+
+	single, err := store.LoadJob("notify-1")
+	if err != nil || single == nil || single.Name != job.Name {
+		t.Errorf("LoadJob = %+v, %v", single, err)
+	}
+	if missing, err := store.LoadJob("nope"); err != nil || missing != nil {
+		t.Errorf("LoadJob(missing) = %+v, %v", missing, err)
+	}
+
+	// SetTargets leaves the rest of the row alone
+	if err := store.SetTargets("notify-1", []Target{{Type: TargetFile, Path: "/tmp/out.log"}}); err != nil {
+		t.Fatalf("SetTargets: %v", err)
+	}
+	single, _ = store.LoadJob("notify-1")
+	if len(single.Targets) != 1 || single.Targets[0].Path != "/tmp/out.log" || single.LastHash != "abc" || len(single.Deferred) != 2 {
+		t.Errorf("after SetTargets = %+v", single)
+	}
+	if err := store.SetTargets("notify-1", nil); err != nil {
+		t.Fatalf("SetTargets(nil): %v", err)
+	}
+	if single, _ = store.LoadJob("notify-1"); single.Targets != nil {
+		t.Errorf("targets not cleared: %v", single.Targets)
+	}
+	if err := store.SetTargets("nope", nil); err == nil {
+		t.Error("SetTargets on a missing job should fail")
+	}
+}
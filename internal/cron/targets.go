@@ -0,0 +1,423 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pltanton/lingti-bot/internal/security"
+)
+
+// Target types for Job.Targets
+const (
+	TargetPlatform = "platform"
+	TargetWebhook  = "webhook"
+	TargetFile     = "file"
+)
+
+// Target is a destination that receives a job's results
+type Target struct {
+	Type      string `json:"type"`                 // "platform", "webhook", or "file"
+	Platform  string `json:"platform,omitempty"`   // Router platform (empty = the job's own chat)
+	ChannelID string `json:"channel_id,omitempty"` // Channel to send to (type=platform)
+	UserID    string `json:"user_id,omitempty"`    // Optional user to mention (type=platform)
+	URL       string `json:"url,omitempty"`        // Endpoint to POST to (type=webhook)
+	Path      string `json:"path,omitempty"`       // File to append to (type=file)
+}
+
+// Sink delivers job output to one type of target
+type Sink interface {
+	Deliver(ctx context.Context, job *Job, target Target, message string) error
+}
+
+// SinkFunc adapts a function to the Sink interface
+type SinkFunc func(ctx context.Context, job *Job, target Target, message string) error
+
+// Deliver calls f
+func (f SinkFunc) Deliver(ctx context.Context, job *Job, target Target, message string) error {
+	return f(ctx, job, target, message)
+}
+
+// ParseTarget parses a target spec:
+//
+//	platform                          the chat the job was created from
+//	platform:<name>:<channelID>[:<userID>]
+//	webhook:<url>
+//	file:<path>
+func ParseTarget(spec string) (Target, error) {
+	spec = strings.TrimSpace(spec)
+	kind, rest, _ := strings.Cut(spec, ":")
+	switch kind {
+	case TargetPlatform:
+		if rest == "" {
+			return Target{Type: TargetPlatform}, nil
+		}
+		parts := strings.SplitN(rest, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return Target{}, fmt.Errorf("invalid platform target %q (expected platform:<name>:<channelID>[:<userID>])", spec)
+		}
+		t := Target{Type: TargetPlatform, Platform: parts[0], ChannelID: parts[1]}
+		if len(parts) == 3 {
+			t.UserID = parts[2]
+		}
+		return t, nil
+	case TargetWebhook:
+		if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") {
+			return Target{}, fmt.Errorf("invalid webhook target %q (expected webhook:<http(s) url>)", spec)
+		}
+		return Target{Type: TargetWebhook, URL: rest}, nil
+	case TargetFile:
+		if rest == "" {
+			return Target{}, fmt.Errorf("invalid file target %q (expected file:<path>)", spec)
+		}
+		return Target{Type: TargetFile, Path: rest}, nil
+	}
+	return Target{}, fmt.Errorf("unknown target %q (use platform[:<name>:<channelID>], webhook:<url>, or file:<path>)", spec)
+}
+
+// ParseTargets parses a list of target specs
+func ParseTargets(specs []string) ([]Target, error) {
+	targets := make([]Target, 0, len(specs))
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		t, err := ParseTarget(spec)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// String returns the spec form of the target accepted by ParseTarget
+func (t Target) String() string {
+	switch t.Type {
+	case TargetPlatform:
+		if t.Platform == "" {
+			return TargetPlatform
+		}
+		s := fmt.Sprintf("platform:%s:%s", t.Platform, t.ChannelID)
+		if t.UserID != "" {
+			s += ":" + t.UserID
+		}
+		return s
+	case TargetWebhook:
+		return "webhook:" + t.URL
+	case TargetFile:
+		return "file:" + t.Path
+	}
+	return t.Type
+}
+
+// RegisterSink installs the sink used for targets of the given type,
+// replacing any existing one.
+func (s *Scheduler) RegisterSink(targetType string, sink Sink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sinks[targetType] = sink
+}
+
+// SetFileLimits sets the file limits of jobs that don't carry their own:
+// file targets must be under allowedPaths (when set), and are refused when
+// disabled is true
+func (s *Scheduler) SetFileLimits(allowedPaths []string, disabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowedPaths = append([]string(nil), allowedPaths...)
+	s.noFileTargets = disabled
+}
+
+// SetWebhookHosts limits webhook targets to the given hosts ("example.com"
+// or "*.example.com"), which may be private addresses. Without hosts,
+// webhooks may only reach public addresses.
+func (s *Scheduler) SetWebhookHosts(hosts []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhookHosts = append([]string(nil), hosts...)
+}
+
+// SetTargets replaces a job's delivery targets
+func (s *Scheduler) SetTargets(id string, targets []Target) error {
+	s.mu.Lock()
+	job, exists := s.jobs[id]
+	if !exists {
+		s.mu.Unlock()
+		return fmt.Errorf("job not found: %s", id)
+	}
+	for _, t := range targets {
+		if err := s.checkTargetLocked(job, t); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+	job.Targets = append([]Target(nil), targets...)
+	// The new targets replace any stored ones, so skip saveJob's reload
+	err := s.store.SaveJob(job)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// GetJob returns a copy of a job by ID
+func (s *Scheduler) GetJob(id string) (*Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, exists := s.jobs[id]
+	if !exists {
+		return nil, false
+	}
+	return job.Clone(), true
+}
+
+// targetsFor returns where a job's results go. Jobs without explicit targets
+// deliver to the chat they were created from. Platform targets without a
+// platform/channel inherit the job's own chat.
+func targetsFor(job *Job) []Target {
+	if len(job.Targets) == 0 {
+		if job.Platform != "" && job.ChannelID != "" {
+			return []Target{{Type: TargetPlatform, Platform: job.Platform, ChannelID: job.ChannelID, UserID: job.UserID}}
+		}
+		return nil
+	}
+	targets := make([]Target, 0, len(job.Targets))
+	for _, t := range job.Targets {
+		if t.Type == TargetPlatform && t.Platform == "" {
+			if job.Platform == "" || job.ChannelID == "" {
+				continue
+			}
+			t.Platform, t.ChannelID, t.UserID = job.Platform, job.ChannelID, job.UserID
+		}
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// deliverToTargets sends a message to every target of a job, continuing past
+// individual failures.
+func (s *Scheduler) deliverToTargets(ctx context.Context, job *Job, targets []Target, message string) error {
+	var errs []error
+	for _, t := range targets {
+		s.mu.RLock()
+		sink, ok := s.sinks[t.Type]
+		s.mu.RUnlock()
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: no sink registered", t))
+			continue
+		}
+		// Targets may have been edited since they were checked
+		if err := s.checkTarget(job, t); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t, err))
+			continue
+		}
+		if err := sink.Deliver(ctx, job, t, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// saveJob writes a job to the store. Its targets are reloaded first, so
+// edits made outside the running scheduler (e.g. via the CLI) aren't
+// overwritten with the scheduler's copy.
+func (s *Scheduler) saveJob(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveJobLocked(job)
+}
+
+// saveJobLocked is saveJob with s.mu held
+func (s *Scheduler) saveJobLocked(job *Job) error {
+	if stored, err := s.store.LoadJob(job.ID); err == nil && stored != nil {
+		job.Targets = stored.Targets
+	}
+	return s.store.SaveJob(job)
+}
+
+// refreshTargets reloads a job's targets from the store so that edits made
+// outside the running scheduler (e.g. via the CLI) take effect on the next run.
+func (s *Scheduler) refreshTargets(job *Job) {
+	stored, err := s.store.LoadJob(job.ID)
+	if err != nil || stored == nil {
+		return
+	}
+	s.mu.Lock()
+	job.Targets = stored.Targets
+	s.mu.Unlock()
+}
+
+// checkTarget reports whether a job may deliver to a target
+func (s *Scheduler) checkTarget(job *Job, t Target) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.checkTargetLocked(job, t)
+}
+
+// checkTargetLocked is checkTarget with s.mu held
+func (s *Scheduler) checkTargetLocked(job *Job, t Target) error {
+	switch t.Type {
+	case TargetFile:
+		allowed, disabled := job.AllowedPaths, job.NoFileTargets
+		if len(allowed) == 0 && !disabled {
+			allowed, disabled = s.allowedPaths, s.noFileTargets
+		}
+		if disabled {
+			return fmt.Errorf("file targets are disabled")
+		}
+		return security.NewPathChecker(allowed).CheckPath(t.Path)
+	case TargetWebhook:
+		u, err := url.Parse(t.URL)
+		if err != nil {
+			return fmt.Errorf("invalid webhook URL: %w", err)
+		}
+		if len(s.webhookHosts) > 0 && !matchHost(s.webhookHosts, u.Hostname()) {
+			return fmt.Errorf("webhook host %s is not in cron.webhook_hosts", u.Hostname())
+		}
+	}
+	return nil
+}
+
+// matchHost reports whether host is one of hosts, which may start with "*."
+func matchHost(hosts []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// publicIP reports whether ip is routable on the internet
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	// Carrier-grade NAT, 100.64.0.0/10
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
+}
+
+// Webhook clients, shared by all deliveries so their connections are reused.
+// publicWebhookClient only connects to public addresses, which is checked on
+// every connection so DNS can't point a host at the local network.
+var (
+	allowlistedWebhookClient = newWebhookClient(nil)
+	publicWebhookClient      = newWebhookClient(func(network, address string, _ syscall.RawConn) error {
+		h, _, _ := net.SplitHostPort(address)
+		if ip := net.ParseIP(h); ip == nil || !publicIP(ip) {
+			return fmt.Errorf("webhook address %s is not public; add the host to cron.webhook_hosts to allow it", h)
+		}
+		return nil
+	})
+)
+
+func newWebhookClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: control}
+	return &http.Client{Transport: &http.Transport{DialContext: dialer.DialContext, IdleConnTimeout: 90 * time.Second}}
+}
+
+// webhookClient returns the client for a webhook host: hosts that aren't
+// allowlisted may only resolve to public addresses
+func (s *Scheduler) webhookClient(host string) *http.Client {
+	s.mu.RLock()
+	allowlisted := matchHost(s.webhookHosts, host)
+	s.mu.RUnlock()
+	if allowlisted {
+		return allowlistedWebhookClient
+	}
+	return publicWebhookClient
+}
+
+// platformSink delivers through the scheduler's ChatNotifier
+func (s *Scheduler) platformSink(ctx context.Context, job *Job, target Target, message string) error {
+	if s.chatNotifier == nil {
+		return fmt.Errorf("chat notifier not available")
+	}
+	return s.chatNotifier.NotifyChatUser(target.Platform, target.ChannelID, target.UserID, message)
+}
+
+// webhookPayload is the JSON body POSTed to webhook targets
+type webhookPayload struct {
+	JobID     string    `json:"job_id"`
+	JobName   string    `json:"job_name"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// webhookSink POSTs the message as JSON to the target URL
+func (s *Scheduler) webhookSink(ctx context.Context, job *Job, target Target, message string) error {
+	body, err := json.Marshal(webhookPayload{
+		JobID:     job.ID,
+		JobName:   job.Name,
+		Text:      message,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.webhookClient(req.URL.Hostname()).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fileSink appends the message with a timestamp header to the target file
+func fileSink(ctx context.Context, job *Job, target Target, message string) error {
+	path := target.Path
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "[%s] %s\n%s\n\n", time.Now().Format("2006-01-02 15:04:05"), job.Name, message)
+	return err
+}
@@ -0,0 +1,202 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Target
+	}{
+		{"platform", Target{Type: TargetPlatform}},
+		{"platform:feishu:oc_123", Target{Type: TargetPlatform, Platform: "feishu", ChannelID: "oc_123"}},
+		{"platform:slack:C1:U9", Target{Type: TargetPlatform, Platform: "slack", ChannelID: "C1", UserID: "U9"}},
+		{"webhook:https://example.com/hook?a=b", Target{Type: TargetWebhook, URL: "https://example.com/hook?a=b"}},
+		{"file:~/reports/daily.log", Target{Type: TargetFile, Path: "~/reports/daily.log"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.spec)
+		if err != nil {
+			t.Errorf("ParseTarget(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTarget(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+		if got.String() != tt.spec {
+			t.Errorf("Target.String() = %q, want %q", got.String(), tt.spec)
+		}
+	}
+
+	for _, bad := range []string{"", "email:a@b.c", "platform:slack", "webhook:ftp://x", "file:"} {
+		if _, err := ParseTarget(bad); err == nil {
+			t.Errorf("ParseTarget(%q) expected error", bad)
+		}
+	}
+}
+
+func TestTargetsFor(t *testing.T) {
+	job := &Job{Platform: "slack", ChannelID: "C1", UserID: "U1"}
+	if got := targetsFor(job); len(got) != 1 || got[0].ChannelID != "C1" {
+		t.Errorf("expected origin chat as default target, got %+v", got)
+	}
+
+	job.Targets = []Target{{Type: TargetPlatform}, {Type: TargetFile, Path: "/tmp/x"}}
+	got := targetsFor(job)
+	if len(got) != 2 || got[0].Platform != "slack" || got[0].UserID != "U1" {
+		t.Errorf("expected bare platform target to inherit origin chat, got %+v", got)
+	}
+
+	if got := targetsFor(&Job{}); len(got) != 0 {
+		t.Errorf("expected no targets, got %+v", got)
+	}
+}
+
+func TestDeliverToMultipleTargets(t *testing.T) {
+	var received webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	notifier := &fakeNotifier{}
+	s := newTestScheduler(t, notifier)
+	s.SetWebhookHosts([]string{"127.0.0.1"})
+	path := filepath.Join(t.TempDir(), "out", "results.log")
+	job := &Job{
+		ID:        "j1",
+		Name:      "report",
+		Platform:  "slack",
+		ChannelID: "C1",
+		Targets: []Target{
+			{Type: TargetPlatform},
+			{Type: TargetWebhook, URL: srv.URL},
+			{Type: TargetFile, Path: path},
+		},
+	}
+
+	if err := s.notify(context.Background(), job, "all good"); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0] != "all good" {
+		t.Errorf("platform target not delivered: %v", notifier.sent)
+	}
+	if received.JobID != "j1" || received.Text != "all good" {
+		t.Errorf("webhook payload = %+v", received)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "all good") {
+		t.Errorf("file target not written: %q, %v", data, err)
+	}
+}
+
+func TestTargetLimits(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer srv.Close()
+
+	s := newTestScheduler(t, &fakeNotifier{})
+	allowed := t.TempDir()
+	s.SetFileLimits([]string{allowed}, false)
+	inside := Target{Type: TargetFile, Path: filepath.Join(allowed, "out.log")}
+	outside := Target{Type: TargetFile, Path: filepath.Join(t.TempDir(), "out.log")}
+	local := Target{Type: TargetWebhook, URL: srv.URL}
+
+	job := &Job{ID: "j1", Name: "report", Targets: []Target{inside}}
+	if err := s.checkTarget(job, inside); err != nil {
+		t.Errorf("allowed file refused: %v", err)
+	}
+	if err := s.checkTarget(job, outside); err == nil {
+		t.Error("file outside allowed_paths accepted")
+	}
+	if err := s.checkTarget(&Job{NoFileTargets: true}, inside); err == nil {
+		t.Error("file target accepted with file tools disabled")
+	}
+	// The job's own limits win over the scheduler's
+	if err := s.checkTarget(&Job{AllowedPaths: []string{filepath.Dir(outside.Path)}}, outside); err != nil {
+		t.Errorf("job limits ignored: %v", err)
+	}
+
+	// Loopback webhooks need an allowlist
+	if err := s.notify(context.Background(), &Job{ID: "j2", Targets: []Target{local}}, "hi"); err == nil || hits != 0 {
+		t.Errorf("loopback webhook delivered: hits=%d, err=%v", hits, err)
+	}
+	s.SetWebhookHosts([]string{"hooks.example.com"})
+	if err := s.checkTarget(job, local); err == nil {
+		t.Error("webhook host outside the allowlist accepted")
+	}
+	s.SetWebhookHosts([]string{"127.0.0.1"})
+	if err := s.notify(context.Background(), &Job{ID: "j3", Targets: []Target{local}}, "hi"); err != nil || hits != 1 {
+		t.Errorf("allowlisted webhook: hits=%d, err=%v", hits, err)
+	}
+
+	if _, err := s.CreateJob(&Job{Name: "bad", Schedule: "0 9 * * *", Message: "hi", Targets: []Target{outside}}); err == nil {
+		t.Error("job with a file target outside allowed_paths created")
+	}
+	created, err := s.CreateJob(&Job{Name: "ok", Schedule: "0 9 * * *", Message: "hi", Targets: []Target{inside}})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	if err := s.SetTargets(created.ID, []Target{outside}); err == nil {
+		t.Error("SetTargets accepted a file outside allowed_paths")
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	hosts := []string{"hooks.example.com", "*.corp.internal"}
+	for host, want := range map[string]bool{
+		"hooks.example.com": true,
+		"HOOKS.example.com": true,
+		"a.corp.internal":   true,
+		"corp.internal":     false,
+		"example.com":       false,
+		"evilcorp.internal": false,
+	} {
+		if got := matchHost(hosts, host); got != want {
+			t.Errorf("matchHost(%s) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestSaveKeepsStoredTargets(t *testing.T) {
+	s := newTestScheduler(t, &fakeNotifier{})
+	job, err := s.CreateJob(&Job{Name: "report", Schedule: "0 9 * * *", Message: "hi"})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	// Edit the targets in the store, as `lingti-bot cron targets` does
+	stored, err := s.store.LoadJob(job.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("LoadJob: %v", err)
+	}
+	webhook := Target{Type: TargetWebhook, URL: "https://example.com/hook"}
+	stored.Targets = []Target{webhook}
+	if err := s.store.SaveJob(stored); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+
+	if err := s.PauseJob(job.ID); err != nil {
+		t.Fatalf("PauseJob: %v", err)
+	}
+	if err := s.SetAfter(job.ID, "", ""); err != nil {
+		t.Fatalf("SetAfter: %v", err)
+	}
+	stored, _ = s.store.LoadJob(job.ID)
+	if len(stored.Targets) != 1 || stored.Targets[0] != webhook {
+		t.Errorf("stored targets overwritten: %v", stored.Targets)
+	}
+	if stored.Enabled {
+		t.Error("pause not saved")
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/pltanton/lingti-bot/internal/logger"
+	"github.com/pltanton/lingti-bot/internal/security"
 )
 
 // MessageType defines the type of gateway message
@@ -81,6 +82,9 @@ type Gateway struct {
 	broadcast   chan []byte
 	handler     MessageHandler
 	authTokens  []string // Optional allowed authentication tokens (any one is accepted)
+	sender      Sender   // Set by SetSender; backs POST /send
+	paths       *security.PathChecker
+	noFiles     bool
 	mu          sync.RWMutex
 	ctx         context.Context
 	cancel      context.CancelFunc
@@ -149,6 +153,7 @@ func (g *Gateway) Start(ctx context.Context) error {
 	mux.HandleFunc("/ws", g.handleWebSocket)
 	mux.HandleFunc("/health", g.handleHealth)
 	mux.HandleFunc("/status", g.handleStatus)
+	mux.HandleFunc("/send", g.handleSend)
 
 	server := &http.Server{
 		Addr:    g.addr,
@@ -247,13 +252,17 @@ func (g *Gateway) handleStatus(w http.ResponseWriter, r *http.Request) {
 	clientCount := len(g.clients)
 	g.mu.RUnlock()
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{
+	status := map[string]any{
 		"status":       "running",
 		"clients":      clientCount,
 		"addr":         g.addr,
 		"auth_enabled": len(g.authTokens) > 0,
-	})
+	}
+	if g.sender != nil && g.authorizeHTTP(r) {
+		status["platforms"] = g.sender.Platforms()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
 }
 
 // SendToClient sends a message to a specific client
@@ -410,11 +419,8 @@ func (c *Client) handleAuth(msg Message) {
 		return
 	}
 
-	for _, allowed := range c.gateway.authTokens {
-		if payload.Token == allowed {
-			c.authorized = true
-			break
-		}
+	if c.gateway.validToken(payload.Token) {
+		c.authorized = true
 	}
 	if c.authorized {
 		c.sendAuthResult(true, "")
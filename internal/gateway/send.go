@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pltanton/lingti-bot/internal/logger"
+	"github.com/pltanton/lingti-bot/internal/router"
+	"github.com/pltanton/lingti-bot/internal/security"
+)
+
+// Sender delivers messages for the /send endpoint. *router.Router
+// implements it.
+type Sender interface {
+	SendToUser(platform, channelID string, resp router.Response) error
+	Platforms() []string
+}
+
+// SendPayload is the body of POST /send. Files are paths on the gateway's
+// host.
+type SendPayload struct {
+	Platform  string   `json:"platform"`
+	ChannelID string   `json:"channel_id"`
+	Text      string   `json:"text,omitempty"`
+	Files     []string `json:"files,omitempty"`
+}
+
+// SetSender enables POST /send, which lets other processes (such as
+// `lingti-bot serve`) post to the gateway's platforms
+func (g *Gateway) SetSender(s Sender) {
+	g.sender = s
+}
+
+// SetFilePolicy applies the file tools' security settings to the files of
+// POST /send: they must be under allowedPaths (when set), and none may be
+// sent when disabled is true
+func (g *Gateway) SetFilePolicy(allowedPaths []string, disabled bool) {
+	g.paths = security.NewPathChecker(allowedPaths)
+	g.noFiles = disabled
+}
+
+// handleSend delivers a message through the gateway's platforms. It needs
+// one of the auth tokens as a bearer token; without tokens only local
+// clients outside a browser may send.
+func (g *Gateway) handleSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Browsers can't send JSON cross-origin without a preflight, which
+	// this endpoint doesn't answer
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	if !g.authorizeHTTP(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if g.sender == nil {
+		http.Error(w, "no platforms attached", http.StatusServiceUnavailable)
+		return
+	}
+
+	var payload SendPayload
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if payload.Platform == "" || payload.ChannelID == "" || (payload.Text == "" && len(payload.Files) == 0) {
+		http.Error(w, "platform, channel_id and text or files are required", http.StatusBadRequest)
+		return
+	}
+
+	resp := router.Response{Text: payload.Text}
+	for _, f := range payload.Files {
+		if err := g.checkFile(f); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		resp.Files = append(resp.Files, router.FileAttachment{Path: f})
+	}
+	if err := g.sender.SendToUser(payload.Platform, payload.ChannelID, resp); err != nil {
+		logger.Warn("[Gateway] Send to %s/%s failed: %v", payload.Platform, payload.ChannelID, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	logger.Info("[Gateway] Sent message to %s/%s", payload.Platform, payload.ChannelID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "sent"})
+}
+
+// checkFile checks a file of POST /send against the file policy
+func (g *Gateway) checkFile(path string) error {
+	if g.noFiles {
+		return fmt.Errorf("sending files is disabled")
+	}
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~/") {
+		return fmt.Errorf("file path must be absolute: %s", path)
+	}
+	if g.paths != nil {
+		return g.paths.CheckPath(path)
+	}
+	return nil
+}
+
+// authorizeHTTP checks the bearer token of an HTTP API request. Without
+// tokens it accepts loopback clients that aren't web pages: requests with
+// an Origin header, or a Host other than a loopback address (DNS
+// rebinding), are refused.
+func (g *Gateway) authorizeHTTP(r *http.Request) bool {
+	if len(g.authTokens) == 0 {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback() && r.Header.Get("Origin") == "" && isLoopbackHost(r.Host)
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && g.validToken(token)
+}
+
+// validToken reports whether token is one of the auth tokens
+func (g *Gateway) validToken(token string) bool {
+	valid := false
+	for _, allowed := range g.authTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// isLoopbackHost reports whether a Host header names this machine
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Remote sends messages through a running gateway's HTTP API. It
+// implements the same SendToUser and Platforms methods as *router.Router.
+type Remote struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewRemote returns a Remote for the gateway at url (e.g.
+// "http://127.0.0.1:18789"), authenticating with token when set
+func NewRemote(url, token string) *Remote {
+	return &Remote{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SendToUser posts a message to a platform chat through the gateway
+func (g *Remote) SendToUser(platform, channelID string, resp router.Response) error {
+	payload := SendPayload{Platform: platform, ChannelID: channelID, Text: resp.Text}
+	for _, f := range resp.Files {
+		payload.Files = append(payload.Files, f.Path)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, g.url+"/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	res, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gateway unreachable: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("gateway: HTTP %d: %s", res.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
+
+// Platforms returns the gateway's platforms, or nil if it can't be reached
+func (g *Remote) Platforms() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.url+"/status", nil)
+	if err != nil {
+		return nil
+	}
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+	res, err := g.client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer res.Body.Close()
+	var status struct {
+		Platforms []string `json:"platforms"`
+	}
+	if json.NewDecoder(res.Body).Decode(&status) != nil {
+		return nil
+	}
+	return status.Platforms
+}
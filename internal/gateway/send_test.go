@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pltanton/lingti-bot/internal/router"
+)
+
+// fakeSender records messages instead of sending them
+type fakeSender struct {
+	sent []string
+}
+
+func (f *fakeSender) Platforms() []string { return []string{"slack", "telegram"} }
+
+func (f *fakeSender) SendToUser(platform, channelID string, resp router.Response) error {
+	f.sent = append(f.sent, platform+"/"+channelID+": "+resp.Text)
+	return nil
+}
+
+func TestRemoteSend(t *testing.T) {
+	g := New(Config{AuthToken: "s3cret"})
+	sender := &fakeSender{}
+	g.SetSender(sender)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/send", g.handleSend)
+	mux.HandleFunc("/status", g.handleStatus)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	if err := NewRemote(srv.URL, "wrong").SendToUser("telegram", "42", router.Response{Text: "hi"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("send with a wrong token: err = %v, want 401", err)
+	}
+
+	remote := NewRemote(srv.URL+"/", "s3cret")
+	if err := remote.SendToUser("telegram", "42", router.Response{Text: "hi"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got := strings.Join(sender.sent, "|"); got != "telegram/42: hi" {
+		t.Errorf("sent %q", got)
+	}
+	if got := strings.Join(remote.Platforms(), ","); got != "slack,telegram" {
+		t.Errorf("platforms = %q", got)
+	}
+}
+
+func TestSendWithoutTokensIsLoopbackOnly(t *testing.T) {
+	g := New(Config{})
+	g.SetSender(&fakeSender{})
+	tests := []struct {
+		name, addr, host, origin, contentType string
+		want                                  int
+	}{
+		{"local", "127.0.0.1:5000", "127.0.0.1:18789", "", "application/json", http.StatusOK},
+		{"localhost", "[::1]:5000", "localhost:18789", "", "application/json; charset=utf-8", http.StatusOK},
+		{"remote", "192.168.1.20:5000", "127.0.0.1:18789", "", "application/json", http.StatusUnauthorized},
+		{"web page", "127.0.0.1:5000", "127.0.0.1:18789", "https://evil.example", "application/json", http.StatusUnauthorized},
+		{"DNS rebinding", "127.0.0.1:5000", "evil.example:18789", "", "application/json", http.StatusUnauthorized},
+		{"simple POST", "127.0.0.1:5000", "127.0.0.1:18789", "", "text/plain", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/send", strings.NewReader(`{"platform":"slack","channel_id":"C1","text":"hi"}`))
+		req.RemoteAddr = tt.addr
+		req.Host = tt.host
+		req.Header.Set("Content-Type", tt.contentType)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		w := httptest.NewRecorder()
+		g.handleSend(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: HTTP %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	// Platforms are only listed to clients that may send
+	for host, want := range map[string]bool{"127.0.0.1:18789": true, "evil.example": false} {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		req.RemoteAddr = "127.0.0.1:5000"
+		req.Host = host
+		w := httptest.NewRecorder()
+		g.handleStatus(w, req)
+		if got := strings.Contains(w.Body.String(), "platforms"); got != want {
+			t.Errorf("status for Host %s lists platforms: %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestSendFilePolicy(t *testing.T) {
+	allowed := t.TempDir()
+	g := New(Config{AuthToken: "s3cret"})
+	g.SetSender(&fakeSender{})
+	send := func(file string) int {
+		req := httptest.NewRequest(http.MethodPost, "/send", strings.NewReader(`{"platform":"slack","channel_id":"C1","files":["`+file+`"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		g.handleSend(w, req)
+		return w.Code
+	}
+
+	g.SetFilePolicy([]string{allowed}, false)
+	for file, want := range map[string]int{
+		allowed + "/report.pdf": http.StatusOK,
+		"/etc/passwd":           http.StatusForbidden,
+		allowed + "/../id_rsa":  http.StatusForbidden,
+		"report.pdf":            http.StatusForbidden,
+	} {
+		if got := send(file); got != want {
+			t.Errorf("send %s: HTTP %d, want %d", file, got, want)
+		}
+	}
+
+	g.SetFilePolicy(nil, true)
+	if got := send(allowed + "/report.pdf"); got != http.StatusForbidden {
+		t.Errorf("send with file tools disabled: HTTP %d, want 403", got)
+	}
+}
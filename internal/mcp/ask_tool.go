@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pltanton/lingti-bot/internal/router"
+)
+
+// askToolName is the tool that hands a task to the full agent
+const askToolName = "ask_lingti"
+
+// AgentRunner runs a message through the agent loop. *agent.AgentPool
+// implements it.
+type AgentRunner interface {
+	// HandleMessageAs handles msg with the named agent, or routes it like a
+	// chat message when agentID is empty
+	HandleMessageAs(ctx context.Context, agentID string, msg router.Message) (router.Response, error)
+	// AgentIDs lists the named agents
+	AgentIDs() []string
+}
+
+// SetAgent registers the ask_lingti tool, which delegates a task to the
+// agent with its skills, browser rules and bindings
+func (s *Server) SetAgent(runner AgentRunner) {
+	s.agent = runner
+
+	agentDesc := "ID of the agent to use (default: the agent bound to the mcp platform, else the default agent)"
+	if ids := runner.AgentIDs(); len(ids) > 0 {
+		agentDesc += ". Available: " + strings.Join(ids, ", ")
+	}
+	s.mcpServer.AddTool(mcp.NewTool(askToolName,
+		mcp.WithDescription("Ask the lingti-bot agent to carry out a task. The agent plans and runs its own tools "+
+			"(files, shell, browser, skills, cron) over several rounds and returns its final answer with any files it produced. "+
+			"Progress notifications report each tool round."),
+		mcp.WithString("message", mcp.Required(), mcp.Description("The task or question for the agent")),
+		mcp.WithString("agent", mcp.Description(agentDesc)),
+		mcp.WithString("session_key", mcp.Description("Conversation to continue; calls with the same key share memory (default: this client's session)")),
+		mcp.WithArray("files", mcp.Description("Paths of local files for the agent to work with"), mcp.Items(map[string]any{"type": "string"})),
+	), s.askLingti)
+}
+
+func (s *Server) askLingti(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	text, _ := req.Params.Arguments["message"].(string)
+	if strings.TrimSpace(text) == "" {
+		return mcp.NewToolResultError("message is required"), nil
+	}
+	agentID, _ := req.Params.Arguments["agent"].(string)
+	sessionKey, _ := req.Params.Arguments["session_key"].(string)
+	if sessionKey == "" {
+		sessionKey = stdioSessionID
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			sessionKey = session.SessionID()
+		}
+	}
+
+	files, err := s.askFiles(req.Params.Arguments["files"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(files) > 0 {
+		text += "\n\nAttached files:\n- " + strings.Join(files, "\n- ")
+	}
+
+	if req.Params.Meta != nil && req.Params.Meta.ProgressToken != nil {
+		token := req.Params.Meta.ProgressToken
+		sessionCtx := ctx
+		ctx = router.ContextWithToolRounds(ctx, func(round router.ToolRound) {
+			message := fmt.Sprintf("Round %d: %s", round.Round, strings.Join(round.Tools, ", "))
+			if round.Errors > 0 {
+				message += fmt.Sprintf(" (%d failed)", round.Errors)
+			}
+			err := server.ServerFromContext(sessionCtx).SendNotificationToClient(sessionCtx, "notifications/progress", map[string]any{
+				"progressToken": token,
+				"progress":      round.Round,
+				"total":         round.MaxRounds,
+				"message":       message,
+			})
+			if err != nil {
+				log.Printf("[MCP] Failed to send progress: %v", err)
+			}
+		})
+	}
+
+	resp, err := s.agent.HandleMessageAs(ctx, agentID, router.Message{
+		Platform:  "mcp",
+		ChannelID: sessionKey,
+		UserID:    "mcp",
+		Username:  "mcp",
+		Text:      text,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent(resp.Text)}}
+	for _, f := range resp.Files {
+		result.Content = append(result.Content, attachmentContent(f))
+	}
+	return result, nil
+}
+
+// askFiles validates the files argument against the security policy and
+// returns absolute paths
+func (s *Server) askFiles(arg any) ([]string, error) {
+	items, _ := arg.([]any)
+	if len(items) > 0 && s.disableFileTools {
+		return nil, fmt.Errorf("ACCESS DENIED: file operations are disabled by security policy")
+	}
+	var files []string
+	for _, item := range items {
+		path, ok := item.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("files must be a list of paths")
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		if s.pathChecker.HasRestrictions() {
+			if err := s.pathChecker.CheckPath(abs); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return nil, err
+		}
+		files = append(files, abs)
+	}
+	return files, nil
+}
+
+// attachmentContent returns a file the agent attached: images inline,
+// other files up to maxFileResourceLen as embedded resources, and larger
+// files by path only
+func attachmentContent(f router.FileAttachment) mcp.Content {
+	name := f.Name
+	if name == "" {
+		name = filepath.Base(f.Path)
+	}
+	info, err := os.Stat(f.Path)
+	if err != nil || info.Size() > maxFileResourceLen {
+		return mcp.NewTextContent(fmt.Sprintf("Attached file %s: %s", name, f.Path))
+	}
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return mcp.NewTextContent(fmt.Sprintf("Attached file %s: %s", name, f.Path))
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(f.Path))
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if f.MediaType == "image" || strings.HasPrefix(mimeType, "image/") {
+		if mimeType == "" {
+			mimeType = "image/png"
+		}
+		return mcp.NewImageContent(encoded, mimeType)
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: fileURI(f.Path), MIMEType: mimeType, Blob: encoded})
+}
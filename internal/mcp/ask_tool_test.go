@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pltanton/lingti-bot/internal/router"
+)
+
+// fakeAgent runs two tool rounds and attaches a report
+type fakeAgent struct {
+	report  string
+	agentID string
+	msg     router.Message
+}
+
+func (f *fakeAgent) AgentIDs() []string { return []string{"writer"} }
+
+func (f *fakeAgent) HandleMessageAs(ctx context.Context, agentID string, msg router.Message) (router.Response, error) {
+	f.agentID, f.msg = agentID, msg
+	if onRound := router.ToolRoundsFromContext(ctx); onRound != nil {
+		onRound(router.ToolRound{Round: 1, MaxRounds: 10, Tools: []string{"file_read"}})
+		onRound(router.ToolRound{Round: 2, MaxRounds: 10, Tools: []string{"browser_navigate", "browser_snapshot"}, Errors: 1})
+	}
+	return router.Response{Text: "Done.", Files: []router.FileAttachment{{Path: f.report}}}, nil
+}
+
+func TestAskLingti(t *testing.T) {
+	root := t.TempDir()
+	input := filepath.Join(root, "input.txt")
+	os.WriteFile(input, []byte("data"), 0644)
+	report := filepath.Join(t.TempDir(), "report.csv")
+	os.WriteFile(report, []byte("a,b\n"), 0644)
+
+	s := newTestServer(t, SecurityOptions{AllowedPaths: []string{root}})
+	runner := &fakeAgent{report: report}
+	s.SetAgent(runner)
+	handler, closeSessions := s.httpHandler(TransportHTTP, HTTPOptions{})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	defer closeSessions()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, _ := client.NewStreamableHttpClient(ts.URL + "/mcp")
+	if err := initialize(ctx, c); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	var progress []map[string]any
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == "notifications/progress" {
+			progress = append(progress, n.Params.AdditionalFields)
+		}
+	})
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = askToolName
+	req.Params.Arguments = map[string]any{"message": "summarize", "agent": "writer", "files": []any{input}}
+	req.Params.Meta = &struct {
+		ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
+	}{ProgressToken: "t1"}
+	result, err := c.CallTool(ctx, req)
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Done." {
+		t.Errorf("text = %q", text)
+	}
+	if res := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents); res.URI != fileURI(report) {
+		t.Errorf("attachment = %+v", res)
+	}
+
+	if runner.agentID != "writer" || runner.msg.Platform != "mcp" || runner.msg.ChannelID == "" || !strings.Contains(runner.msg.Text, input) {
+		t.Errorf("agent %q got %+v", runner.agentID, runner.msg)
+	}
+	if len(progress) != 2 || progress[0]["progressToken"] != "t1" || progress[1]["progress"] != float64(2) ||
+		progress[1]["message"] != "Round 2: browser_navigate, browser_snapshot (1 failed)" {
+		t.Errorf("progress = %v", progress)
+	}
+
+	// Files outside the allowed roots are refused before the agent runs
+	req.Params.Arguments = map[string]any{"message": "summarize", "files": []any{report}}
+	if result, err := c.CallTool(ctx, req); err != nil || !result.IsError {
+		t.Errorf("file outside the roots: %+v, %v", result, err)
+	}
+}
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
@@ -27,9 +28,21 @@ func CronCreate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		return mcp.NewToolResultError("name is required"), nil
 	}
 
-	schedule, ok := req.Params.Arguments["schedule"].(string)
-	if !ok || schedule == "" {
-		return mcp.NewToolResultError("schedule is required"), nil
+	// Chained jobs may run only after another job instead of on a schedule
+	schedule, _ := req.Params.Arguments["schedule"].(string)
+	after, _ := req.Params.Arguments["after"].(string)
+	afterOn, _ := req.Params.Arguments["after_on"].(string)
+	if schedule == "" && after == "" {
+		return mcp.NewToolResultError("schedule is required (or after)"), nil
+	}
+	var parsed *cronpkg.ParsedSchedule
+	if schedule != "" {
+		p, err := cronpkg.ParseSchedule(schedule, time.Now())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		parsed = p
+		schedule = p.Spec
 	}
 
 	tool, ok := req.Params.Arguments["tool"].(string)
@@ -40,18 +53,60 @@ func CronCreate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 	// Arguments are optional
 	arguments, _ := req.Params.Arguments["arguments"].(map[string]any)
 
+	// Targets are optional
+	var specs []string
+	if raw, ok := req.Params.Arguments["targets"].([]any); ok {
+		for _, item := range raw {
+			if spec, ok := item.(string); ok {
+				specs = append(specs, spec)
+			}
+		}
+	}
+	targets, err := cronpkg.ParseTargets(specs)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Create job
-	job, err := cronScheduler.AddJob(name, schedule, tool, arguments)
+	job, err := cronScheduler.CreateJob(&cronpkg.Job{
+		Name:      name,
+		Schedule:  schedule,
+		Tool:      tool,
+		Arguments: arguments,
+		Targets:   targets,
+		After:     after,
+		AfterOn:   afterOn,
+	})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to create job: %v", err)), nil
 	}
 
 	result := fmt.Sprintf("✓ Job created successfully\n\nID: %s\nName: %s\nSchedule: %s\nTool: %s\nStatus: enabled",
 		job.ID, job.Name, job.Schedule, job.Tool)
+	if parsed != nil {
+		result += fmt.Sprintf("\nRuns: %s", parsed.Description)
+		for _, t := range parsed.NextRuns(time.Now(), 3) {
+			result += fmt.Sprintf("\nNext run: %s", t.Format("2006-01-02 15:04 (Mon)"))
+		}
+	}
+	if job.After != "" {
+		result += fmt.Sprintf("\nAfter: %s", job.After)
+	}
+	for _, t := range job.Targets {
+		result += fmt.Sprintf("\nTarget: %s", t)
+	}
 
 	return mcp.NewToolResultText(result), nil
 }
 
+// afterOnOrDefault returns the trigger condition of a chained job
+func afterOnOrDefault(on string) string {
+	if on == "" {
+		return cronpkg.AfterSuccess
+	}
+	return on
+}
+
 // CronList lists all scheduled jobs
 func CronList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if cronScheduler == nil {
@@ -64,13 +119,21 @@ func CronList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult
 		return mcp.NewToolResultText("No scheduled jobs"), nil
 	}
 
-	result := fmt.Sprintf("Scheduled Jobs (%d total)\n\n", len(jobs))
+	running := make(map[string]cronpkg.RunningJob)
+	for _, r := range cronScheduler.RunningJobs() {
+		running[r.ID] = r
+	}
+
+	result := fmt.Sprintf("Scheduled Jobs (%d total, %d running)\n\n", len(jobs), len(running))
 
 	for i, job := range jobs {
 		status := "enabled"
 		if !job.Enabled {
 			status = "paused"
 		}
+		if r, ok := running[job.ID]; ok {
+			status += fmt.Sprintf(", running since %s", r.Since.Format("15:04:05"))
+		}
 
 		lastRun := "never"
 		if job.LastRun != nil {
@@ -84,10 +147,16 @@ func CronList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult
 
 		result += fmt.Sprintf("%d. %s (ID: %s)\n", i+1, job.Name, job.ID)
 		result += fmt.Sprintf("   Schedule: %s\n", job.Schedule)
+		if job.After != "" {
+			result += fmt.Sprintf("   After: %s (on %s)\n", job.After, afterOnOrDefault(job.AfterOn))
+		}
 		result += fmt.Sprintf("   Tool: %s\n", job.Tool)
 		result += fmt.Sprintf("   Status: %s\n", status)
 		result += fmt.Sprintf("   Last Run: %s\n", lastRun)
 		result += fmt.Sprintf("   Last Error: %s\n", lastError)
+		for _, t := range job.Targets {
+			result += fmt.Sprintf("   Target: %s\n", t)
+		}
 		result += "\n"
 	}
 
@@ -156,9 +225,12 @@ func registerCronTools(s *Server) {
 	s.addTool(mcp.NewTool("cron_create",
 		mcp.WithDescription("Create a scheduled job that runs periodically"),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Human-readable name for the job")),
-		mcp.WithString("schedule", mcp.Required(), mcp.Description("Cron expression (e.g., '0 * * * *' for every hour)")),
+		mcp.WithString("schedule", mcp.Description("Cron expression (e.g., '0 * * * *' for every hour) or phrase like 'every weekday at 9:30'; optional when 'after' is set")),
 		mcp.WithString("tool", mcp.Required(), mcp.Description("MCP tool to execute")),
 		mcp.WithObject("arguments", mcp.Description("Arguments to pass to the tool")),
+		mcp.WithString("after", mcp.Description("ID of a job to run after; {{output}}, {{error}} and {{upstream}} in arguments receive its result")),
+		mcp.WithString("after_on", mcp.Description("Run after the upstream job on 'success' (default), 'failure', or 'always'")),
+		mcp.WithArray("targets", mcp.Description("Where to deliver results: 'platform:<name>:<channelID>', 'webhook:<url>', or 'file:<path>'")),
 	), CronCreate)
 
 	// cron_list
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pltanton/lingti-bot/internal/router"
+)
+
+// sendMessageToolName is the tool that posts to a chat platform
+const sendMessageToolName = "send_message"
+
+// Messenger delivers messages to chat platforms. *router.Router implements it
+// for platforms attached to this process, *gateway.Remote through a running
+// gateway.
+type Messenger interface {
+	SendToUser(platform, channelID string, resp router.Response) error
+	// Platforms lists the platforms messages can be sent to, or nil when
+	// unknown
+	Platforms() []string
+}
+
+// SetMessenger registers the send_message tool and delivers cron
+// notifications for platform targets through m
+func (s *Server) SetMessenger(m Messenger) {
+	s.messenger = m
+
+	platformDesc := "Platform to send to, e.g. telegram, slack, discord"
+	if names := m.Platforms(); len(names) > 0 {
+		platformDesc += ". Available: " + strings.Join(names, ", ")
+	}
+	s.addTool(mcp.NewTool(sendMessageToolName,
+		mcp.WithDescription("Send a message, optionally with files, to a chat or user on a chat platform"),
+		mcp.WithString("platform", mcp.Required(), mcp.Description(platformDesc)),
+		mcp.WithString("channel", mcp.Required(), mcp.Description("Chat, channel or user ID on the platform")),
+		mcp.WithString("text", mcp.Description("Message text")),
+		mcp.WithArray("files", mcp.Description("Paths of local files to attach"), mcp.Items(map[string]any{"type": "string"})),
+	), s.sendMessage)
+}
+
+func (s *Server) sendMessage(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	platform, _ := req.Params.Arguments["platform"].(string)
+	channel, _ := req.Params.Arguments["channel"].(string)
+	text, _ := req.Params.Arguments["text"].(string)
+	if platform == "" || channel == "" {
+		return mcp.NewToolResultError("platform and channel are required"), nil
+	}
+
+	files, err := s.askFiles(req.Params.Arguments["files"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if text == "" && len(files) == 0 {
+		return mcp.NewToolResultError("text or files are required"), nil
+	}
+
+	resp := router.Response{Text: text}
+	for _, f := range files {
+		resp.Files = append(resp.Files, router.FileAttachment{Path: f, Name: filepath.Base(f)})
+	}
+	if err := s.messenger.SendToUser(platform, channel, resp); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to send: %v", err)), nil
+	}
+	log.Printf("[MCP] Sent message to %s/%s", platform, channel)
+	return mcp.NewToolResultText(fmt.Sprintf("Message sent to %s/%s", platform, channel)), nil
+}
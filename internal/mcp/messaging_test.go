@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pltanton/lingti-bot/internal/router"
+)
+
+// fakeMessenger records what it is asked to send
+type fakeMessenger struct {
+	sent []string // platform/channel: text [files]
+}
+
+func (f *fakeMessenger) Platforms() []string { return []string{"telegram"} }
+
+func (f *fakeMessenger) SendToUser(platform, channelID string, resp router.Response) error {
+	entry := platform + "/" + channelID + ": " + resp.Text
+	for _, file := range resp.Files {
+		entry += " [" + file.Name + "]"
+	}
+	f.sent = append(f.sent, entry)
+	return nil
+}
+
+func TestSendMessage(t *testing.T) {
+	root := t.TempDir()
+	report := filepath.Join(root, "report.pdf")
+	os.WriteFile(report, []byte("%PDF"), 0644)
+
+	s := newTestServer(t, SecurityOptions{AllowedPaths: []string{root}})
+	if err := s.NotifyChatUser("telegram", "42", "", "before"); err != nil {
+		t.Fatalf("NotifyChatUser without a messenger: %v", err)
+	}
+	if _, ok := s.toolHandlers[sendMessageToolName]; ok {
+		t.Fatal("send_message is offered without a messenger")
+	}
+
+	messenger := &fakeMessenger{}
+	s.SetMessenger(messenger)
+	call := func(args map[string]any) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = sendMessageToolName
+		req.Params.Arguments = args
+		result, err := s.toolHandlers[sendMessageToolName](context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := call(map[string]any{"platform": "telegram", "channel": "42", "text": "Build done", "files": []any{report}}); result.IsError {
+		t.Fatalf("send_message = %+v", result)
+	}
+	if result := call(map[string]any{"platform": "telegram", "channel": "42", "files": []any{"/etc/hosts"}}); !result.IsError {
+		t.Error("file outside the allowed roots was sent")
+	}
+	if result := call(map[string]any{"platform": "telegram", "channel": "42"}); !result.IsError {
+		t.Error("empty message was sent")
+	}
+
+	// Cron notifications for platform targets are delivered too
+	if err := s.NotifyChatUser("telegram", "42", "", "Job finished"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "telegram/42: Build done [report.pdf]|telegram/42: Job finished"
+	if got := strings.Join(messenger.sent, "|"); got != want {
+		t.Errorf("sent %q, want %q", got, want)
+	}
+}
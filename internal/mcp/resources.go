@@ -0,0 +1,336 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pltanton/lingti-bot/internal/browser"
+	"github.com/pltanton/lingti-bot/internal/skills"
+)
+
+// Resource URIs published by the server. Files under the allowed roots use
+// plain file:// URIs.
+const (
+	skillsURI            = "lingti://skills"
+	cronJobsURI          = "lingti://cron/jobs"
+	browserSnapshotURI   = "lingti://browser/snapshot"
+	browserScreenshotURI = "lingti://browser/screenshot"
+)
+
+const (
+	cronResourceRuns   = 20      // runs included in a job resource
+	maxFileResourceLen = 1 << 20 // larger files are not served as resources
+	maxDirEntries      = 500     // entries listed in a directory resource
+)
+
+func skillURI(name string) string { return skillsURI + "/" + url.PathEscape(name) }
+
+func cronJobURI(id string) string { return cronJobsURI + "/" + id }
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// registerResources publishes skills, cron jobs, the browser page and the
+// allowed file-system roots as resources, and skills as prompts
+func registerResources(s *Server) {
+	s.mcpServer.AddResource(mcp.NewResource(skillsURI, "Skills",
+		mcp.WithResourceDescription("Skills that are ready to use, with their resource URIs"),
+		mcp.WithMIMEType("application/json"),
+	), s.readSkills)
+	s.syncSkills(s.skillCache.Snapshot().Report)
+
+	s.mcpServer.AddResource(mcp.NewResource(cronJobsURI, "Scheduled jobs",
+		mcp.WithResourceDescription("Cron jobs with their schedule, state and last run"),
+		mcp.WithMIMEType("application/json"),
+	), s.readCronJobs)
+	s.mcpServer.AddResourceTemplate(mcp.NewResourceTemplate(cronJobsURI+"/{id}", "Scheduled job",
+		mcp.WithTemplateDescription(fmt.Sprintf("A cron job and its last %d runs", cronResourceRuns)),
+		mcp.WithTemplateMIMEType("application/json"),
+	), s.readCronJob)
+
+	s.mcpServer.AddResource(mcp.NewResource(browserSnapshotURI, "Browser snapshot",
+		mcp.WithResourceDescription("Accessibility tree of the current browser page; its refs work with browser_click and browser_type"),
+		mcp.WithMIMEType("text/plain"),
+	), readBrowserSnapshot)
+	s.mcpServer.AddResource(mcp.NewResource(browserScreenshotURI, "Browser screenshot",
+		mcp.WithResourceDescription("Screenshot of the current browser page"),
+		mcp.WithMIMEType("image/png"),
+	), readBrowserScreenshot)
+
+	// Without configured roots every path is allowed, which is too much to publish
+	if s.disableFileTools || !s.pathChecker.HasRestrictions() {
+		return
+	}
+	for _, root := range s.pathChecker.AllowedPaths() {
+		s.mcpServer.AddResource(mcp.NewResource(fileURI(root), filepath.Base(root),
+			mcp.WithResourceDescription("Allowed directory "+root),
+			mcp.WithMIMEType("inode/directory"),
+		), s.readFile)
+	}
+	s.mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("file://{+path}", "File",
+		mcp.WithTemplateDescription("A file or directory under one of the allowed directories"),
+	), s.readFile)
+}
+
+// syncSkills publishes the ready skills in report as resources and prompts,
+// removing the resources of skills that are gone. Prompts can't be
+// unregistered, so a removed skill's prompt reports that it's unavailable.
+func (s *Server) syncSkills(report skills.StatusReport) {
+	s.skillsMu.Lock()
+	defer s.skillsMu.Unlock()
+
+	current := make(map[string]string)
+	for _, skill := range report.Skills {
+		if skill.Status != skills.StatusReady {
+			continue
+		}
+		current[skill.Name] = skill.Description
+		if desc, ok := s.skillResources[skill.Name]; ok && desc == skill.Description {
+			continue
+		}
+		s.mcpServer.AddResource(mcp.NewResource(skillURI(skill.Name), skill.Name,
+			mcp.WithResourceDescription(skill.Description),
+			mcp.WithMIMEType("text/markdown"),
+		), s.readSkill)
+		s.mcpServer.AddPrompt(mcp.NewPrompt(skill.Name,
+			mcp.WithPromptDescription(skill.Description),
+			mcp.WithArgument("task", mcp.ArgumentDescription("What to do with the skill")),
+		), s.getSkillPrompt)
+	}
+	for name := range s.skillResources {
+		if _, ok := current[name]; !ok {
+			s.mcpServer.RemoveResource(skillURI(name))
+		}
+	}
+	s.skillResources = current
+}
+
+// readySkill returns a skill that is ready to use from the current snapshot
+func (s *Server) readySkill(name string) (*skills.SkillStatus, error) {
+	skill, ok := s.skillCache.Snapshot().Report.Find(name)
+	if !ok || skill.Status != skills.StatusReady {
+		return nil, fmt.Errorf("skill %q is not available", name)
+	}
+	return skill, nil
+}
+
+func (s *Server) readSkills(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	type skillInfo struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		Source      string `json:"source"`
+		URI         string `json:"uri"`
+	}
+	list := []skillInfo{}
+	for _, skill := range s.skillCache.Snapshot().Report.Skills {
+		if skill.Status == skills.StatusReady {
+			list = append(list, skillInfo{skill.Name, skill.Description, string(skill.Source), skillURI(skill.Name)})
+		}
+	}
+	return jsonContents(req.Params.URI, list)
+}
+
+func (s *Server) readSkill(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	name, err := url.PathUnescape(strings.TrimPrefix(req.Params.URI, skillsURI+"/"))
+	if err != nil {
+		return nil, err
+	}
+	skill, err := s.readySkill(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(skill.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", skill.FilePath, err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/markdown", Text: string(data)}}, nil
+}
+
+// getSkillPrompt returns a skill's instructions, in the form the agent's
+// skill_read tool uses, followed by the task
+func (s *Server) getSkillPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	skill, err := s.readySkill(req.Params.Name)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Skill: %s\n", skill.Name)
+	if skill.Description != "" {
+		fmt.Fprintf(&sb, "%s\n", skill.Description)
+	}
+	fmt.Fprintf(&sb, "Directory: %s\n\n## Instructions\n\n", skill.BaseDir)
+	if skill.Content != "" {
+		sb.WriteString(skill.Content)
+	} else {
+		sb.WriteString("(SKILL.md has no instructions)")
+	}
+	if task := req.Params.Arguments["task"]; task != "" {
+		fmt.Fprintf(&sb, "\n\n## Task\n\n%s", task)
+	}
+	return mcp.NewGetPromptResult(skill.Description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(sb.String())),
+	}), nil
+}
+
+func (s *Server) readCronJobs(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	type jobInfo struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		Schedule  string `json:"schedule"`
+		Enabled   bool   `json:"enabled"`
+		LastRun   string `json:"last_run,omitempty"`
+		LastError string `json:"last_error,omitempty"`
+		URI       string `json:"uri"`
+	}
+	list := []jobInfo{}
+	for _, job := range s.cronScheduler.ListJobs() {
+		info := jobInfo{ID: job.ID, Name: job.Name, Schedule: job.Schedule, Enabled: job.Enabled, LastError: job.LastError, URI: cronJobURI(job.ID)}
+		if job.LastRun != nil {
+			info.LastRun = job.LastRun.Format("2006-01-02 15:04:05")
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return jsonContents(req.Params.URI, list)
+}
+
+func (s *Server) readCronJob(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id := strings.TrimPrefix(req.Params.URI, cronJobsURI+"/")
+	job, ok := s.cronScheduler.GetJob(id)
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	runs, err := s.cronScheduler.History(id, cronResourceRuns)
+	if err != nil {
+		return nil, err
+	}
+	return jsonContents(req.Params.URI, map[string]any{"job": job, "runs": runs})
+}
+
+// browserPage returns the current page of the client's browser session
+// without starting a browser, since reading a resource shouldn't open one
+func browserPage(ctx context.Context) (*rod.Page, *browser.Browser, error) {
+	b, ok := browser.Sessions().Lookup(browserSessionKey(ctx))
+	if !ok || !b.IsRunning() {
+		return nil, nil, errors.New("browser is not running; start it with browser_start or browser_navigate")
+	}
+	page, err := b.ActivePage()
+	return page, b, err
+}
+
+func readBrowserSnapshot(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	page, b, err := browserPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, refs, err := browser.Snapshot(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture snapshot: %w", err)
+	}
+	b.SetRefs(refs)
+
+	header := ""
+	if info, _ := page.Info(); info != nil {
+		header = fmt.Sprintf("URL: %s\nTitle: %s\nRefs: %d\n\n", info.URL, info.Title, len(refs))
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/plain", Text: header + snapshot}}, nil
+}
+
+func readBrowserScreenshot(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	page, _, err := browserPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := page.Screenshot(false, &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng})
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{URI: req.Params.URI, MIMEType: "image/png", Blob: base64.StdEncoding.EncodeToString(data)}}, nil
+}
+
+// rootPath returns the local path of a file:// URI under an allowed root
+func (s *Server) rootPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", fmt.Errorf("invalid file URI %q", uri)
+	}
+	path := filepath.Clean(filepath.FromSlash(u.Path))
+	if s.disableFileTools || !s.pathChecker.HasRestrictions() || !s.pathChecker.IsAllowed(path) {
+		return "", fmt.Errorf("%s is outside the allowed directories", path)
+	}
+	return path, nil
+}
+
+// readFile serves a file under an allowed root, or a directory as a listing
+// of the URIs of its entries
+func (s *Server) readFile(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	path, err := s.rootPath(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		for i, entry := range entries {
+			if i == maxDirEntries {
+				fmt.Fprintf(&sb, "... and %d more\n", len(entries)-maxDirEntries)
+				break
+			}
+			name := entry.Name()
+			if entry.IsDir() {
+				name += "/"
+			}
+			fmt.Fprintf(&sb, "%s\t%s\n", name, fileURI(filepath.Join(path, entry.Name())))
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/plain", Text: sb.String()}}, nil
+	}
+
+	if info.Size() > maxFileResourceLen {
+		return nil, fmt.Errorf("%s is too large (%d bytes, limit %d)", path, info.Size(), maxFileResourceLen)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if utf8.Valid(data) {
+		if mimeType == "" {
+			mimeType = "text/plain"
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, MIMEType: mimeType, Text: string(data)}}, nil
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{URI: req.Params.URI, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)}}, nil
+}
+
+func jsonContents(uri string, v any) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)}}, nil
+}
@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
+)
+
+func readText(t *testing.T, c *client.Client, uri string) string {
+	t.Helper()
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	result, err := c.ReadResource(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ReadResource(%s): %v", uri, err)
+	}
+	return result.Contents[0].(mcp.TextResourceContents).Text
+}
+
+func TestResourcesAndPrompts(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "notes.txt"), []byte("hello"), 0644)
+	s := newTestServer(t, SecurityOptions{AllowedPaths: []string{root}})
+	handler, closeSessions := s.httpHandler(TransportHTTP, HTTPOptions{})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	defer closeSessions()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Skills added after startup are published once the cache picks them up
+	skillDir := filepath.Join(os.Getenv("HOME"), ".lingti", "skills", "greeter")
+	os.MkdirAll(skillDir, 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: greeter\ndescription: Greets people\n---\nSay hello warmly."), 0644)
+	s.skillCache.Refresh()
+	s.syncSkills(s.skillCache.Snapshot().Report)
+
+	c, _ := client.NewStreamableHttpClient(ts.URL + "/mcp")
+	if err := initialize(ctx, c); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	updates := make(chan string, 10)
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == mcp.MethodNotificationResourceUpdated {
+			updates <- n.Params.AdditionalFields["uri"].(string)
+		}
+	})
+
+	resources, err := c.ListResources(ctx, mcp.ListResourcesRequest{})
+	if err != nil {
+		t.Fatalf("ListResources: %v", err)
+	}
+	uris := make(map[string]bool)
+	for _, r := range resources.Resources {
+		uris[r.URI] = true
+	}
+	for _, uri := range []string{skillsURI, skillURI("greeter"), cronJobsURI, browserSnapshotURI, fileURI(root)} {
+		if !uris[uri] {
+			t.Errorf("resource %s not listed", uri)
+		}
+	}
+
+	if text := readText(t, c, skillURI("greeter")); !strings.Contains(text, "Say hello warmly.") {
+		t.Errorf("skill resource = %q", text)
+	}
+	promptReq := mcp.GetPromptRequest{}
+	promptReq.Params.Name = "greeter"
+	promptReq.Params.Arguments = map[string]string{"task": "greet Ada"}
+	prompt, err := c.GetPrompt(ctx, promptReq)
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if text := prompt.Messages[0].Content.(mcp.TextContent).Text; !strings.Contains(text, "Say hello warmly.") || !strings.Contains(text, "greet Ada") {
+		t.Errorf("prompt = %q", text)
+	}
+
+	job, err := s.cronScheduler.CreateJob(&cronpkg.Job{Name: "disk", Schedule: "0 9 * * *", Tool: "disk_usage"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var detail struct {
+		Job  struct{ Name string }
+		Runs []any
+	}
+	if err := json.Unmarshal([]byte(readText(t, c, cronJobURI(job.ID))), &detail); err != nil || detail.Job.Name != "disk" {
+		t.Errorf("job resource = %+v, %v", detail, err)
+	}
+
+	if listing := readText(t, c, fileURI(root)); !strings.Contains(listing, "notes.txt\t"+fileURI(filepath.Join(root, "notes.txt"))) {
+		t.Errorf("root listing = %q", listing)
+	}
+	if text := readText(t, c, fileURI(filepath.Join(root, "notes.txt"))); text != "hello" {
+		t.Errorf("file resource = %q", text)
+	}
+	outside := mcp.ReadResourceRequest{}
+	outside.Params.URI = fileURI(os.Getenv("HOME"))
+	if _, err := c.ReadResource(ctx, outside); err == nil {
+		t.Error("read a file outside the allowed roots")
+	}
+
+	// Subscribed files report changes
+	sub := mcp.SubscribeRequest{}
+	sub.Params.URI = fileURI(filepath.Join(root, "notes.txt"))
+	if err := c.Subscribe(ctx, sub); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	os.WriteFile(filepath.Join(root, "notes.txt"), []byte("changed"), 0644)
+	// Updates are streamed on the next request's response
+	deadline := time.After(5 * time.Second)
+	for got := false; !got; {
+		c.Ping(ctx)
+		select {
+		case uri := <-updates:
+			got = uri == sub.Params.URI
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no resources/updated notification for a subscribed file")
+		}
+	}
+	bad := mcp.SubscribeRequest{}
+	bad.Params.URI = browserScreenshotURI
+	if err := c.Subscribe(ctx, bad); err == nil {
+		t.Error("subscribed to a resource that doesn't report updates")
+	}
+}
+
+func TestSkillWatchNotifies(t *testing.T) {
+	s := newTestServer(t)
+	skillFile := filepath.Join(os.Getenv("HOME"), ".lingti", "skills", "greeter", "SKILL.md")
+	os.MkdirAll(filepath.Dir(skillFile), 0755)
+	os.WriteFile(skillFile, []byte("---\nname: greeter\ndescription: Greets people\n---\nSay hello."), 0644)
+	s.skillCache.Refresh()
+	s.Start()
+
+	handler, closeSessions := s.httpHandler(TransportHTTP, HTTPOptions{})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	defer closeSessions()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, _ := client.NewStreamableHttpClient(ts.URL + "/mcp")
+	if err := initialize(ctx, c); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	updates := make(chan string, 10)
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == mcp.MethodNotificationResourceUpdated {
+			updates <- n.Params.AdditionalFields["uri"].(string)
+		}
+	})
+	sub := mcp.SubscribeRequest{}
+	sub.Params.URI = skillURI("greeter")
+	if err := c.Subscribe(ctx, sub); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	os.WriteFile(skillFile, []byte("---\nname: greeter\ndescription: Greets people by name\n---\nSay hello warmly."), 0644)
+	deadline := time.After(5 * time.Second)
+	for got := false; !got; {
+		c.Ping(ctx)
+		select {
+		case uri := <-updates:
+			got = uri == sub.Params.URI
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no resources/updated notification for a changed skill")
+		}
+	}
+	if text := readText(t, c, skillURI("greeter")); !strings.Contains(text, "Say hello warmly.") {
+		t.Errorf("skill resource after change = %q", text)
+	}
+}
+
+func TestServeStdioSubscriptions(t *testing.T) {
+	root := t.TempDir()
+	s := newTestServer(t, SecurityOptions{AllowedPaths: []string{root}})
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.serveStdio(ctx, stdinR, stdoutW)
+
+	out := bufio.NewScanner(stdoutR)
+	send := func(line string) map[string]any {
+		t.Helper()
+		io.WriteString(stdinW, line+"\n")
+		if !out.Scan() {
+			t.Fatal("no response")
+		}
+		var response map[string]any
+		json.Unmarshal(out.Bytes(), &response)
+		return response
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1"}}}`)
+	if r := send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"` + fileURI(root) + `"}}`); r["error"] != nil {
+		t.Errorf("subscribe = %v", r)
+	}
+	if r := send(`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"file:///etc"}}`); r["error"] == nil {
+		t.Errorf("subscribe outside the roots = %v", r)
+	}
+	if r := send(`{"jsonrpc":"2.0","id":4,"method":"ping"}`); r["result"] == nil {
+		t.Errorf("ping = %v", r)
+	}
+	stdinW.Close()
+}
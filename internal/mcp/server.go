@@ -6,11 +6,18 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/pltanton/lingti-bot/internal/browser"
+	"github.com/pltanton/lingti-bot/internal/config"
 	cronpkg "github.com/pltanton/lingti-bot/internal/cron"
+	"github.com/pltanton/lingti-bot/internal/router"
 	"github.com/pltanton/lingti-bot/internal/security"
+	"github.com/pltanton/lingti-bot/internal/sites"
+	"github.com/pltanton/lingti-bot/internal/skills"
 	"github.com/pltanton/lingti-bot/internal/tools"
 )
 
@@ -29,6 +36,15 @@ type Server struct {
 	toolHandlers  map[string]ToolHandler
 	pathChecker      *security.PathChecker
 	disableFileTools bool
+	startOnce        sync.Once
+	stopWatch        context.CancelFunc // stops the skills watcher started by Start
+
+	skillCache     *skills.Cache
+	skillsMu       sync.Mutex
+	skillResources map[string]string // published skill → description
+	subs           *subscriptions
+	agent          AgentRunner // set by SetAgent; backs the ask_lingti tool
+	messenger      Messenger   // set by SetMessenger; backs send_message and cron notifications
 }
 
 // SecurityOptions holds security settings for the MCP server.
@@ -44,15 +60,30 @@ func NewServer(opts ...SecurityOptions) *Server {
 		opt = opts[0]
 	}
 	s := &Server{
-		mcpServer: server.NewMCPServer(ServerName, ServerVersion,
-			server.WithResourceCapabilities(true, true),
-			server.WithPromptCapabilities(true),
-			server.WithToolCapabilities(true),
-		),
 		toolHandlers:     make(map[string]ToolHandler),
 		pathChecker:      security.NewPathChecker(opt.AllowedPaths),
 		disableFileTools: opt.DisableFileTools,
+		skillCache:       skills.NewCache(config.SkillsSettings),
+		skillResources:   make(map[string]string),
 	}
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		log.Printf("[MCP] Client session %s started", session.SessionID())
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.subs.dropSession(session.SessionID())
+		browser.Sessions().Close("mcp:" + session.SessionID())
+		log.Printf("[MCP] Client session %s closed", session.SessionID())
+	})
+	s.mcpServer = server.NewMCPServer(ServerName, ServerVersion,
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
+		server.WithToolCapabilities(true),
+		server.WithHooks(hooks),
+	)
+	s.subs = newSubscriptions(func(sessionID, uri string) {
+		s.mcpServer.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+	})
 
 	// Register all tools
 	registerFilesystemTools(s)
@@ -77,6 +108,10 @@ func NewServer(opts ...SecurityOptions) *Server {
 		cronStore, _ = cronpkg.NewStore(filepath.Join(os.TempDir(), "lingti.db"))
 	}
 	s.cronScheduler = cronpkg.NewScheduler(cronStore, s, nil, s)
+	s.cronScheduler.SetFileLimits(opt.AllowedPaths, opt.DisableFileTools)
+	if cfg, err := config.Load(); err == nil {
+		s.cronScheduler.SetWebhookHosts(cfg.Cron.WebhookHosts)
+	}
 
 	// Register cron tools
 	registerCronTools(s)
@@ -84,14 +119,56 @@ func NewServer(opts ...SecurityOptions) *Server {
 	// Set global scheduler for cron tools
 	SetCronScheduler(s.cronScheduler)
 
-	// Start the scheduler
-	if err := s.cronScheduler.Start(); err != nil {
-		log.Printf("[CRON] Warning: Failed to start cron scheduler: %v", err)
-	}
+	// Publish skills, jobs, the browser page and allowed roots as resources
+	registerResources(s)
+	s.cronScheduler.OnRun(func(job *cronpkg.Job, _ cronpkg.Run) {
+		s.subs.updated(cronJobURI(job.ID))
+		s.subs.updated(cronJobsURI)
+	})
 
 	return s
 }
 
+// Start starts the cron scheduler and the skills watcher. Only the first
+// call has an effect, so jobs run once however many clients share the server.
+func (s *Server) Start() {
+	s.startOnce.Do(func() {
+		if err := s.cronScheduler.Start(); err != nil {
+			log.Printf("[CRON] Warning: Failed to start cron scheduler: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopWatch = cancel
+		if err := s.skillCache.Watch(ctx); err != nil {
+			log.Printf("[Skills] Warning: skills will not reload automatically: %v", err)
+			return
+		}
+		events, unsubscribe := s.skillCache.Subscribe()
+		go s.publishSkillChanges(ctx, events, unsubscribe)
+	})
+}
+
+// publishSkillChanges updates the skill resources and prompts and notifies
+// subscribers when the skills cache reports changes, until ctx is done
+func (s *Server) publishSkillChanges(ctx context.Context, events <-chan skills.ChangeEvent, unsubscribe func()) {
+	defer unsubscribe()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			s.syncSkills(s.skillCache.Snapshot().Report)
+			for _, change := range event.Changes {
+				s.subs.updated(skillURI(change.Name))
+			}
+			s.subs.updated(skillsURI)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // GetMCPServer returns the underlying MCP server
 func (s *Server) GetMCPServer() *server.MCPServer {
 	return s.mcpServer
@@ -99,6 +176,10 @@ func (s *Server) GetMCPServer() *server.MCPServer {
 
 // Stop gracefully stops the server
 func (s *Server) Stop() error {
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
+	s.subs.close()
 	if s.cronScheduler != nil {
 		return s.cronScheduler.Stop()
 	}
@@ -141,9 +222,12 @@ func (s *Server) NotifyChat(message string) error {
 
 // NotifyChatUser implements the ChatNotifier interface for the cron scheduler
 func (s *Server) NotifyChatUser(platform, channelID, userID, message string) error {
-	// In MCP mode, there's no router to send messages through, so just log
-	log.Printf("[CRON] Notification to %s/%s: %s", platform, channelID, message)
-	return nil
+	if s.messenger == nil {
+		// Without attached platforms or a gateway there's nowhere to send to
+		log.Printf("[CRON] Notification to %s/%s: %s", platform, channelID, message)
+		return nil
+	}
+	return s.messenger.SendToUser(platform, channelID, router.Response{Text: message})
 }
 
 // pathCheckedTools maps tool names to the argument key containing a file path.
@@ -172,11 +256,22 @@ func (s *Server) addTool(tool mcp.Tool, handler ToolHandler) {
 		wrappedHandler = s.wrapPathCheck("working_directory", handler)
 	}
 	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return wrappedHandler(ctx, req)
+		return wrappedHandler(browser.WithSession(ctx, browserSessionKey(ctx)), req)
 	})
 	s.toolHandlers[tool.Name] = handler
 }
 
+// browserSessionKey returns the browser session of the client calling a
+// tool. Unless browser.session_scope is global each HTTP client gets its
+// own; the single stdio client always uses the shared browser and its logins.
+func browserSessionKey(ctx context.Context) string {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil || session.SessionID() == stdioSessionID {
+		return ""
+	}
+	return browser.Sessions().Key("mcp:"+session.SessionID(), "")
+}
+
 func (s *Server) wrapPathCheck(argKey string, handler ToolHandler) ToolHandler {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		if s.pathChecker.HasRestrictions() {
@@ -450,6 +545,13 @@ func registerBrowserTools(s *Server) {
 		mcp.WithString("script", mcp.Required(), mcp.Description("JavaScript code to execute as function body (use 'return' to get values back)")),
 	), tools.BrowserExecuteJS)
 
+	// browser_site_action
+	s.addTool(mcp.NewTool("browser_site_action",
+		mcp.WithDescription("Run a site adapter action on the current page. Available: "+strings.Join(sites.Default().ActionList(), ", ")),
+		mcp.WithString("action", mcp.Required(), mcp.Description("<site>.<action>, e.g. zhihu.comment")),
+		mcp.WithObject("args", mcp.Description("The action's arguments, e.g. {\"comment\": \"...\"}")),
+	), tools.BrowserSiteAction)
+
 	// browser_tabs
 	s.addTool(mcp.NewTool("browser_tabs",
 		mcp.WithDescription("List all open browser tabs with their target IDs and URLs"),
@@ -0,0 +1,363 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	sessionHeader          = "Mcp-Session-Id"
+	defaultIdleTimeout     = 30 * time.Minute
+	streamKeepAlive        = 30 * time.Second
+	maxMessageSize         = 4 << 20
+	sessionNotificationBuf = 100
+)
+
+// httpSession is one Streamable HTTP client. Notifications the server
+// sends it are queued until an SSE stream of the session picks them up: the
+// response stream of a request in flight or the stream opened with GET.
+type httpSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+	lastActive    atomic.Int64 // unix nanoseconds of the last request
+	streams       atomic.Int32 // open SSE streams
+	getStream     atomic.Bool  // a GET stream is open
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func (s *httpSession) SessionID() string { return s.id }
+
+func (s *httpSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *httpSession) Initialize() { s.initialized.Store(true) }
+
+func (s *httpSession) Initialized() bool { return s.initialized.Load() }
+
+func (s *httpSession) touch() { s.lastActive.Store(time.Now().UnixNano()) }
+
+// idle reports whether the session has no open streams and no request since cutoff
+func (s *httpSession) idle(cutoff time.Time) bool {
+	return s.streams.Load() == 0 && s.lastActive.Load() < cutoff.UnixNano()
+}
+
+func (s *httpSession) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// streamableHandler serves the MCP Streamable HTTP transport on one
+// endpoint: POST sends a message (initialize starts a session), GET opens
+// a stream for server notifications, and DELETE ends the session.
+type streamableHandler struct {
+	server      *server.MCPServer
+	handle      func(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage // defaults to server.HandleMessage
+	idleTimeout time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+	reaper   sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newStreamableHandler(s *server.MCPServer, idleTimeout time.Duration) *streamableHandler {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+	return &streamableHandler{
+		server:      s,
+		handle:      s.HandleMessage,
+		idleTimeout: idleTimeout,
+		sessions:    make(map[string]*httpSession),
+		stop:        make(chan struct{}),
+	}
+}
+
+func (h *streamableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.reaper.Do(func() { go h.reapIdle() })
+
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodGet:
+		h.handleGet(w, r)
+	case http.MethodDelete:
+		session, ok := h.session(w, r)
+		if !ok {
+			return
+		}
+		h.endSession(session, "closed by client")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *streamableHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.PARSE_ERROR, "failed to read request body")
+		return
+	}
+	var message struct {
+		ID     any           `json:"id"`
+		Method mcp.MCPMethod `json:"method"`
+	}
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.INVALID_REQUEST, "batched messages are not supported")
+		return
+	}
+	if err := json.Unmarshal(body, &message); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.PARSE_ERROR, "parse error")
+		return
+	}
+
+	var session *httpSession
+	if message.Method == mcp.MethodInitialize {
+		if session, err = h.newSession(r.Context()); err != nil {
+			writeJSONRPCError(w, http.StatusInternalServerError, message.ID, mcp.INTERNAL_ERROR, err.Error())
+			return
+		}
+		w.Header().Set(sessionHeader, session.id)
+	} else {
+		var ok bool
+		if session, ok = h.session(w, r); !ok {
+			return
+		}
+	}
+	session.touch()
+	ctx := h.server.WithContext(r.Context(), session)
+
+	// Notifications and responses to server requests get no reply
+	if message.ID == nil || message.Method == "" {
+		h.handle(ctx, body)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		response := h.handle(ctx, body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// Stream the notifications sent while handling the request, then the response
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONRPCError(w, http.StatusInternalServerError, message.ID, mcp.INTERNAL_ERROR, "streaming unsupported")
+		return
+	}
+	session.streams.Add(1)
+	defer session.streams.Add(-1)
+	responses := make(chan mcp.JSONRPCMessage, 1)
+	go func() { responses <- h.handle(ctx, body) }()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case n := <-session.notifications:
+			writeEvent(w, n)
+			flusher.Flush()
+		case response := <-responses:
+			// Deliver what was sent before the response first
+			for len(session.notifications) > 0 {
+				writeEvent(w, <-session.notifications)
+			}
+			writeEvent(w, response)
+			flusher.Flush()
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleGet opens the session's stream for notifications sent outside of requests
+func (h *streamableHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "Accept must include text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	if !session.getStream.CompareAndSwap(false, true) {
+		http.Error(w, "A stream is already open for this session", http.StatusConflict)
+		return
+	}
+	session.streams.Add(1)
+	defer func() {
+		session.streams.Add(-1)
+		session.getStream.Store(false)
+		session.touch()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case n := <-session.notifications:
+			writeEvent(w, n)
+			flusher.Flush()
+		case <-ticker.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-session.done:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// session looks up the session named by the request's Mcp-Session-Id
+// header, writing an error response if there is none
+func (h *streamableHandler) session(w http.ResponseWriter, r *http.Request) (*httpSession, bool) {
+	id := r.Header.Get(sessionHeader)
+	if id == "" {
+		http.Error(w, "Missing "+sessionHeader+" header; send initialize first", http.StatusBadRequest)
+		return nil, false
+	}
+	h.mu.Lock()
+	session, ok := h.sessions[id]
+	h.mu.Unlock()
+	if !ok {
+		// 404 tells the client to start a new session
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, false
+	}
+	return session, true
+}
+
+func (h *streamableHandler) newSession(ctx context.Context) (*httpSession, error) {
+	session := &httpSession{
+		id:            uuid.New().String(),
+		notifications: make(chan mcp.JSONRPCNotification, sessionNotificationBuf),
+		done:          make(chan struct{}),
+	}
+	if err := h.server.RegisterSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("session registration failed: %w", err)
+	}
+	h.mu.Lock()
+	h.sessions[session.id] = session
+	h.mu.Unlock()
+	return session, nil
+}
+
+func (h *streamableHandler) endSession(session *httpSession, reason string) {
+	h.mu.Lock()
+	_, ok := h.sessions[session.id]
+	delete(h.sessions, session.id)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+	session.close()
+	h.server.UnregisterSession(context.Background(), session.id)
+	log.Printf("[MCP] Session %s ended: %s", session.id, reason)
+}
+
+// reapIdle ends sessions whose client went away without DELETE
+func (h *streamableHandler) reapIdle() {
+	ticker := time.NewTicker(min(h.idleTimeout/2, time.Minute))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cutoff := time.Now().Add(-h.idleTimeout)
+			h.mu.Lock()
+			var idle []*httpSession
+			for _, session := range h.sessions {
+				if session.idle(cutoff) {
+					idle = append(idle, session)
+				}
+			}
+			h.mu.Unlock()
+			for _, session := range idle {
+				h.endSession(session, "idle")
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// closeAll ends every session and stops the idle reaper
+func (h *streamableHandler) closeAll() {
+	h.stopOnce.Do(func() { close(h.stop) })
+	h.mu.Lock()
+	sessions := make([]*httpSession, 0, len(h.sessions))
+	for _, session := range h.sessions {
+		sessions = append(sessions, session)
+	}
+	h.mu.Unlock()
+	for _, session := range sessions {
+		h.endSession(session, "server shutting down")
+	}
+}
+
+// sessionCount returns the number of open sessions
+func (h *streamableHandler) sessionCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.sessions)
+}
+
+func writeEvent(w io.Writer, message any) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[MCP] Failed to marshal message: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+}
+
+func writeJSONRPCError(w http.ResponseWriter, status int, id any, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(newJSONRPCError(id, code, message))
+}
+
+func newJSONRPCError(id any, code int, message string) mcp.JSONRPCError {
+	return mcp.JSONRPCError{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Error: struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Data    any    `json:"data,omitempty"`
+		}{Code: code, Message: message},
+	}
+}
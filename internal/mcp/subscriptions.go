@@ -0,0 +1,332 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioSessionID is the ID mcp-go gives the single stdio client
+const stdioSessionID = "stdio"
+
+// Subscription methods, which mcp-go v0.27 doesn't define
+const (
+	methodResourcesSubscribe   mcp.MCPMethod = "resources/subscribe"
+	methodResourcesUnsubscribe mcp.MCPMethod = "resources/unsubscribe"
+)
+
+// subscriptions tracks which sessions subscribed to which resources and
+// watches the files behind subscribed file:// URIs. mcp-go doesn't handle
+// resources/subscribe, so the transports route those requests here.
+type subscriptions struct {
+	notify func(sessionID, uri string)
+
+	mu       sync.Mutex
+	sessions map[string]map[string]bool // uri → subscribed session IDs
+	watcher  *fsnotify.Watcher          // created on the first file subscription
+	watched  map[string]int             // watched directory → number of subscribed URIs in it
+	paths    map[string]string          // subscribed file:// URI → local path
+}
+
+func newSubscriptions(notify func(sessionID, uri string)) *subscriptions {
+	return &subscriptions{
+		notify:   notify,
+		sessions: make(map[string]map[string]bool),
+		watched:  make(map[string]int),
+		paths:    make(map[string]string),
+	}
+}
+
+// subscribe adds a session's subscription. path is the local file behind a
+// file:// URI, or "" for other resources.
+func (sub *subscriptions) subscribe(sessionID, uri, path string) error {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if sub.sessions[uri][sessionID] {
+		return nil
+	}
+	if path != "" && len(sub.sessions[uri]) == 0 {
+		if err := sub.watch(path); err != nil {
+			return err
+		}
+		sub.paths[uri] = path
+	}
+	if sub.sessions[uri] == nil {
+		sub.sessions[uri] = make(map[string]bool)
+	}
+	sub.sessions[uri][sessionID] = true
+	return nil
+}
+
+// unsubscribeLocked removes a session's subscription; the caller holds sub.mu
+func (sub *subscriptions) unsubscribeLocked(sessionID, uri string) {
+	if !sub.sessions[uri][sessionID] {
+		return
+	}
+	delete(sub.sessions[uri], sessionID)
+	if len(sub.sessions[uri]) > 0 {
+		return
+	}
+	delete(sub.sessions, uri)
+	if path, ok := sub.paths[uri]; ok {
+		delete(sub.paths, uri)
+		sub.unwatch(path)
+	}
+}
+
+func (sub *subscriptions) unsubscribe(sessionID, uri string) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	sub.unsubscribeLocked(sessionID, uri)
+}
+
+// dropSession removes every subscription of a session that ended
+func (sub *subscriptions) dropSession(sessionID string) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	for uri := range sub.sessions {
+		sub.unsubscribeLocked(sessionID, uri)
+	}
+}
+
+// updated tells the sessions subscribed to uri that it changed
+func (sub *subscriptions) updated(uri string) {
+	sub.mu.Lock()
+	sessionIDs := make([]string, 0, len(sub.sessions[uri]))
+	for id := range sub.sessions[uri] {
+		sessionIDs = append(sessionIDs, id)
+	}
+	sub.mu.Unlock()
+	for _, id := range sessionIDs {
+		sub.notify(id, uri)
+	}
+}
+
+// watch starts watching the directory of path (path itself if it is a
+// directory). Watching the parent keeps file subscriptions working when
+// editors replace a file instead of writing it in place.
+func (sub *subscriptions) watch(path string) error {
+	dir := watchDir(path)
+	if sub.watched[dir] > 0 {
+		sub.watched[dir]++
+		return nil
+	}
+	if sub.watcher == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("failed to create file watcher: %w", err)
+		}
+		sub.watcher = watcher
+		go sub.run(watcher)
+	}
+	if err := sub.watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+	sub.watched[dir] = 1
+	return nil
+}
+
+func (sub *subscriptions) unwatch(path string) {
+	dir := watchDir(path)
+	if sub.watched[dir]--; sub.watched[dir] > 0 {
+		return
+	}
+	delete(sub.watched, dir)
+	if sub.watcher != nil {
+		sub.watcher.Remove(dir)
+	}
+}
+
+func watchDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// run reports changes to watched files and directories until the watcher closes
+func (sub *subscriptions) run(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			// A change to a file also changes the listing of its directory
+			sub.updated(fileURI(event.Name))
+			sub.updated(fileURI(filepath.Dir(event.Name)))
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("[MCP] File watcher error: %v", err)
+		}
+	}
+}
+
+// close stops the file watcher
+func (sub *subscriptions) close() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.watcher != nil {
+		sub.watcher.Close()
+		sub.watcher = nil
+		sub.watched = make(map[string]int)
+	}
+}
+
+// handleSubscription answers resources/subscribe and resources/unsubscribe
+// requests. ok is false for any other message, which goes to mcp-go.
+func (s *Server) handleSubscription(sessionID string, message []byte) (response mcp.JSONRPCMessage, ok bool) {
+	var request struct {
+		ID     any           `json:"id"`
+		Method mcp.MCPMethod `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || request.ID == nil {
+		return nil, false
+	}
+	switch request.Method {
+	case methodResourcesSubscribe:
+		path, err := s.subscribable(request.Params.URI)
+		if err == nil {
+			err = s.subs.subscribe(sessionID, request.Params.URI, path)
+		}
+		if err != nil {
+			return newJSONRPCError(request.ID, mcp.INVALID_PARAMS, err.Error()), true
+		}
+	case methodResourcesUnsubscribe:
+		s.subs.unsubscribe(sessionID, request.Params.URI)
+	default:
+		return nil, false
+	}
+	return mcp.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: request.ID, Result: mcp.EmptyResult{}}, true
+}
+
+// subscribable checks that uri names a resource that reports updates and
+// returns the local path of file:// URIs
+func (s *Server) subscribable(uri string) (string, error) {
+	switch {
+	case strings.HasPrefix(uri, "file:"):
+		path, err := s.rootPath(uri)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	case uri == skillsURI, strings.HasPrefix(uri, skillsURI+"/"),
+		uri == cronJobsURI, strings.HasPrefix(uri, cronJobsURI+"/"):
+		return "", nil
+	}
+	return "", fmt.Errorf("resource %q does not support subscriptions", uri)
+}
+
+// handleMessage is mcp-go's HandleMessage plus resource subscriptions, for
+// transports that dispatch messages themselves
+func (s *Server) handleMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		if response, ok := s.handleSubscription(session.SessionID(), message); ok {
+			return response
+		}
+	}
+	return s.mcpServer.HandleMessage(ctx, message)
+}
+
+// serveStdio serves the stdio transport, answering subscription requests
+// before the rest reach mcp-go's stdio server
+func (s *Server) serveStdio(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	out := &lockedWriter{w: stdout}
+	in, pipe := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(stdin)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if response, ok := s.handleSubscription(stdioSessionID, line); ok {
+					writeLine(out, response)
+				} else if _, err := pipe.Write(line); err != nil {
+					return
+				}
+			}
+			if err != nil {
+				pipe.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	stdio := server.NewStdioServer(s.mcpServer)
+	stdio.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+	if err := stdio.Listen(ctx, in, out); err != nil && err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+// interceptSSE answers subscription requests posted to the SSE transport's
+// message endpoint on the session's stream, and passes everything else on
+func (s *Server) interceptSSE(sse *server.SSEServer) http.Handler {
+	messagePath := sse.CompleteMessagePath()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != messagePath {
+			sse.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+		if err != nil {
+			writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.PARSE_ERROR, "failed to read request body")
+			return
+		}
+		sessionID := r.URL.Query().Get("sessionId")
+		if response, ok := s.handleSubscription(sessionID, body); ok {
+			if err := sse.SendEventToSession(sessionID, response); err != nil {
+				s.subs.dropSession(sessionID)
+				writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.INVALID_PARAMS, err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		sse.ServeHTTP(w, r)
+	})
+}
+
+// lockedWriter serializes writes of responses and notifications to stdout
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func writeLine(w io.Writer, message any) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("[MCP] Failed to marshal message: %v", err)
+		return
+	}
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pltanton/lingti-bot/internal/config"
+)
+
+// Transports `lingti-bot serve` can listen on
+const (
+	TransportStdio = "stdio" // one client over stdin/stdout
+	TransportSSE   = "sse"   // HTTP+SSE: GET /sse opens a stream, POST /message?sessionId= sends messages
+	TransportHTTP  = "http"  // Streamable HTTP: POST, GET and DELETE on /mcp
+)
+
+// DefaultPort is the port of the HTTP transports when none is configured
+const DefaultPort = 8686
+
+// shutdownTimeout bounds how long in-flight requests may take after the
+// server is asked to stop
+const shutdownTimeout = 5 * time.Second
+
+// HTTPOptions configures the SSE and Streamable HTTP transports
+type HTTPOptions struct {
+	Addr        string        // listen address, e.g. 127.0.0.1:8686
+	Token       string        // bearer token clients must send; empty disables authentication
+	IdleTimeout time.Duration // Streamable HTTP sessions without requests or open streams for this long are closed (default 30m)
+}
+
+// Serve starts the cron scheduler and serves MCP clients on a transport
+// until ctx is done. All clients share this server, so jobs are scheduled
+// once however many clients connect.
+func (s *Server) Serve(ctx context.Context, transport string, opts HTTPOptions) error {
+	switch transport {
+	case "", TransportStdio:
+		s.Start()
+		return s.serveStdio(ctx, os.Stdin, os.Stdout)
+	case TransportSSE, TransportHTTP:
+		if err := checkListenAuth(opts.Addr, opts.Token); err != nil {
+			return err
+		}
+		s.Start()
+		handler, closeSessions := s.httpHandler(transport, opts)
+		srv := &http.Server{Addr: opts.Addr, Handler: checkOrigin(opts.Addr, requireBearer(opts.Token, handler))}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- srv.ListenAndServe() }()
+		log.Printf("[MCP] Serving %s transport on %s", transport, opts.Addr)
+
+		select {
+		case err := <-errCh:
+			closeSessions()
+			return err
+		case <-ctx.Done():
+		}
+		// Streams never finish on their own, so end the sessions before
+		// waiting for in-flight requests
+		closeSessions()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown transport %q (want %s, %s or %s)", transport, TransportStdio, TransportSSE, TransportHTTP)
+}
+
+// httpHandler returns the handler of an HTTP transport and a function that
+// ends its sessions
+func (s *Server) httpHandler(transport string, opts HTTPOptions) (http.Handler, func()) {
+	if transport == TransportSSE {
+		sse := server.NewSSEServer(s.mcpServer, server.WithKeepAlive(true))
+		return s.interceptSSE(sse), func() { sse.Shutdown(context.Background()) }
+	}
+	h := newStreamableHandler(s.mcpServer, opts.IdleTimeout)
+	h.handle = s.handleMessage
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", h)
+	return mux, h.closeAll
+}
+
+// checkListenAuth refuses to expose the tools beyond this machine without a token
+func checkListenAuth(addr, token string) error {
+	if token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if isLoopbackHost(host) {
+		return nil
+	}
+	return fmt.Errorf("refusing to listen on %s without a token: set one or bind to 127.0.0.1", addr)
+}
+
+// checkOrigin keeps web pages away from the tools, as the MCP transport
+// security guidance asks: requests from a non-local Origin are refused and
+// messages must be application/json, which browsers can't send cross-origin
+// without a preflight. On a loopback address the Host must be local too, so
+// DNS rebinding can't reach the server.
+func checkOrigin(addr string, next http.Handler) http.Handler {
+	host, _, _ := net.SplitHostPort(addr)
+	loopback := isLoopbackHost(host)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !isLoopbackHost(u.Hostname()) {
+				http.Error(w, "Forbidden origin", http.StatusForbidden)
+				return
+			}
+		}
+		if loopback {
+			reqHost := r.Host
+			if h, _, err := net.SplitHostPort(reqHost); err == nil {
+				reqHost = h
+			}
+			if !isLoopbackHost(reqHost) {
+				http.Error(w, "Forbidden host", http.StatusForbidden)
+				return
+			}
+		}
+		if r.Method == http.MethodPost {
+			if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+				http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP
+func isLoopbackHost(host string) bool {
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// TokenPath is where the generated token of the HTTP transports is kept
+func TokenPath() string {
+	return filepath.Join(config.ConfigDir(), "mcp-serve-token")
+}
+
+// LoadOrCreateToken returns the token saved at path, generating and saving
+// a random one the first time
+func LoadOrCreateToken(path string) (string, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// requireBearer rejects requests without "Authorization: Bearer <token>".
+// An empty token allows every request.
+func requireBearer(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="lingti-bot"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
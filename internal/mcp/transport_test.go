@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newTestServer(t *testing.T, opts ...SecurityOptions) *Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	s := NewServer(opts...)
+	t.Cleanup(func() { s.Stop() })
+	// A tool that reports progress while it runs
+	s.mcpServer.AddTool(mcp.NewTool("slow_echo", mcp.WithString("text")), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		server.ServerFromContext(ctx).SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progress": 1})
+		text, _ := req.Params.Arguments["text"].(string)
+		return mcp.NewToolResultText(text), nil
+	})
+	return s
+}
+
+func initialize(ctx context.Context, c *client.Client) error {
+	if err := c.Start(ctx); err != nil {
+		return err
+	}
+	req := mcp.InitializeRequest{}
+	req.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	req.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0"}
+	_, err := c.Initialize(ctx, req)
+	return err
+}
+
+func TestStreamableHTTP(t *testing.T) {
+	s := newTestServer(t)
+	handler := newStreamableHandler(s.mcpServer, 0)
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+	ts := httptest.NewServer(checkOrigin("127.0.0.1:0", requireBearer("s3cret", mux)))
+	defer ts.Close()
+	defer handler.closeAll()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	anonymous, _ := client.NewStreamableHttpClient(ts.URL + "/mcp")
+	if err := initialize(ctx, anonymous); err == nil {
+		t.Error("initialize without a token succeeded")
+	}
+
+	var clients []*client.Client
+	for range 2 {
+		c, err := client.NewStreamableHttpClient(ts.URL+"/mcp", transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer s3cret"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := initialize(ctx, c); err != nil {
+			t.Fatalf("Initialize: %v", err)
+		}
+		clients = append(clients, c)
+	}
+	if n := handler.sessionCount(); n != 2 {
+		t.Fatalf("sessions = %d, want 2", n)
+	}
+
+	progress := make(chan mcp.JSONRPCNotification, 1)
+	clients[0].OnNotification(func(n mcp.JSONRPCNotification) { progress <- n })
+	tools, err := clients[0].ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil || len(tools.Tools) == 0 {
+		t.Fatalf("ListTools = %v, %v", tools, err)
+	}
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "slow_echo"
+	req.Params.Arguments = map[string]any{"text": "hi"}
+	result, err := clients[0].CallTool(ctx, req)
+	if err != nil || result.Content[0].(mcp.TextContent).Text != "hi" {
+		t.Fatalf("CallTool = %+v, %v", result, err)
+	}
+	select {
+	case n := <-progress:
+		if n.Method != "notifications/progress" {
+			t.Errorf("notification = %+v", n)
+		}
+	default:
+		t.Error("progress notification not streamed with the response")
+	}
+
+	// DELETE ends a session, after which its requests get 404 so the client re-initializes
+	id := clients[1].GetTransport().(*transport.StreamableHTTP).GetSessionId()
+	send := func(method, body string) int {
+		httpReq, _ := http.NewRequest(method, ts.URL+"/mcp", strings.NewReader(body))
+		httpReq.Header.Set("Authorization", "Bearer s3cret")
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set(sessionHeader, id)
+		resp, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := send(http.MethodDelete, ""); status != http.StatusNoContent {
+		t.Errorf("DELETE = %d", status)
+	}
+	if n := handler.sessionCount(); n != 1 {
+		t.Errorf("sessions after DELETE = %d, want 1", n)
+	}
+	if status := send(http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"ping"}`); status != http.StatusNotFound {
+		t.Errorf("request for an ended session = %d, want 404", status)
+	}
+}
+
+func TestStreamableHTTPIdleSessions(t *testing.T) {
+	s := newTestServer(t)
+	handler := newStreamableHandler(s.mcpServer, 50*time.Millisecond)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	defer handler.closeAll()
+
+	c, _ := client.NewStreamableHttpClient(ts.URL)
+	if err := initialize(context.Background(), c); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for handler.sessionCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := handler.sessionCount(); n != 0 {
+		t.Errorf("idle session not closed: %d sessions", n)
+	}
+}
+
+func TestSSETransport(t *testing.T) {
+	s := newTestServer(t)
+	handler, closeSessions := s.httpHandler(TransportSSE, HTTPOptions{})
+	ts := httptest.NewServer(checkOrigin("127.0.0.1:0", requireBearer("s3cret", handler)))
+	defer ts.Close()
+	defer closeSessions()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, err := client.NewSSEMCPClient(ts.URL+"/sse", client.WithHeaders(map[string]string{"Authorization": "Bearer s3cret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := initialize(ctx, c); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if tools, err := c.ListTools(ctx, mcp.ListToolsRequest{}); err != nil || len(tools.Tools) == 0 {
+		t.Errorf("ListTools = %v, %v", tools, err)
+	}
+	// Subscriptions are answered on the session's stream
+	sub := mcp.SubscribeRequest{}
+	sub.Params.URI = skillsURI
+	if err := c.Subscribe(ctx, sub); err != nil {
+		t.Errorf("Subscribe: %v", err)
+	}
+}
+
+func TestCheckListenAuth(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"127.0.0.1:8686": true,
+		"[::1]:8686":     true,
+		"localhost:8686": true,
+		"0.0.0.0:8686":   false,
+		":8686":          false,
+	} {
+		if err := checkListenAuth(addr, ""); (err == nil) != ok {
+			t.Errorf("checkListenAuth(%q) = %v", addr, err)
+		}
+		if err := checkListenAuth(addr, "token"); err != nil {
+			t.Errorf("checkListenAuth(%q) with token = %v", addr, err)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name, addr, host, origin, contentType string
+		want                                  int
+	}{
+		{"local client", "127.0.0.1:8686", "127.0.0.1:8686", "", "application/json", http.StatusOK},
+		{"local page", "127.0.0.1:8686", "localhost:8686", "http://localhost:3000", "application/json", http.StatusOK},
+		{"other origin", "127.0.0.1:8686", "127.0.0.1:8686", "https://evil.example", "application/json", http.StatusForbidden},
+		{"DNS rebinding", "127.0.0.1:8686", "evil.example:8686", "", "application/json", http.StatusForbidden},
+		{"simple POST", "127.0.0.1:8686", "127.0.0.1:8686", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"public host", "0.0.0.0:8686", "bot.example.com", "", "application/json", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("{}"))
+		req.Host = tt.host
+		req.Header.Set("Content-Type", tt.contentType)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		w := httptest.NewRecorder()
+		checkOrigin(tt.addr, ok).ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: HTTP %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lingti", "mcp-serve-token")
+	token, err := LoadOrCreateToken(path)
+	if err != nil || len(token) < 32 {
+		t.Fatalf("LoadOrCreateToken = %q, %v", token, err)
+	}
+	if again, _ := LoadOrCreateToken(path); again != token {
+		t.Errorf("token changed between runs: %q, %q", token, again)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("token file mode = %v, %v", info.Mode(), err)
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -64,6 +65,30 @@ func ProgressFromContext(ctx context.Context) ProgressFunc {
 	return fn
 }
 
+// ToolRound describes one round of tool calls made while handling a message.
+type ToolRound struct {
+	Round     int      // 1-based
+	MaxRounds int      // the agent's limit on rounds
+	Tools     []string // tools called in this round
+	Errors    int      // tool calls that returned an error
+}
+
+// ToolRoundFunc is called after each round of tool calls.
+type ToolRoundFunc func(round ToolRound)
+
+type toolRoundKeyType struct{}
+
+// ContextWithToolRounds attaches a ToolRoundFunc to the context.
+func ContextWithToolRounds(ctx context.Context, fn ToolRoundFunc) context.Context {
+	return context.WithValue(ctx, toolRoundKeyType{}, fn)
+}
+
+// ToolRoundsFromContext retrieves the ToolRoundFunc from the context, or nil.
+func ToolRoundsFromContext(ctx context.Context) ToolRoundFunc {
+	fn, _ := ctx.Value(toolRoundKeyType{}).(ToolRoundFunc)
+	return fn
+}
+
 // MessageHandler processes incoming messages and returns responses
 type MessageHandler func(ctx context.Context, msg Message) (Response, error)
 
@@ -222,6 +247,18 @@ func (r *Router) SendToUser(platformName, channelID string, resp Response) error
 	return platform.Send(context.Background(), channelID, resp)
 }
 
+// Platforms returns the names of the registered platforms, sorted
+func (r *Router) Platforms() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.platforms))
+	for name := range r.platforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Wait blocks until the router is stopped
 func (r *Router) Wait() {
 	if r.ctx != nil {
@@ -0,0 +1,221 @@
+package sites
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pltanton/lingti-bot/internal/browser"
+	"github.com/pltanton/lingti-bot/internal/logger"
+)
+
+// defaultInterval is the pause between tries of a step
+const defaultInterval = 200 * time.Millisecond
+
+// Page is what actions run on. RodPage adapts a browser page.
+type Page interface {
+	Eval(script string) (string, error)
+	Click(selector string) error
+	Input(selector, text string) error
+	Press(key string) error
+}
+
+// Run runs an action with the given arguments and returns its Done message
+func (act *Action) Run(ctx context.Context, page Page, args map[string]any) (string, error) {
+	values := make(map[string]string)
+	for _, p := range act.Params {
+		if v, ok := args[p.Name]; ok && v != nil {
+			values[p.Name] = fmt.Sprint(v)
+		}
+		if p.Required && values[p.Name] == "" {
+			return "", fmt.Errorf("%s is required", p.Name)
+		}
+	}
+
+	for i := range act.Steps {
+		s := &act.Steps[i]
+		if !s.applies(values) {
+			continue
+		}
+		result, err := s.run(ctx, page, values)
+		if s.Name != "" {
+			values[s.Name] = result
+		}
+		logger.Debug("[Sites] step %s: %s", s.label(i), result)
+		if err != nil {
+			return "", fmt.Errorf("%s failed: %w", s.label(i), err)
+		}
+		if s.pending.MatchString(result) || (s.expect != nil && !s.expect.MatchString(result)) {
+			if s.Error != "" {
+				return "", fmt.Errorf("%s", expand(s.Error, values, false))
+			}
+			return "", fmt.Errorf("%s: %s", s.label(i), result)
+		}
+		if err := sleep(ctx, s.Wait); err != nil {
+			return "", err
+		}
+	}
+
+	if act.Done == "" {
+		return "Done", nil
+	}
+	return expand(act.Done, values, false), nil
+}
+
+// applies reports whether the step runs with the values so far
+func (s *Step) applies(values map[string]string) bool {
+	if s.If != "" && values[s.If] == "" {
+		return false
+	}
+	if s.Unless != "" && values[s.Unless] != "" {
+		return false
+	}
+	for name, re := range s.skipIf {
+		if re.MatchString(values[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// run does the step, trying again while the result is pending
+func (s *Step) run(ctx context.Context, page Page, values map[string]string) (string, error) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	for try := 0; ; try++ {
+		result, err := s.do(page, values)
+		if err != nil || try >= s.Retry || !s.pending.MatchString(result) {
+			return result, err
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return result, err
+		}
+	}
+}
+
+func (s *Step) do(page Page, values map[string]string) (string, error) {
+	switch {
+	case s.JS != "":
+		return page.Eval(expand(s.JS, values, true))
+	case s.Click != "":
+		return "clicked", page.Click(s.Click)
+	case s.Input != "":
+		return "typed", page.Input(s.Input, expand(s.Text, values, false))
+	case s.Press != "":
+		return "pressed", page.Press(s.Press)
+	}
+	return "", nil
+}
+
+func (s *Step) label(i int) string {
+	if s.Name != "" {
+		return "step " + s.Name
+	}
+	return fmt.Sprintf("step %d", i+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\s*\}\}`)
+
+// expand replaces {{name}} with the value, as a JSON literal for scripts
+func expand(text string, values map[string]string, asJSON bool) string {
+	return placeholder.ReplaceAllStringFunc(text, func(m string) string {
+		v := values[placeholder.FindStringSubmatch(m)[1]]
+		if asJSON {
+			data, _ := json.Marshal(v)
+			return string(data)
+		}
+		return v
+	})
+}
+
+// checkPlaceholders reports placeholders that name nothing known
+func checkPlaceholders(text string, known map[string]bool) error {
+	for _, m := range placeholder.FindAllStringSubmatch(text, -1) {
+		if !known[m[1]] {
+			return fmt.Errorf("unknown placeholder {{%s}}", m[1])
+		}
+	}
+	return nil
+}
+
+// Prompt returns the adapter's section of the agent's system prompt
+func (a *Adapter) Prompt() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Site: %s\n", a.DisplayName())
+	if hints := strings.TrimSpace(a.Hints); hints != "" {
+		sb.WriteString(hints + "\n")
+	}
+	if len(a.Actions) > 0 {
+		sb.WriteString("\nActions for this site (call browser_site_action with action=\"" + a.Name + ".<name>\" and args):\n")
+		for _, name := range a.ActionNames() {
+			act := a.Actions[name]
+			var params []string
+			for _, p := range act.Params {
+				if p.Required {
+					params = append(params, p.Name)
+				} else {
+					params = append(params, p.Name+"?")
+				}
+			}
+			fmt.Fprintf(&sb, "- %s.%s(%s): %s\n", a.Name, name, strings.Join(params, ", "), strings.TrimSpace(act.Description))
+			for _, p := range act.Params {
+				if p.Description != "" {
+					fmt.Fprintf(&sb, "  - %s: %s\n", p.Name, p.Description)
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+// RodPage adapts a browser page for Run
+func RodPage(page *rod.Page) Page {
+	return rodPage{page}
+}
+
+type rodPage struct {
+	page *rod.Page
+}
+
+func (p rodPage) Eval(script string) (string, error) {
+	return browser.ExecuteJS(p.page, script)
+}
+
+func (p rodPage) Click(selector string) error {
+	el, err := p.page.Timeout(5 * time.Second).Element(selector)
+	if err != nil {
+		return fmt.Errorf("element %s not found: %w", selector, err)
+	}
+	return el.CancelTimeout().Click(proto.InputMouseButtonLeft, 1)
+}
+
+func (p rodPage) Input(selector, text string) error {
+	el, err := p.page.Timeout(5 * time.Second).Element(selector)
+	if err != nil {
+		return fmt.Errorf("element %s not found: %w", selector, err)
+	}
+	return el.CancelTimeout().Input(text)
+}
+
+func (p rodPage) Press(key string) error {
+	return browser.Press(p.page, key)
+}
@@ -0,0 +1,480 @@
+// Package sites loads site adapters: declarative descriptions of how the
+// browser tools work with a particular website. An adapter lists the URLs it
+// applies to, prompt hints for the agent, URL normalization and named
+// actions made of JavaScript or selector steps.
+//
+// The built-in adapters are embedded in the binary; YAML files in
+// ~/.lingti/sites/ add more or replace built-ins of the same name.
+package sites
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pltanton/lingti-bot/internal/logger"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed adapters/*.yaml
+var builtin embed.FS
+
+// Adapter describes one website
+type Adapter struct {
+	Name      string             `yaml:"name"`
+	Title     string             `yaml:"title,omitempty"`
+	Match     []string           `yaml:"match"`           // URL globs; * matches any run of characters
+	Hints     string             `yaml:"hints,omitempty"` // added to the agent's prompt while on a matching page
+	Normalize Normalize          `yaml:"normalize,omitempty"`
+	Navigate  Navigate           `yaml:"navigate,omitempty"`
+	Actions   map[string]*Action `yaml:"actions,omitempty"`
+
+	// Path is the file the adapter was loaded from, empty for built-ins
+	Path string `yaml:"-"`
+
+	patterns []*regexp.Regexp
+}
+
+// Normalize turns the URLs of one page into a single form, e.g. for
+// browser_visited
+type Normalize struct {
+	StripQuery    bool      `yaml:"strip_query,omitempty"`
+	StripFragment bool      `yaml:"strip_fragment,omitempty"`
+	Rewrite       []Rewrite `yaml:"rewrite,omitempty"`
+}
+
+// Navigate lists URLs browser_navigate refuses to open, e.g. pages the site
+// only serves when reached by clicking. Unlike hints, the rule applies on
+// every page.
+type Navigate struct {
+	Deny    []string `yaml:"deny,omitempty"`    // URL globs
+	Message string   `yaml:"message,omitempty"` // why, and what to do instead
+
+	deny []*regexp.Regexp
+}
+
+// Rewrite replaces matches of a regular expression
+type Rewrite struct {
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+
+	re *regexp.Regexp
+}
+
+// Action is a named task on the site, run step by step on the current page
+type Action struct {
+	Description string  `yaml:"description"`
+	Params      []Param `yaml:"params,omitempty"`
+	Steps       []Step  `yaml:"steps"`
+	Done        string  `yaml:"done,omitempty"` // message on success; {{name}} is a param or step result
+}
+
+// Param is an argument of an action. Values are strings.
+type Param struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
+// Step is one step of an action. It does one of JS, Click, Input or Press,
+// or only waits. Its result is available to later steps as {{name}}.
+type Step struct {
+	Name   string            `yaml:"name,omitempty"`
+	If     string            `yaml:"if,omitempty"`      // run only when this param is set
+	Unless string            `yaml:"unless,omitempty"`  // skip when this param is set
+	SkipIf map[string]string `yaml:"skip_if,omitempty"` // skip when an earlier step's result matches
+
+	JS    string `yaml:"js,omitempty"`    // script body; {{name}} is replaced by the value as a JSON literal
+	Click string `yaml:"click,omitempty"` // CSS selector
+	Input string `yaml:"input,omitempty"` // CSS selector to type Text into
+	Text  string `yaml:"text,omitempty"`
+	Press string `yaml:"press,omitempty"` // key, e.g. Enter
+
+	Retry    int           `yaml:"retry,omitempty"`    // tries again up to this many times while the result matches Pending
+	Pending  string        `yaml:"pending,omitempty"`  // default ^waiting$
+	Interval time.Duration `yaml:"interval,omitempty"` // between tries, default 200ms
+	Expect   string        `yaml:"expect,omitempty"`   // the result must match, else the action fails
+	Error    string        `yaml:"error,omitempty"`    // failure message, default the result
+	Wait     time.Duration `yaml:"wait,omitempty"`     // pause after the step
+
+	skipIf  map[string]*regexp.Regexp
+	pending *regexp.Regexp
+	expect  *regexp.Regexp
+}
+
+// Registry holds the loaded adapters
+type Registry struct {
+	adapters []*Adapter // sorted by name
+}
+
+var (
+	defaultRegistry *Registry
+	defaultOnce     sync.Once
+)
+
+// Default returns the built-in adapters and those in Dir, loaded once.
+// Invalid files are logged and skipped.
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		var errs []error
+		defaultRegistry, errs = Load(Dir())
+		for _, err := range errs {
+			logger.Warn("[Sites] %v", err)
+		}
+	})
+	return defaultRegistry
+}
+
+// Dir returns the user's site adapter directory
+func Dir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lingti", "sites")
+}
+
+// Load reads the built-in adapters and then the *.yaml files in dir, which
+// replace built-ins of the same name. A missing dir is not an error.
+func Load(dir string) (*Registry, []error) {
+	byName := make(map[string]*Adapter)
+	var errs []error
+
+	files, _ := fs.Glob(builtin, "adapters/*.yaml")
+	for _, name := range files {
+		data, _ := builtin.ReadFile(name)
+		a, err := Parse(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("built-in %s: %w", name, err))
+			continue
+		}
+		byName[a.Name] = a
+	}
+
+	if dir != "" {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*.yaml"))
+		more, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+		for _, path := range append(paths, more...) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			a, err := Parse(data)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			a.Path = path
+			byName[a.Name] = a
+		}
+	}
+
+	r := &Registry{}
+	for _, a := range byName {
+		r.adapters = append(r.adapters, a)
+	}
+	sort.Slice(r.adapters, func(i, j int) bool { return r.adapters[i].Name < r.adapters[j].Name })
+	return r, errs
+}
+
+// Parse parses and validates one adapter
+func Parse(data []byte) (*Adapter, error) {
+	var a Adapter
+	if err := yaml.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	if err := a.compile(); err != nil {
+		if a.Name != "" {
+			return nil, fmt.Errorf("adapter %s: %w", a.Name, err)
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// compile validates the adapter and compiles its patterns
+func (a *Adapter) compile() error {
+	if !namePattern.MatchString(a.Name) {
+		return fmt.Errorf("name %q must be lowercase letters, digits, - and _", a.Name)
+	}
+	if len(a.Match) == 0 {
+		return fmt.Errorf("no match patterns")
+	}
+	for _, glob := range a.Match {
+		a.patterns = append(a.patterns, globPattern(glob))
+	}
+	for _, glob := range a.Navigate.Deny {
+		a.Navigate.deny = append(a.Navigate.deny, globPattern(glob))
+	}
+	if len(a.Navigate.Deny) > 0 && strings.TrimSpace(a.Navigate.Message) == "" {
+		return fmt.Errorf("navigate: message is required with deny")
+	}
+	for i := range a.Normalize.Rewrite {
+		rw := &a.Normalize.Rewrite[i]
+		re, err := regexp.Compile(rw.Pattern)
+		if err != nil {
+			return fmt.Errorf("normalize rewrite %q: %w", rw.Pattern, err)
+		}
+		rw.re = re
+	}
+	for name, action := range a.Actions {
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("action name %q must be lowercase letters, digits, - and _", name)
+		}
+		if err := action.compile(); err != nil {
+			return fmt.Errorf("action %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (act *Action) compile() error {
+	if len(act.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	known := make(map[string]bool)
+	for _, p := range act.Params {
+		if p.Name == "" {
+			return fmt.Errorf("param without a name")
+		}
+		known[p.Name] = true
+	}
+	for i := range act.Steps {
+		s := &act.Steps[i]
+		label := fmt.Sprintf("step %d", i+1)
+		if s.Name != "" {
+			label = "step " + s.Name
+		}
+		if err := s.compile(known); err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		if s.Name != "" {
+			if known[s.Name] {
+				return fmt.Errorf("%s: name is already a param or step", label)
+			}
+			known[s.Name] = true
+		}
+		if err := checkPlaceholders(s.Error, known); err != nil {
+			return fmt.Errorf("%s: error: %w", label, err)
+		}
+	}
+	if err := checkPlaceholders(act.Done, known); err != nil {
+		return fmt.Errorf("done: %w", err)
+	}
+	return nil
+}
+
+// compile checks a step against the names defined before it
+func (s *Step) compile(known map[string]bool) error {
+	kinds := 0
+	for _, set := range []bool{s.JS != "", s.Click != "", s.Input != "", s.Press != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return fmt.Errorf("only one of js, click, input and press may be set")
+	}
+	if kinds == 0 && s.Wait == 0 {
+		return fmt.Errorf("nothing to do: set js, click, input, press or wait")
+	}
+	for _, param := range []string{s.If, s.Unless} {
+		if param != "" && !known[param] {
+			return fmt.Errorf("unknown param %q", param)
+		}
+	}
+	s.skipIf = make(map[string]*regexp.Regexp, len(s.SkipIf))
+	for name, pattern := range s.SkipIf {
+		if !known[name] {
+			return fmt.Errorf("skip_if: unknown step %q", name)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("skip_if %s: %w", name, err)
+		}
+		s.skipIf[name] = re
+	}
+	for _, text := range []string{s.JS, s.Text} {
+		if err := checkPlaceholders(text, known); err != nil {
+			return err
+		}
+	}
+
+	pending := s.Pending
+	if pending == "" {
+		pending = "^waiting$"
+	}
+	var err error
+	if s.pending, err = regexp.Compile(pending); err != nil {
+		return fmt.Errorf("pending: %w", err)
+	}
+	if s.Expect != "" {
+		if s.expect, err = regexp.Compile(s.Expect); err != nil {
+			return fmt.Errorf("expect: %w", err)
+		}
+	}
+	return nil
+}
+
+// DisplayName returns the title, or the name when there is none
+func (a *Adapter) DisplayName() string {
+	if a.Title != "" {
+		return a.Title
+	}
+	return a.Name
+}
+
+// Matches reports whether the adapter applies to rawURL
+func (a *Adapter) Matches(rawURL string) bool {
+	for _, re := range a.patterns {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeURL applies the adapter's normalization to rawURL
+func (a *Adapter) NormalizeURL(rawURL string) string {
+	n := a.Normalize
+	if n.StripQuery || n.StripFragment {
+		if u, err := url.Parse(rawURL); err == nil {
+			if n.StripQuery {
+				u.RawQuery = ""
+				u.ForceQuery = false
+			}
+			if n.StripFragment {
+				u.Fragment = ""
+				u.RawFragment = ""
+			}
+			rawURL = u.String()
+		}
+	}
+	for _, rw := range n.Rewrite {
+		rawURL = rw.re.ReplaceAllString(rawURL, rw.Replace)
+	}
+	return rawURL
+}
+
+// CheckNavigate returns an error if the adapter forbids opening rawURL directly
+func (a *Adapter) CheckNavigate(rawURL string) error {
+	for _, re := range a.Navigate.deny {
+		if re.MatchString(rawURL) {
+			return fmt.Errorf("%s: %s", a.DisplayName(), strings.TrimSpace(a.Navigate.Message))
+		}
+	}
+	return nil
+}
+
+// ActionNames returns the adapter's actions, sorted
+func (a *Adapter) ActionNames() []string {
+	names := make([]string, 0, len(a.Actions))
+	for name := range a.Actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns the adapters sorted by name
+func (r *Registry) All() []*Adapter {
+	return r.adapters
+}
+
+// Get returns the adapter with the given name, or nil
+func (r *Registry) Get(name string) *Adapter {
+	for _, a := range r.adapters {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// Match returns the first adapter that applies to rawURL, or nil
+func (r *Registry) Match(rawURL string) *Adapter {
+	if rawURL == "" {
+		return nil
+	}
+	for _, a := range r.adapters {
+		if a.Matches(rawURL) {
+			return a
+		}
+	}
+	return nil
+}
+
+// Normalize normalizes rawURL with the adapter that applies to it, if any
+func (r *Registry) Normalize(rawURL string) string {
+	if a := r.Match(rawURL); a != nil {
+		return a.NormalizeURL(rawURL)
+	}
+	return rawURL
+}
+
+// CheckNavigate returns an error if any adapter forbids opening rawURL directly
+func (r *Registry) CheckNavigate(rawURL string) error {
+	for _, a := range r.adapters {
+		if err := a.CheckNavigate(rawURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NavigationRules returns one line per adapter that forbids URLs, for the
+// agent's prompt
+func (r *Registry) NavigationRules() []string {
+	var rules []string
+	for _, a := range r.adapters {
+		if len(a.Navigate.Deny) > 0 {
+			rules = append(rules, fmt.Sprintf("%s: %s", a.DisplayName(), strings.TrimSpace(a.Navigate.Message)))
+		}
+	}
+	return rules
+}
+
+// Action resolves a qualified action name like "zhihu.comment"
+func (r *Registry) Action(qualified string) (*Adapter, *Action, error) {
+	site, name, ok := strings.Cut(qualified, ".")
+	if !ok {
+		return nil, nil, fmt.Errorf("action must look like <site>.<action>, e.g. zhihu.comment")
+	}
+	a := r.Get(site)
+	if a == nil {
+		return nil, nil, fmt.Errorf("unknown site %q", site)
+	}
+	action, ok := a.Actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("site %s has no action %q (available: %s)", site, name, strings.Join(a.ActionNames(), ", "))
+	}
+	return a, action, nil
+}
+
+// ActionList returns the qualified names of all actions, e.g. "zhihu.comment"
+func (r *Registry) ActionList() []string {
+	var names []string
+	for _, a := range r.adapters {
+		for _, name := range a.ActionNames() {
+			names = append(names, a.Name+"."+name)
+		}
+	}
+	return names
+}
+
+// globPattern turns a URL glob into an anchored regular expression
+func globPattern(glob string) *regexp.Regexp {
+	parts := strings.Split(glob, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
@@ -0,0 +1,200 @@
+package sites
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Builtin(t *testing.T) {
+	r, errs := Load(filepath.Join(t.TempDir(), "missing"))
+	if len(errs) > 0 {
+		t.Fatalf("built-in adapters: %v", errs)
+	}
+	for _, name := range []string{"zhihu.comment", "xiaohongshu.comment", "xiaohongshu.like", "xiaohongshu.open_note"} {
+		if _, _, err := r.Action(name); err != nil {
+			t.Errorf("Action(%s): %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		url, site, normalized string
+	}{
+		{"https://www.zhihu.com/question/1?utm=x", "zhihu", "https://www.zhihu.com/question/1?utm=x"},
+		{"https://zhuanlan.zhihu.com/p/2", "zhihu", "https://zhuanlan.zhihu.com/p/2"},
+		{"https://www.xiaohongshu.com/explore/697ec7e7?xsec_token=abc", "xiaohongshu", "https://www.xiaohongshu.com/explore/697ec7e7"},
+		{"https://example.com/?q=zhihu.com/", "", "https://example.com/?q=zhihu.com/"},
+	}
+	for _, tt := range tests {
+		site := ""
+		if a := r.Match(tt.url); a != nil {
+			site = a.Name
+		}
+		if site != tt.site {
+			t.Errorf("Match(%s) = %q, want %q", tt.url, site, tt.site)
+		}
+		if got := r.Normalize(tt.url); got != tt.normalized {
+			t.Errorf("Normalize(%s) = %s, want %s", tt.url, got, tt.normalized)
+		}
+	}
+
+	for _, name := range []string{"comment", "zhihu.answer", "weibo.comment"} {
+		if _, _, err := r.Action(name); err == nil {
+			t.Errorf("Action(%s) succeeded", name)
+		}
+	}
+
+	if err := r.CheckNavigate("https://www.xiaohongshu.com/explore/697ec7e7?xsec_token=abc"); err == nil {
+		t.Error("direct note URL allowed")
+	}
+	for _, url := range []string{"https://www.xiaohongshu.com/explore", "https://www.xiaohongshu.com/search_result?keyword=x", "https://www.zhihu.com/"} {
+		if err := r.CheckNavigate(url); err != nil {
+			t.Errorf("CheckNavigate(%s) = %v", url, err)
+		}
+	}
+	if rules := r.NavigationRules(); len(rules) != 1 || !strings.Contains(rules[0], "xiaohongshu.com/explore") {
+		t.Errorf("NavigationRules = %q", rules)
+	}
+}
+
+func TestLoad_UserDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("zhihu.yaml", "name: zhihu\nmatch: ['*://zhihu.example/*']\n")
+	write("weibo.yml", "name: weibo\nmatch: ['*://weibo.com/*']\nhints: Log in first.\n")
+	write("broken.yaml", "name: broken\n")
+	write("notes.txt", "not an adapter")
+
+	r, errs := Load(dir)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken.yaml") {
+		t.Errorf("errors = %v, want one for broken.yaml", errs)
+	}
+	if a := r.Match("https://www.zhihu.com/"); a != nil {
+		t.Errorf("built-in zhihu not overridden: matched %s", a.Path)
+	}
+	if a := r.Match("https://zhihu.example/q"); a == nil || a.Path != filepath.Join(dir, "zhihu.yaml") {
+		t.Errorf("user zhihu not loaded: %+v", a)
+	}
+	if a := r.Match("https://weibo.com/u/1"); a == nil || !strings.Contains(a.Prompt(), "Log in first.") {
+		t.Errorf("weibo adapter not loaded")
+	}
+	if r.Get("xiaohongshu") == nil {
+		t.Error("other built-ins dropped")
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no name":     "match: ['*']",
+		"bad name":    "name: Zhi Hu\nmatch: ['*']",
+		"no match":    "name: x",
+		"no steps":    "name: x\nmatch: ['*']\nactions:\n  a:\n    description: d",
+		"two kinds":   "name: x\nmatch: ['*']\nactions:\n  a:\n    steps:\n      - js: return 1\n        click: button",
+		"unknown var": "name: x\nmatch: ['*']\nactions:\n  a:\n    steps:\n      - js: return {{nope}}",
+		"bad regexp":  "name: x\nmatch: ['*']\nactions:\n  a:\n    steps:\n      - js: return 1\n        expect: '('",
+		"no reason":   "name: x\nmatch: ['*']\nnavigate:\n  deny: ['*://x/*']",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: Parse succeeded", name)
+		}
+	}
+}
+
+// fakePage answers scripts by the first matching substring
+type fakePage struct {
+	answers [][2]string
+	scripts []string
+}
+
+func (p *fakePage) Eval(script string) (string, error) {
+	p.scripts = append(p.scripts, script)
+	for _, a := range p.answers {
+		if strings.Contains(script, a[0]) {
+			return a[1], nil
+		}
+	}
+	return "", nil
+}
+
+func (p *fakePage) Click(string) error         { return nil }
+func (p *fakePage) Input(string, string) error { return nil }
+func (p *fakePage) Press(string) error         { return nil }
+
+func TestRun_ZhihuComment(t *testing.T) {
+	r, _ := Load(t.TempDir())
+	_, act, err := r.Action("zhihu.comment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := act.Run(context.Background(), &fakePage{}, nil); err == nil || !strings.Contains(err.Error(), "comment is required") {
+		t.Errorf("missing comment: %v", err)
+	}
+
+	// Editor already open: skips reply, add and editor
+	page := &fakePage{answers: [][2]string{
+		{"Already open?", "editor already open"},
+		{"ClipboardEvent", "pasted-draftjs"},
+		{"'发布'", "submitted"},
+	}}
+	comment := `说得好 "quoted" </script>`
+	got, err := act.Run(context.Background(), page, map[string]any{"comment": comment})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Comment posted successfully: "+comment {
+		t.Errorf("Run = %q", got)
+	}
+	if len(page.scripts) != 3 {
+		t.Errorf("ran %d scripts, want open, paste and submit", len(page.scripts))
+	}
+	if !strings.Contains(page.scripts[1], `"说得好 \"quoted\" \u003c/script\u003e"`) {
+		t.Errorf("comment not passed as a JSON string:\n%s", page.scripts[1])
+	}
+
+	// A nested reply that can't find the user fails with the script's result
+	page = &fakePage{answers: [][2]string{{"var username", "user not found: bob"}}}
+	_, err = act.Run(context.Background(), page, map[string]any{"comment": "hi", "reply_to": "bob"})
+	if err == nil || err.Error() != "user not found: bob" {
+		t.Errorf("reply error = %v", err)
+	}
+}
+
+func TestRun_Retry(t *testing.T) {
+	a, err := Parse([]byte(`
+name: test
+match: ['*']
+actions:
+  wait:
+    steps:
+      - name: ready
+        js: return state
+        retry: 3
+        interval: 1ms
+        error: "gave up: {{ready}}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	act := a.Actions["wait"]
+
+	page := &fakePage{answers: [][2]string{{"state", "waiting"}}}
+	if _, err := act.Run(context.Background(), page, nil); err == nil || err.Error() != "gave up: waiting" {
+		t.Errorf("pending error = %v", err)
+	}
+	if len(page.scripts) != 4 {
+		t.Errorf("tried %d times, want 4", len(page.scripts))
+	}
+
+	page = &fakePage{answers: [][2]string{{"state", "ok"}}}
+	if got, err := act.Run(context.Background(), page, nil); err != nil || got != "Done" {
+		t.Errorf("Run = %q, %v", got, err)
+	}
+}
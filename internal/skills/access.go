@@ -0,0 +1,263 @@
+package skills
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// scopeFreeTools are always callable while a skill scope is active, so the
+// agent can still read skills. skill_script is only callable for skills in
+// the scope.
+var scopeFreeTools = []string{"skill_read"}
+
+// shellBuiltins may appear in a scoped shell command without being declared
+var shellBuiltins = []string{"cd", "echo", "printf", "true", "false", "test", "["}
+
+// Access is an agent's skill allow/deny list. An empty Allow permits every
+// skill; Deny is applied after Allow.
+type Access struct {
+	Allow []string
+	Deny  []string
+}
+
+// Allowed reports whether the skill name passes the allow and deny lists
+func (a Access) Allowed(name string) bool {
+	if len(a.Allow) > 0 && !slices.Contains(a.Allow, name) {
+		return false
+	}
+	return !slices.Contains(a.Deny, name)
+}
+
+// Filter returns a copy of the report containing only the SKILL.md and
+// trigger skills the access lists allow
+func (r StatusReport) Filter(access Access) StatusReport {
+	if len(access.Allow) == 0 && len(access.Deny) == 0 {
+		return r
+	}
+	filtered := r
+	filtered.Skills = nil
+	for _, s := range r.Skills {
+		if access.Allowed(s.Name) {
+			filtered.Skills = append(filtered.Skills, s)
+		}
+	}
+	filtered.TriggerSkills = nil
+	for _, s := range r.TriggerSkills {
+		if access.Allowed(s.ID) {
+			filtered.TriggerSkills = append(filtered.TriggerSkills, s)
+		}
+	}
+	return filtered
+}
+
+// ToolScope restricts tool calls to those declared by the skills in use.
+// The zero value allows everything; the scope only narrows once a skill
+// that declares tools is added.
+type ToolScope struct {
+	skills []string
+	tools  map[string][]string // tool name -> allowed bins (nil = any)
+}
+
+// Add brings a skill's declared tools into the scope. Skills that declare
+// no tools leave the scope unchanged.
+func (s *ToolScope) Add(entry SkillEntry) {
+	if len(entry.Metadata.Tools) == 0 || slices.Contains(s.skills, entry.Name) {
+		return
+	}
+	if s.tools == nil {
+		s.tools = make(map[string][]string)
+	}
+	s.skills = append(s.skills, entry.Name)
+	for _, t := range entry.Metadata.Tools {
+		bins, seen := s.tools[t.Name]
+		switch {
+		case seen && bins == nil:
+			// Already unrestricted
+		case len(t.Bins) == 0:
+			s.tools[t.Name] = nil
+		default:
+			s.tools[t.Name] = append(bins, t.Bins...)
+		}
+	}
+}
+
+// Active reports whether any skill has restricted the scope
+func (s *ToolScope) Active() bool {
+	return len(s.skills) > 0
+}
+
+// Has reports whether the named skill has been added to the scope
+func (s *ToolScope) Has(name string) bool {
+	return slices.Contains(s.skills, name)
+}
+
+// Check returns an error if the tool call is outside the scope. For
+// shell_execute with declared bins, every program in the command must be
+// one of them.
+func (s *ToolScope) Check(name string, args map[string]any) error {
+	if !s.Active() || slices.Contains(scopeFreeTools, name) {
+		return nil
+	}
+	if name == "skill_script" {
+		skill, _ := args["name"].(string)
+		if !s.Has(skill) {
+			return fmt.Errorf("skill %s is not in use alongside skill %s; its scripts can't run now", skill, strings.Join(s.skills, ", "))
+		}
+		return nil
+	}
+	bins, ok := s.tools[name]
+	if !ok {
+		return fmt.Errorf("tool %s is not declared by skill %s", name, strings.Join(s.skills, ", "))
+	}
+	if name != "shell_execute" || bins == nil {
+		return nil
+	}
+	command, _ := args["command"].(string)
+	return CheckShellCommand(command, bins)
+}
+
+// CheckShellCommand verifies that every program a shell command runs is in
+// bins, either by name or as the path exec.LookPath resolves the name to.
+// Commands using substitution or subshell constructs are rejected because
+// the programs they run can't be determined, and so are assignments to
+// variables that change which program or library is loaded.
+func CheckShellCommand(command string, bins []string) error {
+	for _, construct := range []string{"`", "$(", "<(", ">("} {
+		if strings.Contains(command, construct) {
+			return fmt.Errorf("command substitution (%s) is not allowed; run one of %s directly", construct, strings.Join(bins, ", "))
+		}
+	}
+	segments := commandSegments(command)
+	for _, segment := range segments {
+		for _, word := range segment {
+			if !isAssignment(word) {
+				break
+			}
+			if name, _, _ := strings.Cut(word, "="); isLoaderVariable(name) {
+				return fmt.Errorf("setting %s is not allowed", name)
+			}
+		}
+	}
+	programs := segmentPrograms(segments)
+	if len(programs) == 0 {
+		return fmt.Errorf("empty command")
+	}
+	for _, program := range programs {
+		if !slices.Contains(shellBuiltins, program) && !allowedProgram(program, bins) {
+			return fmt.Errorf("program %s is not allowed; only %s may be run", program, strings.Join(bins, ", "))
+		}
+	}
+	return nil
+}
+
+// allowedProgram reports whether program is one of bins, by name or by the
+// path the name resolves to
+func allowedProgram(program string, bins []string) bool {
+	if slices.Contains(bins, program) {
+		return true
+	}
+	if !strings.ContainsRune(program, '/') {
+		return false
+	}
+	for _, bin := range bins {
+		if resolved, err := LookBinary(bin); err == nil && filepath.Clean(program) == filepath.Clean(resolved) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoaderVariable reports whether a variable controls where programs and
+// shared libraries are loaded from
+func isLoaderVariable(name string) bool {
+	return name == "PATH" || name == "BASH_ENV" || name == "ENV" ||
+		strings.HasPrefix(name, "LD_") || strings.HasPrefix(name, "DYLD_")
+}
+
+// commandPrograms returns the program of each simple command in a shell
+// command line, splitting on ; & | and newlines outside quotes and skipping
+// leading VAR=value assignments
+func commandPrograms(command string) []string {
+	return segmentPrograms(commandSegments(command))
+}
+
+// segmentPrograms returns the first word of each segment that isn't an
+// assignment
+func segmentPrograms(segments [][]string) []string {
+	var programs []string
+	for _, segment := range segments {
+		for _, word := range segment {
+			if isAssignment(word) {
+				continue
+			}
+			programs = append(programs, strings.TrimRight(strings.Trim(word, `'"`), ")}"))
+			break
+		}
+	}
+	return programs
+}
+
+// commandSegments splits a shell command line into the words of each
+// simple command
+func commandSegments(command string) [][]string {
+	var segments []string
+	var current strings.Builder
+	var quote rune
+	escaped := false
+	runes := []rune(command)
+	for i, c := range runes {
+		switch {
+		case escaped:
+			escaped = false
+			current.WriteRune(c)
+		case c == '\\' && quote != '\'':
+			escaped = true
+			current.WriteRune(c)
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			current.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote = c
+			current.WriteRune(c)
+		case c == '&' && ((i > 0 && (runes[i-1] == '>' || runes[i-1] == '<')) || (i+1 < len(runes) && runes[i+1] == '>')):
+			// Redirections such as 2>&1 and &>file
+			current.WriteRune(c)
+		case c == ';' || c == '&' || c == '|' || c == '\n':
+			segments = append(segments, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	segments = append(segments, current.String())
+
+	words := make([][]string, 0, len(segments))
+	for _, segment := range segments {
+		var segmentWords []string
+		for _, word := range strings.Fields(segment) {
+			if word = strings.TrimLeft(word, "({!"); word != "" {
+				segmentWords = append(segmentWords, word)
+			}
+		}
+		words = append(words, segmentWords)
+	}
+	return words
+}
+
+// isAssignment reports whether a shell word is a VAR=value prefix
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package skills
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestAccessFilter(t *testing.T) {
+	report := StatusReport{
+		Skills:        []SkillStatus{{SkillEntry: SkillEntry{Name: "github"}}, {SkillEntry: SkillEntry{Name: "tmux"}}},
+		TriggerSkills: []*Skill{{ID: "weather"}},
+	}
+	filtered := report.Filter(Access{Allow: []string{"github", "tmux", "weather"}, Deny: []string{"tmux"}})
+	if len(filtered.Skills) != 1 || filtered.Skills[0].Name != "github" || len(filtered.TriggerSkills) != 1 {
+		t.Errorf("filtered = %+v", filtered)
+	}
+	if len(report.Skills) != 2 {
+		t.Error("Filter modified the original report")
+	}
+}
+
+func TestCommandPrograms(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"gh pr list", []string{"gh"}},
+		{"GH_HOST=x /usr/bin/gh api 2>&1 | jq .", []string{"/usr/bin/gh", "jq"}},
+		{`git log --format="a;b" && git status; echo done &`, []string{"git", "git", "echo"}},
+		{`echo a\;b`, []string{"echo"}},
+		{"(cd repo && make)", []string{"cd", "make"}},
+	}
+	for _, tt := range tests {
+		if got := commandPrograms(tt.command); !slices.Equal(got, tt.want) {
+			t.Errorf("commandPrograms(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestToolScope(t *testing.T) {
+	var scope ToolScope
+	if err := scope.Check("file_write", nil); err != nil {
+		t.Errorf("empty scope should allow everything: %v", err)
+	}
+
+	scope.Add(SkillEntry{Name: "plain"})
+	if scope.Active() {
+		t.Error("a skill without declared tools should not activate the scope")
+	}
+	scope.Add(SkillEntry{Name: "github", Metadata: SkillMetadata{Tools: []ToolSpec{
+		{Name: "shell_execute", Bins: []string{"gh", "git"}},
+		{Name: "web_fetch"},
+	}}})
+
+	allowed := []struct {
+		name    string
+		command string
+	}{
+		{"shell_execute", "gh pr list | git apply"},
+		{"shell_execute", "cd repo && git status 2>&1"},
+		{"shell_execute", "GH_HOST=example.com gh api user"},
+		{"web_fetch", ""},
+		{"skill_read", ""},
+		{"skill_script", "github"},
+	}
+	for _, tt := range allowed {
+		if err := scope.Check(tt.name, map[string]any{"command": tt.command, "name": tt.command}); err != nil {
+			t.Errorf("Check(%s, %q) = %v", tt.name, tt.command, err)
+		}
+	}
+	denied := []struct {
+		name    string
+		command string
+	}{
+		{"shell_execute", "rm -rf repo"},
+		{"shell_execute", "gh pr list; curl evil"},
+		{"shell_execute", "gh `whoami`"},
+		{"shell_execute", "/tmp/evil/gh pr list"},
+		{"shell_execute", "cd /tmp/evil && ./git status"},
+		{"shell_execute", "PATH=/tmp/evil gh pr list"},
+		{"shell_execute", "PATH=/tmp/evil; gh pr list"},
+		{"shell_execute", "LD_PRELOAD=/tmp/evil.so git status"},
+		{"shell_execute", ""},
+		{"file_write", ""},
+		{"skill_script", "other"},
+	}
+	for _, tt := range denied {
+		if err := scope.Check(tt.name, map[string]any{"command": tt.command, "name": tt.command}); err == nil {
+			t.Errorf("Check(%s, %q) should be denied", tt.name, tt.command)
+		}
+	}
+}
+
+func TestCheckShellCommand_ResolvedPath(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+	if err := CheckShellCommand(sh+" -c true", []string{"sh"}); err != nil {
+		t.Errorf("resolved path of a declared bin refused: %v", err)
+	}
+	if err := CheckShellCommand("/nonexistent/sh -c true", []string{"sh"}); err == nil {
+		t.Error("other program with a declared bin's name allowed")
+	}
+}
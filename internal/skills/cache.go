@@ -0,0 +1,302 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	cacheDebounce        = 300 * time.Millisecond // wait for a burst of file events to settle
+	cacheRefreshInterval = time.Minute            // rescan for PATH, env and config changes
+)
+
+// ChangeKind describes how a skill changed between snapshots
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeUpdated ChangeKind = "updated" // SKILL.md or JSON definition changed
+	ChangeStatus  ChangeKind = "status"  // eligibility changed (e.g. missing → ready)
+)
+
+// Change is one skill that differs from the previous snapshot
+type Change struct {
+	Name    string     `json:"name"`
+	Kind    ChangeKind `json:"kind"`
+	Detail  string     `json:"detail,omitempty"`
+	Trigger bool       `json:"trigger,omitempty"` // a JSON trigger/action skill
+}
+
+// ChangeEvent is published to subscribers when a rescan finds changes
+type ChangeEvent struct {
+	Version int       `json:"version"`
+	Time    time.Time `json:"time"`
+	Changes []Change  `json:"changes"`
+}
+
+// Snapshot is an immutable view of the discovered skills. Callers must not
+// modify the report.
+type Snapshot struct {
+	Version  int
+	LoadedAt time.Time
+	Report   StatusReport
+	Changes  []Change // differences from the previous snapshot
+}
+
+// Cache keeps the skill status report up to date by watching the managed,
+// workspace and extra skill directories, so callers don't rescan the disk
+// on every message. Eligibility also depends on PATH, environment and
+// config, which are picked up by a periodic rescan.
+type Cache struct {
+	load func() (disabled, extraDirs []string)
+
+	snapshot atomic.Pointer[Snapshot]
+
+	mu          sync.Mutex
+	subscribers map[chan ChangeEvent]struct{}
+	watched     map[string]bool
+}
+
+// NewCache creates a cache; load returns the configured disabled skills and
+// extra directories and is called on every rescan
+func NewCache(load func() (disabled, extraDirs []string)) *Cache {
+	c := &Cache{
+		load:        load,
+		subscribers: make(map[chan ChangeEvent]struct{}),
+		watched:     make(map[string]bool),
+	}
+	disabled, extraDirs := load()
+	c.snapshot.Store(&Snapshot{Version: 1, LoadedAt: time.Now(), Report: BuildStatusReport(disabled, extraDirs)})
+	return c
+}
+
+// Snapshot returns the current skills snapshot
+func (c *Cache) Snapshot() *Snapshot {
+	return c.snapshot.Load()
+}
+
+// Subscribe returns a channel receiving change events and a function that
+// unsubscribes. Slow subscribers miss events rather than block rescans.
+func (c *Cache) Subscribe() (<-chan ChangeEvent, func()) {
+	ch := make(chan ChangeEvent, 8)
+	c.mu.Lock()
+	c.subscribers[ch] = struct{}{}
+	c.mu.Unlock()
+	return ch, func() {
+		c.mu.Lock()
+		if _, ok := c.subscribers[ch]; ok {
+			delete(c.subscribers, ch)
+			close(ch)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Refresh rescans the skill directories and, if anything changed, stores a
+// new snapshot and notifies subscribers. It returns the changes found.
+func (c *Cache) Refresh() []Change {
+	disabled, extraDirs := c.load()
+	report := BuildStatusReport(disabled, extraDirs)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev := c.snapshot.Load()
+	changes := diffReports(prev.Report, report)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	next := &Snapshot{Version: prev.Version + 1, LoadedAt: time.Now(), Report: report, Changes: changes}
+	c.snapshot.Store(next)
+	event := ChangeEvent{Version: next.Version, Time: next.LoadedAt, Changes: changes}
+	for ch := range c.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return changes
+}
+
+// Watch rescans on file changes in the skill directories until ctx is done.
+// Directories that don't exist yet are picked up by the periodic rescan.
+func (c *Cache) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create skills watcher: %w", err)
+	}
+	c.addWatches(watcher)
+
+	go func() {
+		defer watcher.Close()
+		ticker := time.NewTicker(cacheRefreshInterval)
+		defer ticker.Stop()
+		debounce := time.NewTimer(cacheDebounce)
+		debounce.Stop()
+
+		rescan := func() {
+			// Watch new skill directories before publishing, so edits made
+			// right after a change event aren't missed
+			c.addWatches(watcher)
+			if changes := c.Refresh(); len(changes) > 0 {
+				log.Printf("[Skills] Reloaded skills: %s", FormatChanges(changes))
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) != 0 {
+					debounce.Reset(cacheDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("[Skills] Watcher error: %v", err)
+			case <-debounce.C:
+				rescan()
+			case <-ticker.C:
+				rescan()
+			}
+		}
+	}()
+	return nil
+}
+
+// watchDirs lists the skill roots and the skill directories inside them
+func (c *Cache) watchDirs() []string {
+	report := c.Snapshot().Report
+	_, extraDirs := c.load()
+	roots := append([]string{report.ManagedDir, report.WorkspaceDir}, extraDirs...)
+
+	var dirs []string
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		dirs = append(dirs, root)
+		for _, e := range entries {
+			if e.IsDir() {
+				dirs = append(dirs, filepath.Join(root, e.Name()))
+			}
+		}
+	}
+	return dirs
+}
+
+// addWatches starts watching skill directories not yet watched and forgets
+// ones that disappeared
+func (c *Cache) addWatches(watcher *fsnotify.Watcher) {
+	dirs := c.watchDirs()
+	current := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		current[dir] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for dir := range c.watched {
+		if !current[dir] {
+			watcher.Remove(dir)
+			delete(c.watched, dir)
+		}
+	}
+	for _, dir := range dirs {
+		if c.watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			log.Printf("[Skills] Cannot watch %s: %v", dir, err)
+			continue
+		}
+		c.watched[dir] = true
+	}
+}
+
+// diffReports lists the SKILL.md and trigger skills that differ between two reports
+func diffReports(prev, next StatusReport) []Change {
+	var changes []Change
+
+	before := make(map[string]SkillStatus, len(prev.Skills))
+	for _, s := range prev.Skills {
+		before[s.Name] = s
+	}
+	after := make(map[string]bool, len(next.Skills))
+	for _, s := range next.Skills {
+		after[s.Name] = true
+		old, ok := before[s.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Name: s.Name, Kind: ChangeAdded, Detail: string(s.Status)})
+		case !reflect.DeepEqual(old.SkillEntry, s.SkillEntry):
+			changes = append(changes, Change{Name: s.Name, Kind: ChangeUpdated, Detail: string(s.Status)})
+		case old.Status != s.Status:
+			changes = append(changes, Change{Name: s.Name, Kind: ChangeStatus, Detail: fmt.Sprintf("%s → %s", old.Status, s.Status)})
+		}
+	}
+	for name := range before {
+		if !after[name] {
+			changes = append(changes, Change{Name: name, Kind: ChangeRemoved})
+		}
+	}
+
+	triggersBefore := make(map[string]*Skill, len(prev.TriggerSkills))
+	for _, s := range prev.TriggerSkills {
+		triggersBefore[s.ID] = s
+	}
+	triggersAfter := make(map[string]bool, len(next.TriggerSkills))
+	for _, s := range next.TriggerSkills {
+		triggersAfter[s.ID] = true
+		old, ok := triggersBefore[s.ID]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Name: s.ID, Kind: ChangeAdded, Trigger: true})
+		case !reflect.DeepEqual(old, s):
+			changes = append(changes, Change{Name: s.ID, Kind: ChangeUpdated, Trigger: true})
+		}
+	}
+	for id := range triggersBefore {
+		if !triggersAfter[id] {
+			changes = append(changes, Change{Name: id, Kind: ChangeRemoved, Trigger: true})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// FormatChanges renders changes on one line, e.g. "+weather, ~github (missing → ready)"
+func FormatChanges(changes []Change) string {
+	symbols := map[ChangeKind]string{ChangeAdded: "+", ChangeRemoved: "-", ChangeUpdated: "~", ChangeStatus: "~"}
+	var b []byte
+	for i, ch := range changes {
+		if i > 0 {
+			b = append(b, ", "...)
+		}
+		b = append(b, symbols[ch.Kind]+ch.Name...)
+		if ch.Kind == ChangeStatus {
+			b = append(b, " ("+ch.Detail+")"...)
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,118 @@
+package skills
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSkillMD(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: " + name + "\ndescription: " + name + " skill\n" + body + "---\n\n# " + name + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findChange(changes []Change, name string) (Change, bool) {
+	for _, ch := range changes {
+		if ch.Name == name {
+			return ch, true
+		}
+	}
+	return Change{}, false
+}
+
+func TestCacheRefresh(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	extra := t.TempDir()
+	writeSkillMD(t, extra, "alpha", "")
+
+	cache := NewCache(func() ([]string, []string) { return nil, []string{extra} })
+	first := cache.Snapshot()
+	if _, ok := first.Report.Find("alpha"); !ok {
+		t.Fatal("alpha not in the initial snapshot")
+	}
+	if changes := cache.Refresh(); changes != nil {
+		t.Errorf("Refresh without changes = %v", changes)
+	}
+	if cache.Snapshot() != first {
+		t.Error("snapshot replaced without changes")
+	}
+
+	events, unsubscribe := cache.Subscribe()
+	defer unsubscribe()
+
+	writeSkillMD(t, extra, "beta", "metadata:\n  requires:\n    bins: [\"beta-cli\"]\n")
+	os.RemoveAll(filepath.Join(extra, "alpha"))
+	changes := cache.Refresh()
+	if ch, ok := findChange(changes, "beta"); !ok || ch.Kind != ChangeAdded {
+		t.Errorf("beta change = %+v (all: %v)", ch, changes)
+	}
+	if ch, ok := findChange(changes, "alpha"); !ok || ch.Kind != ChangeRemoved {
+		t.Errorf("alpha change = %+v (all: %v)", ch, changes)
+	}
+	select {
+	case event := <-events:
+		if event.Version != first.Version+1 || len(event.Changes) != len(changes) {
+			t.Errorf("event = %+v", event)
+		}
+	default:
+		t.Error("no change event published")
+	}
+	if _, ok := first.Report.Find("alpha"); !ok {
+		t.Error("earlier snapshot was modified")
+	}
+
+	// Installing the required binary makes the skill ready
+	os.WriteFile(filepath.Join(bin, "beta-cli"), []byte("#!/bin/sh\n"), 0755)
+	changes = cache.Refresh()
+	if ch, ok := findChange(changes, "beta"); !ok || ch.Kind != ChangeStatus || ch.Detail != "missing → ready" {
+		t.Errorf("beta status change = %+v (all: %v)", ch, changes)
+	}
+	if got := FormatChanges(changes); got != "~beta (missing → ready)" {
+		t.Errorf("FormatChanges = %q", got)
+	}
+}
+
+func TestCacheWatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	extra := t.TempDir()
+	cache := NewCache(func() ([]string, []string) { return nil, []string{extra} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, unsubscribe := cache.Subscribe()
+	defer unsubscribe()
+	if err := cache.Watch(ctx); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	writeSkillMD(t, extra, "gamma", "")
+	select {
+	case event := <-events:
+		if ch, ok := findChange(event.Changes, "gamma"); !ok || ch.Kind != ChangeAdded {
+			t.Errorf("event = %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change event after adding a skill")
+	}
+
+	// Edits inside a newly created skill directory are watched too
+	writeSkillMD(t, extra, "gamma", "metadata:\n  emoji: \"🧪\"\n")
+	select {
+	case event := <-events:
+		if ch, ok := findChange(event.Changes, "gamma"); !ok || ch.Kind != ChangeUpdated {
+			t.Errorf("event = %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change event after editing a skill")
+	}
+}
@@ -0,0 +1,136 @@
+package skills
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSkillFiles caps the file listing returned for a skill directory
+const maxSkillFiles = 200
+
+// scriptInterpreters maps script extensions to the program that runs them
+// when the script itself is not executable
+var scriptInterpreters = map[string]string{
+	".sh":   "sh",
+	".bash": "bash",
+	".py":   "python3",
+	".js":   "node",
+	".mjs":  "node",
+	".ts":   "npx tsx",
+	".rb":   "ruby",
+	".pl":   "perl",
+}
+
+// Find returns the discovered skill with the given name
+func (r *StatusReport) Find(name string) (*SkillStatus, bool) {
+	for i := range r.Skills {
+		if r.Skills[i].Name == name {
+			return &r.Skills[i], true
+		}
+	}
+	return nil, false
+}
+
+// AlwaysSkills returns eligible skills marked always: true, whose
+// instructions are included in every system prompt
+func (r *StatusReport) AlwaysSkills() []SkillStatus {
+	var result []SkillStatus
+	for _, s := range r.Skills {
+		if s.Status == StatusReady && s.Metadata.Always && s.Content != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// SkillFiles lists the files in a skill's directory relative to BaseDir,
+// skipping hidden files and directories. At most maxSkillFiles are returned;
+// truncated reports whether the listing was cut short.
+func SkillFiles(entry SkillEntry) (files []string, truncated bool, err error) {
+	err = filepath.WalkDir(entry.BaseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == entry.BaseDir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if len(files) == maxSkillFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+		rel, err := filepath.Rel(entry.BaseDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list skill files: %w", err)
+	}
+	return files, truncated, nil
+}
+
+// ResolveSkillScript returns the absolute path of a script inside a skill's
+// directory, rejecting paths that escape BaseDir
+func ResolveSkillScript(entry SkillEntry, script string) (string, error) {
+	if script == "" {
+		return "", fmt.Errorf("script is required")
+	}
+	if filepath.IsAbs(script) {
+		return "", fmt.Errorf("script must be a path relative to the skill directory")
+	}
+
+	base, err := filepath.EvalSymlinks(entry.BaseDir)
+	if err != nil {
+		return "", fmt.Errorf("skill directory not found: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(base, filepath.FromSlash(script)))
+	if err != nil {
+		return "", fmt.Errorf("script not found: %s", script)
+	}
+	if rel, err := filepath.Rel(base, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("script %s is outside the skill directory", script)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("script not found: %s", script)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a file", script)
+	}
+	return path, nil
+}
+
+// ScriptCommand returns the program and arguments that run a script.
+// Executable files run directly; others use an interpreter chosen by
+// file extension.
+func ScriptCommand(path string, args []string) (string, []string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.Mode()&0111 != 0 {
+		return path, args, nil
+	}
+
+	interpreter, ok := scriptInterpreters[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", nil, fmt.Errorf("%s is not executable and has no known interpreter", filepath.Base(path))
+	}
+	fields := strings.Fields(interpreter)
+	return fields[0], append(append(fields[1:], path), args...), nil
+}
@@ -0,0 +1,50 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeTestSkill(t *testing.T) SkillEntry {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "greet")
+	os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: greet\n---\n# Greet"), 0644)
+	os.WriteFile(filepath.Join(dir, "scripts", "hello.sh"), []byte("echo hello $1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0644)
+	os.WriteFile(filepath.Join(filepath.Dir(dir), "secret.sh"), []byte("echo secret\n"), 0644)
+	return SkillEntry{Name: "greet", BaseDir: dir}
+}
+
+func TestSkillFiles(t *testing.T) {
+	entry := writeTestSkill(t)
+	files, truncated, err := SkillFiles(entry)
+	if err != nil {
+		t.Fatalf("SkillFiles: %v", err)
+	}
+	if truncated || !slices.Equal(files, []string{"SKILL.md", "scripts/hello.sh"}) {
+		t.Errorf("SkillFiles = %v (truncated=%v)", files, truncated)
+	}
+}
+
+func TestResolveSkillScript(t *testing.T) {
+	entry := writeTestSkill(t)
+
+	path, err := ResolveSkillScript(entry, "scripts/hello.sh")
+	if err != nil {
+		t.Fatalf("ResolveSkillScript: %v", err)
+	}
+	program, args, err := ScriptCommand(path, []string{"world"})
+	if err != nil || program != "sh" || !slices.Equal(args, []string{path, "world"}) {
+		t.Errorf("ScriptCommand = %s %v, %v", program, args, err)
+	}
+
+	for _, script := range []string{"", "../secret.sh", "/bin/sh", "scripts", "missing.sh"} {
+		if _, err := ResolveSkillScript(entry, script); err == nil {
+			t.Errorf("ResolveSkillScript(%q): expected error", script)
+		}
+	}
+}
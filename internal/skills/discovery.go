@@ -26,6 +26,7 @@ const (
 type SkillEntry struct {
 	Name        string        `json:"name" yaml:"name"`
 	Description string        `json:"description" yaml:"description"`
+	Version     string        `json:"version,omitempty" yaml:"version,omitempty"`
 	Homepage    string        `json:"homepage,omitempty" yaml:"homepage,omitempty"`
 	FilePath    string        `json:"file_path" yaml:"-"`
 	BaseDir     string        `json:"base_dir" yaml:"-"`
@@ -42,6 +43,7 @@ type SkillMetadata struct {
 	Always   bool         `json:"always,omitempty" yaml:"always,omitempty"`
 	Requires Requirements `json:"requires,omitempty" yaml:"requires,omitempty"`
 	Install  []InstallSpec `json:"install,omitempty" yaml:"install,omitempty"`
+	Tools    []ToolSpec    `json:"tools,omitempty" yaml:"tools,omitempty"` // tools the skill's instructions may use
 }
 
 // Requirements defines what a skill needs to be eligible
@@ -63,11 +65,19 @@ type InstallSpec struct {
 	Bins    []string `json:"bins,omitempty" yaml:"bins,omitempty"`
 }
 
+// ToolSpec declares a tool a skill uses. For shell_execute, Bins limits the
+// programs its commands may run.
+type ToolSpec struct {
+	Name string   `json:"name" yaml:"name"`
+	Bins []string `json:"bins,omitempty" yaml:"bins,omitempty"`
+}
+
 // skillFrontmatter is the raw YAML structure in SKILL.md frontmatter.
 // Supports both our flat format and openclaw's nested {"openclaw": {...}} format.
 type skillFrontmatter struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+	Version     string `yaml:"version,omitempty"`
 	Homepage    string `yaml:"homepage,omitempty"`
 	Metadata    any    `yaml:"metadata,omitempty"` // Can be SkillMetadata or {"openclaw": SkillMetadata}
 }
@@ -98,6 +108,7 @@ func ParseSkillMD(path string) (*SkillEntry, error) {
 	entry := &SkillEntry{
 		Name:        fm.Name,
 		Description: fm.Description,
+		Version:     fm.Version,
 		Homepage:    fm.Homepage,
 		FilePath:    path,
 		BaseDir:     filepath.Dir(path),
@@ -115,21 +126,18 @@ func resolveMetadata(raw any) SkillMetadata {
 		return SkillMetadata{}
 	}
 
-	// Re-marshal and try to unmarshal as our SkillMetadata directly
+	// Unwrap openclaw format: {"openclaw": {...}}
+	if m, ok := raw.(map[string]any); ok {
+		if nested, ok := m["openclaw"]; ok {
+			raw = nested
+		}
+	}
+
+	// Re-marshal and unmarshal as our SkillMetadata
 	data, err := yaml.Marshal(raw)
 	if err != nil {
 		return SkillMetadata{}
 	}
-
-	// Try openclaw format: {"openclaw": {...}}
-	var openclawWrapper struct {
-		OpenClaw SkillMetadata `yaml:"openclaw"`
-	}
-	if err := yaml.Unmarshal(data, &openclawWrapper); err == nil && openclawWrapper.OpenClaw.Emoji != "" {
-		return openclawWrapper.OpenClaw
-	}
-
-	// Try flat format
 	var meta SkillMetadata
 	if err := yaml.Unmarshal(data, &meta); err == nil {
 		return meta
@@ -314,12 +322,25 @@ func managedSkillsDir() string {
 	return filepath.Join(home, ".lingti", "skills")
 }
 
-// HasBinary checks if a binary exists in PATH
+// HasBinary checks if a binary exists in PATH or in SetupBinDir
 func HasBinary(name string) bool {
-	_, err := exec.LookPath(name)
+	_, err := LookBinary(name)
 	return err == nil
 }
 
+// LookBinary finds a binary in PATH, then in SetupBinDir where download
+// installers put binaries
+func LookBinary(name string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err == nil || !validBinName(name) {
+		return path, err
+	}
+	if path, setupErr := exec.LookPath(filepath.Join(SetupBinDir(), name)); setupErr == nil {
+		return path, nil
+	}
+	return "", err
+}
+
 // RuntimeOS returns the current OS in the format used by skill metadata
 func RuntimeOS() string {
 	return runtime.GOOS
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"text/template"
 	"time"
@@ -38,8 +39,8 @@ func (e *ShellExecutor) Execute(ctx ExecutionContext, action Action) ExecutionRe
 		}
 	}
 
-	// Template substitution
-	command = substituteVariables(command, ctx)
+	// Template substitution; values come from chat, so they're quoted
+	command = substituteVariables(command, ctx, shellQuote)
 
 	// Safety check
 	if containsDangerousCommand(command) {
@@ -115,7 +116,7 @@ func (e *HTTPExecutor) Execute(ctx ExecutionContext, action Action) ExecutionRes
 	}
 
 	// Template substitution
-	url = substituteVariables(url, ctx)
+	url = substituteVariables(url, ctx, nil)
 
 	method := "GET"
 	if m, ok := action.Config["method"].(string); ok {
@@ -132,7 +133,7 @@ func (e *HTTPExecutor) Execute(ctx ExecutionContext, action Action) ExecutionRes
 
 	var body io.Reader
 	if b, ok := action.Config["body"].(string); ok {
-		b = substituteVariables(b, ctx)
+		b = substituteVariables(b, ctx, nil)
 		body = strings.NewReader(b)
 	}
 
@@ -148,7 +149,7 @@ func (e *HTTPExecutor) Execute(ctx ExecutionContext, action Action) ExecutionRes
 	if headers, ok := action.Config["headers"].(map[string]any); ok {
 		for key, val := range headers {
 			if v, ok := val.(string); ok {
-				req.Header.Set(key, substituteVariables(v, ctx))
+				req.Header.Set(key, substituteVariables(v, ctx, nil))
 			}
 		}
 	}
@@ -205,7 +206,7 @@ func (e *PromptExecutor) Execute(ctx ExecutionContext, action Action) ExecutionR
 	}
 
 	// Template substitution
-	prompt = substituteVariables(prompt, ctx)
+	prompt = substituteVariables(prompt, ctx, nil)
 
 	if e.Handler == nil {
 		return ExecutionResult{
@@ -229,6 +230,60 @@ func (e *PromptExecutor) Execute(ctx ExecutionContext, action Action) ExecutionR
 	}
 }
 
+// ToolExecutor runs a registered tool through the host's tool executor
+type ToolExecutor struct {
+	Handler func(ctx context.Context, name string, args map[string]any) (string, error)
+}
+
+// NewToolExecutor creates a new tool executor
+func NewToolExecutor(handler func(ctx context.Context, name string, args map[string]any) (string, error)) *ToolExecutor {
+	return &ToolExecutor{Handler: handler}
+}
+
+// Execute runs a tool
+func (e *ToolExecutor) Execute(ctx ExecutionContext, action Action) ExecutionResult {
+	name, ok := action.Config["tool"].(string)
+	if !ok || name == "" {
+		return ExecutionResult{
+			Success: false,
+			Error:   fmt.Errorf("tool action requires 'tool' config"),
+		}
+	}
+
+	if e.Handler == nil {
+		return ExecutionResult{
+			Success: false,
+			Error:   fmt.Errorf("no tool handler configured"),
+		}
+	}
+
+	// Template substitution in string arguments
+	args := make(map[string]any)
+	if raw, ok := action.Config["arguments"].(map[string]any); ok {
+		for key, val := range raw {
+			if v, ok := val.(string); ok {
+				val = substituteVariables(v, ctx, nil)
+			}
+			args[key] = val
+		}
+	}
+
+	result, err := e.Handler(ctx.Context, name, args)
+	if err != nil {
+		return ExecutionResult{
+			Success:  false,
+			Output:   result,
+			Error:    err,
+			Continue: action.Config["continue_on_error"] == true,
+		}
+	}
+
+	return ExecutionResult{
+		Success: true,
+		Output:  result,
+	}
+}
+
 // WorkflowExecutor executes multi-step workflows
 type WorkflowExecutor struct {
 	Registry *Registry
@@ -304,44 +359,57 @@ func (e *WorkflowExecutor) Execute(ctx ExecutionContext, action Action) Executio
 
 // Helper functions
 
-func substituteVariables(text string, ctx ExecutionContext) string {
-	// Replace context variables
-	text = strings.ReplaceAll(text, "{{.Message}}", ctx.Message)
-	text = strings.ReplaceAll(text, "{{.SessionID}}", ctx.SessionID)
-	text = strings.ReplaceAll(text, "{{.UserID}}", ctx.UserID)
-	text = strings.ReplaceAll(text, "{{.Platform}}", ctx.Platform)
-
-	// Replace match groups
-	for i, match := range ctx.Matches {
-		text = strings.ReplaceAll(text, fmt.Sprintf("{{.Match%d}}", i), match)
+// substituteVariables fills {{.Name}} placeholders in an action's config.
+// Environment variables and templates are expanded in the skill's own text
+// only: the values, which may come from chat, are inserted once, passed
+// through quote if it's set, and never expanded again.
+func substituteVariables(text string, ctx ExecutionContext, quote func(string) string) string {
+	if quote == nil {
+		quote = func(s string) string { return s }
 	}
 
-	// Replace custom variables
+	data := make(map[string]any)
+	variables := make(map[string]string, len(ctx.Variables))
 	for key, val := range ctx.Variables {
-		text = strings.ReplaceAll(text, fmt.Sprintf("{{.%s}}", key), val)
+		variables[key] = quote(val)
+		data[key] = variables[key]
 	}
+	matches := make([]string, len(ctx.Matches))
+	for i, match := range ctx.Matches {
+		matches[i] = quote(match)
+		data[fmt.Sprintf("Match%d", i)] = matches[i]
+	}
+	data["Message"] = quote(ctx.Message)
+	data["SessionID"] = quote(ctx.SessionID)
+	data["UserID"] = quote(ctx.UserID)
+	data["Platform"] = quote(ctx.Platform)
+	data["Matches"] = matches
+	data["Variables"] = variables
 
-	// Environment variables
 	text = os.ExpandEnv(text)
 
-	// Use text/template for more complex substitutions
 	tmpl, err := template.New("cmd").Parse(text)
-	if err == nil {
-		var buf bytes.Buffer
-		tmpl.Execute(&buf, map[string]any{
-			"Message":   ctx.Message,
-			"SessionID": ctx.SessionID,
-			"UserID":    ctx.UserID,
-			"Platform":  ctx.Platform,
-			"Matches":   ctx.Matches,
-			"Variables": ctx.Variables,
+	if err != nil {
+		// Not a valid template: fill the simple placeholders only
+		return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+			if v, ok := data[placeholderPattern.FindStringSubmatch(m)[1]].(string); ok {
+				return v
+			}
+			return m
 		})
-		if buf.Len() > 0 {
-			text = buf.String()
-		}
 	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return text
+	}
+	return buf.String()
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*\.([A-Za-z0-9_-]+)\s*\}\}`)
 
-	return text
+// shellQuote quotes s as a single /bin/sh word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 func containsDangerousCommand(cmd string) bool {
@@ -37,7 +37,7 @@ func FormatList(report StatusReport, opts FormatListOptions) string {
 		return string(data)
 	}
 
-	if len(skills) == 0 {
+	if len(skills) == 0 && len(report.TriggerSkills) == 0 {
 		if opts.Eligible {
 			return "No eligible skills found. Run `lingti-bot skills list` to see all skills."
 		}
@@ -59,10 +59,11 @@ func FormatList(report StatusReport, opts FormatListOptions) string {
 	nameW := 20
 	descW := 36
 	sourceW := 10
+	signatureW := 16
 
 	// Header
-	fmt.Fprintf(&b, "  %-*s %-*s %-*s %-*s",
-		statusW, "Status", nameW, "Skill", descW, "Description", sourceW, "Source")
+	fmt.Fprintf(&b, "  %-*s %-*s %-*s %-*s %-*s",
+		statusW, "Status", nameW, "Skill", descW, "Description", sourceW, "Source", signatureW, "Signature")
 	if opts.Verbose {
 		b.WriteString("  Missing")
 	}
@@ -73,12 +74,14 @@ func FormatList(report StatusReport, opts FormatListOptions) string {
 		name := formatSkillName(skill.SkillEntry)
 		desc := truncate(skill.Description, descW)
 		source := string(skill.Source)
+		signature := formatSignature(skill.Signature, skill.Publisher)
 
-		fmt.Fprintf(&b, "  %-*s %-*s %s%-*s%s %-*s",
+		fmt.Fprintf(&b, "  %-*s %-*s %s%-*s%s %-*s %-*s",
 			statusW+colorLen(status), status,
 			nameW+colorLen(name), name,
 			colorGray, descW, desc, colorReset,
-			sourceW, source)
+			sourceW, source,
+			signatureW+colorLen(signature), signature)
 
 		if opts.Verbose {
 			missing := formatMissingSummary(skill.Missing)
@@ -89,9 +92,64 @@ func FormatList(report StatusReport, opts FormatListOptions) string {
 		b.WriteString("\n")
 	}
 
+	if len(report.TriggerSkills) > 0 {
+		formatTriggerSkills(&b, report.TriggerSkills, opts.Eligible)
+	}
+
 	return b.String()
 }
 
+// formatTriggerSkills appends the JSON trigger/action skills table
+func formatTriggerSkills(b *strings.Builder, skills []*Skill, enabledOnly bool) {
+	statusW := 12
+	nameW := 20
+	triggerW := 36
+
+	fmt.Fprintf(b, "\n%sTrigger skills%s %s(%s)%s\n\n", colorBold, colorReset, colorGray, ShortenHomePath(managedSkillsDir()), colorReset)
+	fmt.Fprintf(b, "  %-*s %-*s %-*s %s\n", statusW, "Status", nameW, "Skill", triggerW, "Triggers", "Description")
+
+	for _, skill := range skills {
+		if enabledOnly && !skill.Enabled {
+			continue
+		}
+		status := colorGreen + "✓ enabled" + colorReset
+		if !skill.Enabled {
+			status = colorYellow + "⏸ disabled" + colorReset
+		}
+		name := colorCyan + skill.ID + colorReset
+		triggers := truncate(FormatTriggers(skill.Triggers), triggerW)
+
+		fmt.Fprintf(b, "  %-*s %-*s %-*s %s%s%s\n",
+			statusW+colorLen(status), status,
+			nameW+colorLen(name), name,
+			triggerW, triggers,
+			colorGray, skill.Description, colorReset)
+	}
+}
+
+// FormatTriggers summarizes a skill's triggers, e.g. "/weather, re:^天气(.*), @0 8 * * *"
+func FormatTriggers(triggers []Trigger) string {
+	parts := make([]string, 0, len(triggers))
+	for _, t := range triggers {
+		switch t.Type {
+		case TriggerCommand:
+			parts = append(parts, "/"+strings.TrimPrefix(t.Command, "/"))
+		case TriggerPattern:
+			parts = append(parts, "re:"+t.Pattern)
+		case TriggerKeyword:
+			parts = append(parts, fmt.Sprintf("%q", t.Pattern))
+		case TriggerSchedule:
+			parts = append(parts, "@"+t.Schedule)
+		default:
+			parts = append(parts, string(t.Type))
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
 // FormatInfo formats detailed info for a single skill
 func FormatInfo(report StatusReport, name string, asJSON bool) string {
 	var skill *SkillStatus
@@ -138,7 +196,13 @@ func FormatInfo(report StatusReport, name string, asJSON bool) string {
 	// Details
 	b.WriteString(colorBold + "Details:" + colorReset + "\n")
 	fmt.Fprintf(&b, "  %sSource:%s   %s\n", colorGray, colorReset, skill.Source)
+	if skill.Version != "" {
+		fmt.Fprintf(&b, "  %sVersion:%s  %s\n", colorGray, colorReset, skill.Version)
+	}
 	fmt.Fprintf(&b, "  %sPath:%s     %s\n", colorGray, colorReset, ShortenHomePath(skill.FilePath))
+	if skill.Signature != "" {
+		fmt.Fprintf(&b, "  %sSigned:%s   %s\n", colorGray, colorReset, formatSignature(skill.Signature, skill.Publisher))
+	}
 	if skill.Homepage != "" {
 		fmt.Fprintf(&b, "  %sHomepage:%s %s\n", colorGray, colorReset, skill.Homepage)
 	}
@@ -212,13 +276,22 @@ func FormatInfo(report StatusReport, name string, asJSON bool) string {
 			}
 			fmt.Fprintf(&b, "  %s→%s %s\n", colorYellow, colorReset, label)
 		}
+		fmt.Fprintf(&b, "  %sRun: lingti-bot skills setup %s%s\n", colorGray, skill.Name, colorReset)
+	}
+
+	if len(skill.Diagnostics) > 0 {
+		b.WriteString("\n" + colorBold + "Diagnostics:" + colorReset + "\n")
+		for _, d := range skill.Diagnostics {
+			b.WriteString(formatDiagnostic(d, false))
+		}
 	}
 
 	return b.String()
 }
 
-// FormatCheck formats a summary check of all skills
-func FormatCheck(report StatusReport, asJSON bool) string {
+// FormatCheck formats a summary check of all skills. With strict, diags
+// (from report.Lint) are listed in full.
+func FormatCheck(report StatusReport, asJSON, strict bool, diags []Diagnostic) string {
 	eligible, disabled, missing := report.CountByStatus()
 
 	if asJSON {
@@ -233,6 +306,9 @@ func FormatCheck(report StatusReport, asJSON bool) string {
 			"disabled":             skillNames(report.DisabledSkills()),
 			"missing_requirements": report.MissingSkills(),
 		}
+		if strict {
+			result["diagnostics"] = diags
+		}
 		data, _ := json.MarshalIndent(result, "", "  ")
 		return string(data)
 	}
@@ -268,9 +344,41 @@ func FormatCheck(report StatusReport, asJSON bool) string {
 		}
 	}
 
+	if strict {
+		b.WriteString("\n" + colorBold + "Diagnostics:" + colorReset + "\n")
+		if len(diags) == 0 {
+			b.WriteString("  " + colorGreen + "✓" + colorReset + " no problems found\n")
+		}
+		for _, d := range diags {
+			b.WriteString(formatDiagnostic(d, true))
+		}
+	} else if invalid := report.InvalidSkills(); len(invalid) > 0 {
+		fmt.Fprintf(&b, "\n%s!%s %d skill(s) have frontmatter errors: %s %s(run with --strict for details)%s\n",
+			colorRed, colorReset, len(invalid), strings.Join(skillNames(invalid), ", "), colorGray, colorReset)
+	}
+
 	return b.String()
 }
 
+// formatDiagnostic renders one diagnostic line, with its file if withFile
+func formatDiagnostic(d Diagnostic, withFile bool) string {
+	mark := colorRed + "✗" + colorReset
+	if d.Severity == SeverityWarning {
+		mark = colorYellow + "!" + colorReset
+	}
+	location := d.Field
+	if withFile {
+		location = ShortenHomePath(d.File)
+		if d.Field != "" {
+			location += ": " + d.Field
+		}
+	}
+	if location == "" {
+		return fmt.Sprintf("  %s %s\n", mark, d.Message)
+	}
+	return fmt.Sprintf("  %s %s%s:%s %s\n", mark, colorGray, location, colorReset, d.Message)
+}
+
 // --- helpers ---
 
 func formatStatus(status EligibilityStatus) string {
@@ -286,6 +394,20 @@ func formatStatus(status EligibilityStatus) string {
 	}
 }
 
+// formatSignature renders the signature status of an installed skill,
+// e.g. "✓ acme"; skills not installed with 'skills install' have none
+func formatSignature(status SignatureStatus, publisher string) string {
+	switch status {
+	case SignatureVerified:
+		return colorGreen + "✓ " + publisher + colorReset
+	case SignatureUntrusted:
+		return colorYellow + "! " + publisher + " (untrusted)" + colorReset
+	case SignatureUnsigned:
+		return colorYellow + "unsigned" + colorReset
+	}
+	return ""
+}
+
 func formatSkillName(entry SkillEntry) string {
 	emoji := entry.Metadata.Emoji
 	if emoji == "" {
@@ -0,0 +1,159 @@
+package skills
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Index is a catalogue of installable skills. It is a JSON file that can be
+// hosted anywhere (http(s) URL) or kept locally for offline use:
+//
+//	{
+//	  "skills": [
+//	    {"name": "weather", "version": "1.2.0", "description": "...",
+//	     "source": "https://github.com/acme/skills.git@v1.2.0", "path": "weather"}
+//	  ]
+//	}
+//
+// Relative sources are resolved against the index location.
+type Index struct {
+	Skills   []IndexEntry `json:"skills"`
+	location string
+}
+
+// IndexEntry is one installable skill version
+type IndexEntry struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Source      string   `json:"source"`             // git URL, archive, or directory, optionally with @ref
+	Path        string   `json:"path,omitempty"`     // skill directory within the source
+	Tags        []string `json:"tags,omitempty"`     // extra search terms
+	Checksum    string   `json:"checksum,omitempty"` // expected sha256 of the skill directory
+}
+
+// LoadIndex reads an index from an http(s) URL or a local file
+func LoadIndex(location string) (*Index, error) {
+	if location == "" {
+		return nil, fmt.Errorf("no skill index configured (set skills.index in bot.yaml or pass --index)")
+	}
+	data, err := readLocation(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skill index: %w", err)
+	}
+
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse skill index %s: %w", location, err)
+	}
+	index.location = location
+
+	for i, entry := range index.Skills {
+		if entry.Name == "" || entry.Source == "" {
+			return nil, fmt.Errorf("skill index %s: entry %d requires name and source", location, i)
+		}
+		index.Skills[i].Source = index.resolve(entry.Source)
+	}
+	return &index, nil
+}
+
+// resolve makes a relative source absolute with respect to the index location
+func (ix *Index) resolve(source string) string {
+	if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+		return source
+	}
+	if isURL(ix.location) {
+		base, err := url.Parse(ix.location)
+		if err != nil {
+			return source
+		}
+		ref, err := url.Parse(source)
+		if err != nil {
+			return source
+		}
+		return base.ResolveReference(ref).String()
+	}
+	return filepath.Join(filepath.Dir(ix.location), source)
+}
+
+// Search returns entries whose name, description or tags contain query
+// (case-insensitive), sorted by name and newest version first. An empty
+// query lists every entry.
+func (ix *Index) Search(query string) []IndexEntry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var results []IndexEntry
+	for _, entry := range ix.Skills {
+		haystack := strings.ToLower(entry.Name + " " + entry.Description + " " + strings.Join(entry.Tags, " "))
+		if strings.Contains(haystack, query) {
+			results = append(results, entry)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return CompareVersions(results[i].Version, results[j].Version) > 0
+	})
+	return results
+}
+
+// Resolve finds a skill by name. An empty version selects the newest entry.
+func (ix *Index) Resolve(name, version string) (IndexEntry, error) {
+	var best *IndexEntry
+	for i, entry := range ix.Skills {
+		if entry.Name != name {
+			continue
+		}
+		if version != "" {
+			if strings.TrimPrefix(entry.Version, "v") == strings.TrimPrefix(version, "v") {
+				return entry, nil
+			}
+			continue
+		}
+		if best == nil || CompareVersions(entry.Version, best.Version) > 0 {
+			best = &ix.Skills[i]
+		}
+	}
+	if best == nil {
+		if version != "" {
+			return IndexEntry{}, fmt.Errorf("skill %s@%s not found in index %s", name, version, ix.location)
+		}
+		return IndexEntry{}, fmt.Errorf("skill %s not found in index %s", name, ix.location)
+	}
+	return *best, nil
+}
+
+// CompareVersions compares dotted versions numerically ("1.10" > "1.9"),
+// ignoring a leading "v". Non-numeric parts compare as strings.
+func CompareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,798 @@
+package skills
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// sourceKind is how a skill source is fetched
+type sourceKind string
+
+const (
+	sourceGit     sourceKind = "git"
+	sourceArchive sourceKind = "archive"
+	sourceDir     sourceKind = "dir"
+	sourceJSON    sourceKind = "json"
+	sourceIndex   sourceKind = "index"
+)
+
+// skillSource is a parsed install argument: <git-url|tarball|dir|name>[@ref]
+type skillSource struct {
+	kind     sourceKind
+	location string
+	ref      string
+	path     string // skill directory within the source (index entries)
+}
+
+// parseSource classifies an install argument
+func parseSource(spec string) (skillSource, error) {
+	location, ref := splitRef(spec)
+	if location == "" {
+		return skillSource{}, fmt.Errorf("empty skill source")
+	}
+
+	lower := strings.ToLower(location)
+	switch {
+	case strings.HasSuffix(lower, ".json"):
+		return skillSource{kind: sourceJSON, location: location, ref: ref}, nil
+	case isArchive(lower):
+		return skillSource{kind: sourceArchive, location: location, ref: ref}, nil
+	case strings.HasPrefix(lower, "git@"), strings.HasPrefix(lower, "git://"),
+		strings.HasPrefix(lower, "ssh://"), strings.HasSuffix(lower, ".git"), isURL(lower):
+		return skillSource{kind: sourceGit, location: location, ref: ref}, nil
+	}
+
+	if info, err := os.Stat(location); err == nil {
+		if !info.IsDir() {
+			return skillSource{}, fmt.Errorf("%s is not a directory, archive, or .json skill", location)
+		}
+		abs, err := filepath.Abs(location)
+		if err != nil {
+			return skillSource{}, err
+		}
+		return skillSource{kind: sourceDir, location: abs, ref: ref}, nil
+	}
+	if strings.ContainsAny(location, `/\`) {
+		return skillSource{}, fmt.Errorf("skill source not found: %s", location)
+	}
+	return skillSource{kind: sourceIndex, location: location, ref: ref}, nil
+}
+
+// splitRef splits "source@ref". The @ of scp-style git URLs (git@host:repo)
+// and of URL credentials (https://user@host/...) is not a ref separator.
+func splitRef(spec string) (string, string) {
+	i := strings.LastIndex(spec, "@")
+	if i <= 0 || i == len(spec)-1 {
+		return spec, ""
+	}
+	location, ref := spec[:i], spec[i+1:]
+	if strings.Contains(ref, ":") {
+		return spec, ""
+	}
+	if scheme := strings.Index(location, "://"); scheme >= 0 && !strings.Contains(location[scheme+3:], "/") {
+		return spec, ""
+	}
+	return location, ref
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func isArchive(s string) bool {
+	return strings.HasSuffix(s, ".tar.gz") || strings.HasSuffix(s, ".tgz") || strings.HasSuffix(s, ".zip")
+}
+
+// readLocation reads a local file or downloads an http(s) URL
+func readLocation(location string) ([]byte, error) {
+	if !isURL(location) {
+		return os.ReadFile(location)
+	}
+	resp, err := http.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: HTTP %d", location, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Installer installs skills into the managed skills directory and records
+// them in its lockfile
+type Installer struct {
+	Dir   string // managed skills directory (default ~/.lingti/skills)
+	Index string // skill index location (URL or file) for installs by name
+
+	// TrustedKeys maps publisher names to their "ed25519:<base64>" public
+	// keys. Skills must be signed by one of them unless AllowUnsigned is set.
+	TrustedKeys   map[string]string
+	AllowUnsigned bool
+}
+
+// NewInstaller creates an installer for the managed skills directory
+func NewInstaller(index string) *Installer {
+	return &Installer{Dir: managedSkillsDir(), Index: index}
+}
+
+// InstallResult describes one installed skill
+type InstallResult struct {
+	Name     string
+	Kind     string
+	Version  string
+	Source   string
+	Replaced bool         // an existing skill with the same name was replaced
+	Warnings []Diagnostic // frontmatter warnings; skills with errors are not installed
+
+	Signature SignatureStatus
+	Publisher string // publisher of a signed skill
+}
+
+// Install installs every skill found in a source: a git URL, a .tar.gz/.tgz
+// or .zip archive (file or URL), a local directory, a JSON trigger skill, or
+// a skill name looked up in the index. A git ref or index version may be
+// appended as @ref.
+func (in *Installer) Install(spec string) ([]InstallResult, error) {
+	src, err := parseSource(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := LoadLockFile(in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	results, err := in.install(src, lock)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock.Save(in.Dir); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// install fetches a source and installs its skills, updating lock in memory
+func (in *Installer) install(src skillSource, lock *LockFile) ([]InstallResult, error) {
+	switch src.kind {
+	case sourceIndex:
+		index, err := LoadIndex(in.Index)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := index.Resolve(src.location, src.ref)
+		if err != nil {
+			return nil, err
+		}
+		return in.installIndexEntry(entry, src.ref, lock)
+	case sourceJSON:
+		if src.ref != "" {
+			return nil, fmt.Errorf("@%s: refs are only supported for git sources and index names", src.ref)
+		}
+		return in.installTrigger(src, lock)
+	}
+
+	fetched, err := fetchSource(src)
+	if err != nil {
+		return nil, err
+	}
+	defer fetched.cleanup()
+
+	dirs, err := findSkillDirs(fetched.root, src.path)
+	if err != nil {
+		return nil, err
+	}
+	// Validate and verify every skill first so that a bad one doesn't leave
+	// the source half installed
+	for _, dir := range dirs {
+		if _, err := ValidateSkillDir(dir); err != nil {
+			return nil, err
+		}
+		skill, err := ParseSkillMD(filepath.Join(dir, "SKILL.md"))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := in.checkSignature(dir, skill.Name); err != nil {
+			return nil, err
+		}
+	}
+	var results []InstallResult
+	for _, dir := range dirs {
+		result, err := in.installDir(dir, fetched, src, lock, "")
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// installIndexEntry installs the skill an index entry points to
+func (in *Installer) installIndexEntry(entry IndexEntry, requested string, lock *LockFile) ([]InstallResult, error) {
+	src, err := parseSource(entry.Source)
+	if err != nil {
+		return nil, fmt.Errorf("index entry %s: %w", entry.Name, err)
+	}
+	if src.kind == sourceIndex || src.kind == sourceJSON {
+		return nil, fmt.Errorf("index entry %s: source must be a git URL, archive, or directory", entry.Name)
+	}
+	src.path = entry.Path
+
+	fetched, err := fetchSource(src)
+	if err != nil {
+		return nil, err
+	}
+	defer fetched.cleanup()
+
+	dirs, err := findSkillDirs(fetched.root, src.path)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		skill, err := ParseSkillMD(filepath.Join(dir, "SKILL.md"))
+		if err != nil || skill.Name != entry.Name {
+			continue
+		}
+		if entry.Checksum != "" {
+			sum, err := Checksum(dir)
+			if err != nil {
+				return nil, err
+			}
+			if sum != entry.Checksum {
+				return nil, fmt.Errorf("checksum mismatch for %s: index says %s, got %s", entry.Name, entry.Checksum, sum)
+			}
+		}
+		result, err := in.installDir(dir, fetched, src, lock, in.Index)
+		if err != nil {
+			return nil, err
+		}
+		locked := lock.Skills[result.Name]
+		locked.Ref = requested
+		if entry.Version != "" {
+			locked.Version, result.Version = entry.Version, entry.Version
+		}
+		return []InstallResult{result}, nil
+	}
+	return nil, fmt.Errorf("index entry %s: no skill named %s found in %s", entry.Name, entry.Name, entry.Source)
+}
+
+// installDir copies one skill directory into the managed dir
+func (in *Installer) installDir(dir string, fetched *fetchedSource, src skillSource, lock *LockFile, index string) (InstallResult, error) {
+	diags, err := ValidateSkillDir(dir)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	skill, err := ParseSkillMD(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		return InstallResult{}, err
+	}
+	if strings.ContainsAny(skill.Name, `/\`) || skill.Name == "." || skill.Name == ".." {
+		return InstallResult{}, fmt.Errorf("invalid skill name %q in %s", skill.Name, dir)
+	}
+	signature, err := in.checkSignature(dir, skill.Name)
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	checksum, err := Checksum(dir)
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	dest := filepath.Join(in.Dir, skill.Name)
+	_, statErr := os.Stat(dest)
+	replaced := statErr == nil
+	if err := replaceTree(dir, dest); err != nil {
+		return InstallResult{}, err
+	}
+
+	version := skill.Version
+	if version == "" {
+		version = fetched.version
+	}
+	rel, _ := filepath.Rel(fetched.root, dir)
+	lock.Skills[skill.Name] = &LockEntry{
+		Name:        skill.Name,
+		Kind:        KindSkillMD,
+		Source:      src.location,
+		Ref:         src.ref,
+		Path:        filepath.ToSlash(rel),
+		Index:       index,
+		Version:     version,
+		Checksum:    checksum,
+		Signature:   signature.Status,
+		Publisher:   signature.Publisher,
+		InstalledAt: time.Now().UTC(),
+	}
+	result := InstallResult{Name: skill.Name, Kind: KindSkillMD, Version: version, Source: src.location, Replaced: replaced, Warnings: diags}
+	result.Signature, result.Publisher = signature.Status, signature.Publisher
+	return result, nil
+}
+
+// installTrigger installs a JSON trigger/action skill
+func (in *Installer) installTrigger(src skillSource, lock *LockFile) ([]InstallResult, error) {
+	if !in.AllowUnsigned {
+		return nil, fmt.Errorf("JSON trigger skills can't be signed; use --allow-unsigned to install %s", src.location)
+	}
+	data, err := readLocation(src.location)
+	if err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(filepath.Join(in.Dir, triggerFileName(data)))
+	skill, err := InstallSkillFile(data, in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := Checksum(filepath.Join(in.Dir, skill.ID+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	location := src.location
+	if !isURL(location) {
+		if abs, err := filepath.Abs(location); err == nil {
+			location = abs
+		}
+	}
+	lock.Skills[skill.ID] = &LockEntry{
+		Name:        skill.ID,
+		Kind:        KindTrigger,
+		Source:      location,
+		Version:     skill.Version,
+		Checksum:    checksum,
+		Signature:   SignatureUnsigned,
+		InstalledAt: time.Now().UTC(),
+	}
+	return []InstallResult{{Name: skill.ID, Kind: KindTrigger, Version: skill.Version, Source: location, Replaced: statErr == nil, Signature: SignatureUnsigned}}, nil
+}
+
+// triggerFileName returns the file a JSON skill is installed as, or "" if it doesn't parse
+func triggerFileName(data []byte) string {
+	var skill Skill
+	if err := json.Unmarshal(data, &skill); err != nil || skill.ID == "" {
+		return ""
+	}
+	return skill.ID + ".json"
+}
+
+// UpdateResult describes the outcome of updating one skill
+type UpdateResult struct {
+	Name       string
+	OldVersion string
+	NewVersion string
+	Status     string // "updated", "up to date", "skipped", or "failed"
+	Detail     string
+}
+
+// Update reinstalls skills from their recorded sources. Skills installed
+// from an index without a version move to the newest indexed version; git
+// skills follow their recorded ref. Locally modified skills are skipped
+// unless force is set. An empty names list updates every locked skill.
+// Skills that were installed unsigned may stay unsigned; a signed skill must
+// remain signed by a trusted key.
+func (in *Installer) Update(names []string, force bool) ([]UpdateResult, error) {
+	lock, err := LoadLockFile(in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		names = lock.Names()
+	}
+	for _, name := range names {
+		if _, ok := lock.Skills[name]; !ok {
+			return nil, fmt.Errorf("skill %s was not installed with 'skills install'", name)
+		}
+	}
+
+	var results []UpdateResult
+	for _, name := range names {
+		results = append(results, in.updateOne(lock, lock.Skills[name], force))
+	}
+	if err := lock.Save(in.Dir); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func (in *Installer) updateOne(lock *LockFile, entry *LockEntry, force bool) UpdateResult {
+	result := UpdateResult{Name: entry.Name, OldVersion: entry.Version}
+
+	installed := filepath.Join(in.Dir, entry.Name)
+	if entry.Kind == KindTrigger {
+		installed += ".json"
+	}
+	current, _ := Checksum(installed)
+	if current != "" && current != entry.Checksum && !force {
+		result.Status, result.Detail = "skipped", "locally modified (use --force to overwrite)"
+		return result
+	}
+
+	installer := *in
+	if entry.Signature != SignatureVerified {
+		installer.AllowUnsigned = true
+	}
+
+	// Install into a scratch lock so that failures leave the entry untouched
+	scratch := &LockFile{Version: lock.Version, Skills: make(map[string]*LockEntry)}
+	var err error
+	switch {
+	case entry.Index != "":
+		installer.Index = entry.Index
+		var index *Index
+		if index, err = LoadIndex(entry.Index); err == nil {
+			var indexed IndexEntry
+			if indexed, err = index.Resolve(entry.Name, entry.Ref); err == nil {
+				_, err = installer.installIndexEntry(indexed, entry.Ref, scratch)
+			}
+		}
+	case entry.Kind == KindTrigger:
+		_, err = installer.installTrigger(skillSource{kind: sourceJSON, location: entry.Source}, scratch)
+	default:
+		src, perr := parseSource(entry.Source)
+		if perr != nil {
+			err = perr
+			break
+		}
+		src.ref, src.path = entry.Ref, entry.Path
+		_, err = installer.install(src, scratch)
+	}
+	if err != nil {
+		result.Status, result.Detail = "failed", err.Error()
+		return result
+	}
+
+	updated, ok := scratch.Skills[entry.Name]
+	if !ok {
+		result.Status, result.Detail = "failed", "skill no longer found in its source"
+		return result
+	}
+	for name, e := range scratch.Skills {
+		lock.Skills[name] = e
+	}
+	result.NewVersion = updated.Version
+	if updated.Checksum == current {
+		lock.Skills[entry.Name].InstalledAt = entry.InstalledAt
+		result.Status = "up to date"
+	} else {
+		result.Status = "updated"
+	}
+	return result
+}
+
+// Remove deletes an installed skill from the managed dir and the lockfile
+func (in *Installer) Remove(name string) error {
+	lock, err := LoadLockFile(in.Dir)
+	if err != nil {
+		return err
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid skill name %q", name)
+	}
+
+	removed := false
+	dir := filepath.Join(in.Dir, name)
+	if _, err := os.Stat(filepath.Join(dir, "SKILL.md")); err == nil {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+		removed = true
+	}
+	file := filepath.Join(in.Dir, name+".json")
+	if _, err := os.Stat(file); err == nil {
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		removed = true
+	}
+	if _, ok := lock.Skills[name]; ok {
+		delete(lock.Skills, name)
+		removed = true
+	}
+	if !removed {
+		return fmt.Errorf("skill %s is not installed in %s", name, ShortenHomePath(in.Dir))
+	}
+	return lock.Save(in.Dir)
+}
+
+// Search looks up skills in the index
+func (in *Installer) Search(query string) ([]IndexEntry, error) {
+	index, err := LoadIndex(in.Index)
+	if err != nil {
+		return nil, err
+	}
+	return index.Search(query), nil
+}
+
+// fetchedSource is a source checked out into a temporary directory
+type fetchedSource struct {
+	root    string
+	version string // commit for git sources
+	cleanup func()
+}
+
+// fetchSource makes a source available on disk
+func fetchSource(src skillSource) (*fetchedSource, error) {
+	switch src.kind {
+	case sourceDir:
+		if src.ref != "" {
+			return nil, fmt.Errorf("@%s: refs are only supported for git sources and index names", src.ref)
+		}
+		return &fetchedSource{root: src.location, cleanup: func() {}}, nil
+	case sourceGit:
+		return fetchGit(src)
+	case sourceArchive:
+		if src.ref != "" {
+			return nil, fmt.Errorf("@%s: refs are only supported for git sources and index names", src.ref)
+		}
+		return fetchArchive(src)
+	}
+	return nil, fmt.Errorf("cannot fetch %s source %s", src.kind, src.location)
+}
+
+// fetchGit clones a repository, checking out src.ref when set
+func fetchGit(src skillSource) (*fetchedSource, error) {
+	if !HasBinary("git") {
+		return nil, fmt.Errorf("git is required to install from %s", src.location)
+	}
+	tmp, err := os.MkdirTemp("", "lingti-skill-*")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+
+	dir := filepath.Join(tmp, "repo")
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if src.ref != "" {
+		args = append(args, "--branch", src.ref)
+	}
+	if err := runGit("", append(args, src.location, dir)...); err != nil {
+		if src.ref == "" {
+			cleanup()
+			return nil, err
+		}
+		// The ref may be a commit, which --branch can't fetch shallowly
+		os.RemoveAll(dir)
+		if err := runGit("", "clone", "--quiet", src.location, dir); err != nil {
+			cleanup()
+			return nil, err
+		}
+		if err := runGit(dir, "checkout", "--quiet", src.ref); err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--short=12", "HEAD").Output()
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	return &fetchedSource{root: dir, version: strings.TrimSpace(string(out)), cleanup: cleanup}, nil
+}
+
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// fetchArchive downloads (if needed) and extracts a .tar.gz, .tgz or .zip archive
+func fetchArchive(src skillSource) (*fetchedSource, error) {
+	data, err := readLocation(src.location)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "lingti-skill-*")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+
+	if strings.HasSuffix(strings.ToLower(src.location), ".zip") {
+		err = extractZip(data, tmp)
+	} else {
+		err = extractTarGz(data, tmp)
+	}
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	return &fetchedSource{root: tmp, cleanup: cleanup}, nil
+}
+
+// archivePath maps an archive entry to a path under dest, rejecting entries
+// that would escape it
+func archivePath(dest, name string) (string, error) {
+	target := filepath.Join(dest, filepath.FromSlash(name))
+	if target != dest && !strings.HasPrefix(target, dest+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %s escapes the extraction directory", name)
+	}
+	return target, nil
+}
+
+func extractTarGz(data []byte, dest string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decompress: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("tar read error: %w", err)
+		}
+		target, err := archivePath(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractZip(data []byte, dest string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to open zip: %w", err)
+	}
+	for _, f := range zr.File {
+		target, err := archivePath(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(target, rc, f.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes r to path, keeping only the executable bits of mode
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if mode&0111 != 0 {
+		perm = 0755
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// findSkillDirs locates skill directories in a fetched source: the source
+// root (or sub path) itself, its immediate subdirectories, or those of a
+// skills/ or bundled-skills/ directory. A single wrapping directory, as in
+// GitHub archives, is descended into.
+func findSkillDirs(root, sub string) ([]string, error) {
+	base := root
+	if sub != "" {
+		var err error
+		if base, err = archivePath(root, sub); err != nil {
+			return nil, err
+		}
+	}
+
+	for range 2 {
+		if hasSkillMD(base) {
+			return []string{base}, nil
+		}
+		var dirs []string
+		for _, parent := range []string{base, filepath.Join(base, "skills"), filepath.Join(base, "bundled-skills")} {
+			entries, _ := os.ReadDir(parent)
+			for _, e := range entries {
+				if e.IsDir() && hasSkillMD(filepath.Join(parent, e.Name())) {
+					dirs = append(dirs, filepath.Join(parent, e.Name()))
+				}
+			}
+		}
+		if len(dirs) > 0 {
+			return dirs, nil
+		}
+
+		entries, _ := os.ReadDir(base)
+		if len(entries) != 1 || !entries[0].IsDir() {
+			break
+		}
+		base = filepath.Join(base, entries[0].Name())
+	}
+	return nil, fmt.Errorf("no SKILL.md found in %s", sub)
+}
+
+func hasSkillMD(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "SKILL.md"))
+	return err == nil
+}
+
+// replaceTree copies src to dest, replacing dest. The copy is staged next
+// to dest and swapped in, so a failed copy leaves the old skill in place.
+func replaceTree(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create skill directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(dest), ".install-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	files, err := treeFiles(src)
+	if err != nil {
+		return err
+	}
+	for _, rel := range files {
+		info, err := os.Stat(filepath.Join(src, rel))
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(filepath.Join(src, rel))
+		if err != nil {
+			return err
+		}
+		err = writeFile(filepath.Join(staging, rel), f, info.Mode())
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to remove old %s: %w", dest, err)
+	}
+	if err := os.Rename(staging, dest); err != nil {
+		return fmt.Errorf("failed to install %s: %w", dest, err)
+	}
+	return nil
+}
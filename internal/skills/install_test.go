@@ -0,0 +1,220 @@
+package skills
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func writeSkillDir(t *testing.T, dir, name, version, body string) {
+	t.Helper()
+	os.MkdirAll(dir, 0755)
+	content := "---\nname: " + name + "\ndescription: test\n"
+	if version != "" {
+		content += "version: " + version + "\n"
+	}
+	content += "---\n" + body
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitRef(t *testing.T) {
+	tests := []struct{ spec, location, ref string }{
+		{"weather", "weather", ""},
+		{"weather@1.2.0", "weather", "1.2.0"},
+		{"git@github.com:acme/skills.git", "git@github.com:acme/skills.git", ""},
+		{"git@github.com:acme/skills.git@v2", "git@github.com:acme/skills.git", "v2"},
+		{"https://github.com/acme/skills@main", "https://github.com/acme/skills", "main"},
+		{"https://token@github.com/acme/skills", "https://token@github.com/acme/skills", ""},
+	}
+	for _, tt := range tests {
+		location, ref := splitRef(tt.spec)
+		if location != tt.location || ref != tt.ref {
+			t.Errorf("splitRef(%q) = %q, %q; want %q, %q", tt.spec, location, ref, tt.location, tt.ref)
+		}
+	}
+}
+
+func TestInstallFromDirectory(t *testing.T) {
+	src := t.TempDir()
+	writeSkillDir(t, filepath.Join(src, "skills", "alpha"), "alpha", "1.0.0", "Alpha")
+	writeSkillDir(t, filepath.Join(src, "skills", "beta"), "beta", "", "Beta")
+
+	in := &Installer{Dir: t.TempDir(), AllowUnsigned: true}
+	results, err := in.Install(src)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "alpha" || results[0].Version != "1.0.0" || results[0].Replaced {
+		t.Fatalf("results = %+v", results)
+	}
+	if !hasSkillMD(filepath.Join(in.Dir, "beta")) {
+		t.Error("beta was not copied")
+	}
+
+	lock, err := LoadLockFile(in.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := lock.Skills["alpha"]
+	if entry == nil || entry.Source != src || entry.Path != "skills/alpha" || entry.Kind != KindSkillMD {
+		t.Fatalf("lock entry = %+v", entry)
+	}
+	if sum, _ := Checksum(filepath.Join(in.Dir, "alpha")); sum != entry.Checksum {
+		t.Errorf("lock checksum %s does not match installed skill %s", entry.Checksum, sum)
+	}
+
+	// Update: unchanged, then a source change, then a local modification
+	if res, _ := in.Update([]string{"alpha"}, false); res[0].Status != "up to date" {
+		t.Errorf("expected up to date, got %+v", res[0])
+	}
+	writeSkillDir(t, filepath.Join(src, "skills", "alpha"), "alpha", "1.1.0", "Alpha v2")
+	if res, _ := in.Update([]string{"alpha"}, false); res[0].Status != "updated" || res[0].NewVersion != "1.1.0" {
+		t.Errorf("expected update to 1.1.0, got %+v", res[0])
+	}
+	os.WriteFile(filepath.Join(in.Dir, "alpha", "notes.txt"), []byte("mine"), 0644)
+	if res, _ := in.Update([]string{"alpha"}, false); res[0].Status != "skipped" {
+		t.Errorf("expected locally modified skill to be skipped, got %+v", res[0])
+	}
+	if res, _ := in.Update([]string{"alpha"}, true); res[0].Status != "updated" {
+		t.Errorf("expected --force to overwrite, got %+v", res[0])
+	}
+	if _, err := os.Stat(filepath.Join(in.Dir, "alpha", "notes.txt")); err == nil {
+		t.Error("forced update kept a stale local file")
+	}
+
+	if err := in.Remove("beta"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	lock, _ = LoadLockFile(in.Dir)
+	if _, ok := lock.Skills["beta"]; ok || hasSkillMD(filepath.Join(in.Dir, "beta")) {
+		t.Error("beta still installed after Remove")
+	}
+	if err := in.Remove("beta"); err == nil {
+		t.Error("expected removing a missing skill to fail")
+	}
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestInstallFromArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "weather.tar.gz")
+	os.WriteFile(archive, tarGz(t, map[string]string{
+		"weather-main/SKILL.md":       "---\nname: weather\n---\nWeather",
+		"weather-main/scripts/get.sh": "echo sunny",
+	}), 0644)
+
+	in := &Installer{Dir: t.TempDir(), AllowUnsigned: true}
+	results, err := in.Install(archive)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "weather" {
+		t.Fatalf("results = %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(in.Dir, "weather", "scripts", "get.sh")); err != nil {
+		t.Errorf("script not extracted: %v", err)
+	}
+
+	evil := filepath.Join(dir, "evil.tgz")
+	os.WriteFile(evil, tarGz(t, map[string]string{"../../escape/SKILL.md": "---\nname: x\n---"}), 0644)
+	if _, err := in.Install(evil); err == nil {
+		t.Error("expected archive path traversal to be rejected")
+	}
+}
+
+func TestInstallFromIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeSkillDir(t, filepath.Join(dir, "repo", "weather"), "weather", "", "Weather")
+	sum, _ := Checksum(filepath.Join(dir, "repo", "weather"))
+
+	index := Index{Skills: []IndexEntry{
+		{Name: "weather", Version: "1.9.0", Description: "Forecasts", Source: "./repo", Path: "weather"},
+		{Name: "weather", Version: "1.10.0", Description: "Forecasts", Source: "./repo", Path: "weather", Checksum: sum},
+		{Name: "github", Version: "0.1.0", Description: "GitHub CLI", Tags: []string{"git"}, Source: "./missing"},
+	}}
+	data, _ := json.Marshal(index)
+	indexPath := filepath.Join(dir, "index.json")
+	os.WriteFile(indexPath, data, 0644)
+
+	in := &Installer{Dir: t.TempDir(), Index: indexPath, AllowUnsigned: true}
+	if found, err := in.Search("GIT"); err != nil || len(found) != 1 || found[0].Name != "github" {
+		t.Errorf("Search(GIT) = %+v, %v", found, err)
+	}
+
+	results, err := in.Install("weather")
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if results[0].Version != "1.10.0" {
+		t.Errorf("expected newest version, got %+v", results[0])
+	}
+	lock, _ := LoadLockFile(in.Dir)
+	if e := lock.Skills["weather"]; e.Index != indexPath || e.Version != "1.10.0" || e.Ref != "" {
+		t.Errorf("lock entry = %+v", e)
+	}
+
+	if results, err := in.Install("weather@1.9.0"); err != nil || results[0].Version != "1.9.0" {
+		t.Errorf("Install(weather@1.9.0) = %+v, %v", results, err)
+	}
+	if _, err := in.Install("weather@2.0"); err == nil {
+		t.Error("expected unknown version to fail")
+	}
+
+	index.Skills[1].Checksum = "sha256:bad"
+	data, _ = json.Marshal(index)
+	os.WriteFile(indexPath, data, 0644)
+	if _, err := in.Install("weather"); err == nil {
+		t.Error("expected checksum mismatch to fail")
+	}
+}
+
+func TestInstallFromGit(t *testing.T) {
+	if !HasBinary("git") {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "--quiet")
+	writeSkillDir(t, repo, "tmux", "", "v1")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1")
+	writeSkillDir(t, repo, "tmux", "", "v2")
+	git("commit", "--quiet", "-am", "v2")
+
+	in := &Installer{Dir: t.TempDir(), AllowUnsigned: true}
+	results, err := in.Install(repo + "/.git@v1")
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	body, _ := os.ReadFile(filepath.Join(in.Dir, "tmux", "SKILL.md"))
+	if !bytes.HasSuffix(bytes.TrimSpace(body), []byte("v1")) || len(results[0].Version) != 12 {
+		t.Errorf("expected tag v1 with a commit version, got %q (%+v)", body, results[0])
+	}
+}
@@ -0,0 +1,385 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a frontmatter diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // the field is ignored or the skill can't work as declared
+	SeverityWarning Severity = "warning" // likely a mistake, but the skill loads
+)
+
+// Diagnostic is one problem found in a SKILL.md
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Field    string   `json:"field,omitempty"` // dotted path, e.g. metadata.install[0].kind
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity"`
+}
+
+// String renders the diagnostic as "file: field: message"
+func (d Diagnostic) String() string {
+	if d.Field == "" {
+		return fmt.Sprintf("%s: %s", d.File, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.File, d.Field, d.Message)
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diags []Diagnostic) bool {
+	return slices.ContainsFunc(diags, func(d Diagnostic) bool { return d.Severity == SeverityError })
+}
+
+// ValidationError is returned when a skill fails validation
+type ValidationError struct {
+	Skill       string // skill directory name
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	var lines []string
+	for _, d := range e.Diagnostics {
+		if d.Severity != SeverityError {
+			continue
+		}
+		if d.Field == "" {
+			lines = append(lines, d.Message)
+		} else {
+			lines = append(lines, d.Field+": "+d.Message)
+		}
+	}
+	return fmt.Sprintf("%s/SKILL.md is invalid:\n  %s", e.Skill, strings.Join(lines, "\n  "))
+}
+
+// frontmatterKeys are the top-level SKILL.md keys lingti-bot reads
+var frontmatterKeys = []string{"name", "description", "version", "homepage", "metadata"}
+
+// openclawOnlyKeys are OpenClaw metadata fields lingti-bot doesn't support
+var openclawOnlyKeys = map[string]bool{"config": true, "primaryEnv": true, "skillKey": true}
+
+// osAliases maps common OS names to the GOOS values metadata.os uses
+var osAliases = map[string]string{"macos": "darwin", "mac": "darwin", "osx": "darwin", "win": "windows", "win32": "windows"}
+
+// LintSkillMD validates a SKILL.md file. Besides what ParseSkillMD rejects,
+// it reports unknown or mistyped keys (which the parser silently ignores)
+// and values the rest of lingti-bot can't use.
+func LintSkillMD(path string) []Diagnostic {
+	l := &linter{file: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.errorf("", "%v", err)
+		return l.diags
+	}
+	frontmatter, _, err := splitFrontmatter(string(data))
+	if err != nil {
+		l.errorf("", "%v", err)
+		return l.diags
+	}
+	if strings.TrimSpace(frontmatter) == "" {
+		l.errorf("", "no YAML frontmatter (--- delimited) found")
+		return l.diags
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		l.errorf("", "invalid YAML: %v", err)
+		return l.diags
+	}
+	l.lintFrontmatter(doc.Content[0])
+
+	entry, err := ParseSkillMD(path)
+	if err != nil {
+		l.errorf("", "%v", err)
+		return l.diags
+	}
+	l.lintEntry(entry)
+	return l.diags
+}
+
+// LintDirs validates every SKILL.md one level below the given directories,
+// including files that fail to parse and so never show up as skills
+func LintDirs(dirs []string) []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			path := filepath.Join(dir, e.Name(), "SKILL.md")
+			if _, err := os.Stat(path); e.IsDir() && err == nil {
+				diags = append(diags, LintSkillMD(path)...)
+			}
+		}
+	}
+	return diags
+}
+
+// ValidateSkillDir lints a skill directory's SKILL.md and returns a
+// *ValidationError if it has errors, along with all diagnostics
+func ValidateSkillDir(dir string) ([]Diagnostic, error) {
+	diags := LintSkillMD(filepath.Join(dir, "SKILL.md"))
+	if HasErrors(diags) {
+		return diags, &ValidationError{Skill: filepath.Base(dir), Diagnostics: diags}
+	}
+	return diags, nil
+}
+
+type linter struct {
+	file       string
+	metaPrefix string // "metadata" or "metadata.openclaw"
+	diags      []Diagnostic
+}
+
+func (l *linter) add(sev Severity, field, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{File: l.file, Field: field, Message: fmt.Sprintf(format, args...), Severity: sev})
+}
+
+func (l *linter) errorf(field, format string, args ...any) {
+	l.add(SeverityError, field, format, args...)
+}
+
+func (l *linter) warnf(field, format string, args ...any) {
+	l.add(SeverityWarning, field, format, args...)
+}
+
+// lintFrontmatter checks the keys and value types of the raw frontmatter
+func (l *linter) lintFrontmatter(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		l.errorf("", "frontmatter must be a mapping")
+		return
+	}
+	l.metaPrefix = "metadata"
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		switch {
+		case key == "metadata":
+			l.lintMetadata(value)
+		case slices.Contains(frontmatterKeys, key):
+			if value.Kind != yaml.ScalarNode {
+				l.errorf(key, "must be a string")
+			}
+		default:
+			l.warnf(key, "unknown field%s; it is ignored", suggest(key, frontmatterKeys))
+		}
+	}
+}
+
+// lintMetadata checks flat or OpenClaw-nested metadata against SkillMetadata
+func (l *linter) lintMetadata(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != "openclaw" {
+				continue
+			}
+			if len(node.Content) > 2 {
+				l.warnf("metadata", "fields next to openclaw are ignored")
+			}
+			l.metaPrefix = "metadata.openclaw"
+			node = node.Content[i+1]
+			break
+		}
+	}
+	before := len(l.diags)
+	l.lintNode(node, reflect.TypeOf(SkillMetadata{}), l.metaPrefix)
+
+	// Type errors make the parser drop the whole block, not just the field
+	var meta SkillMetadata
+	if err := node.Decode(&meta); err != nil {
+		if !HasErrors(l.diags[before:]) {
+			l.errorf(l.metaPrefix, "%v", err)
+		}
+		l.errorf(l.metaPrefix, "could not be decoded, so all metadata (requirements, installers, tools) is ignored")
+	}
+}
+
+// lintNode checks a YAML node against the Go type it is decoded into. A
+// mismatch anywhere makes the parser drop the whole metadata block.
+func (l *linter) lintNode(node *yaml.Node, t reflect.Type, field string) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			l.errorf(field, "must be a mapping")
+			return
+		}
+		fields := yamlFields(t)
+		known := make([]string, 0, len(fields))
+		for name := range fields {
+			known = append(known, name)
+		}
+		slices.Sort(known)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			ft, ok := fields[key]
+			switch {
+			case ok:
+				l.lintNode(node.Content[i+1], ft, field+"."+key)
+			case openclawOnlyKeys[key]:
+				l.warnf(field+"."+key, "OpenClaw field not supported by lingti-bot; it is ignored")
+			default:
+				l.errorf(field+"."+key, "unknown field%s; it is ignored", suggest(key, known))
+			}
+		}
+	case reflect.Slice:
+		if node.Kind == yaml.ScalarNode {
+			l.errorf(field, "must be a list, e.g. [%s]", node.Value)
+			return
+		}
+		if node.Kind != yaml.SequenceNode {
+			l.errorf(field, "must be a list")
+			return
+		}
+		for i, item := range node.Content {
+			l.lintNode(item, t.Elem(), fmt.Sprintf("%s[%d]", field, i))
+		}
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			l.errorf(field, "must be true or false")
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			l.errorf(field, "must be a string")
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of a struct to their field types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// lintEntry checks the values of a parsed skill
+func (l *linter) lintEntry(entry *SkillEntry) {
+	m := entry.Metadata
+	if entry.Description == "" {
+		l.warnf("description", "missing; the agent picks skills by their description")
+	}
+	if dir := filepath.Base(entry.BaseDir); entry.Name != dir {
+		l.warnf("name", "%q differs from the directory name %q", entry.Name, dir)
+	}
+
+	for i, goos := range m.OS {
+		if slices.Contains(KnownOS, goos) {
+			continue
+		}
+		hint := suggest(goos, KnownOS)
+		if alias, ok := osAliases[strings.ToLower(goos)]; ok {
+			hint = fmt.Sprintf(" (did you mean %q?)", alias)
+		}
+		l.errorf(fmt.Sprintf("%s.os[%d]", l.metaPrefix, i), "unknown OS %q%s; the skill is never eligible on it", goos, hint)
+	}
+
+	for _, req := range []struct {
+		key  string
+		bins []string
+	}{{"bins", m.Requires.Bins}, {"any_bins", m.Requires.AnyBins}} {
+		for i, bin := range req.bins {
+			if !validBinName(bin) || strings.ContainsAny(bin, " ") {
+				l.errorf(fmt.Sprintf("%s.requires.%s[%d]", l.metaPrefix, req.key, i), "invalid binary name %q; use the command name as found in PATH", bin)
+			}
+		}
+	}
+	for i, name := range m.Requires.Env {
+		if !isAssignment(name + "=") {
+			l.errorf(fmt.Sprintf("%s.requires.env[%d]", l.metaPrefix, i), "invalid environment variable name %q", name)
+		}
+	}
+
+	required := slices.Concat(m.Requires.Bins, m.Requires.AnyBins)
+	if len(m.Install) > 0 && len(required) == 0 {
+		l.warnf(l.metaPrefix+".install", "installers are declared but requires.bins and requires.any_bins are empty")
+	}
+	for i, spec := range m.Install {
+		field := fmt.Sprintf("%s.install[%d]", l.metaPrefix, i)
+		target, ok := installKindField[spec.Kind]
+		if !ok {
+			l.errorf(field+".kind", "unknown install kind %q%s; want one of %s", spec.Kind, suggest(spec.Kind, installKinds), strings.Join(installKinds, ", "))
+			continue
+		}
+		if installTarget(spec) == "" {
+			l.errorf(field+"."+target, "required for kind %s", spec.Kind)
+		}
+		if spec.Kind == "download" && len(spec.Bins) == 0 {
+			l.errorf(field+".bins", "required for kind download")
+		}
+		for _, bin := range spec.Bins {
+			if len(required) > 0 && !slices.Contains(required, bin) {
+				l.warnf(field+".bins", "%s is not in requires.bins or requires.any_bins", bin)
+			}
+		}
+	}
+
+	for i, tool := range m.Tools {
+		field := fmt.Sprintf("%s.tools[%d]", l.metaPrefix, i)
+		if tool.Name == "" {
+			l.errorf(field+".name", "required")
+		} else if len(tool.Bins) > 0 && tool.Name != "shell_execute" {
+			l.warnf(field+".bins", "only applies to shell_execute")
+		}
+	}
+}
+
+// suggest returns ` (did you mean "x"?)` for the candidate closest to word,
+// or "" if none is close
+func suggest(word string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(word), c); d < bestDist && d < len(c) {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,124 @@
+package skills
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLintSkill(t *testing.T, dir, name, frontmatter string) string {
+	t.Helper()
+	os.MkdirAll(filepath.Join(dir, name), 0755)
+	path := filepath.Join(dir, name, "SKILL.md")
+	os.WriteFile(path, []byte("---\n"+frontmatter+"---\n\n# "+name+"\n"), 0644)
+	return path
+}
+
+// diagnosticsByField indexes diagnostics as "severity field"
+func diagnosticsByField(diags []Diagnostic) map[string]string {
+	m := make(map[string]string)
+	for _, d := range diags {
+		m[string(d.Severity)+" "+d.Field] = d.Message
+	}
+	return m
+}
+
+func TestLintSkillMD(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLintSkill(t, dir, "demo", `name: demo
+description: Demo skill
+descripton: typo
+metadata:
+  os: [macos, linux]
+  requries:
+    bins: [demo]
+  requires:
+    bin: [demo]
+    env: [DEMO_KEY, BAD-NAME]
+  install:
+    - kind: brw
+      formula: demo
+    - kind: download
+      url: https://example.com/demo
+    - kind: npm
+      bins: [demo]
+`)
+	got := diagnosticsByField(LintSkillMD(path))
+	want := map[string]string{
+		"warning descripton":                "did you mean \"description\"",
+		"error metadata.requries":           "did you mean \"requires\"",
+		"error metadata.requires.bin":       "did you mean \"bins\"",
+		"error metadata.os[0]":              "did you mean \"darwin\"",
+		"error metadata.requires.env[1]":    "invalid environment variable",
+		"error metadata.install[0].kind":    "unknown install kind \"brw\" (did you mean \"brew\"?)",
+		"error metadata.install[1].bins":    "required for kind download",
+		"error metadata.install[2].package": "required for kind npm",
+		"warning metadata.install":          "requires.bins and requires.any_bins are empty",
+	}
+	for key, substr := range want {
+		if msg, ok := got[key]; !ok || !strings.Contains(msg, substr) {
+			t.Errorf("%s: got %q, want message containing %q", key, msg, substr)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d diagnostics, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestLintSkillMDTypes(t *testing.T) {
+	dir := t.TempDir()
+	// A string where a list is expected makes the parser drop all metadata
+	path := writeLintSkill(t, dir, "demo", "name: demo\ndescription: Demo\nmetadata:\n  requires:\n    bins: demo\n  always: maybe\n")
+	got := diagnosticsByField(LintSkillMD(path))
+	if !strings.Contains(got["error metadata.requires.bins"], "must be a list") || got["error metadata.always"] == "" {
+		t.Errorf("type errors not reported: %v", got)
+	}
+	if !strings.Contains(got["error metadata"], "all metadata") {
+		t.Errorf("dropped metadata not reported: %v", got)
+	}
+
+	// OpenClaw-nested metadata is checked under its own prefix, even without an emoji
+	path = writeLintSkill(t, dir, "nested", `name: nested
+description: Nested
+metadata: {"openclaw": {"os": ["linux"], "requires": {"bins": ["nested"], "config": ["channels.x"]}}}
+`)
+	diags := LintSkillMD(path)
+	if len(diags) != 1 || diags[0].Field != "metadata.openclaw.requires.config" || diags[0].Severity != SeverityWarning {
+		t.Errorf("openclaw diagnostics = %v", diags)
+	}
+	entry, err := ParseSkillMD(path)
+	if err != nil || len(entry.Metadata.Requires.Bins) != 1 {
+		t.Errorf("openclaw metadata without emoji not parsed: %+v, %v", entry, err)
+	}
+}
+
+func TestLintDirsAndInstall(t *testing.T) {
+	src := t.TempDir()
+	writeLintSkill(t, src, "good", "name: good\ndescription: Good\n")
+	writeLintSkill(t, src, "broken", "name: broken\ndescription: Broken\nmetadata:\n  os: [plan9]\n")
+	writeLintSkill(t, src, "unparsable", "description: no name\n")
+
+	diags := LintDirs([]string{src})
+	files := make(map[string]bool)
+	for _, d := range diags {
+		files[filepath.Base(filepath.Dir(d.File))] = true
+	}
+	if !files["broken"] || !files["unparsable"] || files["good"] {
+		t.Errorf("LintDirs reported %v", diags)
+	}
+
+	in := &Installer{Dir: t.TempDir(), AllowUnsigned: true}
+	_, err := in.Install(src)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Install error = %v, want a ValidationError", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(in.Dir, "good")); statErr == nil {
+		t.Error("valid skill installed although another skill in the source is invalid")
+	}
+	if _, err := in.Install(filepath.Join(src, "good")); err != nil {
+		t.Errorf("Install(good): %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package skills
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// LockFileName is the lockfile recording installed skills in the managed dir.
+// It deliberately doesn't end in .json so it is never mistaken for a trigger skill.
+const LockFileName = "skills.lock"
+
+// Kinds of installed skills
+const (
+	KindSkillMD = "skill"   // directory with a SKILL.md
+	KindTrigger = "trigger" // JSON trigger/action skill
+)
+
+// LockFile records where each installed skill came from
+type LockFile struct {
+	Version int                   `json:"version"`
+	Skills  map[string]*LockEntry `json:"skills"`
+}
+
+// LockEntry describes one installed skill
+type LockEntry struct {
+	Name        string    `json:"name"`
+	Kind        string    `json:"kind"`
+	Source      string    `json:"source"`          // git URL, archive, directory, or file the skill was installed from
+	Ref         string    `json:"ref,omitempty"`   // git ref or index version requested at install time
+	Path        string    `json:"path,omitempty"`  // skill directory within the source
+	Index       string    `json:"index,omitempty"` // index the skill was resolved from, if any
+	Version     string    `json:"version,omitempty"`
+	Checksum    string    `json:"checksum"`
+	InstalledAt time.Time `json:"installed_at"`
+
+	Signature SignatureStatus `json:"signature,omitempty"` // signature status at install time; empty for skills installed before signing
+	Publisher string          `json:"publisher,omitempty"` // publisher of a signed skill
+}
+
+// LoadLockFile reads the lockfile in dir. A missing lockfile is empty.
+func LoadLockFile(dir string) (*LockFile, error) {
+	lock := &LockFile{Version: 1, Skills: make(map[string]*LockEntry)}
+	data, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile: %w", err)
+	}
+	if lock.Skills == nil {
+		lock.Skills = make(map[string]*LockEntry)
+	}
+	return lock, nil
+}
+
+// Save writes the lockfile to dir
+func (l *LockFile) Save(dir string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create skill directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, LockFileName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
+
+// Names returns the locked skill names, sorted
+func (l *LockFile) Names() []string {
+	names := make([]string, 0, len(l.Skills))
+	for name := range l.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the lock entry of a managed SKILL.md skill. A nil
+// lockfile has no entries.
+func (l *LockFile) lookup(entry SkillEntry) (*LockEntry, bool) {
+	if l == nil || entry.Source != SourceManaged {
+		return nil, false
+	}
+	locked, ok := l.Skills[entry.Name]
+	return locked, ok && locked.Kind == KindSkillMD
+}
+
+// Checksum hashes a skill directory or file as "sha256:<hex>". Directory
+// checksums cover every file's relative path and content in sorted order,
+// skipping .git.
+func Checksum(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if !info.IsDir() {
+		if err := hashFile(h, path); err != nil {
+			return "", err
+		}
+		return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	files, err := treeFiles(path)
+	if err != nil {
+		return "", err
+	}
+	for _, rel := range files {
+		fh := sha256.New()
+		if err := hashFile(fh, filepath.Join(path, rel)); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%x\n", filepath.ToSlash(rel), fh.Sum(nil))
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// treeFiles lists the regular files under dir (relative paths, sorted),
+// skipping .git directories and symlinks
+func treeFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
@@ -0,0 +1,163 @@
+package skills
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TriggerMatch is an enabled skill selected by an incoming message
+type TriggerMatch struct {
+	Skill   *Skill
+	Trigger Trigger
+	Matches []string // Command: [text, args]; pattern: regex match and capture groups; keyword: [text]
+}
+
+// skillIDPattern keeps skill IDs safe to use as file names
+var skillIDPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ValidateSkill checks that a skill is well formed before it is registered
+func ValidateSkill(skill *Skill) error {
+	if skill.ID == "" {
+		return fmt.Errorf("skill ID is required")
+	}
+	if !skillIDPattern.MatchString(skill.ID) {
+		return fmt.Errorf("invalid skill ID %q: use lowercase letters, digits, '-' and '_'", skill.ID)
+	}
+	if len(skill.Actions) == 0 {
+		return fmt.Errorf("skill %s has no actions", skill.ID)
+	}
+
+	for i, trigger := range skill.Triggers {
+		switch trigger.Type {
+		case TriggerCommand:
+			if strings.TrimPrefix(trigger.Command, "/") == "" {
+				return fmt.Errorf("skill %s: trigger %d: command trigger requires 'command'", skill.ID, i)
+			}
+		case TriggerPattern:
+			if trigger.Pattern == "" {
+				return fmt.Errorf("skill %s: trigger %d: pattern trigger requires 'pattern'", skill.ID, i)
+			}
+			if _, err := regexp.Compile(trigger.Pattern); err != nil {
+				return fmt.Errorf("skill %s: trigger %d: invalid pattern: %w", skill.ID, i, err)
+			}
+		case TriggerKeyword:
+			if strings.TrimSpace(trigger.Pattern) == "" {
+				return fmt.Errorf("skill %s: trigger %d: keyword trigger requires 'pattern'", skill.ID, i)
+			}
+		case TriggerSchedule:
+			if trigger.Schedule == "" {
+				return fmt.Errorf("skill %s: trigger %d: schedule trigger requires 'schedule'", skill.ID, i)
+			}
+		case TriggerEvent:
+		default:
+			return fmt.Errorf("skill %s: trigger %d: unknown trigger type %q", skill.ID, i, trigger.Type)
+		}
+	}
+	return nil
+}
+
+// MatchMessage finds the enabled skill whose command, pattern or keyword
+// trigger matches a message. Command triggers take precedence over patterns,
+// and patterns over keywords; ties are broken by skill ID.
+func (r *Registry) MatchMessage(text string) (TriggerMatch, bool) {
+	return r.MatchMessageWith(text, Access{})
+}
+
+// MatchMessageWith is MatchMessage limited to the skills access allows
+func (r *Registry) MatchMessageWith(text string, access Access) (TriggerMatch, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return TriggerMatch{}, false
+	}
+
+	r.mu.RLock()
+	skills := make([]*Skill, 0, len(r.skills))
+	for _, skill := range r.skills {
+		if skill.Enabled && access.Allowed(skill.ID) {
+			skills = append(skills, skill)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(skills, func(i, j int) bool { return skills[i].ID < skills[j].ID })
+
+	for _, triggerType := range []TriggerType{TriggerCommand, TriggerPattern, TriggerKeyword} {
+		for _, skill := range skills {
+			for _, trigger := range skill.Triggers {
+				if trigger.Type != triggerType {
+					continue
+				}
+				if matches := matchTrigger(trigger, text); matches != nil {
+					return TriggerMatch{Skill: skill, Trigger: trigger, Matches: matches}, true
+				}
+			}
+		}
+	}
+	return TriggerMatch{}, false
+}
+
+// matchTrigger returns the match groups for a trigger, or nil if it doesn't match
+func matchTrigger(trigger Trigger, text string) []string {
+	switch trigger.Type {
+	case TriggerCommand:
+		command := "/" + strings.TrimPrefix(trigger.Command, "/")
+		name, args, _ := strings.Cut(text, " ")
+		if !strings.EqualFold(name, command) {
+			return nil
+		}
+		return []string{text, strings.TrimSpace(args)}
+	case TriggerPattern:
+		re, err := regexp.Compile(trigger.Pattern)
+		if err != nil {
+			return nil
+		}
+		return re.FindStringSubmatch(text)
+	case TriggerKeyword:
+		if strings.Contains(strings.ToLower(text), strings.ToLower(strings.TrimSpace(trigger.Pattern))) {
+			return []string{text}
+		}
+	}
+	return nil
+}
+
+// ScheduleTriggers returns the schedule triggers of all enabled skills, keyed by skill ID
+func (r *Registry) ScheduleTriggers() map[string][]Trigger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	triggers := make(map[string][]Trigger)
+	for id, skill := range r.skills {
+		if !skill.Enabled {
+			continue
+		}
+		for _, trigger := range skill.Triggers {
+			if trigger.Type == TriggerSchedule {
+				triggers[id] = append(triggers[id], trigger)
+			}
+		}
+	}
+	return triggers
+}
+
+// ResultOutput combines the results of a skill run into a single reply: the
+// output of the last action that produced any, or the first error that
+// stopped the run.
+func ResultOutput(results []ExecutionResult) (string, error) {
+	var output string
+	for _, result := range results {
+		if !result.Success && !result.Continue {
+			if result.Error == nil {
+				result.Error = fmt.Errorf("action failed")
+			}
+			if result.Output != "" {
+				return result.Output, result.Error
+			}
+			return output, result.Error
+		}
+		if result.Output != "" {
+			output = result.Output
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,147 @@
+package skills
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateSkill(t *testing.T) {
+	action := []Action{{ID: "a", Type: ActionShell}}
+	bad := []*Skill{
+		{Actions: action},
+		{ID: "no-actions"},
+		{ID: "s", Actions: action, Triggers: []Trigger{{Type: TriggerCommand}}},
+		{ID: "s", Actions: action, Triggers: []Trigger{{Type: TriggerPattern, Pattern: "("}}},
+		{ID: "s", Actions: action, Triggers: []Trigger{{Type: TriggerSchedule}}},
+		{ID: "s", Actions: action, Triggers: []Trigger{{Type: "webhook"}}},
+		{ID: "../evil", Actions: action},
+		{ID: "a/b", Actions: action},
+		{ID: `a\b`, Actions: action},
+		{ID: "..", Actions: action},
+		{ID: "Weather", Actions: action},
+	}
+	for _, skill := range bad {
+		if err := ValidateSkill(skill); err == nil {
+			t.Errorf("ValidateSkill(%+v): expected error", skill)
+		}
+	}
+}
+
+func TestMatchMessage(t *testing.T) {
+	r := NewRegistry(t.TempDir())
+	action := []Action{{ID: "a", Type: ActionShell}}
+	for _, skill := range []*Skill{
+		{ID: "weather", Enabled: true, Actions: action, Triggers: []Trigger{{Type: TriggerCommand, Command: "weather"}}},
+		{ID: "ip", Enabled: true, Actions: action, Triggers: []Trigger{{Type: TriggerPattern, Pattern: `^whois (\S+)$`}}},
+		{ID: "help", Enabled: true, Actions: action, Triggers: []Trigger{{Type: TriggerKeyword, Pattern: "Help"}}},
+		{ID: "off", Enabled: false, Actions: action, Triggers: []Trigger{{Type: TriggerKeyword, Pattern: "hello"}}},
+	} {
+		if err := r.Register(skill); err != nil {
+			t.Fatalf("Register(%s): %v", skill.ID, err)
+		}
+	}
+
+	tests := []struct {
+		text    string
+		skill   string
+		matches []string
+	}{
+		{"/weather 北京", "weather", []string{"/weather 北京", "北京"}},
+		{"/WEATHER", "weather", []string{"/WEATHER", ""}},
+		{"/weatherman", "", nil},
+		{"whois example.com", "ip", []string{"whois example.com", "example.com"}},
+		{"please help me", "help", []string{"please help me"}},
+		{"/weather help", "weather", []string{"/weather help", "help"}}, // command beats keyword
+		{"hello", "", nil}, // disabled
+	}
+	for _, tt := range tests {
+		m, ok := r.MatchMessage(tt.text)
+		if tt.skill == "" {
+			if ok {
+				t.Errorf("MatchMessage(%q) = %s, expected no match", tt.text, m.Skill.ID)
+			}
+			continue
+		}
+		if !ok || m.Skill.ID != tt.skill {
+			t.Errorf("MatchMessage(%q) = %+v, want skill %s", tt.text, m, tt.skill)
+			continue
+		}
+		if len(m.Matches) != len(tt.matches) {
+			t.Errorf("MatchMessage(%q) matches = %q, want %q", tt.text, m.Matches, tt.matches)
+			continue
+		}
+		for i := range tt.matches {
+			if m.Matches[i] != tt.matches[i] {
+				t.Errorf("MatchMessage(%q) matches = %q, want %q", tt.text, m.Matches, tt.matches)
+				break
+			}
+		}
+	}
+}
+
+func TestToolExecutor(t *testing.T) {
+	var gotName string
+	var gotArgs map[string]any
+	exec := NewToolExecutor(func(ctx context.Context, name string, args map[string]any) (string, error) {
+		gotName, gotArgs = name, args
+		if name == "broken" {
+			return "", errors.New("boom")
+		}
+		return "ok", nil
+	})
+
+	ctx := ExecutionContext{Context: context.Background(), Matches: []string{"/read notes.txt", "notes.txt"}}
+	result := exec.Execute(ctx, Action{Type: ActionTool, Config: map[string]any{
+		"tool":      "file_read",
+		"arguments": map[string]any{"path": "~/{{.Match1}}", "limit": 10.0},
+	}})
+	if !result.Success || result.Output != "ok" {
+		t.Fatalf("Execute = %+v", result)
+	}
+	if gotName != "file_read" || gotArgs["path"] != "~/notes.txt" || gotArgs["limit"] != 10.0 {
+		t.Errorf("handler called with %s %v", gotName, gotArgs)
+	}
+
+	if result := exec.Execute(ctx, Action{Type: ActionTool, Config: map[string]any{"tool": "broken"}}); result.Success {
+		t.Error("expected handler error to fail the action")
+	}
+	if result := exec.Execute(ctx, Action{Type: ActionTool}); result.Success {
+		t.Error("expected missing tool config to fail the action")
+	}
+}
+
+func TestShellExecutor_QuotesValues(t *testing.T) {
+	t.Setenv("SKILL_SECRET", "hunter2")
+	message := `'; echo pwned; $SKILL_SECRET {{.UserID}} $(id)`
+	ctx := ExecutionContext{
+		Context: context.Background(),
+		Message: message,
+		UserID:  "u1",
+		Matches: []string{message, message},
+	}
+	result := NewShellExecutor().Execute(ctx, Action{Type: ActionShell, Config: map[string]any{
+		"command": "printf '%s|' {{.Match1}} {{.Message}}",
+	}})
+	if !result.Success {
+		t.Fatalf("Execute = %+v", result)
+	}
+	if want := message + "|" + message + "|"; result.Output != want {
+		t.Errorf("output = %q, want the message verbatim", result.Output)
+	}
+}
+
+func TestResultOutput(t *testing.T) {
+	output, err := ResultOutput([]ExecutionResult{
+		{Success: true, Output: "first"},
+		{Success: false, Error: errors.New("ignored"), Continue: true},
+		{Success: true, Output: "last"},
+	})
+	if err != nil || output != "last" {
+		t.Errorf("ResultOutput = %q, %v", output, err)
+	}
+
+	if _, err := ResultOutput([]ExecutionResult{{Success: false}}); err == nil {
+		t.Error("expected failed action to return an error")
+	}
+}
@@ -0,0 +1,344 @@
+package skills
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Files added to a skill by `skills pack`. MANIFEST.json lists the hash of
+// every other file; MANIFEST.sig is the ed25519 signature of MANIFEST.json.
+const (
+	ManifestFileName  = "MANIFEST.json"
+	SignatureFileName = "MANIFEST.sig"
+)
+
+// keyPrefix marks encoded ed25519 keys, e.g. "ed25519:<base64>"
+const keyPrefix = "ed25519:"
+
+// publisherPattern is the form of publisher names, which are keys of skills.trusted_keys
+var publisherPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// SignatureStatus is the outcome of verifying a skill's signature
+type SignatureStatus string
+
+const (
+	SignatureVerified  SignatureStatus = "verified"  // signed by a trusted publisher key
+	SignatureUntrusted SignatureStatus = "untrusted" // validly signed, but the publisher key is not trusted
+	SignatureUnsigned  SignatureStatus = "unsigned"  // no manifest
+)
+
+// Manifest describes a packed skill. Files maps every file's slash-separated
+// relative path to its "sha256:<hex>" hash.
+type Manifest struct {
+	Name      string            `json:"name"`
+	Version   string            `json:"version"`
+	Publisher string            `json:"publisher"`
+	PublicKey string            `json:"public_key"`
+	Files     map[string]string `json:"files"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// SigningKey is a publisher's ed25519 key pair, stored as JSON by `skills keygen`
+type SigningKey struct {
+	Publisher  string `json:"publisher"`
+	PublicKey  string `json:"public_key"`
+	PrivateKey string `json:"private_key"`
+	private    ed25519.PrivateKey
+}
+
+// GenerateSigningKey creates a new key pair for a publisher
+func GenerateSigningKey(publisher string) (*SigningKey, error) {
+	if !publisherPattern.MatchString(publisher) {
+		return nil, fmt.Errorf("invalid publisher name %q: use letters, digits, dots, hyphens and underscores", publisher)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return &SigningKey{
+		Publisher:  publisher,
+		PublicKey:  FormatPublicKey(pub),
+		PrivateKey: keyPrefix + base64.StdEncoding.EncodeToString(priv),
+		private:    priv,
+	}, nil
+}
+
+// LoadSigningKey reads a key file written by SigningKey.Save
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	var key SigningKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(key.PrivateKey, keyPrefix))
+	if err != nil || len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("signing key %s has an invalid private_key", path)
+	}
+	key.private = ed25519.PrivateKey(raw)
+	key.PublicKey = FormatPublicKey(key.private.Public().(ed25519.PublicKey))
+	if key.Publisher == "" {
+		return nil, fmt.Errorf("signing key %s has no publisher", path)
+	}
+	return &key, nil
+}
+
+// Save writes the key pair to path, readable only by the owner
+func (k *SigningKey) Save(path string) error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// FormatPublicKey encodes a public key as "ed25519:<base64>", the form used
+// in manifests and in skills.trusted_keys
+func FormatPublicKey(key ed25519.PublicKey) string {
+	return keyPrefix + base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey decodes a key written by FormatPublicKey
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(s), keyPrefix)
+	if !ok {
+		return nil, fmt.Errorf("public key must start with %q", keyPrefix)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key %q", s)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Pack signs a skill directory and writes it to outDir as
+// <name>-<version>.tar.gz, returning the archive path. The archive holds the
+// skill under a <name>/ directory together with MANIFEST.json and
+// MANIFEST.sig. The skill must pass validation and declare a version.
+func Pack(dir string, key *SigningKey, outDir string) (string, error) {
+	if _, err := ValidateSkillDir(dir); err != nil {
+		return "", err
+	}
+	skill, err := ParseSkillMD(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		return "", err
+	}
+	if skill.Version == "" {
+		return "", fmt.Errorf("%s has no version; set version in its SKILL.md frontmatter", skill.Name)
+	}
+
+	files, err := packFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	manifest := Manifest{
+		Name:      skill.Name,
+		Version:   skill.Version,
+		Publisher: key.Publisher,
+		PublicKey: key.PublicKey,
+		Files:     make(map[string]string, len(files)),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	for _, rel := range files {
+		sum, err := fileChecksum(filepath.Join(dir, rel))
+		if err != nil {
+			return "", err
+		}
+		manifest.Files[filepath.ToSlash(rel)] = sum
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	manifestData = append(manifestData, '\n')
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key.private, manifestData)) + "\n"
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	addFile := func(name string, data []byte, mode int64) error {
+		header := &tar.Header{Name: skill.Name + "/" + name, Mode: mode, Size: int64(len(data)), Typeflag: tar.TypeReg, ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	for _, rel := range files {
+		path := filepath.Join(dir, rel)
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		mode := int64(0644)
+		if info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+		if err := addFile(filepath.ToSlash(rel), data, mode); err != nil {
+			return "", fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+	}
+	if err := addFile(ManifestFileName, manifestData, 0644); err != nil {
+		return "", err
+	}
+	if err := addFile(SignatureFileName, []byte(signature), 0644); err != nil {
+		return "", err
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", err
+	}
+	out := filepath.Join(outDir, fmt.Sprintf("%s-%s.tar.gz", skill.Name, skill.Version))
+	if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	return out, nil
+}
+
+// packFiles lists the files of a skill that a manifest covers: everything
+// treeFiles finds except the manifest and signature themselves
+func packFiles(dir string) ([]string, error) {
+	files, err := treeFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(files, func(rel string) bool {
+		return rel == ManifestFileName || rel == SignatureFileName
+	}), nil
+}
+
+func fileChecksum(path string) (string, error) {
+	h := sha256.New()
+	if err := hashFile(h, path); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SignatureInfo is the verified signature state of a skill directory
+type SignatureInfo struct {
+	Status    SignatureStatus
+	Publisher string
+	Version   string
+}
+
+// VerifySkillDir checks a skill directory against its MANIFEST.json and
+// MANIFEST.sig. A directory without a manifest is unsigned. A bad signature
+// or a file that is missing, extra or changed is an error. A valid
+// signature is verified if trusted maps the manifest's publisher to the
+// signing key, and untrusted otherwise.
+func VerifySkillDir(dir string, trusted map[string]string) (SignatureInfo, error) {
+	name := filepath.Base(dir)
+	manifestData, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return SignatureInfo{Status: SignatureUnsigned}, nil
+	}
+	if err != nil {
+		return SignatureInfo{}, err
+	}
+	sigData, err := os.ReadFile(filepath.Join(dir, SignatureFileName))
+	if err != nil {
+		return SignatureInfo{}, fmt.Errorf("%s: %s without %s", name, ManifestFileName, SignatureFileName)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return SignatureInfo{}, fmt.Errorf("%s: failed to parse %s: %w", name, ManifestFileName, err)
+	}
+	if manifest.Name != "" {
+		name = manifest.Name
+	}
+	key, err := ParsePublicKey(manifest.PublicKey)
+	if err != nil {
+		return SignatureInfo{}, fmt.Errorf("%s: %w", name, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil || !ed25519.Verify(key, manifestData, signature) {
+		return SignatureInfo{}, fmt.Errorf("%s: invalid signature", name)
+	}
+
+	files, err := packFiles(dir)
+	if err != nil {
+		return SignatureInfo{}, err
+	}
+	for _, rel := range files {
+		want, ok := manifest.Files[filepath.ToSlash(rel)]
+		if !ok {
+			return SignatureInfo{}, fmt.Errorf("%s: %s is not in the signed manifest", name, filepath.ToSlash(rel))
+		}
+		got, err := fileChecksum(filepath.Join(dir, rel))
+		if err != nil {
+			return SignatureInfo{}, err
+		}
+		if got != want {
+			return SignatureInfo{}, fmt.Errorf("%s: %s does not match the signed manifest", name, filepath.ToSlash(rel))
+		}
+	}
+	if len(files) != len(manifest.Files) {
+		var missing []string
+		for rel := range manifest.Files {
+			if !slices.Contains(files, filepath.FromSlash(rel)) {
+				missing = append(missing, rel)
+			}
+		}
+		sort.Strings(missing)
+		return SignatureInfo{}, fmt.Errorf("%s: signed files missing: %s", name, strings.Join(missing, ", "))
+	}
+
+	info := SignatureInfo{Status: SignatureUntrusted, Publisher: manifest.Publisher, Version: manifest.Version}
+	if want, ok := trusted[manifest.Publisher]; ok {
+		if trustedKey, err := ParsePublicKey(want); err == nil && trustedKey.Equal(key) {
+			info.Status = SignatureVerified
+		}
+	}
+	return info, nil
+}
+
+// checkSignature verifies a skill directory and applies the installer's
+// signing policy: unsigned and untrusted skills need AllowUnsigned
+func (in *Installer) checkSignature(dir, name string) (SignatureInfo, error) {
+	info, err := VerifySkillDir(dir, in.TrustedKeys)
+	if err != nil {
+		return info, err
+	}
+	if in.AllowUnsigned {
+		return info, nil
+	}
+	switch info.Status {
+	case SignatureUnsigned:
+		return info, fmt.Errorf("%s is not signed (use --allow-unsigned to install it anyway)", name)
+	case SignatureUntrusted:
+		return info, fmt.Errorf("%s is signed by %q, whose key is not in skills.trusted_keys (use --allow-unsigned to install it anyway)", name, info.Publisher)
+	}
+	return info, nil
+}
@@ -0,0 +1,115 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "weather")
+	writeSkillDir(t, src, "weather", "1.2.0", "Weather")
+	os.MkdirAll(filepath.Join(src, "scripts"), 0755)
+	os.WriteFile(filepath.Join(src, "scripts", "get.sh"), []byte("#!/bin/sh\necho sunny\n"), 0755)
+
+	key, err := GenerateSigningKey("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "signing.key")
+	if err := key.Save(keyPath); err != nil {
+		t.Fatal(err)
+	}
+	if key, err = LoadSigningKey(keyPath); err != nil {
+		t.Fatalf("LoadSigningKey: %v", err)
+	}
+
+	archive, err := Pack(src, key, filepath.Join(dir, "dist"))
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	if filepath.Base(archive) != "weather-1.2.0.tar.gz" {
+		t.Errorf("archive = %s", archive)
+	}
+
+	// Untrusted publishers are rejected unless unsigned skills are allowed
+	in := &Installer{Dir: t.TempDir()}
+	if _, err := in.Install(archive); err == nil || !strings.Contains(err.Error(), "not in skills.trusted_keys") {
+		t.Errorf("Install without trusted keys: %v", err)
+	}
+	other, _ := GenerateSigningKey("acme")
+	in.TrustedKeys = map[string]string{"acme": other.PublicKey}
+	if _, err := in.Install(archive); err == nil {
+		t.Error("expected a key that doesn't match the trusted one to be rejected")
+	}
+
+	in.TrustedKeys = map[string]string{"acme": key.PublicKey}
+	results, err := in.Install(archive)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if results[0].Signature != SignatureVerified || results[0].Publisher != "acme" || results[0].Version != "1.2.0" {
+		t.Errorf("result = %+v", results[0])
+	}
+	lock, _ := LoadLockFile(in.Dir)
+	if e := lock.Skills["weather"]; e.Signature != SignatureVerified || e.Publisher != "acme" {
+		t.Errorf("lock entry = %+v", e)
+	}
+	if info, err := os.Stat(filepath.Join(in.Dir, "weather", "scripts", "get.sh")); err != nil || info.Mode()&0111 == 0 {
+		t.Errorf("script missing or not executable: %v", err)
+	}
+
+	// Any change to the signed files fails verification, even with AllowUnsigned
+	installed := filepath.Join(in.Dir, "weather")
+	for name, tamper := range map[string]func(){
+		"changed": func() {
+			os.WriteFile(filepath.Join(installed, "SKILL.md"), []byte("---\nname: weather\n---\nevil"), 0644)
+		},
+		"extra":   func() { os.WriteFile(filepath.Join(installed, "evil.sh"), []byte("echo extra"), 0755) },
+		"missing": func() { os.Remove(filepath.Join(installed, "scripts", "get.sh")) },
+	} {
+		if err := replaceTree(installed, filepath.Join(dir, "pristine")); err != nil {
+			t.Fatal(err)
+		}
+		tamper()
+		if _, err := VerifySkillDir(installed, in.TrustedKeys); err == nil {
+			t.Errorf("%s file: verification passed", name)
+		}
+		strict := &Installer{Dir: t.TempDir(), AllowUnsigned: true}
+		if _, err := strict.Install(installed); err == nil {
+			t.Errorf("%s file: tampered skill installed", name)
+		}
+		if err := replaceTree(filepath.Join(dir, "pristine"), installed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Unsigned skills need AllowUnsigned, and then stay updatable
+	plain := filepath.Join(dir, "plain")
+	writeSkillDir(t, plain, "plain", "", "Plain")
+	if _, err := in.Install(plain); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("Install(unsigned): %v", err)
+	}
+	in.AllowUnsigned = true
+	if results, err := in.Install(plain); err != nil || results[0].Signature != SignatureUnsigned {
+		t.Fatalf("Install(unsigned) with AllowUnsigned = %+v, %v", results, err)
+	}
+	in.AllowUnsigned = false
+	if res, _ := in.Update([]string{"plain"}, false); res[0].Status != "up to date" {
+		t.Errorf("update of an unsigned skill = %+v", res[0])
+	}
+}
+
+func TestPackRequiresVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeSkillDir(t, filepath.Join(dir, "demo"), "demo", "", "Demo")
+	key, _ := GenerateSigningKey("acme")
+	if _, err := Pack(filepath.Join(dir, "demo"), key, dir); err == nil || !strings.Contains(err.Error(), "no version") {
+		t.Errorf("Pack without version: %v", err)
+	}
+	if _, err := GenerateSigningKey("acme corp"); err == nil {
+		t.Error("expected an invalid publisher name to fail")
+	}
+}
@@ -3,10 +3,12 @@ package skills
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 )
 
@@ -26,9 +28,11 @@ type Skill struct {
 
 // Trigger defines when a skill should be activated
 type Trigger struct {
-	Type    TriggerType `json:"type"`
-	Pattern string      `json:"pattern,omitempty"` // Regex pattern for pattern trigger
-	Command string      `json:"command,omitempty"` // Command name for command trigger
+	Type     TriggerType `json:"type"`
+	Pattern  string      `json:"pattern,omitempty"`  // Regex pattern for pattern trigger, word for keyword trigger
+	Command  string      `json:"command,omitempty"`  // Command name for command trigger
+	Schedule string      `json:"schedule,omitempty"` // Cron expression or natural-language schedule for schedule trigger
+	Targets  []string    `json:"targets,omitempty"`  // Where scheduled results are delivered (cron target specs)
 }
 
 // TriggerType defines the type of trigger
@@ -67,6 +71,7 @@ type ExecutionContext struct {
 	SessionID string
 	UserID    string
 	Platform  string
+	ChannelID string
 	Message   string
 	Matches   []string          // Regex capture groups
 	Variables map[string]string // Variables from previous actions
@@ -107,6 +112,11 @@ func NewRegistry(skillDir string) *Registry {
 	}
 }
 
+// Dir returns the directory JSON skills are loaded from and saved to
+func (r *Registry) Dir() string {
+	return r.skillDir
+}
+
 // RegisterExecutor registers an action executor
 func (r *Registry) RegisterExecutor(actionType ActionType, executor SkillExecutor) {
 	r.mu.Lock()
@@ -119,8 +129,8 @@ func (r *Registry) Register(skill *Skill) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if skill.ID == "" {
-		return fmt.Errorf("skill ID is required")
+	if err := ValidateSkill(skill); err != nil {
+		return err
 	}
 
 	if _, exists := r.skills[skill.ID]; exists {
@@ -312,6 +322,23 @@ func (r *Registry) LoadFromDirectory(dir string) error {
 	return nil
 }
 
+// Reload replaces the registered skills with the JSON skills currently in
+// the skill directory, so edited and deleted files take effect. Files that
+// fail to parse are skipped and reported in the returned error.
+func (r *Registry) Reload() error {
+	loaded, err := ReadSkillFiles(r.skillDir)
+	skills := make(map[string]*Skill, len(loaded))
+	for _, skill := range loaded {
+		skills[skill.ID] = skill
+	}
+
+	r.mu.Lock()
+	r.skills = skills
+	r.mu.Unlock()
+	log.Printf("[Skills] Reloaded %d skill(s) from %s", len(skills), r.skillDir)
+	return err
+}
+
 // LoadFromFile loads a skill from a JSON file
 func (r *Registry) LoadFromFile(path string) error {
 	data, err := os.ReadFile(path)
@@ -327,6 +354,69 @@ func (r *Registry) LoadFromFile(path string) error {
 	return r.Register(&skill)
 }
 
+// ReadSkillFiles parses the JSON skills in a directory without registering
+// them, sorted by ID. Files that fail to parse or validate are skipped and
+// reported in the returned error.
+func ReadSkillFiles(dir string) ([]*Skill, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read skill directory: %w", err)
+	}
+
+	var skills []*Skill
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		var skill Skill
+		if err := json.Unmarshal(data, &skill); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if err := ValidateSkill(&skill); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		skills = append(skills, &skill)
+	}
+
+	sort.Slice(skills, func(i, j int) bool { return skills[i].ID < skills[j].ID })
+	return skills, errors.Join(errs...)
+}
+
+// InstallSkillFile validates a JSON skill and writes it to dir as <id>.json,
+// replacing any previous version of the skill
+func InstallSkillFile(data []byte, dir string) (*Skill, error) {
+	var skill Skill
+	if err := json.Unmarshal(data, &skill); err != nil {
+		return nil, fmt.Errorf("failed to parse skill: %w", err)
+	}
+	if err := ValidateSkill(&skill); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create skill directory: %w", err)
+	}
+
+	path := filepath.Join(dir, skill.ID+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write skill file: %w", err)
+	}
+	return &skill, nil
+}
+
 // SaveToFile saves a skill to a JSON file
 func (r *Registry) SaveToFile(skillID string) error {
 	skill, ok := r.Get(skillID)
@@ -0,0 +1,263 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KnownOS lists the values metadata.os may contain
+var KnownOS = []string{"darwin", "linux", "windows"}
+
+// installKinds lists the supported InstallSpec kinds
+var installKinds = []string{"brew", "apt", "go", "npm", "download"}
+
+// installKindField is the InstallSpec field naming what each install kind installs
+var installKindField = map[string]string{
+	"brew":     "formula",
+	"apt":      "package",
+	"go":       "module",
+	"npm":      "package",
+	"download": "url",
+}
+
+// skillNamePattern is the form of names `skills new` accepts
+var skillNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ScaffoldOptions describes a new skill
+type ScaffoldOptions struct {
+	Name        string
+	Description string
+	Emoji       string
+	OS          []string
+	Bins        []string
+	AnyBins     []string
+	Env         []string
+	Install     []InstallSpec
+	OpenClaw    bool // nest metadata under "openclaw" for OpenClaw compatibility
+}
+
+// ParseInstallSpec parses an installer given as kind:target, e.g.
+// brew:jq, apt:jq, go:github.com/acme/tool/cmd/tool, npm:tool or
+// download:https://example.com/tool.tar.gz
+func ParseInstallSpec(s string) (InstallSpec, error) {
+	kind, target, ok := strings.Cut(s, ":")
+	if !ok || target == "" {
+		return InstallSpec{}, fmt.Errorf("invalid installer %q (want kind:target)", s)
+	}
+	spec := InstallSpec{Kind: kind}
+	switch installKindField[kind] {
+	case "formula":
+		spec.Formula = target
+	case "package":
+		spec.Package = target
+	case "module":
+		spec.Module = target
+	case "url":
+		spec.URL = target
+	default:
+		return InstallSpec{}, fmt.Errorf("unknown install kind %q (want one of %s)", kind, strings.Join(installKinds, ", "))
+	}
+	return spec, nil
+}
+
+// ValidateSkillName checks that a new skill's name can be used as its directory name
+func ValidateSkillName(name string) error {
+	if !skillNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid skill name %q: use lowercase letters, digits and hyphens", name)
+	}
+	return nil
+}
+
+// installTarget returns what an install spec installs: its formula,
+// package, module or URL
+func installTarget(spec InstallSpec) string {
+	switch installKindField[spec.Kind] {
+	case "formula":
+		return spec.Formula
+	case "package":
+		return spec.Package
+	case "module":
+		return spec.Module
+	case "url":
+		return spec.URL
+	}
+	return ""
+}
+
+// Validate checks the options before anything is written
+func (o ScaffoldOptions) Validate() error {
+	if err := ValidateSkillName(o.Name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(o.Description) == "" {
+		return fmt.Errorf("description is required")
+	}
+	for _, goos := range o.OS {
+		if !slices.Contains(KnownOS, goos) {
+			return fmt.Errorf("invalid os %q (want one of %s)", goos, strings.Join(KnownOS, ", "))
+		}
+	}
+	for _, name := range o.Env {
+		if !isAssignment(name + "=") {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+	if len(o.Install) > 0 && len(o.Bins) == 0 && len(o.AnyBins) == 0 {
+		return fmt.Errorf("installers need the binaries they provide (--bins or --any-bins)")
+	}
+	for _, spec := range o.Install {
+		if _, ok := installKindField[spec.Kind]; !ok {
+			return fmt.Errorf("unknown install kind %q", spec.Kind)
+		}
+	}
+	return nil
+}
+
+// metadata builds the skill metadata; installers default to providing the
+// required binaries
+func (o ScaffoldOptions) metadata() SkillMetadata {
+	m := SkillMetadata{
+		Emoji:    o.Emoji,
+		OS:       o.OS,
+		Requires: Requirements{Bins: o.Bins, AnyBins: o.AnyBins, Env: o.Env},
+	}
+	if m.Emoji == "" {
+		m.Emoji = "🧩"
+	}
+	for _, spec := range o.Install {
+		if spec.ID == "" {
+			spec.ID = spec.Kind
+		}
+		if len(spec.Bins) == 0 {
+			spec.Bins = slices.Concat(o.Bins, o.AnyBins)
+		}
+		if spec.Label == "" && spec.Kind != "download" {
+			spec.Label = fmt.Sprintf("Install %s (%s)", installTarget(spec), spec.Kind)
+		}
+		m.Install = append(m.Install, spec)
+	}
+	return m
+}
+
+// Scaffold creates a skill directory named after the skill in parent with
+// SKILL.md, an example script and a test file, and returns the path of the
+// SKILL.md. The written frontmatter is parsed back to make sure it loads as
+// intended.
+func Scaffold(parent string, opts ScaffoldOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(parent, opts.Name)
+	if _, err := os.Stat(dir); err == nil {
+		return "", fmt.Errorf("%s already exists", dir)
+	}
+
+	metadata := opts.metadata()
+	frontmatter := struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+		Version     string `yaml:"version"`
+		Metadata    any    `yaml:"metadata"`
+	}{Name: opts.Name, Description: opts.Description, Version: "0.1.0", Metadata: metadata}
+	if opts.OpenClaw {
+		frontmatter.Metadata = map[string]SkillMetadata{"openclaw": metadata}
+	}
+	fm, err := marshalYAML(frontmatter)
+	if err != nil {
+		return "", fmt.Errorf("failed to render frontmatter: %w", err)
+	}
+
+	suite, err := marshalYAML(scaffoldTestSuite(opts.Name))
+	if err != nil {
+		return "", fmt.Errorf("failed to render test file: %w", err)
+	}
+
+	files := []struct {
+		path    string
+		content string
+		mode    os.FileMode
+	}{
+		{"SKILL.md", "---\n" + fm + "---\n\n" + scaffoldBody(opts), 0644},
+		{"scripts/example.sh", fmt.Sprintf("#!/bin/sh\n# Example script for the %s skill. skill_script runs it from the\n# skill directory with SKILL_DIR set.\necho \"Hello from %s: $*\"\n", opts.Name, opts.Name), 0755},
+		{TestFileName, "# Run with: lingti-bot skills test " + opts.Name + "\n" + suite, 0644},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(f.content), f.mode); err != nil {
+			return "", err
+		}
+	}
+
+	path := filepath.Join(dir, "SKILL.md")
+	entry, err := ParseSkillMD(path)
+	if err != nil {
+		return path, err
+	}
+	if entry.Name != opts.Name || !reflect.DeepEqual(entry.Metadata, metadata) {
+		return path, fmt.Errorf("%s does not load back as written; check the frontmatter", path)
+	}
+	return path, nil
+}
+
+// escapedRune matches the \UXXXXXXXX escapes yaml.v3 writes for emoji
+var escapedRune = regexp.MustCompile(`\\U([0-9A-F]{8})`)
+
+// marshalYAML renders v with two-space indentation, keeping emoji readable
+func marshalYAML(v any) (string, error) {
+	var sb strings.Builder
+	enc := yaml.NewEncoder(&sb)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	enc.Close()
+	return escapedRune.ReplaceAllStringFunc(sb.String(), func(esc string) string {
+		code, _ := strconv.ParseUint(esc[2:], 16, 32)
+		return string(rune(code))
+	}), nil
+}
+
+// scaffoldBody is the Markdown instructions of a new skill
+func scaffoldBody(opts ScaffoldOptions) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n%s\n\n", opts.Name, opts.Description)
+	sb.WriteString("## When to Use\n\nDescribe the requests this skill handles.\n\n")
+	sb.WriteString("## Commands\n\n")
+	if bins := slices.Concat(opts.Bins, opts.AnyBins); len(bins) > 0 {
+		fmt.Fprintf(&sb, "Explain how to run `%s` for common tasks.\n\n", bins[0])
+	}
+	sb.WriteString("Run the example script with `skill_script`:\n\n```bash\nscripts/example.sh <name>\n```\n")
+	return sb.String()
+}
+
+// scaffoldTestSuite is a replayed test case that reads the skill and runs
+// its example script
+func scaffoldTestSuite(name string) TestSuite {
+	return TestSuite{Cases: []TestCase{{
+		Name:   "uses-skill",
+		Prompt: "Use the " + name + " skill to greet world",
+		Expect: TestExpect{
+			Tools: []ExpectedToolCall{
+				{Name: "skill_read", Args: map[string]string{"name": "^" + regexp.QuoteMeta(name) + "$"}},
+				{Name: "skill_script", Args: map[string]string{"script": "example"}},
+			},
+			AnswerContains: []string{"hello"},
+		},
+		Replay: []ReplayTurn{
+			{ToolCalls: []ToolCallData{{Name: "skill_read", Args: map[string]any{"name": name}}}},
+			{ToolCalls: []ToolCallData{{Name: "skill_script", Args: map[string]any{"name": name, "script": "scripts/example.sh", "args": []any{"world"}}}}},
+			{Text: "Hello from " + name + ": world"},
+		},
+	}}}
+}
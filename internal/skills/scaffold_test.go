@@ -0,0 +1,79 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	for _, openclaw := range []bool{false, true} {
+		parent := t.TempDir()
+		install, err := ParseInstallSpec("brew:jq")
+		if err != nil {
+			t.Fatalf("ParseInstallSpec: %v", err)
+		}
+		path, err := Scaffold(parent, ScaffoldOptions{
+			Name:        "json-tool",
+			Description: "Query JSON: with jq",
+			Emoji:       "🧪",
+			OS:          []string{"darwin", "linux"},
+			Bins:        []string{"jq"},
+			Env:         []string{"JQ_COLORS"},
+			Install:     []InstallSpec{install},
+			OpenClaw:    openclaw,
+		})
+		if err != nil {
+			t.Fatalf("Scaffold(openclaw=%v): %v", openclaw, err)
+		}
+
+		entry, err := ParseSkillMD(path)
+		if err != nil {
+			t.Fatalf("ParseSkillMD: %v", err)
+		}
+		m := entry.Metadata
+		if entry.Description != "Query JSON: with jq" || m.Emoji != "🧪" || len(m.OS) != 2 || m.Requires.Bins[0] != "jq" || m.Requires.Env[0] != "JQ_COLORS" {
+			t.Errorf("openclaw=%v: entry = %+v", openclaw, entry)
+		}
+		if len(m.Install) != 1 || m.Install[0].Formula != "jq" || m.Install[0].Bins[0] != "jq" || m.Install[0].Label != "Install jq (brew)" {
+			t.Errorf("openclaw=%v: install = %+v", openclaw, m.Install)
+		}
+		if data, _ := os.ReadFile(path); openclaw != strings.Contains(string(data), "openclaw:") {
+			t.Errorf("openclaw=%v: frontmatter:\n%s", openclaw, data)
+		}
+
+		if info, err := os.Stat(filepath.Join(parent, "json-tool", "scripts", "example.sh")); err != nil || info.Mode()&0111 == 0 {
+			t.Errorf("example script missing or not executable: %v", err)
+		}
+		suite, err := LoadTestSuite(*entry)
+		if err != nil || len(suite.Cases) != 1 || len(suite.Cases[0].Replay) == 0 {
+			t.Errorf("test suite = %+v, %v", suite, err)
+		}
+
+		if _, err := Scaffold(parent, ScaffoldOptions{Name: "json-tool", Description: "again"}); err == nil {
+			t.Error("expected an error for an existing skill")
+		}
+	}
+}
+
+func TestScaffoldValidate(t *testing.T) {
+	cases := map[string]ScaffoldOptions{
+		"invalid skill name":   {Name: "My Skill", Description: "x"},
+		"description":          {Name: "demo"},
+		"invalid os":           {Name: "demo", Description: "x", OS: []string{"macos"}},
+		"environment variable": {Name: "demo", Description: "x", Env: []string{"MY-KEY"}},
+		"installers need":      {Name: "demo", Description: "x", Install: []InstallSpec{{Kind: "npm", Package: "demo"}}},
+	}
+	for want, opts := range cases {
+		if err := opts.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate(%+v) = %v, want error containing %q", opts, err, want)
+		}
+	}
+	if _, err := ParseInstallSpec("pip:demo"); err == nil {
+		t.Error("expected an error for an unknown install kind")
+	}
+	if _, err := ParseInstallSpec("brew"); err == nil {
+		t.Error("expected an error for a missing target")
+	}
+}
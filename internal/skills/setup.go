@@ -0,0 +1,294 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// installKindOS lists the operating systems each install kind runs on.
+// Kinds not listed run anywhere their tool is available.
+var installKindOS = map[string][]string{
+	"brew": {"darwin", "linux"},
+	"apt":  {"linux"},
+}
+
+// installKindTool is the program each install kind needs
+var installKindTool = map[string]string{
+	"brew": "brew",
+	"apt":  "apt-get",
+	"go":   "go",
+	"npm":  "npm",
+}
+
+// SetupBinDir is where "download" installers put binaries
+func SetupBinDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lingti", "bin")
+}
+
+// UseSetupBinDir appends SetupBinDir to PATH, so commands and scripts find
+// downloaded binaries. Binaries already in PATH take precedence.
+func UseSetupBinDir() {
+	dir := SetupBinDir()
+	current := os.Getenv("PATH")
+	if slices.Contains(filepath.SplitList(current), dir) {
+		return
+	}
+	if current != "" {
+		dir = current + string(os.PathListSeparator) + dir
+	}
+	os.Setenv("PATH", dir)
+}
+
+// validBinName reports whether a declared binary name is a plain file name
+func validBinName(bin string) bool {
+	return bin != "" && bin != "." && bin != ".." && !strings.ContainsAny(bin, `/\`)
+}
+
+// SetupStep is one installer chosen for a skill
+type SetupStep struct {
+	Spec    InstallSpec
+	Command []string // program and arguments; empty for downloads
+	Dest    string   // download destination directory
+}
+
+// String renders the step as the shell command it runs
+func (s SetupStep) String() string {
+	if s.Spec.Kind == "download" {
+		return fmt.Sprintf("download %s → %s", s.Spec.URL, ShortenHomePath(s.Dest))
+	}
+	return strings.Join(s.Command, " ")
+}
+
+// SetupPlan is the set of installers that provide a skill's missing binaries
+type SetupPlan struct {
+	Skill   SkillStatus
+	Steps   []SetupStep
+	Skipped []string // install specs that can't run here, with the reason
+}
+
+// PlanSetup chooses install specs for a skill's missing binaries on the
+// current OS. Specs are tried in declaration order; a spec is used when its
+// tool is available and it provides a binary that is still missing (specs
+// that don't declare bins are assumed to provide everything).
+func PlanSetup(skill SkillStatus) (*SetupPlan, error) {
+	if skill.Status != StatusMissing {
+		return nil, fmt.Errorf("skill %s is %s, nothing to set up", skill.Name, skill.Status)
+	}
+	if len(skill.Missing.OS) > 0 {
+		return nil, fmt.Errorf("skill %s requires %s (this is %s)", skill.Name, strings.Join(skill.Missing.OS, "/"), RuntimeOS())
+	}
+
+	plan := &SetupPlan{Skill: skill}
+	needed := slices.Clone(skill.Missing.Bins)
+	needAny := len(skill.Missing.AnyBins) > 0
+	if len(needed) == 0 && !needAny {
+		return nil, fmt.Errorf("skill %s has no missing binaries (missing env: %s)", skill.Name, strings.Join(skill.Missing.Env, ", "))
+	}
+	if len(skill.Metadata.Install) == 0 {
+		return nil, fmt.Errorf("skill %s declares no installers", skill.Name)
+	}
+
+	for _, spec := range skill.Metadata.Install {
+		if len(needed) == 0 && !needAny {
+			break
+		}
+		provides := len(spec.Bins) == 0 ||
+			slices.ContainsFunc(spec.Bins, func(bin string) bool { return slices.Contains(needed, bin) }) ||
+			(needAny && slices.ContainsFunc(spec.Bins, func(bin string) bool { return slices.Contains(skill.Missing.AnyBins, bin) }))
+		if !provides {
+			continue
+		}
+		step, err := setupStep(spec)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s: %v", specLabel(spec), err))
+			continue
+		}
+		plan.Steps = append(plan.Steps, step)
+
+		if len(spec.Bins) == 0 {
+			needed, needAny = nil, false
+			continue
+		}
+		needed = slices.DeleteFunc(needed, func(bin string) bool { return slices.Contains(spec.Bins, bin) })
+		if slices.ContainsFunc(spec.Bins, func(bin string) bool { return slices.Contains(skill.Missing.AnyBins, bin) }) {
+			needAny = false
+		}
+	}
+
+	if len(plan.Steps) == 0 {
+		return nil, fmt.Errorf("no installer for skill %s works on %s: %s", skill.Name, RuntimeOS(), strings.Join(plan.Skipped, "; "))
+	}
+	if len(needed) > 0 {
+		plan.Skipped = append(plan.Skipped, "no installer provides: "+strings.Join(needed, ", "))
+	}
+	if needAny {
+		plan.Skipped = append(plan.Skipped, "no installer provides any of: "+strings.Join(skill.Missing.AnyBins, ", "))
+	}
+	return plan, nil
+}
+
+// specLabel names an install spec for messages
+func specLabel(spec InstallSpec) string {
+	if spec.Label != "" {
+		return spec.Label
+	}
+	if spec.ID != "" && spec.ID != spec.Kind {
+		return fmt.Sprintf("%s (%s)", spec.Kind, spec.ID)
+	}
+	return spec.Kind
+}
+
+// setupStep builds the command for an install spec, or reports why it can't
+// run on this machine
+func setupStep(spec InstallSpec) (SetupStep, error) {
+	if oses, ok := installKindOS[spec.Kind]; ok && !slices.Contains(oses, RuntimeOS()) {
+		return SetupStep{}, fmt.Errorf("not supported on %s", RuntimeOS())
+	}
+	if tool, ok := installKindTool[spec.Kind]; ok && !HasBinary(tool) {
+		return SetupStep{}, fmt.Errorf("%s not found in PATH", tool)
+	}
+
+	step := SetupStep{Spec: spec}
+	switch spec.Kind {
+	case "brew":
+		if spec.Formula == "" {
+			return SetupStep{}, fmt.Errorf("missing formula")
+		}
+		step.Command = []string{"brew", "install", spec.Formula}
+	case "apt":
+		if spec.Package == "" {
+			return SetupStep{}, fmt.Errorf("missing package")
+		}
+		step.Command = []string{"apt-get", "install", "-y", spec.Package}
+		if os.Geteuid() != 0 {
+			if !HasBinary("sudo") {
+				return SetupStep{}, fmt.Errorf("requires root and sudo is not available")
+			}
+			step.Command = append([]string{"sudo"}, step.Command...)
+		}
+	case "go":
+		if spec.Module == "" {
+			return SetupStep{}, fmt.Errorf("missing module")
+		}
+		module := spec.Module
+		if !strings.Contains(module, "@") {
+			module += "@latest"
+		}
+		step.Command = []string{"go", "install", module}
+	case "npm":
+		if spec.Package == "" {
+			return SetupStep{}, fmt.Errorf("missing package")
+		}
+		step.Command = []string{"npm", "install", "-g", spec.Package}
+	case "download":
+		if spec.URL == "" {
+			return SetupStep{}, fmt.Errorf("missing url")
+		}
+		if len(spec.Bins) == 0 {
+			return SetupStep{}, fmt.Errorf("download installers must declare bins")
+		}
+		for _, bin := range spec.Bins {
+			if !validBinName(bin) {
+				return SetupStep{}, fmt.Errorf("invalid binary name %q", bin)
+			}
+		}
+		step.Dest = SetupBinDir()
+	default:
+		return SetupStep{}, fmt.Errorf("unknown install kind %q", spec.Kind)
+	}
+	return step, nil
+}
+
+// Run executes the plan's steps in order, streaming installer output to out.
+// It stops at the first failing step.
+func (p *SetupPlan) Run(ctx context.Context, out io.Writer) error {
+	for _, step := range p.Steps {
+		fmt.Fprintf(out, "→ %s\n", step)
+		if step.Spec.Kind == "download" {
+			if err := runDownload(step); err != nil {
+				return fmt.Errorf("%s: %w", specLabel(step.Spec), err)
+			}
+			continue
+		}
+		cmd := exec.CommandContext(ctx, step.Command[0], step.Command[1:]...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = out
+		cmd.Stderr = out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", specLabel(step.Spec), err)
+		}
+	}
+	return nil
+}
+
+// Verify returns the binaries the plan should have provided that are still
+// not in PATH or SetupBinDir. When the skill only needed one of several binaries, finding
+// any of them is enough.
+func (p *SetupPlan) Verify() []string {
+	var missing []string
+	for _, bin := range p.Skill.Missing.Bins {
+		if !HasBinary(bin) {
+			missing = append(missing, bin)
+		}
+	}
+	if len(p.Skill.Missing.AnyBins) > 0 && !slices.ContainsFunc(p.Skill.Missing.AnyBins, HasBinary) {
+		missing = append(missing, strings.Join(p.Skill.Missing.AnyBins, "|"))
+	}
+	return missing
+}
+
+// runDownload fetches a binary or archive and places the spec's bins in Dest
+func runDownload(step SetupStep) error {
+	for _, bin := range step.Spec.Bins {
+		if !validBinName(bin) {
+			return fmt.Errorf("invalid binary name %q", bin)
+		}
+	}
+	if err := os.MkdirAll(step.Dest, 0755); err != nil {
+		return err
+	}
+
+	if !isArchive(step.Spec.URL) {
+		// A bare binary; it becomes the (single) declared bin
+		data, err := readLocation(step.Spec.URL)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(step.Dest, step.Spec.Bins[0]), data, 0755)
+	}
+
+	fetched, err := fetchArchive(skillSource{kind: sourceArchive, location: step.Spec.URL})
+	if err != nil {
+		return err
+	}
+	defer fetched.cleanup()
+
+	files, err := treeFiles(fetched.root)
+	if err != nil {
+		return err
+	}
+	for _, bin := range step.Spec.Bins {
+		idx := slices.IndexFunc(files, func(rel string) bool { return path.Base(filepath.ToSlash(rel)) == bin })
+		if idx == -1 {
+			return fmt.Errorf("archive does not contain %s", bin)
+		}
+		f, err := os.Open(filepath.Join(fetched.root, files[idx]))
+		if err != nil {
+			return err
+		}
+		err = writeFile(filepath.Join(step.Dest, bin), f, 0755)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package skills
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func missingSkill(install ...InstallSpec) SkillStatus {
+	return SkillStatus{
+		SkillEntry: SkillEntry{Name: "demo", Metadata: SkillMetadata{
+			Requires: Requirements{Bins: []string{"demo-cli"}},
+			Install:  install,
+		}},
+		Status:  StatusMissing,
+		Missing: MissingRequirements{Bins: []string{"demo-cli"}},
+	}
+}
+
+func TestPlanSetup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installers are shell scripts")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	os.WriteFile(filepath.Join(bin, "npm"), []byte("#!/bin/sh\n"), 0755)
+
+	plan, err := PlanSetup(missingSkill(
+		InstallSpec{Kind: "pip", Package: "demo", Bins: []string{"demo-cli"}},
+		InstallSpec{Kind: "go", Module: "example.com/demo", Bins: []string{"demo-cli"}},
+		InstallSpec{Kind: "npm", Package: "demo-cli", Bins: []string{"demo-cli"}},
+		InstallSpec{Kind: "download", URL: "https://example.com/demo", Bins: []string{"demo-cli"}},
+	))
+	if err != nil {
+		t.Fatalf("PlanSetup: %v", err)
+	}
+	if len(plan.Steps) != 1 || plan.Steps[0].String() != "npm install -g demo-cli" {
+		t.Errorf("steps = %v", plan.Steps)
+	}
+	if len(plan.Skipped) != 2 || !strings.Contains(plan.Skipped[0], "unknown install kind") || !strings.Contains(plan.Skipped[1], "go not found") {
+		t.Errorf("skipped = %v", plan.Skipped)
+	}
+
+	if _, err := PlanSetup(missingSkill(InstallSpec{Kind: "go", Module: "example.com/demo"})); err == nil {
+		t.Error("expected an error when no installer can run")
+	}
+	ready := missingSkill()
+	ready.Status = StatusReady
+	if _, err := PlanSetup(ready); err == nil {
+		t.Error("expected an error for a ready skill")
+	}
+}
+
+func TestSetupRunAndVerify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake installers are shell scripts")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	// A fake brew that "installs" the formula as an executable in PATH
+	brew := "#!/bin/sh\nprintf '#!/bin/sh\\n' > \"" + bin + "/$2\" && chmod +x \"" + bin + "/$2\"\n"
+	os.WriteFile(filepath.Join(bin, "brew"), []byte(brew), 0755)
+
+	plan, err := PlanSetup(missingSkill(InstallSpec{Kind: "brew", Formula: "demo-cli", Bins: []string{"demo-cli"}}))
+	if err != nil {
+		t.Fatalf("PlanSetup: %v", err)
+	}
+	if missing := plan.Verify(); len(missing) != 1 {
+		t.Fatalf("Verify before run = %v", missing)
+	}
+	if err := plan.Run(context.Background(), io.Discard); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if missing := plan.Verify(); len(missing) != 0 {
+		t.Errorf("Verify after run = %v", missing)
+	}
+}
+
+func TestSetupDownload(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	archive := filepath.Join(t.TempDir(), "demo.tar.gz")
+	os.WriteFile(archive, tarGz(t, map[string]string{
+		"demo-1.0/bin/demo-cli": "#!/bin/sh\necho demo\n",
+		"demo-1.0/README.md":    "demo",
+	}), 0644)
+
+	plan, err := PlanSetup(missingSkill(InstallSpec{Kind: "download", URL: archive, Bins: []string{"demo-cli"}}))
+	if err != nil {
+		t.Fatalf("PlanSetup: %v", err)
+	}
+	if err := plan.Run(context.Background(), io.Discard); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(SetupBinDir(), "demo-cli"))
+	if err != nil {
+		t.Fatalf("binary not installed: %v", err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Error("downloaded binary is not executable")
+	}
+	if missing := plan.Verify(); len(missing) != 0 {
+		t.Errorf("binary in %s not found: %v", SetupBinDir(), missing)
+	}
+
+	for _, bin := range []string{"../evil", "bin/evil", `..\evil`, ".."} {
+		if _, err := PlanSetup(missingSkill(InstallSpec{Kind: "download", URL: archive, Bins: []string{bin}})); err == nil {
+			t.Errorf("PlanSetup accepted binary name %q", bin)
+		}
+		step := SetupStep{Spec: InstallSpec{Kind: "download", URL: archive, Bins: []string{bin}}, Dest: SetupBinDir()}
+		if err := runDownload(step); err == nil {
+			t.Errorf("runDownload accepted binary name %q", bin)
+		}
+	}
+}
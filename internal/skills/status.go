@@ -25,25 +25,44 @@ type MissingRequirements struct {
 // SkillStatus is the full eligibility report for one skill
 type SkillStatus struct {
 	SkillEntry
-	Status  EligibilityStatus   `json:"status"`
-	Missing MissingRequirements `json:"missing"`
+	Status      EligibilityStatus   `json:"status"`
+	Missing     MissingRequirements `json:"missing"`
+	Diagnostics []Diagnostic        `json:"diagnostics,omitempty"` // frontmatter problems found by LintSkillMD
+
+	// Signature status recorded in the lockfile for managed skills installed with `skills install`
+	Signature SignatureStatus `json:"signature,omitempty"`
+	Publisher string          `json:"publisher,omitempty"`
 }
 
 // StatusReport is the full report for all discovered skills
 type StatusReport struct {
-	Skills       []SkillStatus `json:"skills"`
-	BundledDir   string        `json:"bundled_dir"`
-	ManagedDir   string        `json:"managed_dir"`
-	WorkspaceDir string        `json:"workspace_dir"`
+	Skills        []SkillStatus `json:"skills"`
+	TriggerSkills []*Skill      `json:"trigger_skills,omitempty"` // JSON trigger/action skills in the managed dir
+	BundledDir    string        `json:"bundled_dir"`
+	ManagedDir    string        `json:"managed_dir"`
+	WorkspaceDir  string        `json:"workspace_dir"`
+	ExtraDirs     []string      `json:"extra_dirs,omitempty"`
 }
 
 // BuildStatusReport discovers all skills and checks eligibility for each.
 func BuildStatusReport(disabledList []string, extraDirs []string) StatusReport {
 	entries := DiscoverSkills(disabledList, extraDirs)
 
+	// A broken lockfile only hides signature statuses; install and update report it
+	lock, _ := LoadLockFile(managedSkillsDir())
+
 	statuses := make([]SkillStatus, 0, len(entries))
 	for _, entry := range entries {
-		statuses = append(statuses, checkEligibility(entry))
+		status := checkEligibility(entry)
+		status.Diagnostics = LintSkillMD(entry.FilePath)
+		if locked, ok := lock.lookup(entry); ok {
+			status.Signature = locked.Signature
+			status.Publisher = locked.Publisher
+			if status.Signature == "" {
+				status.Signature = SignatureUnsigned
+			}
+		}
+		statuses = append(statuses, status)
 	}
 
 	workspaceDir := ""
@@ -51,14 +70,25 @@ func BuildStatusReport(disabledList []string, extraDirs []string) StatusReport {
 		workspaceDir = cwd + "/skills"
 	}
 
+	// Unreadable JSON skills are reported when the registry loads them
+	triggerSkills, _ := ReadSkillFiles(managedSkillsDir())
+
 	return StatusReport{
-		Skills:       statuses,
-		BundledDir:   resolveBundledSkillsDir(),
-		ManagedDir:   managedSkillsDir(),
-		WorkspaceDir: workspaceDir,
+		Skills:        statuses,
+		TriggerSkills: triggerSkills,
+		BundledDir:    resolveBundledSkillsDir(),
+		ManagedDir:    managedSkillsDir(),
+		WorkspaceDir:  workspaceDir,
+		ExtraDirs:     extraDirs,
 	}
 }
 
+// Lint validates every SKILL.md in the report's directories, including
+// files that failed to parse and so are not in Skills
+func (r StatusReport) Lint() []Diagnostic {
+	return LintDirs(slices.Concat([]string{r.BundledDir}, r.ExtraDirs, []string{r.ManagedDir, r.WorkspaceDir}))
+}
+
 // checkEligibility evaluates all gating rules for a skill entry.
 func checkEligibility(entry SkillEntry) SkillStatus {
 	status := SkillStatus{
@@ -132,6 +162,17 @@ func (r *StatusReport) CountByStatus() (eligible, disabled, missing int) {
 	return
 }
 
+// InvalidSkills returns skills whose frontmatter has errors
+func (r *StatusReport) InvalidSkills() []SkillStatus {
+	var result []SkillStatus
+	for _, s := range r.Skills {
+		if HasErrors(s.Diagnostics) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
 // EligibleSkills returns only skills that are ready to use
 func (r *StatusReport) EligibleSkills() []SkillStatus {
 	var result []SkillStatus
@@ -0,0 +1,228 @@
+package skills
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Skill test files live next to SKILL.md
+const (
+	TestFileName      = "SKILL.test.yaml"
+	RecordingFileName = "SKILL.test.replay.json" // provider responses saved by `skills test --record`
+)
+
+// TestSuite is the set of test cases for one skill
+type TestSuite struct {
+	Skill string     `yaml:"-"`
+	Path  string     `yaml:"-"`
+	Cases []TestCase `yaml:"cases"`
+}
+
+// TestCase sends one prompt to the agent and checks what it did
+type TestCase struct {
+	Name   string       `yaml:"name"`
+	Prompt string       `yaml:"prompt"`
+	Expect TestExpect   `yaml:"expect"`
+	Replay []ReplayTurn `yaml:"replay,omitempty"` // fake provider responses, used instead of a recording
+}
+
+// TestExpect holds a case's assertions
+type TestExpect struct {
+	Tools          []ExpectedToolCall `yaml:"tools,omitempty"`           // calls that must happen, in this order
+	NotTools       []string           `yaml:"not_tools,omitempty"`       // tools that must not be called
+	AnswerContains []string           `yaml:"answer_contains,omitempty"` // case-insensitive substrings of the final answer
+}
+
+// ExpectedToolCall matches a tool call by name, argument regexes and a
+// regex for the result the agent got
+type ExpectedToolCall struct {
+	Name   string            `yaml:"name"`
+	Args   map[string]string `yaml:"args,omitempty"`   // argument name -> regex
+	Result string            `yaml:"result,omitempty"` // regex
+}
+
+// ReplayTurn is one provider response: tool calls, or the final text
+type ReplayTurn struct {
+	Text      string         `yaml:"text,omitempty" json:"text,omitempty"`
+	ToolCalls []ToolCallData `yaml:"tool_calls,omitempty" json:"tool_calls,omitempty"`
+}
+
+// ToolCallData is a tool call made during a test, with the result the
+// agent got. Replays return the recorded result instead of running the tool.
+type ToolCallData struct {
+	Name   string         `yaml:"name" json:"name"`
+	Args   map[string]any `yaml:"args,omitempty" json:"args,omitempty"`
+	Result string         `yaml:"result,omitempty" json:"result,omitempty"`
+}
+
+// Transcript is what the agent did for one test case
+type Transcript struct {
+	ToolCalls []ToolCallData
+	Answer    string
+}
+
+// LoadTestSuite reads the test file of a skill. It returns an error wrapping
+// os.ErrNotExist when the skill has no tests.
+func LoadTestSuite(entry SkillEntry) (*TestSuite, error) {
+	path := filepath.Join(entry.BaseDir, TestFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	suite := &TestSuite{Skill: entry.Name, Path: path}
+	if err := yaml.Unmarshal(data, suite); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	seen := make(map[string]bool)
+	for i, c := range suite.Cases {
+		if c.Name == "" || c.Prompt == "" {
+			return nil, fmt.Errorf("%s: case %d requires name and prompt", path, i+1)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("%s: duplicate case name %q", path, c.Name)
+		}
+		seen[c.Name] = true
+		for _, tool := range c.Expect.Tools {
+			for arg, pattern := range tool.Args {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return nil, fmt.Errorf("%s: case %q: invalid regex for %s.%s: %w", path, c.Name, tool.Name, arg, err)
+				}
+			}
+			if _, err := regexp.Compile(tool.Result); err != nil {
+				return nil, fmt.Errorf("%s: case %q: invalid result regex for %s: %w", path, c.Name, tool.Name, err)
+			}
+		}
+	}
+	return suite, nil
+}
+
+// LoadRecordings reads the recorded provider responses next to a test file,
+// keyed by case name. A missing file has no recordings.
+func LoadRecordings(dir string) (map[string][]ReplayTurn, error) {
+	recordings := make(map[string][]ReplayTurn)
+	data, err := os.ReadFile(filepath.Join(dir, RecordingFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return recordings, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &recordings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", RecordingFileName, err)
+	}
+	return recordings, nil
+}
+
+// SaveRecordings writes recorded provider responses next to a test file
+func SaveRecordings(dir string, recordings map[string][]ReplayTurn) error {
+	data, err := json.MarshalIndent(recordings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, RecordingFileName), append(data, '\n'), 0644)
+}
+
+// Check returns the assertions the transcript fails, or nil if it passes
+func (c TestCase) Check(t Transcript) []string {
+	var failures []string
+
+	next := 0
+	for _, want := range c.Expect.Tools {
+		found := false
+		for next < len(t.ToolCalls) {
+			call := t.ToolCalls[next]
+			next++
+			if want.matches(call) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			failures = append(failures, fmt.Sprintf("expected tool call %s, got: %s", want, callNames(t.ToolCalls)))
+			break
+		}
+	}
+
+	for _, name := range c.Expect.NotTools {
+		for _, call := range t.ToolCalls {
+			if call.Name == name {
+				failures = append(failures, fmt.Sprintf("tool %s must not be called", name))
+				break
+			}
+		}
+	}
+
+	answer := strings.ToLower(t.Answer)
+	for _, text := range c.Expect.AnswerContains {
+		if !strings.Contains(answer, strings.ToLower(text)) {
+			failures = append(failures, fmt.Sprintf("answer does not contain %q", text))
+		}
+	}
+	return failures
+}
+
+// matches reports whether a call has the expected name, its arguments match
+// every regex and its result matches. Non-string arguments are matched in
+// their JSON form.
+func (e ExpectedToolCall) matches(call ToolCallData) bool {
+	if call.Name != e.Name {
+		return false
+	}
+	if e.Result != "" {
+		if matched, _ := regexp.MatchString(e.Result, call.Result); !matched {
+			return false
+		}
+	}
+	for arg, pattern := range e.Args {
+		value, ok := call.Args[arg]
+		if !ok {
+			return false
+		}
+		s, isString := value.(string)
+		if !isString {
+			data, _ := json.Marshal(value)
+			s = string(data)
+		}
+		if matched, _ := regexp.MatchString(pattern, s); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the expectation as name(arg=~pattern, ...) => ~result
+func (e ExpectedToolCall) String() string {
+	s := e.Name
+	if len(e.Args) > 0 {
+		parts := make([]string, 0, len(e.Args))
+		for _, arg := range slices.Sorted(maps.Keys(e.Args)) {
+			parts = append(parts, arg+"=~"+e.Args[arg])
+		}
+		s += "(" + strings.Join(parts, ", ") + ")"
+	}
+	if e.Result != "" {
+		s += " => ~" + e.Result
+	}
+	return s
+}
+
+// callNames lists the tools called, in order
+func callNames(calls []ToolCallData) string {
+	if len(calls) == 0 {
+		return "no tool calls"
+	}
+	names := make([]string, len(calls))
+	for i, call := range calls {
+		names[i] = call.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,74 @@
+package skills
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTestSuite(t *testing.T) {
+	dir := t.TempDir()
+	entry := SkillEntry{Name: "github", BaseDir: dir}
+	if _, err := LoadTestSuite(entry); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("missing test file error = %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, TestFileName), []byte(`
+cases:
+  - name: lists PRs
+    prompt: show my open PRs
+    expect:
+      tools:
+        - name: shell_execute
+          args: {command: "^gh pr list"}
+      answer_contains: [pull request]
+    replay:
+      - tool_calls:
+          - name: shell_execute
+            args: {command: gh pr list}
+      - text: You have 2 open pull requests.
+`), 0644)
+	suite, err := LoadTestSuite(entry)
+	if err != nil {
+		t.Fatalf("LoadTestSuite: %v", err)
+	}
+	if len(suite.Cases) != 1 || len(suite.Cases[0].Replay) != 2 || suite.Cases[0].Replay[0].ToolCalls[0].Args["command"] != "gh pr list" {
+		t.Errorf("suite = %+v", suite)
+	}
+
+	os.WriteFile(filepath.Join(dir, TestFileName), []byte("cases:\n  - name: bad\n    prompt: x\n    expect:\n      tools:\n        - name: t\n          args: {a: \"(\"}\n"), 0644)
+	if _, err := LoadTestSuite(entry); err == nil {
+		t.Error("expected an invalid regex to be rejected")
+	}
+}
+
+func TestTestCaseCheck(t *testing.T) {
+	tc := TestCase{Expect: TestExpect{
+		Tools: []ExpectedToolCall{
+			{Name: "skill_read", Args: map[string]string{"name": "^github$"}},
+			{Name: "shell_execute", Args: map[string]string{"command": "^gh pr list", "timeout": "^60$"}},
+		},
+		NotTools:       []string{"file_write"},
+		AnswerContains: []string{"Pull Request"},
+	}}
+
+	transcript := Transcript{
+		ToolCalls: []ToolCallData{
+			{Name: "skill_read", Args: map[string]any{"name": "github"}},
+			{Name: "shell_execute", Args: map[string]any{"command": "gh pr list -R acme/web", "timeout": float64(60)}},
+		},
+		Answer: "You have 2 open pull requests.",
+	}
+	if failures := tc.Check(transcript); len(failures) != 0 {
+		t.Errorf("expected pass, got %v", failures)
+	}
+
+	// Out of order calls, a forbidden tool and a wrong answer all fail
+	transcript.ToolCalls = []ToolCallData{transcript.ToolCalls[1], transcript.ToolCalls[0], {Name: "file_write"}}
+	transcript.Answer = "done"
+	if failures := tc.Check(transcript); len(failures) != 3 {
+		t.Errorf("expected 3 failures, got %v", failures)
+	}
+}
@@ -14,10 +14,11 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/pltanton/lingti-bot/internal/browser"
 	"github.com/pltanton/lingti-bot/internal/logger"
+	"github.com/pltanton/lingti-bot/internal/sites"
 )
 
 // BrowserStart launches a browser instance or connects to an existing Chrome.
-func BrowserStart(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserStart(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	opts := browser.StartOptions{
 		Headless: false,
 	}
@@ -35,7 +36,10 @@ func BrowserStart(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		opts.ConnectURL = c
 	}
 
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	logger.Debug("[browser_start] headless=%v url=%q cdp_url=%q executable=%q", opts.Headless, opts.URL, opts.ConnectURL, opts.ExecutablePath)
 
 	var startErr error
@@ -66,14 +70,21 @@ func BrowserStart(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 }
 
 // BrowserStop closes the browser or disconnects from external Chrome.
-func BrowserStop(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	b := browser.Instance()
+func BrowserStop(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	wasConnected := b.IsConnected()
 	logger.Debug("[browser_stop] connected=%v", wasConnected)
 	if err := b.Stop(); err != nil {
 		logger.Debug("[browser_stop] failed: %v", err)
 		return mcp.NewToolResultError(fmt.Sprintf("failed to stop browser: %v", err)), nil
 	}
+	if b.Session() != "" {
+		logger.Debug("[browser_stop] closed session %s", b.Session())
+		return mcp.NewToolResultText("Browser session closed"), nil
+	}
 	if wasConnected {
 		logger.Debug("[browser_stop] disconnected (Chrome still running)")
 		return mcp.NewToolResultText("Disconnected from browser (Chrome is still running)"), nil
@@ -83,21 +94,31 @@ func BrowserStop(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult,
 }
 
 // BrowserStatus returns the current browser state.
-func BrowserStatus(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	info := browser.Instance().Status()
+func BrowserStatus(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	info := b.Status()
 	data, _ := json.Marshal(info)
 	return mcp.NewToolResultText(string(data)), nil
 }
 
 // BrowserNavigate navigates to a URL.
-func BrowserNavigate(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserNavigate(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	url, ok := req.Params.Arguments["url"].(string)
 	if !ok || url == "" {
 		return mcp.NewToolResultError("url is required"), nil
 	}
 
 	logger.Debug("[browser_navigate] url=%q", url)
-	b := browser.Instance()
+	if err := sites.Default().CheckNavigate(url); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("refusing to navigate: %v", err)), nil
+	}
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	if err := b.EnsureRunning(); err != nil {
 		logger.Debug("[browser_navigate] EnsureRunning failed: %v", err)
 		return mcp.NewToolResultError(fmt.Sprintf("failed to start browser: %v", err)), nil
@@ -133,9 +154,12 @@ func BrowserNavigate(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 }
 
 // BrowserSnapshot captures the accessibility tree with numbered refs.
-func BrowserSnapshot(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserSnapshot(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("[browser_snapshot] capturing accessibility tree...")
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	if err := b.EnsureRunning(); err != nil {
 		logger.Debug("[browser_snapshot] EnsureRunning failed: %v", err)
 		return mcp.NewToolResultError(fmt.Sprintf("failed to start browser: %v", err)), nil
@@ -183,9 +207,12 @@ func BrowserSnapshot(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolRes
 }
 
 // BrowserScreenshot captures a screenshot of the current page.
-func BrowserScreenshot(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserScreenshot(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("[browser_screenshot] capturing...")
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	if err := b.EnsureRunning(); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to start browser: %v", err)), nil
 	}
@@ -258,14 +285,17 @@ func truncateSnapshot(snapshot string, maxLines int) string {
 }
 
 // BrowserClick clicks an element by ref number.
-func BrowserClick(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserClick(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ref, ok := req.Params.Arguments["ref"].(float64)
 	if !ok {
 		return mcp.NewToolResultError("ref is required (number)"), nil
 	}
 
 	logger.Debug("[browser_click] ref=%d", int(ref))
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	page, err := b.ActivePage()
 	if err != nil {
 		logger.Debug("[browser_click] ActivePage failed: %v", err)
@@ -337,7 +367,7 @@ func BrowserClick(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 }
 
 // BrowserType types text into an element by ref number.
-func BrowserType(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserType(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ref, ok := req.Params.Arguments["ref"].(float64)
 	if !ok {
 		return mcp.NewToolResultError("ref is required (number)"), nil
@@ -353,7 +383,10 @@ func BrowserType(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResul
 	}
 
 	logger.Debug("[browser_type] ref=%d text=%q submit=%v", int(ref), text, submit)
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	page, err := b.ActivePage()
 	if err != nil {
 		logger.Debug("[browser_type] ActivePage failed: %v", err)
@@ -420,13 +453,16 @@ func BrowserType(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResul
 }
 
 // BrowserPress presses a keyboard key.
-func BrowserPress(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserPress(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	key, ok := req.Params.Arguments["key"].(string)
 	if !ok || key == "" {
 		return mcp.NewToolResultError("key is required (e.g., Enter, Tab, Escape)"), nil
 	}
 
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	page, err := b.ActivePage()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get page: %v", err)), nil
@@ -442,356 +478,39 @@ func BrowserPress(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 	return mcp.NewToolResultText(fmt.Sprintf("Pressed %s", key)), nil
 }
 
-// BrowserCommentZhihu posts a comment on a Zhihu answer using the verified JS recipe.
-// It expands the first answer's comment section, types the comment, and submits.
-// Optional reply_to param: username to reply to (nested reply) instead of posting a top-level comment.
-func BrowserCommentZhihu(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	comment, ok := req.Params.Arguments["comment"].(string)
-	if !ok || comment == "" {
-		return mcp.NewToolResultError("comment is required"), nil
+// BrowserSiteAction runs a site adapter action, such as "zhihu.comment", on
+// the active page. The page must belong to the adapter's site.
+func BrowserSiteAction(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, _ := req.Params.Arguments["action"].(string)
+	if name == "" {
+		return mcp.NewToolResultError("action is required, e.g. zhihu.comment"), nil
 	}
-	replyTo, _ := req.Params.Arguments["reply_to"].(string)
+	args, _ := req.Params.Arguments["args"].(map[string]any)
 
-	b := browser.Instance()
-	page, err := b.ActivePage()
+	adapter, action, err := sites.Default().Action(name)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to get page: %v", err)), nil
-	}
-
-	var r1 string
-
-	if replyTo != "" {
-		// Nested reply mode: find the 回复 button next to the specified user's comment and click it.
-		jsonReplyTo, _ := json.Marshal(replyTo)
-		r1, err = browser.ExecuteJS(page, fmt.Sprintf(`
-			var username = %s;
-			// Find links whose text exactly matches the username (avatar + name links both match)
-			var userEls = Array.from(document.querySelectorAll('a')).filter(function(a) {
-				return a.textContent.trim() === username;
-			});
-			if (!userEls.length) { return 'user not found: ' + username; }
-
-			// Collect all 回复 buttons on the page
-			var replyBtns = Array.from(document.querySelectorAll('button')).filter(function(b) {
-				return b.textContent.replace(/\u200b/g,'').trim() === '回复';
-			});
-			if (!replyBtns.length) { return 'no reply buttons found'; }
-
-			// Find the first 回复 button that follows the first username element in DOM order
-			var userEl = userEls[0];
-			var found = null;
-			for (var i = 0; i < replyBtns.length; i++) {
-				if (userEl.compareDocumentPosition(replyBtns[i]) & Node.DOCUMENT_POSITION_FOLLOWING) {
-					found = replyBtns[i];
-					break;
-				}
-			}
-			if (found) { found.click(); return 'clicked reply for: ' + username; }
-			return 'reply button not found after: ' + username;
-		`, string(jsonReplyTo)))
-		logger.Debug("[browser_comment_zhihu] reply_to step1: %s", r1)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("reply_to step failed: %v", err)), nil
-		}
-		if !strings.HasPrefix(r1, "clicked") {
-			return mcp.NewToolResultError(r1), nil
-		}
-	} else {
-		// Top-level comment mode: open the article/answer comment editor.
-		// Step 1a: if editor is already open, skip opening it.
-		// Otherwise click "添加评论" directly if visible, else click "X条评论" to expand
-		// the comment section first, then click "添加评论" inside it.
-		r1, err = browser.ExecuteJS(page, `
-			// Already open?
-			if (document.querySelector('.public-DraftEditor-content')) { return 'editor already open'; }
-
-			// Prefer clicking "添加评论" directly (zhuanlan pages show this right away)
-			var addBtn = Array.from(document.querySelectorAll('button,span,a')).find(function(e) {
-				return e.textContent.replace(/\u200b/g,'').trim() === '添加评论';
-			});
-			if (addBtn) { addBtn.click(); return 'clicked 添加评论'; }
-
-			// On question pages "X条评论" toggles the list; "添加评论" appears inside.
-			// Click the first answer's comment toggle to expand it.
-			// Exclude "收起评论" (collapse) — we must not collapse an already-open section.
-			var toggleBtn = Array.from(document.querySelectorAll('button,span')).find(function(e) {
-				var t = e.textContent.replace(/\u200b/g,'').trim();
-				if (t.indexOf('收起') !== -1) return false;
-				return /^[\d]+\s*条评论$/.test(t) || t === '评论';
-			});
-			if (toggleBtn) { toggleBtn.click(); return 'expanded: ' + toggleBtn.textContent.trim(); }
-
-			return 'no comment button found';
-		`)
-		logger.Debug("[browser_comment_zhihu] step1a: %s", r1)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("step1a failed: %v", err)), nil
-		}
-
-		// Step 1b: if we expanded comments (not directly clicked 添加评论), now find and
-		// click the "添加评论" input placeholder that appears inside the expanded section.
-		// Poll up to 4s for it to appear.
-		// IMPORTANT: textContent is recursive, so querying 'div' would match any ancestor
-		// that contains "添加评论" in its subtree. We restrict to elements that either:
-		//   (a) have no child elements (leaf nodes), or
-		//   (b) are known interactive types (button, span, a)
-		//   (c) are the DraftEditor placeholder div specifically
-		if r1 != "editor already open" && r1 != "clicked 添加评论" {
-			var addClicked bool
-			for range 20 {
-				time.Sleep(200 * time.Millisecond)
-				res, _ := browser.ExecuteJS(page, `
-					// Already open after expand?
-					if (document.querySelector('.public-DraftEditor-content')) { return 'editor appeared'; }
-
-					// Specific known selectors for the comment input area on Zhihu question pages
-					var specific = document.querySelector(
-						'.CommentInput, [class*="CommentInput"], ' +
-						'.DraftEditor-root, [class*="comment-input"], ' +
-						'[placeholder="添加评论"], [data-placeholder="添加评论"]'
-					);
-					if (specific) { specific.click(); return 'clicked specific'; }
-
-					// button/span/a with exact text — safe (not recursive parent match)
-					var btn = Array.from(document.querySelectorAll('button,span,a')).find(function(e) {
-						return e.textContent.replace(/\u200b/g,'').trim() === '添加评论';
-					});
-					if (btn) { btn.click(); return 'clicked btn: ' + btn.tagName; }
-
-					// Leaf div/label with exact text (no child elements that also contain the text)
-					var leaf = Array.from(document.querySelectorAll('div,label')).find(function(e) {
-						if (e.textContent.replace(/\u200b/g,'').trim() !== '添加评论') return false;
-						// Make sure no child element also has this text (i.e. we are the real target)
-						return !Array.from(e.children).some(function(c) {
-							return c.textContent.replace(/\u200b/g,'').trim() === '添加评论';
-						});
-					});
-					if (leaf) { leaf.click(); return 'clicked leaf: ' + leaf.className.slice(0,40); }
-
-					return 'waiting';
-				`)
-				if res != "waiting" {
-					addClicked = true
-					break
-				}
-			}
-			if !addClicked {
-				return mcp.NewToolResultError(fmt.Sprintf("could not find 添加评论 after expanding comments (step1=%s)", r1)), nil
-			}
-		}
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// Step 1c: wait for an editor to appear (poll up to 4s).
-	// Zhihu article top-level comments use Draft.js (.public-DraftEditor-content).
-	// Nested replies use a plain textarea/contenteditable.
-	var editorType string
-	if r1 == "editor already open" {
-		editorType = "draftjs"
-	} else {
-		for range 20 {
-			time.Sleep(200 * time.Millisecond)
-			editorType, _ = browser.ExecuteJS(page, `
-				if (document.querySelector('.public-DraftEditor-content')) { return 'draftjs'; }
-				var el = document.activeElement;
-				if (el && (el.tagName === 'TEXTAREA' || el.contentEditable === 'true' || el.getAttribute('role') === 'textbox')) {
-					return 'plain:' + el.tagName;
-				}
-				var ta = document.querySelector('textarea');
-				if (ta) { return 'plain:TEXTAREA'; }
-				return 'waiting';
-			`)
-			if editorType != "waiting" {
-				break
-			}
-		}
-	}
-	if editorType == "waiting" || editorType == "" {
-		return mcp.NewToolResultError(fmt.Sprintf("editor did not appear (step1=%s)", r1)), nil
-	}
-
-	// Step 2: insert text via ClipboardEvent paste.
-	// For Draft.js: targets .public-DraftEditor-content
-	// For plain textarea/contenteditable: targets the active element or first textarea.
-	// ClipboardEvent paste works for both Draft.js and React-controlled plain editors;
-	// execCommand('insertText') is used as fallback for plain editors.
-	jsonComment, _ := json.Marshal(comment)
-	var pasteScript string
-	if editorType == "draftjs" {
-		pasteScript = fmt.Sprintf(`
-			var ed = document.querySelector('.public-DraftEditor-content');
-			if (!ed) { return 'editor not found'; }
-			ed.click(); ed.focus();
-			document.execCommand('selectAll', false);
-			var dt = new DataTransfer();
-			dt.setData('text/plain', %s);
-			ed.dispatchEvent(new ClipboardEvent('paste', { clipboardData: dt, bubbles: true, cancelable: true }));
-			return 'pasted-draftjs';
-		`, string(jsonComment))
-	} else {
-		pasteScript = fmt.Sprintf(`
-			var ed = document.activeElement;
-			if (!ed || (ed.tagName !== 'TEXTAREA' && ed.contentEditable !== 'true' && ed.getAttribute('role') !== 'textbox')) {
-				ed = document.querySelector('textarea') || document.querySelector('[contenteditable="true"]');
-			}
-			if (!ed) { return 'editor not found'; }
-			ed.focus();
-			var dt = new DataTransfer();
-			dt.setData('text/plain', %s);
-			var pasted = ed.dispatchEvent(new ClipboardEvent('paste', { clipboardData: dt, bubbles: true, cancelable: true }));
-			// Fallback: execCommand for plain contenteditable
-			if (ed.tagName === 'TEXTAREA' || ed.contentEditable === 'true') {
-				document.execCommand('insertText', false, %s);
-			}
-			return 'pasted-plain';
-		`, string(jsonComment), string(jsonComment))
-	}
-	r2, err := browser.ExecuteJS(page, pasteScript)
-	logger.Debug("[browser_comment_zhihu] step2 paste: %s (editorType=%s)", r2, editorType)
-	if err != nil || r2 == "editor not found" {
-		return mcp.NewToolResultError(fmt.Sprintf("step2 paste failed: %v %s", err, r2)), nil
-	}
-
-	// Wait for the editor framework to process the paste and re-render
-	time.Sleep(600 * time.Millisecond)
-
-	// Step 3: click the 发布 submit button.
-	// IMPORTANT: document.querySelector('button.Button--primary') matches the search bar button first.
-	// Must find specifically the button with text "发布".
-	r3, err := browser.ExecuteJS(page, `
-		var btn = Array.from(document.querySelectorAll('button')).find(function(b){ return b.textContent.replace(/\u200b/g,'').trim() === '发布'; });
-		if (btn && !btn.disabled) { btn.click(); return 'submitted'; }
-		if (btn && btn.disabled) { return 'submit button is disabled (comment may be empty)'; }
-		return 'submit button not found';
-	`)
-	logger.Debug("[browser_comment_zhihu] step3 submit: %s", r3)
+	b, err := browser.For(ctx)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("step3 submit failed: %v", err)), nil
-	}
-
-	if r3 == "submitted" {
-		if replyTo != "" {
-			return mcp.NewToolResultText(fmt.Sprintf("Reply to %q posted successfully: %q", replyTo, comment)), nil
-		}
-		return mcp.NewToolResultText(fmt.Sprintf("Comment posted successfully: %q", comment)), nil
-	}
-	return mcp.NewToolResultError(fmt.Sprintf("comment may not have submitted: %s (expand=%s, paste=%s)", r3, r1, r2)), nil
-}
-
-// BrowserCommentXiaohongshu posts a comment on a Xiaohongshu note using the verified JS recipe.
-// The note detail page must already be open. This tool handles the full flow:
-// 1. Click "说点什么..." or "评论" to activate the comment editor
-// 2. Paste text via ClipboardEvent (the only method that enables the 发送 button)
-// 3. Click 发送 to submit
-func BrowserCommentXiaohongshu(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	comment, ok := req.Params.Arguments["comment"].(string)
-	if !ok || comment == "" {
-		return mcp.NewToolResultError("comment is required"), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-
-	b := browser.Instance()
 	page, err := b.ActivePage()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get page: %v", err)), nil
 	}
-
-	// Step 1: activate the comment editor.
-	// The editor may not be focused/visible yet. Click "说点什么..." placeholder,
-	// "评论" button, or the editor itself to activate it.
-	r1, err := browser.ExecuteJS(page, `
-		// Already have editor?
-		var ed = document.querySelector('#content-textarea');
-		if (ed && ed.textContent.trim() !== '' && ed === document.activeElement) {
-			return 'editor already active';
-		}
-
-		// Try clicking "说点什么..." placeholder (the comment input area at the bottom)
-		var placeholder = document.querySelector('.comment-input, .input-box, [class*="comment-input"]');
-		if (placeholder) { placeholder.click(); return 'clicked comment input area'; }
-
-		// Try clicking "评论" button in the bottom action bar
-		var commentBtn = Array.from(document.querySelectorAll('span, button, div')).find(function(e) {
-			var t = e.textContent.trim();
-			return t === '评论' && e.children.length <= 2;
-		});
-		if (commentBtn) { commentBtn.click(); return 'clicked 评论 button'; }
-
-		// Editor exists but just needs focus
-		if (ed) { ed.click(); ed.focus(); return 'focused editor'; }
-
-		return 'no comment entry found';
-	`)
-	logger.Debug("[browser_comment_xiaohongshu] step1 activate: %s", r1)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("step1 failed: %v", err)), nil
-	}
-	if r1 == "no comment entry found" {
-		return mcp.NewToolResultError("comment editor not found — make sure you are on a Xiaohongshu note detail page"), nil
+	if info, err := page.Info(); err == nil && !adapter.Matches(info.URL) {
+		return mcp.NewToolResultError(fmt.Sprintf("the current page %s is not on %s; open it first", info.URL, adapter.DisplayName())), nil
 	}
 
-	// Wait for editor to appear/activate after clicking
-	if r1 != "editor already active" {
-		time.Sleep(500 * time.Millisecond)
-	}
-
-	// Step 1b: verify editor is present (poll up to 3s)
-	var editorReady bool
-	for range 15 {
-		check, _ := browser.ExecuteJS(page, `document.querySelector('#content-textarea') ? 'yes' : 'no'`)
-		if check == "yes" {
-			editorReady = true
-			break
-		}
-		time.Sleep(200 * time.Millisecond)
-	}
-	if !editorReady {
-		return mcp.NewToolResultError("comment editor (#content-textarea) did not appear after activation"), nil
-	}
-
-	// Step 2: paste comment text via ClipboardEvent.
-	// Setting textContent or innerText directly does NOT update the framework state,
-	// so the 发送 button stays disabled. Dispatching a paste ClipboardEvent triggers
-	// the framework's internal handler which enables the button.
-	jsonComment, _ := json.Marshal(comment)
-	r2, err := browser.ExecuteJS(page, fmt.Sprintf(`
-		var ed = document.querySelector('#content-textarea');
-		if (!ed) { return 'editor not found'; }
-		ed.click();
-		ed.focus();
-		// Clear existing content first
-		ed.textContent = '';
-		ed.dispatchEvent(new Event('input', { bubbles: true }));
-		// Paste new content
-		var dt = new DataTransfer();
-		dt.setData('text/plain', %s);
-		ed.dispatchEvent(new ClipboardEvent('paste', { clipboardData: dt, bubbles: true, cancelable: true }));
-		return 'pasted';
-	`, string(jsonComment)))
-	logger.Debug("[browser_comment_xiaohongshu] step2 paste: %s", r2)
-	if err != nil || r2 == "editor not found" {
-		return mcp.NewToolResultError(fmt.Sprintf("step2 paste failed: %v %s", err, r2)), nil
-	}
-
-	// Wait for framework to process the paste event
-	time.Sleep(600 * time.Millisecond)
-
-	// Step 3: click the 发送 submit button.
-	r3, err := browser.ExecuteJS(page, `
-		var btn = Array.from(document.querySelectorAll('button')).find(function(b) {
-			return b.textContent.trim() === '发送';
-		});
-		if (btn && !btn.disabled) { btn.click(); return 'submitted'; }
-		if (btn && btn.disabled) { return 'submit button is disabled (comment may be empty or framework did not register paste)'; }
-		return 'submit button not found';
-	`)
-	logger.Debug("[browser_comment_xiaohongshu] step3 submit: %s", r3)
+	logger.Debug("[browser_site_action] %s args=%v", name, args)
+	result, err := action.Run(ctx, sites.RodPage(page), args)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("step3 submit failed: %v", err)), nil
-	}
-
-	if r3 == "submitted" {
-		return mcp.NewToolResultText(fmt.Sprintf("Comment posted successfully on Xiaohongshu: %q", comment)), nil
+		logger.Debug("[browser_site_action] %s failed: %v", name, err)
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	return mcp.NewToolResultError(fmt.Sprintf("comment may not have submitted: %s (paste=%s)", r3, r2)), nil
+	return mcp.NewToolResultText(result), nil
 }
 
 // visitedURLs tracks URLs that have been processed during iterative browser operations.
@@ -811,8 +530,8 @@ func BrowserVisited(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		if url == "" {
 			return mcp.NewToolResultError("url is required for check action"), nil
 		}
-		// Normalize: strip query params for Xiaohongshu note URLs
-		normalized := normalizeXHSURL(url)
+		// Normalize with the site's adapter, e.g. drop Xiaohongshu's xsec_token
+		normalized := sites.Default().Normalize(url)
 		visitedURLs.Lock()
 		visited := visitedURLs.urls[normalized]
 		visitedURLs.Unlock()
@@ -826,7 +545,7 @@ func BrowserVisited(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		if url == "" {
 			return mcp.NewToolResultError("url is required for mark action"), nil
 		}
-		normalized := normalizeXHSURL(url)
+		normalized := sites.Default().Normalize(url)
 		visitedURLs.Lock()
 		visitedURLs.urls[normalized] = true
 		count := len(visitedURLs.urls)
@@ -856,28 +575,17 @@ func BrowserVisited(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	}
 }
 
-// normalizeXHSURL strips xsec_token and other query params from Xiaohongshu URLs
-// so the same note is recognized regardless of how it was navigated to.
-func normalizeXHSURL(rawURL string) string {
-	// Extract the note ID path for xiaohongshu.com URLs
-	if strings.Contains(rawURL, "xiaohongshu.com") {
-		// URLs look like: https://www.xiaohongshu.com/explore/697ec7e7000000002202d5cc?xsec_token=...
-		// or: https://www.xiaohongshu.com/search_result/697ec7e7000000002202d5cc?xsec_token=...
-		if idx := strings.Index(rawURL, "?"); idx != -1 {
-			return rawURL[:idx]
-		}
-	}
-	return rawURL
-}
-
 // BrowserExecuteJS runs JavaScript on the active page.
-func BrowserExecuteJS(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserExecuteJS(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	script, ok := req.Params.Arguments["script"].(string)
 	if !ok || script == "" {
 		return mcp.NewToolResultError("script is required"), nil
 	}
 
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	if err := b.EnsureRunning(); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to start browser: %v", err)), nil
 	}
@@ -899,7 +607,7 @@ func BrowserExecuteJS(_ context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 }
 
 // BrowserClickAll clicks all elements matching a CSS selector with delay.
-func BrowserClickAll(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func BrowserClickAll(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	selector, ok := req.Params.Arguments["selector"].(string)
 	if !ok || selector == "" {
 		return mcp.NewToolResultError("selector is required (CSS selector)"), nil
@@ -910,7 +618,10 @@ func BrowserClickAll(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		delay = time.Duration(d) * time.Millisecond
 	}
 
-	b := browser.Instance()
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	page, err := b.ActivePage()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get page: %v", err)), nil
@@ -933,13 +644,16 @@ func BrowserClickAll(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 }
 
 // BrowserTabs lists all open tabs.
-func BrowserTabs(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	b := browser.Instance()
+func BrowserTabs(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	if err := b.EnsureRunning(); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to start browser: %v", err)), nil
 	}
 
-	pages, err := b.Rod().Pages()
+	pages, err := b.Pages()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list tabs: %v", err)), nil
 	}
@@ -968,8 +682,11 @@ func BrowserTabs(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult,
 }
 
 // BrowserTabOpen opens a new tab.
-func BrowserTabOpen(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	b := browser.Instance()
+func BrowserTabOpen(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	if err := b.EnsureRunning(); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to start browser: %v", err)), nil
 	}
@@ -995,8 +712,11 @@ func BrowserTabOpen(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 }
 
 // BrowserTabClose closes a tab by target ID or the active tab.
-func BrowserTabClose(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	b := browser.Instance()
+func BrowserTabClose(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	b, err := browser.For(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	if err := b.EnsureRunning(); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to start browser: %v", err)), nil
 	}
@@ -1006,7 +726,7 @@ func BrowserTabClose(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		targetID = t
 	}
 
-	pages, err := b.Rod().Pages()
+	pages, err := b.Pages()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list tabs: %v", err)), nil
 	}
@@ -101,7 +101,7 @@ func NetworkPing(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 		port = p
 	}
 
-	address := fmt.Sprintf("%s:%s", host, port)
+	address := gonet.JoinHostPort(host, port)
 
 	start := time.Now()
 	conn, err := gonet.DialTimeout("tcp", address, time.Duration(timeout)*time.Second)